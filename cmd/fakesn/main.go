@@ -0,0 +1,43 @@
+package main
+
+import (
+	"autoposting/pkg/fakesn"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8090", "listen address")
+	groups := flag.Int("groups", 3, "groups administered by the user in each social network")
+	tokenTTL := flag.Duration("token-ttl", 0, "lifetime of issued tokens, 0 for non-expiring tokens")
+	flag.Parse()
+
+	server := fakesn.New(fakesn.Options{
+		GroupsPerNetwork: *groups,
+		TokenTTL:         *tokenTTL,
+	})
+
+	env := fakesn.Env(fmt.Sprintf("http://%s", *addr))
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	log.Printf("fake social networks listen on %s, point the app at them with:", *addr)
+	for _, name := range names {
+		fmt.Printf("%s=%s\n", name, env[name])
+	}
+
+	srv := http.Server{
+		Addr:              *addr,
+		Handler:           server,
+		ReadHeaderTimeout: 2 * time.Second,
+	}
+	if err := srv.ListenAndServe(); err != nil {
+		log.Fatal("failed to run fake social networks: ", err)
+	}
+}
@@ -27,6 +27,14 @@ func Run(ctx context.Context, config *Config) error {
 
 	cnt.Logger.Debug("Init config", slog.Any("config", config))
 
+	go app.runPostsReconciliation(ctx)
+	go app.runPageHistoryImports(ctx)
+	go app.runPostsMetricsCollection(ctx)
+	go app.runPagesAudienceCollection(ctx)
+	go app.runWatchedPagesCollection(ctx)
+	go app.runCommentsCollection(ctx)
+	go app.runScheduledPosts(ctx)
+
 	app.initAppServer()
 	beforeShutdown := func() {}
 	if err := app.server.Run(ctx, config.ServerAddr, config.IsProd, beforeShutdown); err != nil {
@@ -1,9 +1,29 @@
 package app
 
 import (
+	"autoposting/internal/app/usecase"
+	"autoposting/internal/infrastructure/social_network_client"
 	ewrap "autoposting/pkg/err-wrapper"
+	"errors"
+	"fmt"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/joho/godotenv"
+	"net/url"
 	"os"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPublicURL            = "http://localhost:8080"
+	defaultReconcileInterval    = time.Hour
+	defaultReconcileWindow      = 72 * time.Hour
+	defaultMetricsInterval      = time.Hour
+	defaultMetricsWindow        = 7 * 24 * time.Hour
+	defaultAudienceInterval     = 24 * time.Hour
+	defaultWatchedPagesInterval = 24 * time.Hour
+	defaultCommentsInterval     = 10 * time.Minute
+	defaultCommentsWindow       = 7 * 24 * time.Hour
 )
 
 type Config struct {
@@ -11,16 +31,218 @@ type Config struct {
 	IsProd      bool
 	LogLevel    string
 	ServerAddr  string
+	// PublicURL адрес приложения снаружи, из него строятся OAuth redirect url
+	PublicURL string
+	// SocialNetworks настройки клиентов по имени соц сети
+	SocialNetworks map[string]social_network_client.ClientConfig
+	// DryRun createPost только собирает запросы к соц сетям и ничего не публикует
+	DryRun bool
+	// Events секреты приема уведомлений VK Callback API и Facebook Webhooks
+	Events usecase.EventsConfig
+	// ReconcileInterval период сверки опубликованных постов с соц сетями, 0 - сверка выключена
+	ReconcileInterval time.Duration
+	// ReconcileWindow сверяются посты, опубликованные не раньше этого срока
+	ReconcileWindow time.Duration
+	// MetricsInterval период сбора метрик опубликованных постов, 0 - сбор выключен
+	MetricsInterval time.Duration
+	// MetricsWindow метрики собираются по постам, опубликованным не раньше этого срока
+	MetricsWindow time.Duration
+	// AudienceInterval период сбора числа подписчиков страниц, 0 - сбор выключен
+	AudienceInterval time.Duration
+	// WatchedPagesInterval период сбора данных отслеживаемых страниц, 0 - сбор выключен
+	WatchedPagesInterval time.Duration
+	// CommentsInterval период сбора комментариев опубликованных постов, 0 - сбор выключен
+	CommentsInterval time.Duration
+	// CommentsWindow комментарии собираются по постам, опубликованным не раньше этого срока
+	CommentsWindow time.Duration
 }
 
 func NewConfig() (*Config, error) {
 	if err := godotenv.Load(); err != nil {
 		return nil, ewrap.Errorf("cannot load env file: %w", err)
 	}
-	return &Config{
+	config := &Config{
 		PostgresDSN: os.Getenv("POSTGRES_DSN"),
 		IsProd:      os.Getenv("IS_PROD") == "true",
 		LogLevel:    os.Getenv("LOG_LEVEL"),
 		ServerAddr:  os.Getenv("SERVER_ADDR"),
-	}, nil
+		PublicURL:   os.Getenv("PUBLIC_URL"),
+		DryRun:      os.Getenv("DRY_RUN") == "true",
+		Events: usecase.EventsConfig{
+			VKCallbackSecret:        os.Getenv("VK_CALLBACK_SECRET"),
+			VKCallbackConfirmations: parseKeyValueList(os.Getenv("VK_CALLBACK_CONFIRMATIONS")),
+			FBWebhookVerifyToken:    os.Getenv("FB_WEBHOOK_VERIFY_TOKEN"),
+			FBAppSecret:             os.Getenv("FB_APP_SECRET"),
+		},
+	}
+	var err error
+	if config.ReconcileInterval, err = parseDuration("RECONCILE_INTERVAL", defaultReconcileInterval); err != nil {
+		return nil, err
+	}
+	if config.ReconcileWindow, err = parseDuration("RECONCILE_WINDOW", defaultReconcileWindow); err != nil {
+		return nil, err
+	}
+	if config.MetricsInterval, err = parseDuration("METRICS_INTERVAL", defaultMetricsInterval); err != nil {
+		return nil, err
+	}
+	if config.MetricsWindow, err = parseDuration("METRICS_WINDOW", defaultMetricsWindow); err != nil {
+		return nil, err
+	}
+	if config.AudienceInterval, err = parseDuration("AUDIENCE_INTERVAL", defaultAudienceInterval); err != nil {
+		return nil, err
+	}
+	if config.WatchedPagesInterval, err = parseDuration("WATCHED_PAGES_INTERVAL", defaultWatchedPagesInterval); err != nil {
+		return nil, err
+	}
+	if config.CommentsInterval, err = parseDuration("COMMENTS_INTERVAL", defaultCommentsInterval); err != nil {
+		return nil, err
+	}
+	if config.CommentsWindow, err = parseDuration("COMMENTS_WINDOW", defaultCommentsWindow); err != nil {
+		return nil, err
+	}
+	if config.PublicURL == "" && !config.IsProd {
+		config.PublicURL = defaultPublicURL
+	}
+
+	config.SocialNetworks = map[string]social_network_client.ClientConfig{}
+	for _, network := range social_network_client.Networks() {
+		config.SocialNetworks[network.Name] = newSocialNetworkConfig(network, config.PublicURL)
+	}
+
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// newSocialNetworkConfig читает переменные <NAME>_AUTH_API_URL, <NAME>_API_URL,
+// <NAME>_API_VERSION и <NAME>_REDIRECT_URL, отсутствующие берутся из настроек соц сети
+func newSocialNetworkConfig(
+	network social_network_client.Network,
+	publicURL string,
+) social_network_client.ClientConfig {
+	config := network.DefaultConfig
+	envPrefix := strings.ToUpper(network.Name)
+
+	if authApiUrl := os.Getenv(envPrefix + "_AUTH_API_URL"); authApiUrl != "" {
+		config.AuthApiUrl = authApiUrl
+	}
+	if workApiUrl := os.Getenv(envPrefix + "_API_URL"); workApiUrl != "" {
+		config.WorkApiUrl = workApiUrl
+	}
+	if apiVersion := os.Getenv(envPrefix + "_API_VERSION"); apiVersion != "" {
+		config.ApiVersion = apiVersion
+	}
+
+	config.RedirectUrl = os.Getenv(envPrefix + "_REDIRECT_URL")
+	if config.RedirectUrl == "" && publicURL != "" {
+		config.RedirectUrl = fmt.Sprintf(
+			"%s/auth/get_token?socialNetwork=%s",
+			strings.TrimSuffix(publicURL, "/"),
+			url.QueryEscape(network.Name),
+		)
+	}
+
+	return config
+}
+
+// parseKeyValueList разбирает список вида "key1:value1,key2:value2"
+func parseKeyValueList(value string) map[string]string {
+	values := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if ok && key != "" {
+			values[key] = value
+		}
+	}
+	return values
+}
+
+// parseDuration читает переменную вида "1h30m", пустая - defaultValue
+func parseDuration(name string, defaultValue time.Duration) (time.Duration, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return 0, ewrap.Errorf("cannot parse %s %q as non-negative duration", name, value)
+	}
+	return duration, nil
+}
+
+func (c *Config) validate() error {
+	err := validation.ValidateStruct(
+		c,
+		validation.Field(&c.PublicURL, validation.Required, validation.By(isAbsoluteURL)),
+	)
+	if err != nil {
+		return ewrap.Errorf("failed to validate config: %w", err)
+	}
+
+	for name, networkConfig := range c.SocialNetworks {
+		network, _ := social_network_client.LookupNetwork(name)
+		if err := c.validateSocialNetworkConfig(network, networkConfig); err != nil {
+			return ewrap.Errorf("failed to validate %s config: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) validateSocialNetworkConfig(
+	network social_network_client.Network,
+	networkConfig social_network_client.ClientConfig,
+) error {
+	redirectRules := []validation.Rule{validation.By(isAbsoluteURL)}
+	if network.OAuth != nil {
+		redirectRules = append(redirectRules, validation.Required)
+		if c.IsProd {
+			redirectRules = append(redirectRules, validation.By(isNotLocalURL))
+		}
+	}
+
+	return validation.ValidateStruct(
+		&networkConfig,
+		validation.Field(&networkConfig.AuthApiUrl, validation.By(isAbsoluteURL)),
+		validation.Field(&networkConfig.WorkApiUrl, validation.By(isAbsoluteURL)),
+		validation.Field(
+			&networkConfig.ApiVersion,
+			validation.When(network.DefaultConfig.ApiVersion != "", validation.Required),
+		),
+		validation.Field(&networkConfig.RedirectUrl, redirectRules...),
+	)
+}
+
+func isAbsoluteURL(value interface{}) error {
+	rawURL, _ := value.(string)
+	if rawURL == "" {
+		return nil
+	}
+
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if parsedURL.Scheme == "" || parsedURL.Host == "" {
+		return errors.New("must be an absolute url")
+	}
+
+	return nil
+}
+
+func isNotLocalURL(value interface{}) error {
+	rawURL, _ := value.(string)
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	switch parsedURL.Hostname() {
+	case "localhost", "127.0.0.1", "::1":
+		return errors.New("must not point to localhost in production")
+	}
+
+	return nil
 }
@@ -0,0 +1,201 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+const (
+	// pageHistoryImportPollInterval период проверки очереди импорта истории страниц
+	pageHistoryImportPollInterval = 10 * time.Second
+	// scheduledPostsPollInterval период проверки запланированных постов, на столько публикация может опоздать
+	scheduledPostsPollInterval = 30 * time.Second
+)
+
+// runPeriodic вызывает fn каждые interval, пока не отменен ctx. Первый вызов через interval после старта,
+// 0 - задача выключена. Ошибка fn записывается в лог и не останавливает задачу
+func (app *App) runPeriodic(ctx context.Context, interval time.Duration, name string, fn func(context.Context) error) {
+	if interval == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := fn(ctx); err != nil {
+			app.container.Logger.Error("periodic job failed", slog.String("job", name), slog.Any("err", err))
+		}
+	}
+}
+
+// runPostsReconciliation периодически отмечает посты, удаленные или измененные прямо в соц сети
+func (app *App) runPostsReconciliation(ctx context.Context) {
+	app.runPeriodic(ctx, app.config.ReconcileInterval, "posts reconciliation", func(ctx context.Context) error {
+		result, err := app.container.Usecases.SocialNetwork.ReconcilePosts(ctx, app.config.ReconcileWindow)
+		if result != nil {
+			app.container.Logger.Info(
+				"posts reconciled",
+				slog.Int("checked", result.Checked),
+				slog.Int("modified", result.Modified),
+				slog.Int("deleted", result.Deleted),
+				slog.Int("failed", result.Failed),
+			)
+		}
+		return err
+	})
+}
+
+// runPostsMetricsCollection периодически сохраняет снимок метрик недавно опубликованных постов
+func (app *App) runPostsMetricsCollection(ctx context.Context) {
+	app.runPeriodic(ctx, app.config.MetricsInterval, "posts metrics collection", func(ctx context.Context) error {
+		result, err := app.container.Usecases.SocialNetwork.CollectPostsMetrics(ctx, app.config.MetricsWindow)
+		if result != nil {
+			app.container.Logger.Info(
+				"posts metrics collected",
+				slog.Int("collected", result.Collected),
+				slog.Int("failed", result.Failed),
+			)
+		}
+		return err
+	})
+}
+
+// runPagesAudienceCollection периодически, по умолчанию раз в сутки, сохраняет число подписчиков страниц
+func (app *App) runPagesAudienceCollection(ctx context.Context) {
+	app.runPeriodic(ctx, app.config.AudienceInterval, "pages audience collection", func(ctx context.Context) error {
+		result, err := app.container.Usecases.SocialNetwork.CollectPagesAudience(ctx)
+		if result != nil {
+			app.container.Logger.Info(
+				"pages audience collected",
+				slog.Int("collected", result.Collected),
+				slog.Int("failed", result.Failed),
+			)
+		}
+		return err
+	})
+}
+
+// runWatchedPagesCollection периодически собирает подписчиков и посты отслеживаемых страниц
+func (app *App) runWatchedPagesCollection(ctx context.Context) {
+	app.runPeriodic(ctx, app.config.WatchedPagesInterval, "watched pages collection", func(ctx context.Context) error {
+		result, err := app.container.Usecases.SocialNetwork.CollectWatchedPages(ctx)
+		if result != nil {
+			app.container.Logger.Info(
+				"watched pages collected",
+				slog.Int("collected", result.Collected),
+				slog.Int("failed", result.Failed),
+			)
+		}
+		return err
+	})
+}
+
+// runCommentsCollection периодически сохраняет комментарии недавно опубликованных постов
+// и обрабатывает новые комментарии правилами модерации и автоответов
+func (app *App) runCommentsCollection(ctx context.Context) {
+	app.runPeriodic(ctx, app.config.CommentsInterval, "comments collection", func(ctx context.Context) error {
+		logger := app.container.Logger
+
+		result, collectErr := app.container.Usecases.SocialNetwork.CollectComments(ctx, app.config.CommentsWindow)
+		if result != nil {
+			logger.Info(
+				"comments collected",
+				slog.Int("posts", result.Posts),
+				slog.Int("collected", result.Collected),
+				slog.Int("deleted", result.Deleted),
+				slog.Int("failed", result.Failed),
+			)
+		}
+
+		// Комментарии, собранные раньше, обрабатываются и когда сбор не удался
+		processed, processErr := app.container.Usecases.SocialNetwork.ProcessComments(ctx)
+		if processed != nil && processed.Checked != 0 {
+			logger.Info(
+				"comments processed",
+				slog.Int("checked", processed.Checked),
+				slog.Int("hidden", processed.Hidden),
+				slog.Int("deleted", processed.Deleted),
+				slog.Int("replied", processed.Replied),
+				slog.Int("failed", processed.Failed),
+			)
+		}
+		return errors.Join(collectErr, processErr)
+	})
+}
+
+// runPageHistoryImports выполняет задачи импорта истории страниц по одной, пока очередь не опустеет.
+// Задачи, оставшиеся RUNNING после прошлого запуска, сначала возвращаются в очередь
+func (app *App) runPageHistoryImports(ctx context.Context) {
+	logger := app.container.Logger
+
+	requeued, err := app.container.Usecases.SocialNetwork.RequeueRunningPageHistoryImports(ctx)
+	if err != nil {
+		logger.Error("failed to requeue page history imports", slog.Any("err", err))
+	}
+	if requeued != 0 {
+		logger.Info("page history imports requeued", slog.Int("requeued", requeued))
+	}
+
+	app.runPeriodic(ctx, pageHistoryImportPollInterval, "page history imports", func(ctx context.Context) error {
+		for ctx.Err() == nil {
+			pageHistoryImport, err := app.container.Usecases.SocialNetwork.RunPendingPageHistoryImport(ctx)
+			if err != nil {
+				return err
+			}
+			if pageHistoryImport == nil {
+				return nil
+			}
+			logger.Info(
+				"page history imported",
+				slog.Int64("import", pageHistoryImport.ID),
+				slog.Int("page", pageHistoryImport.Page),
+				slog.String("status", string(pageHistoryImport.Status)),
+				slog.Int("fetched", pageHistoryImport.Fetched),
+				slog.Int("imported", pageHistoryImport.Imported),
+			)
+		}
+		return nil
+	})
+}
+
+// runScheduledPosts публикует запланированные посты, время которых наступило.
+// Посты, публикация которых прервалась при прошлом запуске, сначала отмечаются неудачными
+func (app *App) runScheduledPosts(ctx context.Context) {
+	logger := app.container.Logger
+
+	failed, err := app.container.Usecases.SocialNetwork.FailInterruptedScheduledPosts(ctx)
+	if err != nil {
+		logger.Error("failed to fail interrupted scheduled posts", slog.Any("err", err))
+	}
+	if failed != 0 {
+		logger.Warn("interrupted scheduled posts failed", slog.Int("failed", failed))
+	}
+
+	app.runPeriodic(ctx, scheduledPostsPollInterval, "scheduled posts", func(ctx context.Context) error {
+		for ctx.Err() == nil {
+			scheduledPost, err := app.container.Usecases.SocialNetwork.RunDueScheduledPost(ctx)
+			if err != nil {
+				return err
+			}
+			if scheduledPost == nil {
+				return nil
+			}
+			logger.Info(
+				"scheduled post finished",
+				slog.Int64("scheduledPost", scheduledPost.ID),
+				slog.Int("page", scheduledPost.Page),
+				slog.String("status", string(scheduledPost.Status)),
+			)
+		}
+		return nil
+	})
+}
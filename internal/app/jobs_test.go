@@ -0,0 +1,60 @@
+package app
+
+import (
+	"autoposting/internal/app/registry"
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunPeriodic(t *testing.T) {
+	var logs bytes.Buffer
+	app := &App{
+		container: &registry.Container{Logger: slog.New(slog.NewTextHandler(&logs, nil))},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		app.runPeriodic(ctx, time.Millisecond, "test job", func(context.Context) error {
+			calls++
+			if calls == 3 {
+				cancel()
+			}
+			return errors.New("collect failed")
+		})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("runPeriodic did not stop after ctx cancel")
+	}
+	if calls != 3 {
+		t.Fatalf("got %d calls, want 3", calls)
+	}
+	if got := strings.Count(logs.String(), `job="test job" err="collect failed"`); got != 3 {
+		t.Fatalf("got %d logged errors, want 3:\n%s", got, logs.String())
+	}
+}
+
+func TestRunPeriodicDisabled(t *testing.T) {
+	app := &App{
+		container: &registry.Container{Logger: slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))},
+	}
+
+	called := false
+	app.runPeriodic(context.Background(), 0, "disabled job", func(context.Context) error {
+		called = true
+		return nil
+	})
+	if called {
+		t.Fatal("disabled job was called")
+	}
+}
@@ -12,4 +12,5 @@ type Container struct {
 
 type Usecases struct {
 	SocialNetwork *usecase.SocialNetworkUsecase
+	Events        *usecase.EventsUsecase
 }
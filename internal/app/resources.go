@@ -7,6 +7,14 @@ import (
 	"autoposting/internal/domain/service"
 	"autoposting/internal/infrastructure/postgres"
 	"autoposting/internal/infrastructure/social_network_client"
+	_ "autoposting/internal/infrastructure/social_network_client/bsky"
+	_ "autoposting/internal/infrastructure/social_network_client/discord"
+	_ "autoposting/internal/infrastructure/social_network_client/fb"
+	_ "autoposting/internal/infrastructure/social_network_client/mastodon"
+	_ "autoposting/internal/infrastructure/social_network_client/ok"
+	_ "autoposting/internal/infrastructure/social_network_client/slack"
+	_ "autoposting/internal/infrastructure/social_network_client/vk"
+	_ "autoposting/internal/infrastructure/social_network_client/webhook"
 	ewrap "autoposting/pkg/err-wrapper"
 	"autoposting/pkg/logger"
 	pg_bun "autoposting/pkg/pg-bun"
@@ -42,23 +50,59 @@ func NewContainer(ctx context.Context, config *Config) (*registry.Container, err
 		return nil, ewrap.Errorf("cannot get postgres client: %w", err)
 	}
 
-	socialNetworkClients := map[model.SocialNetworkName]social_network_client.SocialNetworkClient{
-		"VK": social_network_client.NewVKClient(),
-		"OK": social_network_client.NewOKClient(),
-		"FB": social_network_client.NewFBClient(),
+	socialNetworkClients := map[model.SocialNetworkName]social_network_client.SocialNetworkClient{}
+	for _, network := range social_network_client.Networks() {
+		socialNetworkClients[model.SocialNetworkName(network.Name)] = network.NewClient(config.SocialNetworks[network.Name])
 	}
 
 	socialNetworkAccountService := service.NewService(
 		logger,
 		postgres.NewSocialNetworkAccountsRepository(postgresClient),
 		postgres.NewSocialNetworkPagesRepository(postgresClient),
+		postgres.NewPostsRepository(postgresClient),
+		postgres.NewSocialNetworkEventsRepository(postgresClient),
+		postgres.NewProjectSettingsRepository(postgresClient),
+		postgres.NewWebhookDeliveriesRepository(postgresClient),
 		socialNetworkClients,
 	)
+	commentsService := service.NewCommentsService(
+		logger,
+		socialNetworkAccountService,
+		postgres.NewCommentsRepository(postgresClient),
+		postgres.NewCommentModerationRepository(postgresClient),
+		postgres.NewCommentAutoRepliesRepository(postgresClient),
+	)
+	analyticsService := service.NewAnalyticsService(
+		logger,
+		socialNetworkAccountService,
+		postgres.NewPostMetricsRepository(postgresClient),
+		postgres.NewPageAudienceRepository(postgresClient),
+		postgres.NewWatchedPagesRepository(postgresClient),
+	)
+	postsHistoryService := service.NewPostsHistoryService(
+		logger,
+		socialNetworkAccountService,
+		postgres.NewPageHistoryImportsRepository(postgresClient),
+	)
+	scheduledPostsService := service.NewScheduledPostsService(
+		logger,
+		socialNetworkAccountService,
+		analyticsService,
+		postgres.NewScheduledPostsRepository(postgresClient),
+	)
 
 	container := registry.Container{
 		Logger: logger,
 		Usecases: &registry.Usecases{
-			SocialNetwork: usecase.NewSocialNetworkUsecase(socialNetworkAccountService),
+			SocialNetwork: usecase.NewSocialNetworkUsecase(
+				socialNetworkAccountService,
+				commentsService,
+				analyticsService,
+				postsHistoryService,
+				scheduledPostsService,
+				config.DryRun,
+			),
+			Events: usecase.NewEventsUsecase(socialNetworkAccountService, commentsService, config.Events),
 		},
 	}
 
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"autoposting/internal/app/registry"
+	"autoposting/internal/domain"
+	"autoposting/internal/infrastructure/social_network_client/fb"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+)
+
+// maxEventBodySize уведомления соц сетей небольшие, больший запрос - не уведомление
+const maxEventBodySize = 1 << 20
+
+func VKCallbackHandler(container *registry.Container, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxEventBodySize))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		response, err := container.Usecases.Events.ReceiveVKCallback(r.Context(), body)
+		if err != nil {
+			writeEventError(w, logger, "failed to receive vk callback", err)
+			return
+		}
+		_, _ = w.Write([]byte(response))
+	}
+}
+
+func FBWebhookVerifyHandler(container *registry.Container, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		challenge, err := container.Usecases.Events.VerifyFBWebhook(r.URL.Query())
+		if err != nil {
+			writeEventError(w, logger, "failed to verify fb webhook", err)
+			return
+		}
+		_, _ = w.Write([]byte(challenge))
+	}
+}
+
+func FBWebhookHandler(container *registry.Container, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxEventBodySize))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		err = container.Usecases.Events.ReceiveFBWebhook(r.Context(), body, r.Header.Get(fb.WebhookSignatureHeader))
+		if err != nil {
+			writeEventError(w, logger, "failed to receive fb webhook", err)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}
+}
+
+// writeEventError на 5xx соц сети повторяют доставку, на остальные ошибки - нет
+func writeEventError(w http.ResponseWriter, logger *slog.Logger, message string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case domain.IsForbiddenError(err):
+		status = http.StatusForbidden
+	case domain.IsNotFoundError(err):
+		status = http.StatusNotFound
+	case domain.IsValidationError(err):
+		status = http.StatusBadRequest
+	}
+
+	if status == http.StatusInternalServerError {
+		logger.Error(message, slog.Any("err", err))
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	logger.Warn(message, slog.Any("err", err))
+	http.Error(w, err.Error(), status)
+}
@@ -40,6 +40,11 @@ func (s *Server) InitRoutes(container *registry.Container, isProd bool) {
 	)
 	s.router.Handle("/graphql", graphqlHandler)
 	s.router.Handle("/auth/get_token", handlers.GetAccessTokenHandler(container, container.Logger))
+	s.router.Route("/events", func(r chi.Router) {
+		r.Post("/vk", handlers.VKCallbackHandler(container, container.Logger))
+		r.Get("/fb", handlers.FBWebhookVerifyHandler(container, container.Logger))
+		r.Post("/fb", handlers.FBWebhookHandler(container, container.Logger))
+	})
 }
 
 func (s *Server) Run(
@@ -63,7 +68,7 @@ func (s *Server) Run(
 		<-ctx.Done()
 
 		if err := srv.Shutdown(ctx); err != nil {
-			s.logger.Error("failed to shutdown server: %w", err)
+			s.logger.Error("failed to shutdown server", slog.Any("err", err))
 		}
 
 		const sleepShutdown = 5 * time.Second
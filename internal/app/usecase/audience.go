@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"autoposting/internal/domain/model"
+	"autoposting/internal/domain/service"
+	"autoposting/internal/infrastructure/postgres"
+	"autoposting/internal/presentation/graphql/gen"
+	"context"
+)
+
+// audienceDateLayout формат дня точек ряда подписчиков
+const audienceDateLayout = "2006-01-02"
+
+func (u *SocialNetworkUsecase) GetAudienceGrowth(
+	ctx context.Context,
+	input gen.GetAudienceGrowthInput,
+) (gen.GetAudienceGrowthOutput, error) {
+	from, validationErr := parseOptionalTime(input.From, "from")
+	if validationErr != nil {
+		return *validationErr, nil
+	}
+	to, validationErr := parseOptionalTime(input.To, "to")
+	if validationErr != nil {
+		return *validationErr, nil
+	}
+
+	growth, err := u.analyticsService.GetAudienceGrowth(ctx, postgres.FindPageAudienceQuery{
+		PagesIDAnyOf:    input.Pages,
+		ProjectAnyOf:    input.Projects,
+		CollectedAfter:  from,
+		CollectedBefore: to,
+	})
+	if err != nil {
+		return gen.InternalError{
+			Message: err.Error(),
+		}, nil
+	}
+
+	out := gen.GetAudienceGrowthResult{
+		Pages:    make([]*gen.PageAudienceGrowth, 0, len(growth.Pages)),
+		Projects: make([]*gen.ProjectAudienceGrowth, 0, len(growth.Projects)),
+	}
+	for _, pageGrowth := range growth.Pages {
+		out.Pages = append(out.Pages, &gen.PageAudienceGrowth{
+			PageID:         pageGrowth.Page,
+			Project:        pageGrowth.Project,
+			Points:         toGenAudiencePoints(pageGrowth.Points),
+			StartFollowers: pageGrowth.StartFollowers,
+			EndFollowers:   pageGrowth.EndFollowers,
+			Delta:          pageGrowth.Delta,
+		})
+	}
+	for _, projectGrowth := range growth.Projects {
+		out.Projects = append(out.Projects, &gen.ProjectAudienceGrowth{
+			Project:        projectGrowth.Project,
+			Points:         toGenAudiencePoints(projectGrowth.Points),
+			StartFollowers: projectGrowth.StartFollowers,
+			EndFollowers:   projectGrowth.EndFollowers,
+			Delta:          projectGrowth.Delta,
+		})
+	}
+
+	return out, nil
+}
+
+func (u *SocialNetworkUsecase) CollectPagesAudience(ctx context.Context) (*service.CollectAudienceResult, error) {
+	return u.analyticsService.CollectPagesAudience(ctx)
+}
+
+func toGenAudiencePoints(points []model.AudiencePoint) []*gen.AudiencePoint {
+	genPoints := make([]*gen.AudiencePoint, 0, len(points))
+	for _, point := range points {
+		genPoints = append(genPoints, &gen.AudiencePoint{
+			Date:      point.Date.Format(audienceDateLayout),
+			Followers: point.Followers,
+		})
+	}
+	return genPoints
+}
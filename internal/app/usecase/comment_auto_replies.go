@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"autoposting/internal/domain"
+	"autoposting/internal/domain/model"
+	"autoposting/internal/infrastructure/postgres"
+	"autoposting/internal/presentation/graphql/gen"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"time"
+)
+
+const (
+	defaultCommentAutoReplyCooldownMinutes = 60
+	defaultCommentAutoReplyDailyLimit      = 50
+)
+
+func (u *SocialNetworkUsecase) GetCommentAutoReplyRules(
+	ctx context.Context,
+	input gen.GetCommentAutoReplyRulesInput,
+) (gen.GetCommentAutoReplyRulesOutput, error) {
+	query := postgres.FindCommentAutoReplyRulesQuery{
+		PagesIDAnyOf: input.Pages,
+	}
+	for _, post := range input.Posts {
+		query.PostsIDAnyOf = append(query.PostsIDAnyOf, int64(post))
+	}
+
+	rules, err := u.commentsService.GetCommentAutoReplyRules(ctx, query)
+	if err != nil {
+		return gen.InternalError{
+			Message: err.Error(),
+		}, nil
+	}
+
+	out := gen.GetCommentAutoReplyRulesResult{
+		Rules: make([]*gen.CommentAutoReplyRule, 0, len(rules)),
+	}
+	for _, rule := range rules {
+		out.Rules = append(out.Rules, toGenCommentAutoReplyRule(rule))
+	}
+
+	return out, nil
+}
+
+func (u *SocialNetworkUsecase) CreateCommentAutoReplyRule(
+	ctx context.Context,
+	input gen.CreateCommentAutoReplyRuleInput,
+) (gen.CreateCommentAutoReplyRuleOutput, error) {
+	rule := &model.CommentAutoReplyRule{
+		Patterns:        input.Patterns,
+		Template:        input.Template,
+		CooldownMinutes: defaultCommentAutoReplyCooldownMinutes,
+		DailyLimit:      defaultCommentAutoReplyDailyLimit,
+	}
+	if input.PageID != nil {
+		rule.Page = *input.PageID
+	}
+	if input.PostID != nil {
+		post := int64(*input.PostID)
+		rule.Post = &post
+	}
+	if input.CooldownMinutes != nil {
+		rule.CooldownMinutes = *input.CooldownMinutes
+	}
+	if input.DailyLimit != nil {
+		rule.DailyLimit = *input.DailyLimit
+	}
+
+	if err := u.commentsService.CreateCommentAutoReplyRule(ctx, rule); err != nil {
+		switch {
+		case domain.IsValidationError(err):
+			return toGenValidationError(err), nil
+		case domain.IsNotFoundError(err):
+			field := "pageId"
+			if input.PostID != nil {
+				field = "postId"
+			}
+			return gen.ValidationError{
+				Message: err.Error(),
+				Field:   stringPtr(field),
+				Rule:    stringPtr("exists"),
+			}, nil
+		default:
+			return nil, ewrap.Errorf("failed to create comment auto reply rule: %w", err)
+		}
+	}
+
+	return gen.CreateCommentAutoReplyRuleResult{
+		Rule: toGenCommentAutoReplyRule(*rule),
+	}, nil
+}
+
+func (u *SocialNetworkUsecase) DeleteCommentAutoReplyRule(
+	ctx context.Context,
+	input gen.DeleteCommentAutoReplyRuleInput,
+) (gen.DeleteCommentAutoReplyRuleOutput, error) {
+	if err := u.commentsService.DeleteCommentAutoReplyRule(ctx, input.RuleID); err != nil {
+		switch {
+		case domain.IsNotFoundError(err):
+			return gen.ValidationError{
+				Message: err.Error(),
+				Field:   stringPtr("ruleId"),
+				Rule:    stringPtr("exists"),
+			}, nil
+		default:
+			return nil, ewrap.Errorf("failed to delete comment auto reply rule %d: %w", input.RuleID, err)
+		}
+	}
+
+	return gen.DeleteCommentAutoReplyRuleResult{
+		Ok: true,
+	}, nil
+}
+
+func toGenCommentAutoReplyRule(rule model.CommentAutoReplyRule) *gen.CommentAutoReplyRule {
+	out := &gen.CommentAutoReplyRule{
+		ID:              rule.ID,
+		PageID:          rule.Page,
+		Patterns:        rule.Patterns,
+		Template:        rule.Template,
+		CooldownMinutes: rule.CooldownMinutes,
+		DailyLimit:      rule.DailyLimit,
+		CreatedAt:       rule.CreatedAt.Format(time.RFC3339),
+	}
+	if rule.Post != nil {
+		post := int(*rule.Post)
+		out.PostID = &post
+	}
+	return out
+}
@@ -0,0 +1,207 @@
+package usecase
+
+import (
+	"autoposting/internal/domain"
+	"autoposting/internal/domain/model"
+	"autoposting/internal/infrastructure/postgres"
+	"autoposting/internal/presentation/graphql/gen"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+func (u *SocialNetworkUsecase) GetCommentModerationRules(
+	ctx context.Context,
+	input gen.GetCommentModerationRulesInput,
+) (gen.GetCommentModerationRulesOutput, error) {
+	rules, err := u.commentsService.GetCommentModerationRules(ctx, input.Projects)
+	if err != nil {
+		return gen.InternalError{
+			Message: err.Error(),
+		}, nil
+	}
+
+	out := gen.GetCommentModerationRulesResult{
+		Rules: make([]*gen.CommentModerationRule, 0, len(rules)),
+	}
+	for _, rule := range rules {
+		out.Rules = append(out.Rules, toGenCommentModerationRule(rule))
+	}
+
+	return out, nil
+}
+
+func (u *SocialNetworkUsecase) GetCommentModerationLog(
+	ctx context.Context,
+	input gen.GetCommentModerationLogInput,
+) (gen.GetCommentModerationLogOutput, error) {
+	query := postgres.FindCommentModerationLogQuery{
+		ProjectAnyOf: input.Projects,
+		Limit:        defaultCommentsLimit,
+	}
+	if input.Limit != nil {
+		if *input.Limit <= 0 || *input.Limit > maxCommentsLimit {
+			return gen.ValidationError{
+				Message: fmt.Sprintf("limit must be between 1 and %d", maxCommentsLimit),
+				Field:   stringPtr("limit"),
+				Rule:    stringPtr("range"),
+			}, nil
+		}
+		query.Limit = *input.Limit
+	}
+	if input.Offset != nil {
+		if *input.Offset < 0 {
+			return gen.ValidationError{
+				Message: "offset must not be negative",
+				Field:   stringPtr("offset"),
+				Rule:    stringPtr("min"),
+			}, nil
+		}
+		query.Offset = *input.Offset
+	}
+
+	entries, err := u.commentsService.GetCommentModerationLog(ctx, query)
+	if err != nil {
+		return gen.InternalError{
+			Message: err.Error(),
+		}, nil
+	}
+
+	out := gen.GetCommentModerationLogResult{
+		Moderations: make([]*gen.CommentModeration, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		out.Moderations = append(out.Moderations, toGenCommentModeration(entry.Log, entry.Comment))
+	}
+
+	return out, nil
+}
+
+func (u *SocialNetworkUsecase) CreateCommentModerationRule(
+	ctx context.Context,
+	input gen.CreateCommentModerationRuleInput,
+) (gen.CreateCommentModerationRuleOutput, error) {
+	if strings.TrimSpace(input.Project) == "" {
+		return gen.ValidationError{
+			Message: "project must not be empty",
+			Field:   stringPtr("project"),
+			Rule:    stringPtr("required"),
+		}, nil
+	}
+
+	rule := &model.CommentModerationRule{
+		Project: input.Project,
+		Kind:    model.CommentModerationRuleKind(input.Kind),
+		Values:  input.Values,
+		Action:  model.CommentModerationAction(input.Action),
+	}
+	if rule.Values == nil {
+		rule.Values = []string{}
+	}
+	if input.RepeatLimit != nil {
+		rule.RepeatLimit = *input.RepeatLimit
+	}
+	if input.RepeatWindowMinutes != nil {
+		rule.RepeatWindowMinutes = *input.RepeatWindowMinutes
+	}
+	if input.AllowIrreversible != nil {
+		rule.AllowIrreversible = *input.AllowIrreversible
+	}
+
+	if err := u.commentsService.CreateCommentModerationRule(ctx, rule); err != nil {
+		switch {
+		case domain.IsValidationError(err):
+			return toGenValidationError(err), nil
+		default:
+			return nil, ewrap.Errorf("failed to create comment moderation rule: %w", err)
+		}
+	}
+
+	return gen.CreateCommentModerationRuleResult{
+		Rule: toGenCommentModerationRule(*rule),
+	}, nil
+}
+
+func (u *SocialNetworkUsecase) DeleteCommentModerationRule(
+	ctx context.Context,
+	input gen.DeleteCommentModerationRuleInput,
+) (gen.DeleteCommentModerationRuleOutput, error) {
+	if err := u.commentsService.DeleteCommentModerationRule(ctx, input.RuleID); err != nil {
+		switch {
+		case domain.IsNotFoundError(err):
+			return gen.ValidationError{
+				Message: err.Error(),
+				Field:   stringPtr("ruleId"),
+				Rule:    stringPtr("exists"),
+			}, nil
+		default:
+			return nil, ewrap.Errorf("failed to delete comment moderation rule %d: %w", input.RuleID, err)
+		}
+	}
+
+	return gen.DeleteCommentModerationRuleResult{
+		Ok: true,
+	}, nil
+}
+
+func (u *SocialNetworkUsecase) RevertCommentModeration(
+	ctx context.Context,
+	input gen.RevertCommentModerationInput,
+) (gen.RevertCommentModerationOutput, error) {
+	log, err := u.commentsService.RevertCommentModeration(ctx, int64(input.ModerationID))
+	if err != nil {
+		switch {
+		case domain.IsValidationError(err):
+			return toGenValidationError(err), nil
+		case domain.IsNotFoundError(err):
+			return gen.ValidationError{
+				Message: err.Error(),
+				Field:   stringPtr("moderationId"),
+				Rule:    stringPtr("exists"),
+			}, nil
+		case domain.IsInternalError(err):
+			return gen.InternalError{
+				Message: err.Error(),
+			}, nil
+		default:
+			return nil, ewrap.Errorf("failed to revert comment moderation %d: %w", input.ModerationID, err)
+		}
+	}
+
+	return gen.RevertCommentModerationResult{
+		Moderation: toGenCommentModeration(*log, nil),
+	}, nil
+}
+
+func toGenCommentModerationRule(rule model.CommentModerationRule) *gen.CommentModerationRule {
+	return &gen.CommentModerationRule{
+		ID:                  rule.ID,
+		Project:             rule.Project,
+		Kind:                gen.CommentModerationRuleKind(rule.Kind),
+		Values:              rule.Values,
+		RepeatLimit:         rule.RepeatLimit,
+		RepeatWindowMinutes: rule.RepeatWindowMinutes,
+		Action:              gen.CommentModerationAction(rule.Action),
+		AllowIrreversible:   rule.AllowIrreversible,
+		CreatedAt:           rule.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func toGenCommentModeration(log model.CommentModerationLog, comment *model.Comment) *gen.CommentModeration {
+	out := &gen.CommentModeration{
+		ID:        int(log.ID),
+		RuleID:    log.Rule,
+		Action:    gen.CommentModerationAction(log.Action),
+		Reason:    log.Reason,
+		CreatedAt: log.CreatedAt.Format(time.RFC3339),
+	}
+	if !log.RevertedAt.IsZero() {
+		out.RevertedAt = stringPtr(log.RevertedAt.Format(time.RFC3339))
+	}
+	if comment != nil {
+		out.Comment = toGenComment(*comment)
+	}
+	return out
+}
@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"autoposting/internal/domain"
+	"autoposting/internal/domain/model"
+	"autoposting/internal/domain/service"
+	"autoposting/internal/infrastructure/postgres"
+	"autoposting/internal/presentation/graphql/gen"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	defaultCommentsLimit = 50
+	maxCommentsLimit     = 200
+)
+
+func (u *SocialNetworkUsecase) GetComments(
+	ctx context.Context,
+	input gen.GetCommentsInput,
+) (gen.GetCommentsOutput, error) {
+	query := postgres.FindCommentsQuery{
+		PagesIDAnyOf: input.Pages,
+		ProjectAnyOf: input.Projects,
+		Unanswered:   input.Unanswered != nil && *input.Unanswered,
+		Limit:        defaultCommentsLimit,
+	}
+	for _, socialNetwork := range input.SocialNetworks {
+		query.SocialNetworkAnyOf = append(query.SocialNetworkAnyOf, model.SocialNetworkName(socialNetwork))
+	}
+	if input.Limit != nil {
+		if *input.Limit <= 0 || *input.Limit > maxCommentsLimit {
+			return gen.ValidationError{
+				Message: fmt.Sprintf("limit must be between 1 and %d", maxCommentsLimit),
+				Field:   stringPtr("limit"),
+				Rule:    stringPtr("range"),
+			}, nil
+		}
+		query.Limit = *input.Limit
+	}
+	if input.Offset != nil {
+		if *input.Offset < 0 {
+			return gen.ValidationError{
+				Message: "offset must not be negative",
+				Field:   stringPtr("offset"),
+				Rule:    stringPtr("min"),
+			}, nil
+		}
+		query.Offset = *input.Offset
+	}
+
+	comments, err := u.commentsService.GetComments(ctx, query)
+	if err != nil {
+		return gen.InternalError{
+			Message: err.Error(),
+		}, nil
+	}
+
+	out := gen.GetCommentsResult{
+		Comments: make([]*gen.Comment, 0, len(comments)),
+	}
+	for _, comment := range comments {
+		out.Comments = append(out.Comments, toGenComment(comment))
+	}
+
+	return out, nil
+}
+
+func (u *SocialNetworkUsecase) ReplyToComment(
+	ctx context.Context,
+	input gen.ReplyToCommentInput,
+) (gen.ReplyToCommentOutput, error) {
+	if strings.TrimSpace(input.Text) == "" {
+		return gen.ValidationError{
+			Message: "text must not be empty",
+			Field:   stringPtr("text"),
+			Rule:    stringPtr("required"),
+		}, nil
+	}
+
+	reply, err := u.commentsService.ReplyToComment(ctx, int64(input.CommentID), input.Text)
+	if err != nil {
+		switch {
+		case domain.IsValidationError(err):
+			return toGenValidationError(err), nil
+		case domain.IsNotFoundError(err):
+			return gen.ValidationError{
+				Message: err.Error(),
+				Field:   stringPtr("commentId"),
+				Rule:    stringPtr("exists"),
+			}, nil
+		case domain.IsInternalError(err):
+			return gen.InternalError{
+				Message: err.Error(),
+			}, nil
+		default:
+			return nil, ewrap.Errorf("failed to reply to comment %d: %w", input.CommentID, err)
+		}
+	}
+
+	return gen.ReplyToCommentResult{
+		Reply: toGenComment(*reply),
+	}, nil
+}
+
+func (u *SocialNetworkUsecase) DeleteComment(
+	ctx context.Context,
+	input gen.DeleteCommentInput,
+) (gen.DeleteCommentOutput, error) {
+	if err := u.commentsService.DeleteComment(ctx, int64(input.CommentID)); err != nil {
+		switch {
+		case domain.IsValidationError(err):
+			return toGenValidationError(err), nil
+		case domain.IsNotFoundError(err):
+			return gen.ValidationError{
+				Message: err.Error(),
+				Field:   stringPtr("commentId"),
+				Rule:    stringPtr("exists"),
+			}, nil
+		case domain.IsInternalError(err):
+			return gen.InternalError{
+				Message: err.Error(),
+			}, nil
+		default:
+			return nil, ewrap.Errorf("failed to delete comment %d: %w", input.CommentID, err)
+		}
+	}
+
+	return gen.DeleteCommentResult{
+		Ok: true,
+	}, nil
+}
+
+// CollectComments собирает комментарии постов, опубликованных за window
+func (u *SocialNetworkUsecase) CollectComments(
+	ctx context.Context,
+	window time.Duration,
+) (*service.CollectCommentsResult, error) {
+	return u.commentsService.CollectComments(ctx, time.Now().Add(-window))
+}
+
+// ProcessComments обрабатывает правилами модерации и автоответов все еще не обработанные комментарии
+func (u *SocialNetworkUsecase) ProcessComments(ctx context.Context) (*service.ProcessCommentsResult, error) {
+	return u.commentsService.ProcessComments(ctx, nil)
+}
+
+func toGenComment(comment model.Comment) *gen.Comment {
+	out := &gen.Comment{
+		ID:              int(comment.ID),
+		PageID:          comment.Page,
+		PostID:          int(comment.Post),
+		SocialNetwork:   string(comment.SocialNetwork),
+		RemoteCommentID: comment.RemoteCommentID,
+		AuthorID:        comment.AuthorID,
+		AuthorName:      comment.AuthorName,
+		ByPage:          comment.ByPage,
+		Text:            comment.Text,
+		CreatedAt:       comment.CreatedAt.Format(time.RFC3339),
+	}
+	if comment.ParentRemoteCommentID != "" {
+		out.ParentRemoteCommentID = stringPtr(comment.ParentRemoteCommentID)
+	}
+	if !comment.AnsweredAt.IsZero() {
+		out.AnsweredAt = stringPtr(comment.AnsweredAt.Format(time.RFC3339))
+	}
+	if !comment.HiddenAt.IsZero() {
+		out.HiddenAt = stringPtr(comment.HiddenAt.Format(time.RFC3339))
+	}
+	if !comment.DeletedAt.IsZero() {
+		out.DeletedAt = stringPtr(comment.DeletedAt.Format(time.RFC3339))
+	}
+	return out
+}
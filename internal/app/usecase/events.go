@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"autoposting/internal/domain"
+	"autoposting/internal/domain/model"
+	"autoposting/internal/domain/service"
+	"autoposting/internal/infrastructure/social_network_client"
+	"autoposting/internal/infrastructure/social_network_client/fb"
+	"autoposting/internal/infrastructure/social_network_client/vk"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// EventsConfig секреты приема уведомлений соц сетей
+type EventsConfig struct {
+	VKCallbackSecret string
+	// VKCallbackConfirmations строки подтверждения сервера Callback API по id сообщества
+	VKCallbackConfirmations map[string]string
+	FBWebhookVerifyToken    string
+	FBAppSecret             string
+}
+
+type EventsUsecase struct {
+	socialNetworkService *service.SocialNetworkService
+	commentsService      *service.CommentsService
+	config               EventsConfig
+}
+
+func NewEventsUsecase(
+	socialNetworkService *service.SocialNetworkService,
+	commentsService *service.CommentsService,
+	config EventsConfig,
+) *EventsUsecase {
+	return &EventsUsecase{
+		socialNetworkService,
+		commentsService,
+		config,
+	}
+}
+
+// savePageEvents сохраняет события в общий поток, новые и измененные комментарии к постам проектов
+// сразу попадают в comments и обрабатываются правилами
+func (u *EventsUsecase) savePageEvents(
+	ctx context.Context,
+	socialNetwork model.SocialNetworkName,
+	events []social_network_client.PageEvent,
+) error {
+	if _, err := u.socialNetworkService.SavePageEvents(ctx, socialNetwork, events); err != nil {
+		return err
+	}
+	// Повторная доставка снова сохраняет комментарии, если в прошлый раз это не удалось
+	return u.commentsService.IngestEventsComments(ctx, socialNetwork, events)
+}
+
+// ReceiveVKCallback возвращает ответ, которого ждет VK: строку подтверждения или ok
+func (u *EventsUsecase) ReceiveVKCallback(ctx context.Context, body []byte) (string, error) {
+	request, err := vk.ParseCallbackRequest(body)
+	if err != nil {
+		return "", domain.NewValidationError(err.Error(), "body", "vkCallback")
+	}
+
+	if request.IsConfirmation() {
+		confirmation, ok := u.config.VKCallbackConfirmations[strconv.Itoa(request.GroupID)]
+		if !ok {
+			return "", domain.NewNotFoundError(
+				fmt.Sprintf("vk callback confirmation for group %d is not configured", request.GroupID),
+			)
+		}
+		return confirmation, nil
+	}
+
+	if !request.CheckSecret(u.config.VKCallbackSecret) {
+		return "", domain.NewForbiddenError(fmt.Sprintf("invalid vk callback secret for group %d", request.GroupID))
+	}
+
+	event, err := request.PageEvent()
+	if err != nil {
+		return "", domain.NewValidationError(err.Error(), "object", "vkCallback")
+	}
+	if event != nil {
+		err := u.savePageEvents(ctx, model.SocialNetworkName(vk.Name), []social_network_client.PageEvent{*event})
+		if err != nil {
+			return "", ewrap.Errorf("failed to receive vk callback: %w", err)
+		}
+	}
+
+	return "ok", nil
+}
+
+// VerifyFBWebhook возвращает hub.challenge при верном verify token
+func (u *EventsUsecase) VerifyFBWebhook(query url.Values) (string, error) {
+	challenge, ok := fb.VerifyWebhookSubscription(u.config.FBWebhookVerifyToken, query)
+	if !ok {
+		return "", domain.NewForbiddenError("invalid fb webhook verify token")
+	}
+	return challenge, nil
+}
+
+func (u *EventsUsecase) ReceiveFBWebhook(ctx context.Context, body []byte, signature string) error {
+	if !fb.VerifyWebhookSignature(u.config.FBAppSecret, body, signature) {
+		return domain.NewForbiddenError("invalid fb webhook signature")
+	}
+
+	events, err := fb.ParseWebhookEvents(body)
+	if err != nil {
+		return domain.NewValidationError(err.Error(), "body", "fbWebhook")
+	}
+	if err := u.savePageEvents(ctx, model.SocialNetworkName(fb.Name), events); err != nil {
+		return ewrap.Errorf("failed to receive fb webhook: %w", err)
+	}
+
+	return nil
+}
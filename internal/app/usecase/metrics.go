@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"autoposting/internal/domain/model"
+	"autoposting/internal/domain/service"
+	"autoposting/internal/infrastructure/postgres"
+	"autoposting/internal/presentation/graphql/gen"
+	"context"
+	"time"
+)
+
+func (u *SocialNetworkUsecase) GetPostMetrics(
+	ctx context.Context,
+	input gen.GetPostMetricsInput,
+) (gen.GetPostMetricsOutput, error) {
+	from, validationErr := parseOptionalTime(input.From, "from")
+	if validationErr != nil {
+		return *validationErr, nil
+	}
+	to, validationErr := parseOptionalTime(input.To, "to")
+	if validationErr != nil {
+		return *validationErr, nil
+	}
+
+	metrics, err := u.analyticsService.GetPostMetrics(ctx, postgres.FindPostMetricsQuery{
+		PostID:          int64(input.PostID),
+		CollectedAfter:  from,
+		CollectedBefore: to,
+	})
+	if err != nil {
+		return gen.InternalError{
+			Message: err.Error(),
+		}, nil
+	}
+
+	out := gen.GetPostMetricsResult{
+		PostID:  input.PostID,
+		Metrics: make([]*gen.PostMetrics, 0, len(metrics)),
+	}
+	for _, snapshot := range metrics {
+		out.Metrics = append(out.Metrics, &gen.PostMetrics{
+			CollectedAt: snapshot.CollectedAt.Format(time.RFC3339),
+			Likes:       snapshot.Likes,
+			Reposts:     snapshot.Reposts,
+			Comments:    snapshot.Comments,
+			Views:       snapshot.Views,
+			Reach:       snapshot.Reach,
+		})
+	}
+
+	return out, nil
+}
+
+func (u *SocialNetworkUsecase) GetMetricsAggregates(
+	ctx context.Context,
+	input gen.GetMetricsAggregatesInput,
+) (gen.GetMetricsAggregatesOutput, error) {
+	from, validationErr := parseOptionalTime(input.From, "from")
+	if validationErr != nil {
+		return *validationErr, nil
+	}
+	to, validationErr := parseOptionalTime(input.To, "to")
+	if validationErr != nil {
+		return *validationErr, nil
+	}
+
+	query := postgres.AggregatePostMetricsQuery{
+		GroupBy:         model.PostMetricsGroupBy(input.GroupBy),
+		PagesIDAnyOf:    input.Pages,
+		ProjectAnyOf:    input.Projects,
+		PublishedAfter:  from,
+		PublishedBefore: to,
+	}
+	for _, socialNetwork := range input.SocialNetworks {
+		query.SocialNetworkAnyOf = append(query.SocialNetworkAnyOf, model.SocialNetworkName(socialNetwork))
+	}
+
+	aggregates, err := u.analyticsService.AggregatePostMetrics(ctx, query)
+	if err != nil {
+		return gen.InternalError{
+			Message: err.Error(),
+		}, nil
+	}
+
+	out := gen.GetMetricsAggregatesResult{
+		Aggregates: make([]*gen.MetricsAggregate, 0, len(aggregates)),
+	}
+	for _, aggregate := range aggregates {
+		out.Aggregates = append(out.Aggregates, &gen.MetricsAggregate{
+			Key:      aggregate.Key,
+			Posts:    aggregate.Posts,
+			Likes:    aggregate.Likes,
+			Reposts:  aggregate.Reposts,
+			Comments: aggregate.Comments,
+			Views:    aggregate.Views,
+			Reach:    aggregate.Reach,
+		})
+	}
+
+	return out, nil
+}
+
+// CollectPostsMetrics собирает метрики постов, опубликованных за window
+func (u *SocialNetworkUsecase) CollectPostsMetrics(
+	ctx context.Context,
+	window time.Duration,
+) (*service.CollectMetricsResult, error) {
+	return u.analyticsService.CollectPostsMetrics(ctx, time.Now().Add(-window))
+}
+
+// parseOptionalTime пустое значение - нулевое время
+func parseOptionalTime(value *string, field string) (time.Time, *gen.ValidationError) {
+	if value == nil || *value == "" {
+		return time.Time{}, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, *value)
+	if err != nil {
+		return time.Time{}, &gen.ValidationError{
+			Message: field + " must be RFC3339 time",
+			Field:   stringPtr(field),
+			Rule:    stringPtr("format"),
+		}
+	}
+	return parsed, nil
+}
@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"autoposting/internal/domain"
+	"autoposting/internal/domain/model"
+	"autoposting/internal/presentation/graphql/gen"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"time"
+)
+
+func (u *SocialNetworkUsecase) ImportPageHistory(
+	ctx context.Context,
+	input gen.ImportPageHistoryInput,
+) (gen.ImportPageHistoryOutput, error) {
+	since, err := time.Parse(time.RFC3339, input.Since)
+	if err != nil {
+		return gen.ValidationError{
+			Message: "since must be RFC3339 time",
+			Field:   stringPtr("since"),
+			Rule:    stringPtr("format"),
+		}, nil
+	}
+	if since.After(time.Now()) {
+		return gen.ValidationError{
+			Message: "since must not be in the future",
+			Field:   stringPtr("since"),
+			Rule:    stringPtr("max"),
+		}, nil
+	}
+
+	pageHistoryImport, err := u.postsHistoryService.CreatePageHistoryImport(ctx, input.PageID, since)
+	if err != nil {
+		switch {
+		case domain.IsValidationError(err):
+			return gen.ValidationError{
+				Message: err.Error(),
+				Field:   stringPtr("pageId"),
+				Rule:    stringPtr("pageHistory"),
+			}, nil
+		case domain.IsNotFoundError(err):
+			return gen.ValidationError{
+				Message: err.Error(),
+				Field:   stringPtr("pageId"),
+				Rule:    stringPtr("exists"),
+			}, nil
+		case domain.IsInternalError(err):
+			return gen.InternalError{
+				Message: err.Error(),
+			}, nil
+		default:
+			return nil, ewrap.Errorf("failed to import page %d history: %w", input.PageID, err)
+		}
+	}
+
+	return gen.ImportPageHistoryResult{
+		ImportID: int(pageHistoryImport.ID),
+		Status:   gen.PageHistoryImportStatus(pageHistoryImport.Status),
+	}, nil
+}
+
+// RequeueRunningPageHistoryImports возвращает в очередь задачи, прерванные прошлой остановкой приложения
+func (u *SocialNetworkUsecase) RequeueRunningPageHistoryImports(ctx context.Context) (int, error) {
+	return u.postsHistoryService.RequeueRunningPageHistoryImports(ctx)
+}
+
+// RunPendingPageHistoryImport выполняет одну задачу импорта истории, nil - очередь пуста
+func (u *SocialNetworkUsecase) RunPendingPageHistoryImport(ctx context.Context) (*model.PageHistoryImport, error) {
+	return u.postsHistoryService.RunPendingPageHistoryImport(ctx)
+}
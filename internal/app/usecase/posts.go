@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"autoposting/internal/domain/model"
+	"autoposting/internal/domain/service"
+	"autoposting/internal/infrastructure/postgres"
+	"autoposting/internal/presentation/graphql/gen"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	defaultPostsLimit = 50
+	maxPostsLimit     = 200
+)
+
+func (u *SocialNetworkUsecase) GetPosts(
+	ctx context.Context,
+	input gen.GetPostsInput,
+) (gen.GetPostsOutput, error) {
+	query := postgres.FindPostsQuery{
+		PagesIDAnyOf: input.Pages,
+		Limit:        defaultPostsLimit,
+	}
+	if input.Limit != nil {
+		if *input.Limit <= 0 || *input.Limit > maxPostsLimit {
+			return gen.ValidationError{
+				Message: fmt.Sprintf("limit must be between 1 and %d", maxPostsLimit),
+				Field:   stringPtr("limit"),
+				Rule:    stringPtr("range"),
+			}, nil
+		}
+		query.Limit = *input.Limit
+	}
+	if input.Offset != nil {
+		if *input.Offset < 0 {
+			return gen.ValidationError{
+				Message: "offset must not be negative",
+				Field:   stringPtr("offset"),
+				Rule:    stringPtr("min"),
+			}, nil
+		}
+		query.Offset = *input.Offset
+	}
+	if input.RemoteState != nil {
+		query.RemoteStateAnyOf = []model.PostRemoteState{model.PostRemoteState(*input.RemoteState)}
+	}
+
+	posts, err := u.socialNetworkService.GetPosts(ctx, query)
+	if err != nil {
+		return gen.InternalError{
+			Message: err.Error(),
+		}, nil
+	}
+
+	out := make([]*gen.Post, 0, len(posts))
+	for _, post := range posts {
+		out = append(out, toGenPost(post))
+	}
+
+	return gen.GetPostsResult{
+		Posts: out,
+	}, nil
+}
+
+// ReconcilePosts сверяет с соц сетями посты, опубликованные за window
+func (u *SocialNetworkUsecase) ReconcilePosts(
+	ctx context.Context,
+	window time.Duration,
+) (*service.ReconcileResult, error) {
+	return u.postsHistoryService.ReconcilePosts(ctx, time.Now().Add(-window))
+}
+
+func toGenPost(post model.Post) *gen.Post {
+	out := &gen.Post{
+		ID:          int(post.ID),
+		Page:        post.Page,
+		PostData:    toGenPublishedPostData(post.PostData),
+		PublishedAt: post.PublishedAt.Format(time.RFC3339),
+		RemoteState: gen.PostRemoteState(post.RemoteState),
+		Imported:    post.Imported,
+	}
+	if post.RemoteState == "" {
+		out.RemoteState = gen.PostRemoteStatePublished
+	}
+	if post.RemotePostID != "" {
+		out.RemotePostID = stringPtr(post.RemotePostID)
+	}
+	if post.RemoteText != "" {
+		out.RemoteText = stringPtr(post.RemoteText)
+	}
+	if post.RemoteDiff != "" {
+		out.RemoteDiff = stringPtr(post.RemoteDiff)
+	}
+	if !post.ReconciledAt.IsZero() {
+		out.ReconciledAt = stringPtr(post.ReconciledAt.Format(time.RFC3339))
+	}
+	return out
+}
+
+func toGenPublishedPostData(postData *model.PostData) *gen.PublishedPostData {
+	out := &gen.PublishedPostData{
+		Images:    []string{},
+		ImagesAlt: []string{},
+	}
+	if postData == nil {
+		return out
+	}
+
+	out.Text = postData.Text
+	if postData.Images != nil {
+		out.Images = postData.Images
+	}
+	if postData.ImagesAlt != nil {
+		out.ImagesAlt = postData.ImagesAlt
+	}
+	if postData.Video != "" {
+		out.Video = stringPtr(postData.Video)
+	}
+	if postData.Link != "" {
+		out.Link = stringPtr(postData.Link)
+	}
+	if postData.Poll != nil {
+		out.Poll = &gen.Poll{
+			Question: postData.Poll.Question,
+			Answers:  postData.Poll.Answers,
+		}
+	}
+	if postData.ContentWarning != "" {
+		out.ContentWarning = stringPtr(postData.ContentWarning)
+	}
+	if postData.Visibility != "" {
+		visibility := gen.PostVisibility(strings.ToUpper(postData.Visibility))
+		out.Visibility = &visibility
+	}
+	return out
+}
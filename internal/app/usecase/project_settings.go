@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"autoposting/internal/domain"
+	"autoposting/internal/domain/model"
+	"autoposting/internal/presentation/graphql/gen"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+)
+
+func (u *SocialNetworkUsecase) GetProjectSettings(
+	ctx context.Context,
+	input gen.GetProjectSettingsInput,
+) (gen.GetProjectSettingsOutput, error) {
+	settings, err := u.socialNetworkService.GetProjectSettings(ctx, input.Project)
+	if err != nil {
+		switch {
+		case domain.IsInternalError(err):
+			return gen.InternalError{
+				Message: err.Error(),
+			}, nil
+		default:
+			return nil, ewrap.Errorf("failed to get project %s settings: %w", input.Project, err)
+		}
+	}
+
+	return gen.GetProjectSettingsResult{
+		Settings: toGenProjectSettings(settings),
+	}, nil
+}
+
+func (u *SocialNetworkUsecase) SetProjectTimezone(
+	ctx context.Context,
+	input gen.SetProjectTimezoneInput,
+) (gen.SetProjectTimezoneOutput, error) {
+	settings, err := u.socialNetworkService.SetProjectTimezone(ctx, input.Project, input.Timezone)
+	if err != nil {
+		switch {
+		case domain.IsValidationError(err):
+			return toGenValidationError(err), nil
+		case domain.IsInternalError(err):
+			return gen.InternalError{
+				Message: err.Error(),
+			}, nil
+		default:
+			return nil, ewrap.Errorf("failed to set project %s timezone: %w", input.Project, err)
+		}
+	}
+
+	return gen.SetProjectTimezoneResult{
+		Settings: toGenProjectSettings(settings),
+	}, nil
+}
+
+func toGenProjectSettings(settings *model.ProjectSettings) *gen.ProjectSettings {
+	return &gen.ProjectSettings{
+		Project:  settings.Project,
+		Timezone: settings.Timezone,
+	}
+}
@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"autoposting/internal/domain"
+	"autoposting/internal/presentation/graphql/gen"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultRecommendedSlotsLimit = 5
+	maxRecommendedSlotsLimit     = 7 * 24
+)
+
+var genWeekdays = map[time.Weekday]gen.Weekday{
+	time.Monday:    gen.WeekdayMonday,
+	time.Tuesday:   gen.WeekdayTuesday,
+	time.Wednesday: gen.WeekdayWednesday,
+	time.Thursday:  gen.WeekdayThursday,
+	time.Friday:    gen.WeekdayFriday,
+	time.Saturday:  gen.WeekdaySaturday,
+	time.Sunday:    gen.WeekdaySunday,
+}
+
+func (u *SocialNetworkUsecase) RecommendedSlots(
+	ctx context.Context,
+	input gen.RecommendedSlotsInput,
+) (gen.RecommendedSlotsOutput, error) {
+	limit := defaultRecommendedSlotsLimit
+	if input.Limit != nil {
+		if *input.Limit <= 0 || *input.Limit > maxRecommendedSlotsLimit {
+			return gen.ValidationError{
+				Message: fmt.Sprintf("limit must be between 1 and %d", maxRecommendedSlotsLimit),
+				Field:   stringPtr("limit"),
+				Rule:    stringPtr("range"),
+			}, nil
+		}
+		limit = *input.Limit
+	}
+
+	recommendations, err := u.analyticsService.RecommendSlots(ctx, input.PageID, limit)
+	if err != nil {
+		switch {
+		case domain.IsNotFoundError(err):
+			return gen.ValidationError{
+				Message: err.Error(),
+				Field:   stringPtr("pageId"),
+				Rule:    stringPtr("exists"),
+			}, nil
+		case domain.IsInternalError(err):
+			return gen.InternalError{
+				Message: err.Error(),
+			}, nil
+		default:
+			return nil, ewrap.Errorf("failed to recommend page %d slots: %w", input.PageID, err)
+		}
+	}
+
+	out := gen.RecommendedSlotsResult{
+		PageID:        input.PageID,
+		Timezone:      recommendations.Location.String(),
+		AnalyzedPosts: recommendations.AnalyzedPosts,
+		Slots:         make([]*gen.RecommendedSlot, 0, len(recommendations.Slots)),
+	}
+	for _, slot := range recommendations.Slots {
+		out.Slots = append(out.Slots, &gen.RecommendedSlot{
+			Weekday:      genWeekdays[slot.Weekday],
+			Hour:         slot.Hour,
+			Posts:        slot.Posts,
+			Score:        slot.Score,
+			Confidence:   slot.Confidence,
+			NextStartsAt: slot.NextStartsAt.Format(time.RFC3339),
+		})
+	}
+
+	return out, nil
+}
@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"autoposting/internal/domain"
+	"autoposting/internal/domain/model"
+	"autoposting/internal/infrastructure/postgres"
+	"autoposting/internal/presentation/graphql/gen"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultScheduledPostsLimit = 50
+	maxScheduledPostsLimit     = 200
+)
+
+func (u *SocialNetworkUsecase) SchedulePost(
+	ctx context.Context,
+	input gen.SchedulePostInput,
+) (gen.SchedulePostOutput, error) {
+	if len(input.Pages) == 0 {
+		return gen.ValidationError{
+			Message: "pages are empty",
+			Field:   stringPtr("pages"),
+			Rule:    stringPtr("required"),
+		}, nil
+	}
+	publishAt := time.Now()
+	if input.PublishAt != nil {
+		var err error
+		publishAt, err = time.Parse(time.RFC3339, *input.PublishAt)
+		if err != nil {
+			return gen.ValidationError{
+				Message: "publishAt must be RFC3339 time",
+				Field:   stringPtr("publishAt"),
+				Rule:    stringPtr("format"),
+			}, nil
+		}
+	}
+
+	scheduledPosts, err := u.scheduledPostsService.SchedulePost(
+		ctx,
+		input.Pages,
+		toClientPost(input.PostData),
+		publishAt,
+		input.Auto != nil && *input.Auto,
+	)
+	if err != nil {
+		switch {
+		case domain.IsValidationErrors(err):
+			return toGenValidationErrors(err), nil
+		case domain.IsNotFoundError(err):
+			return gen.ValidationError{
+				Message: err.Error(),
+				Field:   stringPtr("pages"),
+				Rule:    stringPtr("exists"),
+			}, nil
+		case domain.IsInternalError(err):
+			return gen.InternalError{
+				Message: err.Error(),
+			}, nil
+		default:
+			return nil, ewrap.Errorf("failed to schedule post in pages %v: %w", input.Pages, err)
+		}
+	}
+
+	out := gen.SchedulePostResult{
+		ScheduledPosts: make([]*gen.ScheduledPost, 0, len(scheduledPosts)),
+	}
+	for _, scheduledPost := range scheduledPosts {
+		out.ScheduledPosts = append(out.ScheduledPosts, toGenScheduledPost(scheduledPost))
+	}
+
+	return out, nil
+}
+
+func (u *SocialNetworkUsecase) GetScheduledPosts(
+	ctx context.Context,
+	input gen.GetScheduledPostsInput,
+) (gen.GetScheduledPostsOutput, error) {
+	query := postgres.FindScheduledPostsQuery{
+		PagesIDAnyOf: input.Pages,
+		Limit:        defaultScheduledPostsLimit,
+	}
+	if input.Limit != nil {
+		if *input.Limit <= 0 || *input.Limit > maxScheduledPostsLimit {
+			return gen.ValidationError{
+				Message: fmt.Sprintf("limit must be between 1 and %d", maxScheduledPostsLimit),
+				Field:   stringPtr("limit"),
+				Rule:    stringPtr("range"),
+			}, nil
+		}
+		query.Limit = *input.Limit
+	}
+	if input.Offset != nil {
+		if *input.Offset < 0 {
+			return gen.ValidationError{
+				Message: "offset must not be negative",
+				Field:   stringPtr("offset"),
+				Rule:    stringPtr("min"),
+			}, nil
+		}
+		query.Offset = *input.Offset
+	}
+	if input.Status != nil {
+		query.StatusAnyOf = []model.ScheduledPostStatus{model.ScheduledPostStatus(*input.Status)}
+	}
+
+	scheduledPosts, err := u.scheduledPostsService.GetScheduledPosts(ctx, query)
+	if err != nil {
+		return gen.InternalError{
+			Message: err.Error(),
+		}, nil
+	}
+
+	out := gen.GetScheduledPostsResult{
+		ScheduledPosts: make([]*gen.ScheduledPost, 0, len(scheduledPosts)),
+	}
+	for _, scheduledPost := range scheduledPosts {
+		out.ScheduledPosts = append(out.ScheduledPosts, toGenScheduledPost(scheduledPost))
+	}
+
+	return out, nil
+}
+
+// FailInterruptedScheduledPosts отмечает неудачными публикации, прерванные прошлой остановкой приложения
+func (u *SocialNetworkUsecase) FailInterruptedScheduledPosts(ctx context.Context) (int, error) {
+	return u.scheduledPostsService.FailInterruptedScheduledPosts(ctx)
+}
+
+// RunDueScheduledPost публикует один пост, время которого наступило, nil - таких постов нет
+func (u *SocialNetworkUsecase) RunDueScheduledPost(ctx context.Context) (*model.ScheduledPost, error) {
+	return u.scheduledPostsService.RunDueScheduledPost(ctx)
+}
+
+func toGenScheduledPost(scheduledPost model.ScheduledPost) *gen.ScheduledPost {
+	out := &gen.ScheduledPost{
+		ID:          int(scheduledPost.ID),
+		PageID:      scheduledPost.Page,
+		PostData:    toGenPublishedPostData(scheduledPost.PostData),
+		RequestedAt: scheduledPost.RequestedAt.Format(time.RFC3339),
+		PublishAt:   scheduledPost.PublishAt.Format(time.RFC3339),
+		Auto:        scheduledPost.Auto,
+		Status:      gen.ScheduledPostStatus(scheduledPost.Status),
+	}
+	if scheduledPost.RemotePostID != "" {
+		out.RemotePostID = stringPtr(scheduledPost.RemotePostID)
+	}
+	if scheduledPost.Error != "" {
+		out.Error = stringPtr(scheduledPost.Error)
+	}
+	if !scheduledPost.PublishedAt.IsZero() {
+		out.PublishedAt = stringPtr(scheduledPost.PublishedAt.Format(time.RFC3339))
+	}
+	return out
+}
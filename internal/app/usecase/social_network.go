@@ -3,23 +3,84 @@ package usecase
 import (
 	"autoposting/internal/domain"
 	"autoposting/internal/domain/service"
+	"autoposting/internal/infrastructure/social_network_client"
 	"autoposting/internal/presentation/graphql/gen"
 	ewrap "autoposting/pkg/err-wrapper"
 	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
 )
 
 type SocialNetworkUsecase struct {
-	socialNetworkService *service.SocialNetworkService
+	socialNetworkService  *service.SocialNetworkService
+	commentsService       *service.CommentsService
+	analyticsService      *service.AnalyticsService
+	postsHistoryService   *service.PostsHistoryService
+	scheduledPostsService *service.ScheduledPostsService
+	// dryRun включает dry-run для всех createPost
+	dryRun bool
 }
 
 func NewSocialNetworkUsecase(
 	socialNetworkService *service.SocialNetworkService,
+	commentsService *service.CommentsService,
+	analyticsService *service.AnalyticsService,
+	postsHistoryService *service.PostsHistoryService,
+	scheduledPostsService *service.ScheduledPostsService,
+	dryRun bool,
 ) *SocialNetworkUsecase {
 	return &SocialNetworkUsecase{
 		socialNetworkService,
+		commentsService,
+		analyticsService,
+		postsHistoryService,
+		scheduledPostsService,
+		dryRun,
 	}
 }
 
+func (u *SocialNetworkUsecase) GetSocialNetworks(
+	ctx context.Context,
+) ([]*gen.SocialNetwork, error) {
+	var out []*gen.SocialNetwork
+	for _, network := range u.socialNetworkService.GetSocialNetworks() {
+		capabilities := &gen.SocialNetworkCapabilities{
+			Video:             network.Capabilities.Video,
+			Links:             network.Capabilities.Links,
+			Polls:             network.Capabilities.Polls,
+			PollExcludesMedia: network.Capabilities.PollExcludesMedia,
+		}
+		if network.Capabilities.MaxTextLength > 0 {
+			maxTextLength := network.Capabilities.MaxTextLength
+			capabilities.MaxTextLength = &maxTextLength
+		}
+		if network.Capabilities.MaxImages > 0 {
+			maxImages := network.Capabilities.MaxImages
+			capabilities.MaxImages = &maxImages
+		}
+		if network.Capabilities.MinImages > 0 {
+			minImages := network.Capabilities.MinImages
+			capabilities.MinImages = &minImages
+		}
+
+		socialNetwork := &gen.SocialNetwork{
+			Name:          network.Name,
+			DisplayName:   network.DisplayName,
+			RequiresOAuth: network.OAuth != nil,
+			Capabilities:  capabilities,
+		}
+		if network.AuthNetwork != "" {
+			authNetwork := network.AuthNetwork
+			socialNetwork.AuthNetwork = &authNetwork
+		}
+		out = append(out, socialNetwork)
+	}
+
+	return out, nil
+}
+
 func (u *SocialNetworkUsecase) CreateSocialNetworkAccount(
 	ctx context.Context,
 	input gen.CreateSocialNetworkAccountInput,
@@ -133,7 +194,14 @@ func (u *SocialNetworkUsecase) GetAuthURL(
 		socialNetworkAccount.Credentials,
 	)
 	if err != nil {
-		if domain.IsInternalError(err) {
+		switch {
+		case domain.IsValidationError(err):
+			return gen.ValidationError{
+				Message: err.Error(),
+				Field:   stringPtr("socialNetwork"),
+				Rule:    stringPtr("oauth"),
+			}, nil
+		case domain.IsInternalError(err):
 			return gen.InternalError{
 				Message: err.Error(),
 			}, nil
@@ -168,27 +236,69 @@ func (u *SocialNetworkUsecase) GetPagesFromSocialNetwork(
 		}
 	}
 
-	pages, err := u.socialNetworkService.GetPagesFromSocialNetwork(socialNetworkAccount)
-	if err != nil {
-		return gen.InternalError{
-			Message: err.Error(),
-		}, nil
+	var (
+		pages      []social_network_client.SocialNetworkPage
+		nextCursor *string
+	)
+	if input.Limit == nil && input.Cursor == nil {
+		pages, err = u.socialNetworkService.GetPagesFromSocialNetwork(socialNetworkAccount)
+		if err != nil {
+			return gen.InternalError{
+				Message: err.Error(),
+			}, nil
+		}
+	} else {
+		var cursor social_network_client.PagesCursor
+		if input.Limit != nil {
+			if *input.Limit <= 0 {
+				return gen.ValidationError{
+					Message: domain.NewValidationError("limit must be positive", "limit", "min").Error(),
+				}, nil
+			}
+			cursor.Limit = *input.Limit
+		}
+		if input.Cursor != nil {
+			cursor.Cursor = *input.Cursor
+		}
+
+		chunk, err := u.socialNetworkService.GetPagesChunkFromSocialNetwork(socialNetworkAccount, cursor)
+		if err != nil {
+			return gen.InternalError{
+				Message: err.Error(),
+			}, nil
+		}
+		pages = chunk.Pages
+		if chunk.NextCursor != "" {
+			nextCursor = &chunk.NextCursor
+		}
 	}
 
 	var out []*gen.SocialNetworkPage
 	for _, page := range pages {
-		out = append(out, &gen.SocialNetworkPage{
+		page := page
+		outPage := &gen.SocialNetworkPage{
 			PageInfo: &gen.SocialNetworkPageInfo{
 				SocialNetworkID: page.ID,
 				PageName:        page.Name,
 				Description:     &page.Description,
 				PreviewImage:    &page.Image,
 			},
-		})
+		}
+		if page.AccessToken != nil {
+			outPage.AccessToken = &gen.AccessToken{
+				Token: page.AccessToken.Token,
+			}
+			if !page.AccessToken.ExpiresAt.IsZero() {
+				expiresIn := page.AccessToken.ExpiresAt.Format(time.RFC3339)
+				outPage.AccessToken.ExpiresIn = &expiresIn
+			}
+		}
+		out = append(out, outPage)
 	}
 
 	return gen.GetPagesFromSocialNetworkResult{
-		Pages: out,
+		Pages:      out,
+		NextCursor: nextCursor,
 	}, nil
 }
 
@@ -196,5 +306,190 @@ func (u *SocialNetworkUsecase) CreatePost(
 	ctx context.Context,
 	input gen.CreatePostInput,
 ) (gen.CreatePostOutput, error) {
-	return nil, nil
+	if len(input.Pages) == 0 {
+		return gen.ValidationError{
+			Message: "pages are empty",
+			Field:   stringPtr("pages"),
+			Rule:    stringPtr("required"),
+		}, nil
+	}
+
+	if u.dryRun || (input.DryRun != nil && *input.DryRun) {
+		return u.prepareCreatePost(ctx, input)
+	}
+
+	results, err := u.socialNetworkService.CreatePost(ctx, input.Pages, toClientPost(input.PostData))
+	if err != nil {
+		switch {
+		case domain.IsValidationErrors(err):
+			return toGenValidationErrors(err), nil
+		case domain.IsNotFoundError(err):
+			return gen.ValidationError{
+				Message: err.Error(),
+				Field:   stringPtr("pages"),
+				Rule:    stringPtr("exists"),
+			}, nil
+		case domain.IsInternalError(err):
+			return gen.InternalError{
+				Message: err.Error(),
+			}, nil
+		case results == nil:
+			return nil, ewrap.Errorf("failed to create post in pages %v: %w", input.Pages, err)
+		}
+	}
+
+	out := gen.CreatePostResult{
+		Ok:      err == nil,
+		Results: make([]*gen.PostPublishResult, 0, len(results)),
+	}
+	if err != nil {
+		out.Error = stringPtr(err.Error())
+	}
+	for _, result := range results {
+		publishResult := &gen.PostPublishResult{
+			Page:          result.Page.ID,
+			SocialNetwork: string(result.SocialNetwork),
+		}
+		if result.Err != nil {
+			out.Ok = false
+			publishResult.Error = stringPtr(result.Err.Error())
+		} else {
+			publishResult.PostID = stringPtr(result.PostID)
+		}
+		out.Results = append(out.Results, publishResult)
+	}
+
+	return out, nil
+}
+
+func (u *SocialNetworkUsecase) prepareCreatePost(
+	ctx context.Context,
+	input gen.CreatePostInput,
+) (gen.CreatePostOutput, error) {
+	requests, err := u.socialNetworkService.PreparePost(ctx, input.Pages, toClientPost(input.PostData))
+	if err != nil {
+		switch {
+		case domain.IsValidationErrors(err):
+			return toGenValidationErrors(err), nil
+		case domain.IsNotFoundError(err):
+			return gen.ValidationError{
+				Message: err.Error(),
+				Field:   stringPtr("pages"),
+				Rule:    stringPtr("exists"),
+			}, nil
+		case domain.IsInternalError(err):
+			return gen.InternalError{
+				Message: err.Error(),
+			}, nil
+		default:
+			return nil, ewrap.Errorf("failed to prepare post for pages %v: %w", input.Pages, err)
+		}
+	}
+
+	out := gen.CreatePostDryRunResult{
+		Requests: make([]*gen.PreparedRequest, 0, len(requests)),
+	}
+	for _, request := range requests {
+		preparedRequest := &gen.PreparedRequest{
+			SocialNetwork: string(request.SocialNetwork),
+			Pages:         make([]int, 0, len(request.Pages)),
+			Method:        request.Request.Method,
+			URL:           request.Request.URL,
+			Params:        toGenRequestParams(request.Request.Params),
+			Headers:       toGenRequestParams(request.Request.Headers),
+		}
+		for _, page := range request.Pages {
+			preparedRequest.Pages = append(preparedRequest.Pages, page.ID)
+		}
+		if request.Request.Body != "" {
+			preparedRequest.Body = stringPtr(request.Request.Body)
+		}
+		out.Requests = append(out.Requests, preparedRequest)
+	}
+
+	return out, nil
+}
+
+// toGenRequestParams раскладывает параметры или заголовки запроса в отсортированный по имени список
+func toGenRequestParams(values map[string][]string) []*gen.RequestParam {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	params := make([]*gen.RequestParam, 0, len(values))
+	for _, name := range names {
+		for _, value := range values[name] {
+			params = append(params, &gen.RequestParam{
+				Name:  name,
+				Value: value,
+			})
+		}
+	}
+	return params
+}
+
+func toClientPost(postData *gen.PostData) social_network_client.Post {
+	post := social_network_client.Post{
+		Text: postData.Text,
+	}
+	if postData.Image != nil {
+		post.Images = append(post.Images, *postData.Image)
+	}
+	post.Images = append(post.Images, postData.Images...)
+	post.ImagesAlt = postData.ImagesAlt
+	if postData.Video != nil {
+		post.Video = *postData.Video
+	}
+	if postData.Link != nil {
+		post.Link = *postData.Link
+	}
+	if postData.Poll != nil {
+		post.Poll = &social_network_client.Poll{
+			Question: postData.Poll.Question,
+			Answers:  postData.Poll.Answers,
+		}
+	}
+	if postData.ContentWarning != nil {
+		post.ContentWarning = *postData.ContentWarning
+	}
+	if postData.Visibility != nil {
+		post.Visibility = social_network_client.PostVisibility(strings.ToLower(string(*postData.Visibility)))
+	}
+	return post
+}
+
+func toGenValidationErrors(err error) gen.ValidationErrors {
+	var validationErrors *domain.ValidationErrors
+	errors.As(err, &validationErrors)
+
+	out := gen.ValidationErrors{
+		Message: err.Error(),
+		Errors:  make([]*gen.ValidationError, 0, len(validationErrors.Errors)),
+	}
+	for _, validationError := range validationErrors.Errors {
+		out.Errors = append(out.Errors, &gen.ValidationError{
+			Message: validationError.Message,
+			Field:   stringPtr(validationError.Field),
+			Rule:    stringPtr(validationError.Rule),
+		})
+	}
+	return out
+}
+
+func toGenValidationError(err error) gen.ValidationError {
+	out := gen.ValidationError{
+		Message: err.Error(),
+	}
+	var validationError *domain.ValidationError
+	if errors.As(err, &validationError) {
+		out.Field = stringPtr(validationError.Field)
+		out.Rule = stringPtr(validationError.Rule)
+	}
+	return out
+}
+
+func stringPtr(s string) *string {
+	return &s
 }
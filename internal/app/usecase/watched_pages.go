@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"autoposting/internal/domain"
+	"autoposting/internal/domain/service"
+	"autoposting/internal/presentation/graphql/gen"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"strings"
+)
+
+func (u *SocialNetworkUsecase) AddWatchedPage(
+	ctx context.Context,
+	input gen.AddWatchedPageInput,
+) (gen.AddWatchedPageOutput, error) {
+	if strings.TrimSpace(input.Project) == "" {
+		return gen.ValidationError{
+			Message: "project must not be empty",
+			Field:   stringPtr("project"),
+			Rule:    stringPtr("required"),
+		}, nil
+	}
+	if strings.TrimSpace(input.PageID) == "" {
+		return gen.ValidationError{
+			Message: "pageId must not be empty",
+			Field:   stringPtr("pageId"),
+			Rule:    stringPtr("required"),
+		}, nil
+	}
+	name := ""
+	if input.Name != nil {
+		name = *input.Name
+	}
+
+	watchedPage, err := u.analyticsService.AddWatchedPage(
+		ctx,
+		input.Project,
+		input.SocialNetwork,
+		strings.TrimSpace(input.PageID),
+		name,
+	)
+	if err != nil {
+		switch {
+		case domain.IsValidationError(err):
+			return toGenValidationError(err), nil
+		case domain.IsInternalError(err):
+			return gen.InternalError{
+				Message: err.Error(),
+			}, nil
+		default:
+			return nil, ewrap.Errorf("failed to add watched page %s: %w", input.PageID, err)
+		}
+	}
+
+	return gen.AddWatchedPageResult{
+		WatchedPageID: watchedPage.ID,
+	}, nil
+}
+
+func (u *SocialNetworkUsecase) RemoveWatchedPage(
+	ctx context.Context,
+	input gen.RemoveWatchedPageInput,
+) (gen.RemoveWatchedPageOutput, error) {
+	if err := u.analyticsService.RemoveWatchedPage(ctx, input.WatchedPageID); err != nil {
+		switch {
+		case domain.IsNotFoundError(err):
+			return gen.ValidationError{
+				Message: err.Error(),
+				Field:   stringPtr("watchedPageId"),
+				Rule:    stringPtr("exists"),
+			}, nil
+		default:
+			return nil, ewrap.Errorf("failed to remove watched page %d: %w", input.WatchedPageID, err)
+		}
+	}
+
+	return gen.RemoveWatchedPageResult{
+		Ok: true,
+	}, nil
+}
+
+func (u *SocialNetworkUsecase) ComparePages(
+	ctx context.Context,
+	input gen.ComparePagesInput,
+) (gen.ComparePagesOutput, error) {
+	from, validationErr := parseOptionalTime(input.From, "from")
+	if validationErr != nil {
+		return *validationErr, nil
+	}
+	to, validationErr := parseOptionalTime(input.To, "to")
+	if validationErr != nil {
+		return *validationErr, nil
+	}
+
+	comparisons, err := u.analyticsService.ComparePages(ctx, input.Project, from, to)
+	if err != nil {
+		return gen.InternalError{
+			Message: err.Error(),
+		}, nil
+	}
+
+	out := gen.ComparePagesResult{
+		Pages: make([]*gen.PageComparison, 0, len(comparisons)),
+	}
+	for _, comparison := range comparisons {
+		kind := gen.PageComparisonKindOwn
+		if comparison.Watched {
+			kind = gen.PageComparisonKindWatched
+		}
+		out.Pages = append(out.Pages, &gen.PageComparison{
+			Kind:           kind,
+			ID:             comparison.ID,
+			SocialNetwork:  string(comparison.SocialNetwork),
+			PageID:         comparison.PageID,
+			Name:           comparison.Name,
+			StartFollowers: comparison.StartFollowers,
+			EndFollowers:   comparison.EndFollowers,
+			FollowersDelta: comparison.FollowersDelta,
+			Posts:          comparison.Posts,
+			Engagement:     comparison.Engagement,
+			EngagementRate: comparison.EngagementRate,
+		})
+	}
+
+	return out, nil
+}
+
+// CollectWatchedPages собирает подписчиков и посты отслеживаемых страниц
+func (u *SocialNetworkUsecase) CollectWatchedPages(ctx context.Context) (*service.CollectWatchedPagesResult, error) {
+	return u.analyticsService.CollectWatchedPages(ctx)
+}
@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"autoposting/internal/domain/model"
+	"autoposting/internal/infrastructure/postgres"
+	"autoposting/internal/presentation/graphql/gen"
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultWebhookDeliveriesLimit = 50
+	maxWebhookDeliveriesLimit     = 200
+)
+
+func (u *SocialNetworkUsecase) GetWebhookDeliveries(
+	ctx context.Context,
+	input gen.GetWebhookDeliveriesInput,
+) (gen.GetWebhookDeliveriesOutput, error) {
+	query := postgres.FindWebhookDeliveriesQuery{
+		PageAnyOf:       input.Pages,
+		DeliveryIDAnyOf: input.DeliveryIds,
+		Limit:           defaultWebhookDeliveriesLimit,
+	}
+	if input.Limit != nil {
+		if *input.Limit <= 0 || *input.Limit > maxWebhookDeliveriesLimit {
+			return gen.ValidationError{
+				Message: fmt.Sprintf("limit must be between 1 and %d", maxWebhookDeliveriesLimit),
+				Field:   stringPtr("limit"),
+				Rule:    stringPtr("range"),
+			}, nil
+		}
+		query.Limit = *input.Limit
+	}
+	if input.Offset != nil {
+		if *input.Offset < 0 {
+			return gen.ValidationError{
+				Message: "offset must not be negative",
+				Field:   stringPtr("offset"),
+				Rule:    stringPtr("min"),
+			}, nil
+		}
+		query.Offset = *input.Offset
+	}
+
+	deliveries, err := u.socialNetworkService.GetWebhookDeliveries(ctx, query)
+	if err != nil {
+		return gen.InternalError{
+			Message: err.Error(),
+		}, nil
+	}
+
+	out := gen.GetWebhookDeliveriesResult{
+		Deliveries: make([]*gen.WebhookDelivery, 0, len(deliveries)),
+	}
+	for _, delivery := range deliveries {
+		out.Deliveries = append(out.Deliveries, toGenWebhookDelivery(delivery))
+	}
+
+	return out, nil
+}
+
+func toGenWebhookDelivery(delivery model.WebhookDelivery) *gen.WebhookDelivery {
+	return &gen.WebhookDelivery{
+		ID:           int(delivery.ID),
+		PageID:       delivery.Page,
+		DeliveryID:   delivery.DeliveryID,
+		Attempt:      delivery.Attempt,
+		StatusCode:   delivery.StatusCode,
+		ResponseBody: delivery.ResponseBody,
+		Error:        delivery.Error,
+		AttemptedAt:  delivery.AttemptedAt.Format(time.RFC3339),
+	}
+}
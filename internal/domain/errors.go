@@ -3,6 +3,7 @@ package domain
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 type SocialNetworkAccountAlreadyExistsError struct {
@@ -19,6 +20,11 @@ type ValidationError struct {
 	Rule    string
 }
 
+// ValidationErrors несколько нарушений, найденных за одну проверку
+type ValidationErrors struct {
+	Errors []*ValidationError
+}
+
 type InternalError struct {
 	Message string
 }
@@ -27,6 +33,11 @@ type NotFoundError struct {
 	Message string
 }
 
+// ForbiddenError запрос не прошел проверку подписи или секрета
+type ForbiddenError struct {
+	Message string
+}
+
 func NewSocialNetworkAccountAlreadyExistsError(message string) *SocialNetworkAccountAlreadyExistsError {
 	return &SocialNetworkAccountAlreadyExistsError{
 		Message: message,
@@ -47,6 +58,12 @@ func NewValidationError(message string, field string, rule string) *ValidationEr
 	}
 }
 
+func NewValidationErrors(errors []*ValidationError) *ValidationErrors {
+	return &ValidationErrors{
+		Errors: errors,
+	}
+}
+
 func NewInternalError(message string) *InternalError {
 	return &InternalError{
 		Message: message,
@@ -59,6 +76,12 @@ func NewNotFoundError(message string) error {
 	}
 }
 
+func NewForbiddenError(message string) error {
+	return &ForbiddenError{
+		Message: message,
+	}
+}
+
 func IsSocialNetworkAccountAlreadyExistsError(err error) bool {
 	var e *SocialNetworkAccountAlreadyExistsError
 
@@ -77,6 +100,12 @@ func IsValidationError(err error) bool {
 	return errors.As(err, &e)
 }
 
+func IsValidationErrors(err error) bool {
+	var e *ValidationErrors
+
+	return errors.As(err, &e)
+}
+
 func IsInternalError(err error) bool {
 	var e *InternalError
 
@@ -89,6 +118,12 @@ func IsNotFoundError(err error) bool {
 	return errors.As(err, &e)
 }
 
+func IsForbiddenError(err error) bool {
+	var e *ForbiddenError
+
+	return errors.As(err, &e)
+}
+
 func (e *SocialNetworkAccountAlreadyExistsError) Error() string {
 	return e.Message
 }
@@ -106,6 +141,14 @@ func (e *ValidationError) Error() string {
 	)
 }
 
+func (e *ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, validationError := range e.Errors {
+		messages = append(messages, validationError.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
 func (e *InternalError) Error() string {
 	return e.Message
 }
@@ -113,3 +156,7 @@ func (e *InternalError) Error() string {
 func (e *NotFoundError) Error() string {
 	return e.Message
 }
+
+func (e *ForbiddenError) Error() string {
+	return e.Message
+}
@@ -0,0 +1,34 @@
+package model
+
+import (
+	"github.com/uptrace/bun"
+	"time"
+)
+
+// Comment комментарий к посту страницы, собранный из соц сети
+type Comment struct {
+	bun.BaseModel   `bun:"table:comments,alias:comment"`
+	ID              int64             `bun:"id,pk,autoincrement"`
+	Page            int               `bun:"page"`
+	Post            int64             `bun:"post"`
+	SocialNetwork   SocialNetworkName `bun:"social_network"`
+	RemotePostID    string            `bun:"remote_post_id"`
+	RemoteCommentID string            `bun:"remote_comment_id"`
+	// ParentRemoteCommentID комментарий, на который это ответ
+	ParentRemoteCommentID string `bun:"parent_remote_comment_id,nullzero"`
+	AuthorID              string `bun:"author_id"`
+	AuthorName            string `bun:"author_name"`
+	// ByPage комментарий оставлен от имени страницы, ответа он не требует
+	ByPage    bool      `bun:"by_page"`
+	Text      string    `bun:"text"`
+	CreatedAt time.Time `bun:"created_at"`
+	// AnsweredAt время первого ответа страницы, нулевое - ответа нет
+	AnsweredAt time.Time `bun:"answered_at,nullzero"`
+	// DeletedAt комментарий удален в соц сети или через API
+	DeletedAt time.Time `bun:"deleted_at,nullzero"`
+	// HiddenAt комментарий скрыт модерацией, нулевое - виден всем
+	HiddenAt time.Time `bun:"hidden_at,nullzero"`
+	// ProcessedAt комментарий обработан правилами модерации и автоответов, нулевое - еще не обработан или изменен после обработки
+	ProcessedAt time.Time `bun:"processed_at,nullzero"`
+	CollectedAt time.Time `bun:"collected_at"`
+}
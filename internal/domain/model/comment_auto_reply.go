@@ -0,0 +1,38 @@
+package model
+
+import (
+	"github.com/uptrace/bun"
+	"time"
+)
+
+// CommentAutoReplyRule автоответ на комментарии к посту или, если Post nil, ко всем постам страницы.
+// Правило отвечает только на комментарии, оставленные после его создания
+type CommentAutoReplyRule struct {
+	bun.BaseModel `bun:"table:comment_auto_reply_rules,alias:rule"`
+	ID            int    `bun:"id,pk,autoincrement"`
+	Page          int    `bun:"page"`
+	Post          *int64 `bun:"post"`
+	// Patterns регулярные выражения без учета регистра, достаточно совпадения одного
+	Patterns []string `bun:"patterns,array"`
+	// Template текст ответа, {author} заменяется именем автора комментария
+	Template string `bun:"template"`
+	// CooldownMinutes после автоответа автору на странице правило не отвечает ему это время
+	CooldownMinutes int `bun:"cooldown_minutes"`
+	// DailyLimit правило не отвечает, если на странице за последние сутки уже столько автоответов
+	DailyLimit int       `bun:"daily_limit"`
+	CreatedAt  time.Time `bun:"created_at"`
+}
+
+// CommentAutoReply ответ, отправленный правилом автоответа. История остается после удаления правила,
+// чтобы лимиты страницы не сбрасывались
+type CommentAutoReply struct {
+	bun.BaseModel `bun:"table:comment_auto_replies,alias:reply"`
+	ID            int64 `bun:"id,pk,autoincrement"`
+	// Rule nil для удаленных правил
+	Rule                 *int      `bun:"rule"`
+	Page                 int       `bun:"page"`
+	Comment              int64     `bun:"comment"`
+	AuthorID             string    `bun:"author_id"`
+	ReplyRemoteCommentID string    `bun:"reply_remote_comment_id"`
+	CreatedAt            time.Time `bun:"created_at"`
+}
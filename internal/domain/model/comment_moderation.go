@@ -0,0 +1,56 @@
+package model
+
+import (
+	"github.com/uptrace/bun"
+	"time"
+)
+
+type CommentModerationRuleKind string
+
+const (
+	// CommentModerationRuleKeywords комментарий содержит одно из слов Values без учета регистра
+	CommentModerationRuleKeywords CommentModerationRuleKind = "KEYWORDS"
+	// CommentModerationRuleRegex текст комментария совпадает с одним из выражений Values
+	CommentModerationRuleRegex CommentModerationRuleKind = "REGEX"
+	// CommentModerationRuleLinkOnly в комментарии нет ничего, кроме ссылок
+	CommentModerationRuleLinkOnly CommentModerationRuleKind = "LINK_ONLY"
+	// CommentModerationRuleRepeatPoster автор оставил на странице больше RepeatLimit комментариев за RepeatWindowMinutes
+	CommentModerationRuleRepeatPoster CommentModerationRuleKind = "REPEAT_POSTER"
+)
+
+type CommentModerationAction string
+
+const (
+	CommentModerationActionHide   CommentModerationAction = "HIDE"
+	CommentModerationActionDelete CommentModerationAction = "DELETE"
+)
+
+// CommentModerationRule правило модерации комментариев страниц проекта.
+// Правила проверяются по возрастанию id, срабатывает первое подходящее
+type CommentModerationRule struct {
+	bun.BaseModel       `bun:"table:comment_moderation_rules,alias:rule"`
+	ID                  int                       `bun:"id,pk,autoincrement"`
+	Project             string                    `bun:"project"`
+	Kind                CommentModerationRuleKind `bun:"kind"`
+	Values              []string                  `bun:"values,array"`
+	RepeatLimit         int                       `bun:"repeat_limit"`
+	RepeatWindowMinutes int                       `bun:"repeat_window_minutes"`
+	Action              CommentModerationAction   `bun:"action"`
+	// AllowIrreversible разрешает DELETE в соц сетях, где удаленный комментарий нельзя восстановить
+	AllowIrreversible bool      `bun:"allow_irreversible"`
+	CreatedAt         time.Time `bun:"created_at"`
+}
+
+// CommentModerationLog действие модерации над комментарием. Rule nil, если правило уже удалено
+type CommentModerationLog struct {
+	bun.BaseModel `bun:"table:comment_moderation_log,alias:log"`
+	ID            int64                   `bun:"id,pk,autoincrement"`
+	Comment       int64                   `bun:"comment"`
+	Rule          *int                    `bun:"rule"`
+	Action        CommentModerationAction `bun:"action"`
+	// Reason что именно совпало, например слово или выражение
+	Reason    string    `bun:"reason"`
+	CreatedAt time.Time `bun:"created_at"`
+	// RevertedAt действие отменено, комментарий снова виден
+	RevertedAt time.Time `bun:"reverted_at,nullzero"`
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"github.com/uptrace/bun"
+	"time"
+)
+
+// PageAudience снимок числа подписчиков страницы
+type PageAudience struct {
+	bun.BaseModel `bun:"table:page_audience"`
+	ID            int64     `bun:"id,pk,autoincrement"`
+	Page          int       `bun:"page"`
+	CollectedAt   time.Time `bun:"collected_at"`
+	Followers     int       `bun:"followers"`
+}
+
+// AudiencePoint подписчики на конец дня Date
+type AudiencePoint struct {
+	Date      time.Time
+	Followers int
+}
+
+// AudienceGrowth ряд подписчиков страницы или проекта, Page нулевой у ряда проекта
+type AudienceGrowth struct {
+	Page           int
+	Project        string
+	Points         []AudiencePoint
+	StartFollowers int
+	EndFollowers   int
+	Delta          int
+}
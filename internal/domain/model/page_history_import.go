@@ -0,0 +1,31 @@
+package model
+
+import (
+	"github.com/uptrace/bun"
+	"time"
+)
+
+type PageHistoryImportStatus string
+
+const (
+	PageHistoryImportStatusPending PageHistoryImportStatus = "PENDING"
+	PageHistoryImportStatusRunning PageHistoryImportStatus = "RUNNING"
+	PageHistoryImportStatusDone    PageHistoryImportStatus = "DONE"
+	PageHistoryImportStatusFailed  PageHistoryImportStatus = "FAILED"
+)
+
+// PageHistoryImport задача импорта постов страницы, опубликованных после Since.
+// Fetched - получено из соц сети, Imported - сохранено, остальные уже были в posts
+type PageHistoryImport struct {
+	bun.BaseModel `bun:"table:page_history_imports"`
+	ID            int64                   `bun:"id,pk,autoincrement"`
+	Page          int                     `bun:"page"`
+	Since         time.Time               `bun:"since"`
+	Status        PageHistoryImportStatus `bun:"status"`
+	Fetched       int                     `bun:"fetched"`
+	Imported      int                     `bun:"imported"`
+	Error         string                  `bun:"error,nullzero"`
+	CreatedAt     time.Time               `bun:"created_at"`
+	StartedAt     time.Time               `bun:"started_at,nullzero"`
+	FinishedAt    time.Time               `bun:"finished_at,nullzero"`
+}
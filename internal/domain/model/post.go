@@ -0,0 +1,50 @@
+package model
+
+import (
+	"github.com/uptrace/bun"
+	"time"
+)
+
+// PostRemoteState состояние поста в соц сети по результатам последней сверки
+type PostRemoteState string
+
+const (
+	PostRemoteStatePublished PostRemoteState = "PUBLISHED"
+	// PostRemoteStateModified текст поста изменили в соц сети
+	PostRemoteStateModified PostRemoteState = "MODIFIED"
+	// PostRemoteStateDeleted пост удалили в соц сети
+	PostRemoteStateDeleted PostRemoteState = "DELETED"
+)
+
+type Post struct {
+	bun.BaseModel `bun:"table:posts"`
+	ID            int64           `bun:"id,pk,autoincrement"`
+	Page          int             `bun:"page"`
+	RemotePostID  string          `bun:"remote_post_id"`
+	PostData      *PostData       `bun:"post_data"`
+	PublishedAt   time.Time       `bun:"published_at"`
+	RemoteState   PostRemoteState `bun:"remote_state"`
+	// Imported пост опубликован в соц сети до подключения страницы и загружен импортом истории
+	Imported bool `bun:"imported"`
+	// RemoteText текст поста в соц сети, если он отличается от опубликованного
+	RemoteText string `bun:"remote_text,nullzero"`
+	// RemoteDiff построчная разница опубликованного текста и RemoteText
+	RemoteDiff   string    `bun:"remote_diff,nullzero"`
+	ReconciledAt time.Time `bun:"reconciled_at,nullzero"`
+}
+
+type PostData struct {
+	Text           string    `json:"text"`
+	Images         []string  `json:"images,omitempty"`
+	ImagesAlt      []string  `json:"imagesAlt,omitempty"`
+	Video          string    `json:"video,omitempty"`
+	Link           string    `json:"link,omitempty"`
+	Poll           *PostPoll `json:"poll,omitempty"`
+	ContentWarning string    `json:"contentWarning,omitempty"`
+	Visibility     string    `json:"visibility,omitempty"`
+}
+
+type PostPoll struct {
+	Question string   `json:"question"`
+	Answers  []string `json:"answers"`
+}
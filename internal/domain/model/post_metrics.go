@@ -0,0 +1,49 @@
+package model
+
+import (
+	"github.com/uptrace/bun"
+	"time"
+)
+
+// PostMetrics снимок счетчиков поста, ряд снимков по CollectedAt - динамика поста
+type PostMetrics struct {
+	bun.BaseModel `bun:"table:post_metrics"`
+	ID            int64     `bun:"id,pk,autoincrement"`
+	Post          int64     `bun:"post"`
+	CollectedAt   time.Time `bun:"collected_at"`
+	Likes         int       `bun:"likes"`
+	Reposts       int       `bun:"reposts"`
+	Comments      int       `bun:"comments"`
+	Views         int       `bun:"views"`
+	Reach         int       `bun:"reach"`
+}
+
+type PostMetricsGroupBy string
+
+const (
+	PostMetricsGroupByPage          PostMetricsGroupBy = "PAGE"
+	PostMetricsGroupByProject       PostMetricsGroupBy = "PROJECT"
+	PostMetricsGroupBySocialNetwork PostMetricsGroupBy = "SOCIAL_NETWORK"
+)
+
+// PostMetricsAggregate сумма последних снимков постов группы, Key - id страницы, проект или соц сеть
+type PostMetricsAggregate struct {
+	Key      string `bun:"key"`
+	Posts    int    `bun:"posts"`
+	Likes    int    `bun:"likes"`
+	Reposts  int    `bun:"reposts"`
+	Comments int    `bun:"comments"`
+	Views    int    `bun:"views"`
+	Reach    int    `bun:"reach"`
+}
+
+// PublishedPostMetrics последний снимок метрик поста вместе со временем публикации
+type PublishedPostMetrics struct {
+	Post        int64     `bun:"post"`
+	PublishedAt time.Time `bun:"published_at"`
+	Likes       int       `bun:"likes"`
+	Reposts     int       `bun:"reposts"`
+	Comments    int       `bun:"comments"`
+	Views       int       `bun:"views"`
+	Reach       int       `bun:"reach"`
+}
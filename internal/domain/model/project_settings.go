@@ -0,0 +1,10 @@
+package model
+
+import "github.com/uptrace/bun"
+
+// ProjectSettings настройки проекта, Timezone - часовой пояс IANA, в котором проект планирует публикации
+type ProjectSettings struct {
+	bun.BaseModel `bun:"table:project_settings"`
+	Project       string `bun:"project,pk"`
+	Timezone      string `bun:"timezone"`
+}
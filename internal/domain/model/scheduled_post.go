@@ -0,0 +1,32 @@
+package model
+
+import (
+	"github.com/uptrace/bun"
+	"time"
+)
+
+type ScheduledPostStatus string
+
+const (
+	ScheduledPostStatusPending    ScheduledPostStatus = "PENDING"
+	ScheduledPostStatusPublishing ScheduledPostStatus = "PUBLISHING"
+	ScheduledPostStatusPublished  ScheduledPostStatus = "PUBLISHED"
+	ScheduledPostStatusFailed     ScheduledPostStatus = "FAILED"
+)
+
+// ScheduledPost пост, который фоновая задача опубликует в страницу в PublishAt.
+// Auto - PublishAt сдвинут к рекомендованному окну страницы, RequestedAt - время, которое просили
+type ScheduledPost struct {
+	bun.BaseModel `bun:"table:scheduled_posts,alias:scheduled"`
+	ID            int64               `bun:"id,pk,autoincrement"`
+	Page          int                 `bun:"page"`
+	PostData      *PostData           `bun:"post_data"`
+	RequestedAt   time.Time           `bun:"requested_at"`
+	PublishAt     time.Time           `bun:"publish_at"`
+	Auto          bool                `bun:"auto"`
+	Status        ScheduledPostStatus `bun:"status"`
+	RemotePostID  string              `bun:"remote_post_id,nullzero"`
+	Error         string              `bun:"error,nullzero"`
+	CreatedAt     time.Time           `bun:"created_at"`
+	PublishedAt   time.Time           `bun:"published_at,nullzero"`
+}
@@ -8,6 +8,8 @@ type SocialNetworkAccount struct {
 	SocialNetwork SocialNetworkName `bun:"social_network"`
 	Credentials   string            `bun:"credentials"`
 	AccessToken   *AccessToken      `bun:"access_token,nullzero"`
+	// AuthAccount аккаунт, credentials и токен которого использует соц сеть без своего OAuth, например Instagram
+	AuthAccount *int `bun:"auth_account"`
 }
 
 type AccessToken struct {
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"encoding/json"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+// SocialNetworkEvent событие страницы из уведомлений соц сетей, Page nil для страниц, не добавленных в проекты
+type SocialNetworkEvent struct {
+	bun.BaseModel   `bun:"table:social_network_events"`
+	ID              int64             `bun:"id,pk,autoincrement"`
+	SocialNetwork   SocialNetworkName `bun:"social_network"`
+	EventID         string            `bun:"event_id"`
+	Page            *int              `bun:"page"`
+	RemotePageID    string            `bun:"remote_page_id"`
+	Type            string            `bun:"type"`
+	RemotePostID    string            `bun:"remote_post_id,nullzero"`
+	RemoteCommentID string            `bun:"remote_comment_id,nullzero"`
+	AuthorID        string            `bun:"author_id,nullzero"`
+	ByPage          bool              `bun:"by_page"`
+	Text            string            `bun:"text,nullzero"`
+	Payload         json.RawMessage   `bun:"payload"`
+	OccurredAt      time.Time         `bun:"occurred_at"`
+	ReceivedAt      time.Time         `bun:"received_at"`
+}
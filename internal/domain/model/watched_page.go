@@ -0,0 +1,41 @@
+package model
+
+import (
+	"github.com/uptrace/bun"
+	"time"
+)
+
+// WatchedPage публичная страница, которую проект отслеживает для сравнения со своими страницами.
+// Страница не принадлежит проекту, данные собираются токенами аккаунтов той же соц сети
+type WatchedPage struct {
+	bun.BaseModel `bun:"table:watched_pages"`
+	ID            int               `bun:"id,pk,autoincrement"`
+	Project       string            `bun:"project"`
+	SocialNetwork SocialNetworkName `bun:"social_network"`
+	PageID        string            `bun:"page_id"`
+	Name          string            `bun:"name"`
+	CreatedAt     time.Time         `bun:"created_at"`
+}
+
+// WatchedPageAudience снимок числа подписчиков отслеживаемой страницы
+type WatchedPageAudience struct {
+	bun.BaseModel `bun:"table:watched_page_audience"`
+	ID            int64     `bun:"id,pk,autoincrement"`
+	WatchedPage   int       `bun:"watched_page"`
+	CollectedAt   time.Time `bun:"collected_at"`
+	Followers     int       `bun:"followers"`
+}
+
+// WatchedPagePost пост отслеживаемой страницы с последними собранными счетчиками
+type WatchedPagePost struct {
+	bun.BaseModel `bun:"table:watched_page_posts"`
+	ID            int64     `bun:"id,pk,autoincrement"`
+	WatchedPage   int       `bun:"watched_page"`
+	RemotePostID  string    `bun:"remote_post_id"`
+	PublishedAt   time.Time `bun:"published_at"`
+	Likes         int       `bun:"likes"`
+	Reposts       int       `bun:"reposts"`
+	Comments      int       `bun:"comments"`
+	Views         int       `bun:"views"`
+	CollectedAt   time.Time `bun:"collected_at"`
+}
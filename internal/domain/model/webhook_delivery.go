@@ -0,0 +1,20 @@
+package model
+
+import (
+	"github.com/uptrace/bun"
+	"time"
+)
+
+// WebhookDelivery попытка доставки поста получателю вебхука. StatusCode nil - ответ не получен,
+// Error - причина неудачной попытки
+type WebhookDelivery struct {
+	bun.BaseModel `bun:"table:webhook_deliveries,alias:delivery"`
+	ID            int64     `bun:"id,pk,autoincrement"`
+	Page          int       `bun:"page"`
+	DeliveryID    string    `bun:"delivery_id"`
+	Attempt       int       `bun:"attempt"`
+	StatusCode    *int      `bun:"status_code"`
+	ResponseBody  string    `bun:"response_body"`
+	Error         *string   `bun:"error"`
+	AttemptedAt   time.Time `bun:"attempted_at"`
+}
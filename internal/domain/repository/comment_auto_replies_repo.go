@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"autoposting/internal/domain/model"
+	"autoposting/internal/infrastructure/postgres"
+	"context"
+)
+
+type CommentAutoRepliesRepository interface {
+	CreateRule(context.Context, *model.CommentAutoReplyRule) error
+	DeleteRule(context.Context, int) error
+	FindRules(context.Context, postgres.FindCommentAutoReplyRulesQuery) ([]model.CommentAutoReplyRule, error)
+	CreateReply(context.Context, *model.CommentAutoReply) error
+	CountReplies(context.Context, postgres.CountCommentAutoRepliesQuery) (int, error)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"autoposting/internal/domain/model"
+	"autoposting/internal/infrastructure/postgres"
+	"context"
+	"time"
+)
+
+type CommentModerationRepository interface {
+	CreateRule(context.Context, *model.CommentModerationRule) error
+	DeleteRule(context.Context, int) error
+	FindRules(context.Context, postgres.FindCommentModerationRulesQuery) ([]model.CommentModerationRule, error)
+	CreateLog(context.Context, *model.CommentModerationLog) error
+	MarkLogReverted(context.Context, int64, time.Time) error
+	FindLog(context.Context, postgres.FindCommentModerationLogQuery) ([]model.CommentModerationLog, error)
+}
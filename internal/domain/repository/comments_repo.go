@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"autoposting/internal/domain/model"
+	"autoposting/internal/infrastructure/postgres"
+	"context"
+	"time"
+)
+
+type CommentsRepository interface {
+	UpsertComments(context.Context, []model.Comment) error
+	MarkMissingCommentsDeleted(context.Context, int64, []string, time.Time) (int, error)
+	MarkCommentDeleted(context.Context, int64, time.Time) error
+	MarkCommentAnswered(context.Context, int64, time.Time) error
+	MarkCommentHidden(context.Context, int64, time.Time) error
+	MarkCommentRestored(context.Context, int64) error
+	MarkCommentsProcessed(context.Context, []int64, time.Time) error
+	CountAuthorComments(context.Context, int, string, time.Time) (int, error)
+	FindComments(context.Context, postgres.FindCommentsQuery) ([]model.Comment, error)
+}
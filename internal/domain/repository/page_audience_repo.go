@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"autoposting/internal/domain/model"
+	"autoposting/internal/infrastructure/postgres"
+	"context"
+)
+
+type PageAudienceRepository interface {
+	CreateAudience(context.Context, []model.PageAudience) error
+	FindDailyAudience(context.Context, postgres.FindPageAudienceQuery) ([]model.PageAudience, error)
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"autoposting/internal/domain/model"
+	"context"
+	"time"
+)
+
+type PageHistoryImportsRepository interface {
+	CreateImport(context.Context, *model.PageHistoryImport) error
+	TakePendingImport(context.Context, time.Time) (*model.PageHistoryImport, error)
+	FinishImport(context.Context, *model.PageHistoryImport) error
+	RequeueRunningImports(context.Context) (int, error)
+}
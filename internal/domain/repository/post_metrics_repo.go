@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"autoposting/internal/domain/model"
+	"autoposting/internal/infrastructure/postgres"
+	"context"
+)
+
+type PostMetricsRepository interface {
+	CreateMetrics(context.Context, []model.PostMetrics) error
+	FindMetrics(context.Context, postgres.FindPostMetricsQuery) ([]model.PostMetrics, error)
+	AggregateMetrics(context.Context, postgres.AggregatePostMetricsQuery) ([]model.PostMetricsAggregate, error)
+	FindLatestMetrics(context.Context, postgres.FindLatestPostMetricsQuery) ([]model.PublishedPostMetrics, error)
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"autoposting/internal/domain/model"
+	"autoposting/internal/infrastructure/postgres"
+	"context"
+)
+
+type PostsRepository interface {
+	CreatePosts(context.Context, []model.Post) error
+	CreateImportedPosts(context.Context, []model.Post) (int, error)
+	FindPosts(context.Context, postgres.FindPostsQuery) ([]model.Post, error)
+	UpdatePostsRemoteState(context.Context, []model.Post) error
+}
@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"autoposting/internal/domain/model"
+	"context"
+)
+
+type ProjectSettingsRepository interface {
+	SaveSettings(context.Context, *model.ProjectSettings) error
+	FindSettings(context.Context, string) (*model.ProjectSettings, error)
+}
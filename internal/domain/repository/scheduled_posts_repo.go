@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"autoposting/internal/domain/model"
+	"autoposting/internal/infrastructure/postgres"
+	"context"
+	"time"
+)
+
+type ScheduledPostsRepository interface {
+	CreateScheduledPosts(context.Context, []model.ScheduledPost) error
+	FindScheduledPosts(context.Context, postgres.FindScheduledPostsQuery) ([]model.ScheduledPost, error)
+	TakeDuePost(context.Context, time.Time) (*model.ScheduledPost, error)
+	FinishScheduledPost(context.Context, *model.ScheduledPost) error
+	FailPublishingPosts(context.Context, string) (int, error)
+}
@@ -0,0 +1,10 @@
+package repository
+
+import (
+	"autoposting/internal/domain/model"
+	"context"
+)
+
+type SocialNetworkEventsRepository interface {
+	CreateEvents(context.Context, []model.SocialNetworkEvent) (int, error)
+}
@@ -2,10 +2,11 @@ package repository
 
 import (
 	"autoposting/internal/domain/model"
+	"autoposting/internal/infrastructure/postgres"
 	"context"
 )
 
 type SocialNetworkPagesRepository interface {
 	CreatePage(context.Context, *model.SocialNetworkPage) error
-	FindPage()
+	FindPages(context.Context, postgres.FindSocialNetworkPageQuery) ([]model.SocialNetworkPage, error)
 }
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"autoposting/internal/domain/model"
+	"autoposting/internal/infrastructure/postgres"
+	"context"
+)
+
+type WatchedPagesRepository interface {
+	CreateWatchedPage(context.Context, *model.WatchedPage) error
+	DeleteWatchedPage(context.Context, int) error
+	FindWatchedPages(context.Context, postgres.FindWatchedPagesQuery) ([]model.WatchedPage, error)
+	CreateWatchedPagesAudience(context.Context, []model.WatchedPageAudience) error
+	UpsertWatchedPagePosts(context.Context, []model.WatchedPagePost) error
+	FindDailyWatchedPagesAudience(context.Context, postgres.FindWatchedPagesStatsQuery) ([]model.WatchedPageAudience, error)
+	AggregateWatchedPagePosts(context.Context, postgres.FindWatchedPagesStatsQuery) ([]model.PostMetricsAggregate, error)
+}
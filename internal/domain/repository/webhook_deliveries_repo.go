@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"autoposting/internal/domain/model"
+	"autoposting/internal/infrastructure/postgres"
+	"context"
+)
+
+type WebhookDeliveriesRepository interface {
+	CreateDeliveries(context.Context, []model.WebhookDelivery) error
+	FindDeliveries(context.Context, postgres.FindWebhookDeliveriesQuery) ([]model.WebhookDelivery, error)
+}
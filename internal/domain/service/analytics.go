@@ -0,0 +1,31 @@
+package service
+
+import (
+	"autoposting/internal/domain/repository"
+	"log/slog"
+)
+
+// AnalyticsService метрики постов, аудитория страниц, рекомендации и наблюдаемые страницы
+type AnalyticsService struct {
+	logger                 *slog.Logger
+	socialNetworkService   *SocialNetworkService
+	postMetricsRepository  repository.PostMetricsRepository
+	pageAudienceRepository repository.PageAudienceRepository
+	watchedPagesRepository repository.WatchedPagesRepository
+}
+
+func NewAnalyticsService(
+	logger *slog.Logger,
+	socialNetworkService *SocialNetworkService,
+	postMetricsRepository repository.PostMetricsRepository,
+	pageAudienceRepository repository.PageAudienceRepository,
+	watchedPagesRepository repository.WatchedPagesRepository,
+) *AnalyticsService {
+	return &AnalyticsService{
+		logger:                 logger,
+		socialNetworkService:   socialNetworkService,
+		postMetricsRepository:  postMetricsRepository,
+		pageAudienceRepository: pageAudienceRepository,
+		watchedPagesRepository: watchedPagesRepository,
+	}
+}
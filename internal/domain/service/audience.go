@@ -0,0 +1,221 @@
+package service
+
+import (
+	"autoposting/internal/domain/model"
+	"autoposting/internal/infrastructure/postgres"
+	"autoposting/internal/infrastructure/social_network_client"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// CollectAudienceResult итог сбора подписчиков, Failed - страницы, число подписчиков которых не удалось получить
+type CollectAudienceResult struct {
+	Collected int
+	Failed    int
+}
+
+// AudienceGrowthResult ряды подписчиков по страницам и по их проектам
+type AudienceGrowthResult struct {
+	Pages    []model.AudienceGrowth
+	Projects []model.AudienceGrowth
+}
+
+// CollectPagesAudience сохраняет снимок числа подписчиков всех страниц,
+// соц сети без PageAudienceFetcher пропускаются
+func (as *AnalyticsService) CollectPagesAudience(ctx context.Context) (*CollectAudienceResult, error) {
+	pages, err := as.socialNetworkService.socialNetworkPagesRepository.FindPages(
+		ctx,
+		postgres.FindSocialNetworkPageQuery{},
+	)
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find pages to collect audience: %w", err)
+	}
+	result := &CollectAudienceResult{}
+	if len(pages) == 0 {
+		return result, nil
+	}
+
+	pagesIDs := make([]int, 0, len(pages))
+	for _, page := range pages {
+		pagesIDs = append(pagesIDs, page.ID)
+	}
+	targets, err := as.socialNetworkService.getPublishTargets(ctx, pagesIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var audience []model.PageAudience
+	collectedAt := time.Now()
+	for _, target := range targets {
+		client := as.socialNetworkService.getClient(target.account.SocialNetwork)
+		fetcher, ok := client.(social_network_client.PageAudienceFetcher)
+		if !ok {
+			continue
+		}
+
+		remotePagesIDs := make([]string, 0, len(target.pages))
+		pagesByRemoteID := make(map[string]int, len(target.pages))
+		for _, page := range target.pages {
+			remotePagesIDs = append(remotePagesIDs, page.PageID)
+			pagesByRemoteID[page.PageID] = page.ID
+		}
+
+		audienceResults, err := fetcher.GetPagesAudience(target.account.Credentials, target.accessToken, remotePagesIDs)
+		if err != nil {
+			as.logger.Error(
+				"failed to get pages audience",
+				slog.String("socialNetwork", string(target.account.SocialNetwork)),
+				slog.Any("err", err),
+			)
+			result.Failed += len(target.pages)
+			continue
+		}
+
+		for _, audienceResult := range audienceResults {
+			pageID, ok := pagesByRemoteID[audienceResult.PageID]
+			if !ok {
+				continue
+			}
+			if audienceResult.Err != nil {
+				as.logger.Warn(
+					"failed to get page audience",
+					slog.String("socialNetwork", string(target.account.SocialNetwork)),
+					slog.Int("page", pageID),
+					slog.Any("err", audienceResult.Err),
+				)
+				result.Failed++
+				continue
+			}
+
+			audience = append(audience, model.PageAudience{
+				Page:        pageID,
+				CollectedAt: collectedAt,
+				Followers:   audienceResult.Followers,
+			})
+			result.Collected++
+		}
+	}
+
+	if err := as.pageAudienceRepository.CreateAudience(ctx, audience); err != nil {
+		return result, ewrap.Errorf("failed to save pages audience: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetAudienceGrowth ряды строятся по последнему снимку дня. В ряду проекта страница,
+// у которой нет снимка за день, учитывается своим последним известным значением
+func (as *AnalyticsService) GetAudienceGrowth(
+	ctx context.Context,
+	query postgres.FindPageAudienceQuery,
+) (*AudienceGrowthResult, error) {
+	audience, err := as.pageAudienceRepository.FindDailyAudience(ctx, query)
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find pages audience: %w", err)
+	}
+	result := &AudienceGrowthResult{}
+	if len(audience) == 0 {
+		return result, nil
+	}
+
+	var pagesIDs []int
+	pointsByPage := map[int][]model.AudiencePoint{}
+	for _, snapshot := range audience {
+		if _, ok := pointsByPage[snapshot.Page]; !ok {
+			pagesIDs = append(pagesIDs, snapshot.Page)
+		}
+		pointsByPage[snapshot.Page] = append(pointsByPage[snapshot.Page], model.AudiencePoint{
+			Date:      audienceDay(snapshot.CollectedAt),
+			Followers: snapshot.Followers,
+		})
+	}
+
+	pages, err := as.socialNetworkService.socialNetworkPagesRepository.FindPages(ctx, postgres.FindSocialNetworkPageQuery{
+		IDAnyOf: pagesIDs,
+	})
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find pages %v: %w", pagesIDs, err)
+	}
+	projectByPage := make(map[int]string, len(pages))
+	for _, page := range pages {
+		projectByPage[page.ID] = page.Project
+	}
+
+	var projects []string
+	pagesByProject := map[string][]int{}
+	for _, pageID := range pagesIDs {
+		project := projectByPage[pageID]
+		if _, ok := pagesByProject[project]; !ok {
+			projects = append(projects, project)
+		}
+		pagesByProject[project] = append(pagesByProject[project], pageID)
+		result.Pages = append(result.Pages, newAudienceGrowth(pageID, project, pointsByPage[pageID]))
+	}
+
+	sort.Strings(projects)
+	for _, project := range projects {
+		result.Projects = append(
+			result.Projects,
+			newAudienceGrowth(0, project, sumAudiencePoints(pagesByProject[project], pointsByPage)),
+		)
+	}
+
+	return result, nil
+}
+
+func newAudienceGrowth(page int, project string, points []model.AudiencePoint) model.AudienceGrowth {
+	growth := model.AudienceGrowth{
+		Page:    page,
+		Project: project,
+		Points:  points,
+	}
+	if len(points) != 0 {
+		growth.StartFollowers = points[0].Followers
+		growth.EndFollowers = points[len(points)-1].Followers
+		growth.Delta = growth.EndFollowers - growth.StartFollowers
+	}
+	return growth
+}
+
+// sumAudiencePoints складывает дневные ряды страниц, points каждой страницы отсортированы по дням
+func sumAudiencePoints(pagesIDs []int, pointsByPage map[int][]model.AudiencePoint) []model.AudiencePoint {
+	var days []time.Time
+	seenDays := map[time.Time]bool{}
+	for _, pageID := range pagesIDs {
+		for _, point := range pointsByPage[pageID] {
+			if !seenDays[point.Date] {
+				seenDays[point.Date] = true
+				days = append(days, point.Date)
+			}
+		}
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Before(days[j])
+	})
+
+	sums := make([]model.AudiencePoint, 0, len(days))
+	nextPoint := make(map[int]int, len(pagesIDs))
+	lastFollowers := make(map[int]int, len(pagesIDs))
+	for _, day := range days {
+		sum := model.AudiencePoint{Date: day}
+		for _, pageID := range pagesIDs {
+			points := pointsByPage[pageID]
+			for nextPoint[pageID] < len(points) && !points[nextPoint[pageID]].Date.After(day) {
+				lastFollowers[pageID] = points[nextPoint[pageID]].Followers
+				nextPoint[pageID]++
+			}
+			sum.Followers += lastFollowers[pageID]
+		}
+		sums = append(sums, sum)
+	}
+	return sums
+}
+
+// audienceDay начало дня снимка по UTC, как его группирует FindDailyAudience
+func audienceDay(collectedAt time.Time) time.Time {
+	year, month, day := collectedAt.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
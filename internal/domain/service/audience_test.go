@@ -0,0 +1,65 @@
+package service
+
+import (
+	"autoposting/internal/infrastructure/social_network_client"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCollectPagesAudience(t *testing.T) {
+	tests := []struct {
+		name          string
+		audience      map[string]social_network_client.PageAudienceResult
+		audienceErr   error
+		wantCollected int
+		wantFailed    int
+		wantFollowers map[int]int
+	}{
+		{
+			name: "collected",
+			audience: map[string]social_network_client.PageAudienceResult{
+				"100": {Followers: 1500},
+				"200": {Err: errors.New("group is private")},
+			},
+			wantCollected: 1,
+			wantFailed:    1,
+			wantFollowers: map[int]int{1: 1500},
+		},
+		{
+			name:        "request fails",
+			audienceErr: errors.New("rate limit"),
+			wantFailed:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			audienceRepository := &fakePageAudienceRepository{}
+			sns := newPagesTestService(&fakeAnalyticsClient{audience: tt.audience, audienceErr: tt.audienceErr})
+			as := NewAnalyticsService(sns.logger, sns, nil, audienceRepository, nil)
+
+			result, err := as.CollectPagesAudience(context.Background())
+			if err != nil {
+				t.Fatalf("CollectPagesAudience: %v", err)
+			}
+			if result.Collected != tt.wantCollected || result.Failed != tt.wantFailed {
+				t.Fatalf(
+					"got collected %d, failed %d, want %d, %d",
+					result.Collected,
+					result.Failed,
+					tt.wantCollected,
+					tt.wantFailed,
+				)
+			}
+			if len(audienceRepository.created) != len(tt.wantFollowers) {
+				t.Fatalf("got %d saved snapshots, want %d", len(audienceRepository.created), len(tt.wantFollowers))
+			}
+			for _, audience := range audienceRepository.created {
+				if followers, ok := tt.wantFollowers[audience.Page]; !ok || audience.Followers != followers {
+					t.Fatalf("got snapshot %+v, want followers %v", audience, tt.wantFollowers)
+				}
+			}
+		})
+	}
+}
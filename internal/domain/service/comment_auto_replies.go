@@ -0,0 +1,248 @@
+package service
+
+import (
+	"autoposting/internal/domain"
+	"autoposting/internal/domain/model"
+	"autoposting/internal/infrastructure/postgres"
+	"autoposting/internal/infrastructure/social_network_client"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// commentAutoReplyDailyWindow дневной лимит ответов на странице считается за скользящие сутки
+	commentAutoReplyDailyWindow = 24 * time.Hour
+	// commentAutoReplyAuthorWait столько ответ с {author} ждет имени автора, которого нет в уведомлениях
+	commentAutoReplyAuthorWait = time.Hour
+
+	commentAutoReplyAuthorPlaceholder = "{author}"
+)
+
+// errCommentAutoReplyDeferred комментарий нужно обработать снова, когда сбор комментариев заполнит имя автора
+var errCommentAutoReplyDeferred = errors.New("comment auto reply is waiting for author name")
+
+// commentAutoReplyMatcher правило с заранее скомпилированными выражениями
+type commentAutoReplyMatcher struct {
+	rule     model.CommentAutoReplyRule
+	patterns []*regexp.Regexp
+}
+
+// CreateCommentAutoReplyRule правило поста привязывается к странице поста
+func (cs *CommentsService) CreateCommentAutoReplyRule(
+	ctx context.Context,
+	rule *model.CommentAutoReplyRule,
+) error {
+	if rule.Post != nil {
+		posts, err := cs.socialNetworkService.postsRepository.FindPosts(ctx, postgres.FindPostsQuery{
+			IDAnyOf: []int64{*rule.Post},
+		})
+		if err != nil {
+			return ewrap.Errorf("failed to find post %d: %w", *rule.Post, err)
+		}
+		if len(posts) == 0 {
+			return domain.NewNotFoundError(fmt.Sprintf("post %d not found", *rule.Post))
+		}
+		if rule.Page != 0 && rule.Page != posts[0].Page {
+			return domain.NewValidationError(
+				fmt.Sprintf("post %d is not published on page %d", *rule.Post, rule.Page),
+				"postId",
+				"page",
+			)
+		}
+		rule.Page = posts[0].Page
+	}
+	if rule.Page == 0 {
+		return domain.NewValidationError("pageId or postId is required", "pageId", "required")
+	}
+
+	targets, err := cs.socialNetworkService.getPublishTargets(ctx, []int{rule.Page})
+	if err != nil {
+		return err
+	}
+	socialNetwork := targets[0].account.SocialNetwork
+	if _, ok := cs.socialNetworkService.getClient(socialNetwork).(social_network_client.CommentsManager); !ok {
+		return domain.NewValidationError(
+			fmt.Sprintf("social network %s does not support comments", socialNetwork),
+			"pageId",
+			"supported",
+		)
+	}
+
+	if _, err := newCommentAutoReplyMatcher(*rule); err != nil {
+		return err
+	}
+	if strings.TrimSpace(rule.Template) == "" {
+		return domain.NewValidationError("template must not be empty", "template", "required")
+	}
+
+	rule.CreatedAt = time.Now()
+	return cs.commentAutoRepliesRepository.CreateRule(ctx, rule)
+}
+
+func (cs *CommentsService) DeleteCommentAutoReplyRule(ctx context.Context, id int) error {
+	return cs.commentAutoRepliesRepository.DeleteRule(ctx, id)
+}
+
+func (cs *CommentsService) GetCommentAutoReplyRules(
+	ctx context.Context,
+	query postgres.FindCommentAutoReplyRulesQuery,
+) ([]model.CommentAutoReplyRule, error) {
+	rules, err := cs.commentAutoRepliesRepository.FindRules(ctx, query)
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find comment auto reply rules: %w", err)
+	}
+	return rules, nil
+}
+
+// autoReplyComment отвечает на комментарий по первому подходящему правилу.
+// Если на странице исчерпан дневной лимит правила или для автора не прошла пауза, ответа нет.
+// Ответ с {author} откладывается, пока имя автора не известно, и не отправляется, если имя так и не пришло.
+// Если ответ опубликован, но не сохранен, возвращается true вместе с ошибкой
+func (cs *CommentsService) autoReplyComment(
+	ctx context.Context,
+	matchers []*commentAutoReplyMatcher,
+	comment *model.Comment,
+) (bool, error) {
+	if !comment.AnsweredAt.IsZero() {
+		return false, nil
+	}
+
+	for _, matcher := range matchers {
+		rule := matcher.rule
+		if rule.Post != nil && *rule.Post != comment.Post {
+			continue
+		}
+		if comment.CreatedAt.Before(rule.CreatedAt) || !matcher.match(comment.Text) {
+			continue
+		}
+		if comment.AuthorName == "" && strings.Contains(rule.Template, commentAutoReplyAuthorPlaceholder) {
+			if time.Since(comment.CreatedAt) < commentAutoReplyAuthorWait {
+				return false, errCommentAutoReplyDeferred
+			}
+			cs.logger.Debug(
+				"comment auto reply skipped without author name",
+				slog.Int("rule", rule.ID),
+				slog.Int64("comment", comment.ID),
+			)
+			return false, nil
+		}
+
+		now := time.Now()
+		daily, err := cs.commentAutoRepliesRepository.CountReplies(ctx, postgres.CountCommentAutoRepliesQuery{
+			Page:  rule.Page,
+			Since: now.Add(-commentAutoReplyDailyWindow),
+		})
+		if err != nil {
+			return false, ewrap.Errorf("failed to count daily replies: %w", err)
+		}
+		if daily >= rule.DailyLimit {
+			cs.logger.Debug(
+				"comment auto reply daily limit reached",
+				slog.Int("rule", rule.ID),
+				slog.Int("page", rule.Page),
+			)
+			return false, nil
+		}
+		if rule.CooldownMinutes > 0 {
+			recent, err := cs.commentAutoRepliesRepository.CountReplies(ctx, postgres.CountCommentAutoRepliesQuery{
+				Page:     rule.Page,
+				AuthorID: comment.AuthorID,
+				Since:    now.Add(-time.Duration(rule.CooldownMinutes) * time.Minute),
+			})
+			if err != nil {
+				return false, ewrap.Errorf("failed to count author replies: %w", err)
+			}
+			if recent != 0 {
+				return false, nil
+			}
+		}
+
+		text := strings.NewReplacer(commentAutoReplyAuthorPlaceholder, comment.AuthorName).Replace(rule.Template)
+		reply, sent, replyErr := cs.replyToComment(ctx, comment.ID, text)
+		if !sent {
+			return false, ewrap.Errorf("failed to auto reply by rule %d: %w", rule.ID, replyErr)
+		}
+		// Запись автоответа нужна паузам и дневному лимиту, поэтому пишется и когда сам ответ не сохранился
+		err = cs.commentAutoRepliesRepository.CreateReply(ctx, &model.CommentAutoReply{
+			Rule:                 &rule.ID,
+			Page:                 rule.Page,
+			Comment:              comment.ID,
+			AuthorID:             comment.AuthorID,
+			ReplyRemoteCommentID: reply.RemoteCommentID,
+			CreatedAt:            now,
+		})
+		if err != nil {
+			return true, ewrap.Errorf("failed to save auto reply to comment %d: %w", comment.ID, err)
+		}
+		if replyErr != nil {
+			return true, ewrap.Errorf("failed to save auto reply by rule %d: %w", rule.ID, replyErr)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+// getCommentAutoReplyMatchers правила страниц по id страницы, правила постов раньше правил страницы
+func (cs *CommentsService) getCommentAutoReplyMatchers(
+	ctx context.Context,
+	pagesIDs []int,
+) (map[int][]*commentAutoReplyMatcher, error) {
+	rules, err := cs.commentAutoRepliesRepository.FindRules(ctx, postgres.FindCommentAutoReplyRulesQuery{
+		PagesIDAnyOf: pagesIDs,
+	})
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find comment auto reply rules: %w", err)
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].Post != nil && rules[j].Post == nil
+	})
+
+	matchersByPage := map[int][]*commentAutoReplyMatcher{}
+	for _, rule := range rules {
+		matcher, err := newCommentAutoReplyMatcher(rule)
+		if err != nil {
+			cs.logger.Warn("invalid comment auto reply rule", slog.Int("rule", rule.ID), slog.Any("err", err))
+			continue
+		}
+		matchersByPage[rule.Page] = append(matchersByPage[rule.Page], matcher)
+	}
+	return matchersByPage, nil
+}
+
+func (m *commentAutoReplyMatcher) match(text string) bool {
+	for _, pattern := range m.patterns {
+		if pattern.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// newCommentAutoReplyMatcher проверяет параметры правила и компилирует выражения
+func newCommentAutoReplyMatcher(rule model.CommentAutoReplyRule) (*commentAutoReplyMatcher, error) {
+	matcher := &commentAutoReplyMatcher{rule: rule}
+	for _, value := range rule.Patterns {
+		pattern, err := regexp.Compile("(?i)" + value)
+		if err != nil {
+			return nil, domain.NewValidationError(fmt.Sprintf("invalid pattern %q: %s", value, err), "patterns", "regex")
+		}
+		matcher.patterns = append(matcher.patterns, pattern)
+	}
+	if len(matcher.patterns) == 0 {
+		return nil, domain.NewValidationError("rule must have at least one pattern", "patterns", "required")
+	}
+	if rule.CooldownMinutes < 0 {
+		return nil, domain.NewValidationError("cooldownMinutes must not be negative", "cooldownMinutes", "min")
+	}
+	if rule.DailyLimit <= 0 {
+		return nil, domain.NewValidationError("dailyLimit must be positive", "dailyLimit", "min")
+	}
+	return matcher, nil
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"autoposting/internal/domain/model"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAutoReplyComment(t *testing.T) {
+	otherPost := int64(2)
+	rule := model.CommentAutoReplyRule{
+		ID:         1,
+		Page:       1,
+		Patterns:   []string{"цена"},
+		Template:   "Спасибо за вопрос, {author}!",
+		DailyLimit: 10,
+	}
+	withCooldown := rule
+	withCooldown.CooldownMinutes = 60
+	forOtherPost := rule
+	forOtherPost.Post = &otherPost
+	createdLater := rule
+	createdLater.CreatedAt = time.Now()
+
+	tests := []struct {
+		name          string
+		rule          model.CommentAutoReplyRule
+		comment       model.Comment
+		dailyReplies  int
+		authorReplies int
+		want          bool
+		wantErr       error
+		wantText      string
+	}{
+		{
+			name:     "author name substituted",
+			rule:     rule,
+			comment:  model.Comment{AuthorName: "Анна", Text: "Какая ЦЕНА?"},
+			want:     true,
+			wantText: "Спасибо за вопрос, Анна!",
+		},
+		{
+			name:    "text does not match",
+			rule:    rule,
+			comment: model.Comment{AuthorName: "Анна", Text: "Привет"},
+		},
+		{
+			name:    "already answered",
+			rule:    rule,
+			comment: model.Comment{AuthorName: "Анна", Text: "цена?", AnsweredAt: time.Now()},
+		},
+		{
+			name:    "rule of other post",
+			rule:    forOtherPost,
+			comment: model.Comment{AuthorName: "Анна", Text: "цена?"},
+		},
+		{
+			name:    "comment before rule",
+			rule:    createdLater,
+			comment: model.Comment{AuthorName: "Анна", Text: "цена?", CreatedAt: time.Now().Add(-time.Hour)},
+		},
+		{
+			name:          "cooldown blocks author",
+			rule:          withCooldown,
+			comment:       model.Comment{AuthorName: "Анна", Text: "цена?"},
+			authorReplies: 1,
+		},
+		{
+			name:          "no cooldown ignores author replies",
+			rule:          rule,
+			comment:       model.Comment{AuthorName: "Анна", Text: "цена?"},
+			authorReplies: 1,
+			want:          true,
+			wantText:      "Спасибо за вопрос, Анна!",
+		},
+		{
+			name:         "daily limit reached",
+			rule:         rule,
+			comment:      model.Comment{AuthorName: "Анна", Text: "цена?"},
+			dailyReplies: 10,
+		},
+		{
+			name:         "under daily limit",
+			rule:         rule,
+			comment:      model.Comment{AuthorName: "Анна", Text: "цена?"},
+			dailyReplies: 9,
+			want:         true,
+			wantText:     "Спасибо за вопрос, Анна!",
+		},
+		{
+			name:    "recent comment without author name deferred",
+			rule:    rule,
+			comment: model.Comment{Text: "цена?", CreatedAt: time.Now()},
+			wantErr: errCommentAutoReplyDeferred,
+		},
+		{
+			name:    "old comment without author name skipped",
+			rule:    rule,
+			comment: model.Comment{Text: "цена?", CreatedAt: time.Now().Add(-commentAutoReplyAuthorWait - time.Minute)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher, err := newCommentAutoReplyMatcher(tt.rule)
+			if err != nil {
+				t.Fatalf("newCommentAutoReplyMatcher: %v", err)
+			}
+			comment := tt.comment
+			comment.ID = 7
+			comment.Page = 1
+			comment.Post = 1
+			comment.RemoteCommentID = "70"
+			comment.AuthorID = "1"
+			autoReplies := &fakeCommentAutoRepliesRepository{
+				dailyReplies:  tt.dailyReplies,
+				authorReplies: tt.authorReplies,
+			}
+			client := &fakeCommentsClient{}
+			cs := newCommentsTestService(
+				&fakeCommentsRepository{comments: []model.Comment{comment}},
+				&fakeCommentModerationRepository{},
+				autoReplies,
+				client,
+			)
+
+			got, err := cs.autoReplyComment(context.Background(), []*commentAutoReplyMatcher{matcher}, &comment)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("got replied %v, want %v", got, tt.want)
+			}
+			if !tt.want {
+				if len(client.replies) != 0 || len(autoReplies.replies) != 0 {
+					t.Fatalf("got replies %v, saved %d, want none", client.replies, len(autoReplies.replies))
+				}
+				return
+			}
+			if len(client.replies) != 1 || client.replies[0] != tt.wantText {
+				t.Fatalf("got replies %v, want [%s]", client.replies, tt.wantText)
+			}
+			if len(autoReplies.replies) != 1 || autoReplies.replies[0].ReplyRemoteCommentID != "reply-70" {
+				t.Fatalf("got saved replies %+v, want reply-70", autoReplies.replies)
+			}
+		})
+	}
+}
+
+// TestProcessCommentsMarksAutoRepliedCommentProcessed отправленный в соц сеть автоответ не повторяется,
+// даже если его не удалось сохранить
+func TestProcessCommentsMarksAutoRepliedCommentProcessed(t *testing.T) {
+	tests := []struct {
+		name           string
+		upsertErr      error
+		createReplyErr error
+		wantSaved      int
+	}{
+		{name: "reply record not saved", createReplyErr: errors.New("connection lost")},
+		{name: "reply comment not saved", upsertErr: errors.New("connection lost"), wantSaved: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comments := &fakeCommentsRepository{
+				comments: []model.Comment{{
+					ID:              7,
+					Page:            1,
+					RemoteCommentID: "70",
+					AuthorID:        "1",
+					AuthorName:      "Анна",
+					Text:            "цена?",
+				}},
+				upsertErr: tt.upsertErr,
+			}
+			autoReplies := &fakeCommentAutoRepliesRepository{
+				rules: []model.CommentAutoReplyRule{{
+					ID:         1,
+					Page:       1,
+					Patterns:   []string{"цена"},
+					Template:   "Ответили в личные сообщения",
+					DailyLimit: 10,
+				}},
+				createReplyErr: tt.createReplyErr,
+			}
+			client := &fakeCommentsClient{}
+			cs := newCommentsTestService(comments, &fakeCommentModerationRepository{}, autoReplies, client)
+
+			result, err := cs.ProcessComments(context.Background(), nil)
+			if err != nil {
+				t.Fatalf("ProcessComments: %v", err)
+			}
+			if len(client.replies) != 1 {
+				t.Fatalf("got replies %v, want one reply", client.replies)
+			}
+			if len(autoReplies.replies) != tt.wantSaved {
+				t.Fatalf("got %d saved auto replies, want %d", len(autoReplies.replies), tt.wantSaved)
+			}
+			if result.Failed != 1 {
+				t.Fatalf("got %d failed comments, want 1", result.Failed)
+			}
+			if len(comments.processed) != 1 || comments.processed[0] != 7 {
+				t.Fatalf("got processed comments %v, want [7]", comments.processed)
+			}
+		})
+	}
+}
@@ -0,0 +1,449 @@
+package service
+
+import (
+	"autoposting/internal/domain"
+	"autoposting/internal/domain/model"
+	"autoposting/internal/infrastructure/postgres"
+	"autoposting/internal/infrastructure/social_network_client"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// commentLinkRegexp ссылки, после удаления которых в комментарии "только ссылки" ничего не остается
+var commentLinkRegexp = regexp.MustCompile(`(?i)(?:https?://|www\.)\S+`)
+
+// CommentModerationLogEntry действие модерации вместе с комментарием, над которым оно выполнено
+type CommentModerationLogEntry struct {
+	Log     model.CommentModerationLog
+	Comment *model.Comment
+}
+
+// commentModerationMatcher правило с заранее скомпилированными выражениями
+type commentModerationMatcher struct {
+	rule     model.CommentModerationRule
+	keywords []string
+	patterns []*regexp.Regexp
+}
+
+func (cs *CommentsService) CreateCommentModerationRule(
+	ctx context.Context,
+	rule *model.CommentModerationRule,
+) error {
+	if _, err := newCommentModerationMatcher(*rule); err != nil {
+		return err
+	}
+	switch rule.Action {
+	case model.CommentModerationActionHide, model.CommentModerationActionDelete:
+	default:
+		return domain.NewValidationError(fmt.Sprintf("unknown moderation action %q", rule.Action), "action", "oneOf")
+	}
+
+	pages, err := cs.socialNetworkService.socialNetworkPagesRepository.FindPages(ctx, postgres.FindSocialNetworkPageQuery{
+		ProjectAnyOf: []string{rule.Project},
+	})
+	if err != nil {
+		return ewrap.Errorf("failed to find pages of project %s: %w", rule.Project, err)
+	}
+	if len(pages) != 0 {
+		pagesIDs := make([]int, 0, len(pages))
+		for _, page := range pages {
+			pagesIDs = append(pagesIDs, page.ID)
+		}
+		targets, err := cs.socialNetworkService.getPublishTargets(ctx, pagesIDs)
+		if err != nil {
+			return err
+		}
+		for _, target := range targets {
+			client := cs.socialNetworkService.getClient(target.account.SocialNetwork)
+			if err := checkCommentModerationSupported(client, target.account.SocialNetwork, *rule); err != nil {
+				return err
+			}
+		}
+	}
+
+	rule.CreatedAt = time.Now()
+	return cs.commentModerationRepository.CreateRule(ctx, rule)
+}
+
+func (cs *CommentsService) DeleteCommentModerationRule(ctx context.Context, id int) error {
+	return cs.commentModerationRepository.DeleteRule(ctx, id)
+}
+
+func (cs *CommentsService) GetCommentModerationRules(
+	ctx context.Context,
+	projects []string,
+) ([]model.CommentModerationRule, error) {
+	rules, err := cs.commentModerationRepository.FindRules(ctx, postgres.FindCommentModerationRulesQuery{
+		ProjectAnyOf: projects,
+	})
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find comment moderation rules: %w", err)
+	}
+	return rules, nil
+}
+
+func (cs *CommentsService) GetCommentModerationLog(
+	ctx context.Context,
+	query postgres.FindCommentModerationLogQuery,
+) ([]CommentModerationLogEntry, error) {
+	logs, err := cs.commentModerationRepository.FindLog(ctx, query)
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find comment moderation log: %w", err)
+	}
+	if len(logs) == 0 {
+		return nil, nil
+	}
+
+	commentsIDs := make([]int64, 0, len(logs))
+	for _, log := range logs {
+		commentsIDs = append(commentsIDs, log.Comment)
+	}
+	comments, err := cs.commentsRepository.FindComments(ctx, postgres.FindCommentsQuery{
+		IDAnyOf:     commentsIDs,
+		WithDeleted: true,
+	})
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find moderated comments: %w", err)
+	}
+	commentsByID := make(map[int64]*model.Comment, len(comments))
+	for i := range comments {
+		commentsByID[comments[i].ID] = &comments[i]
+	}
+
+	entries := make([]CommentModerationLogEntry, 0, len(logs))
+	for _, log := range logs {
+		entries = append(entries, CommentModerationLogEntry{
+			Log:     log,
+			Comment: commentsByID[log.Comment],
+		})
+	}
+	return entries, nil
+}
+
+// moderateComment применяет к комментарию первое подходящее правило, пустое действие - ни одно правило не подошло.
+// Если действие выполнено в соц сети, но не сохранено, возвращается и действие, и ошибка
+func (cs *CommentsService) moderateComment(
+	ctx context.Context,
+	matchers []*commentModerationMatcher,
+	comment *model.Comment,
+) (model.CommentModerationAction, error) {
+	for _, matcher := range matchers {
+		reason, err := cs.matchComment(ctx, matcher, comment)
+		if err != nil {
+			return "", err
+		}
+		if reason == "" {
+			continue
+		}
+
+		applied, err := cs.applyCommentModeration(ctx, comment, matcher.rule, reason)
+		if err != nil {
+			err = ewrap.Errorf("failed to apply moderation rule %d: %w", matcher.rule.ID, err)
+			if applied {
+				return matcher.rule.Action, err
+			}
+			return "", err
+		}
+		return matcher.rule.Action, nil
+	}
+	return "", nil
+}
+
+// RevertCommentModeration возвращает скрытый комментарий или восстанавливает удаленный, если соц сеть это умеет
+func (cs *CommentsService) RevertCommentModeration(
+	ctx context.Context,
+	logID int64,
+) (*model.CommentModerationLog, error) {
+	logs, err := cs.commentModerationRepository.FindLog(ctx, postgres.FindCommentModerationLogQuery{
+		IDAnyOf: []int64{logID},
+	})
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find comment moderation log %d: %w", logID, err)
+	}
+	if len(logs) == 0 {
+		return nil, domain.NewNotFoundError(fmt.Sprintf("comment moderation log %d not found", logID))
+	}
+	log := &logs[0]
+	if !log.RevertedAt.IsZero() {
+		return nil, domain.NewValidationError(
+			fmt.Sprintf("comment moderation log %d is already reverted", logID),
+			"moderationId",
+			"reverted",
+		)
+	}
+
+	comments, err := cs.commentsRepository.FindComments(ctx, postgres.FindCommentsQuery{
+		IDAnyOf:     []int64{log.Comment},
+		WithDeleted: true,
+	})
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find comment %d: %w", log.Comment, err)
+	}
+	if len(comments) == 0 {
+		return nil, domain.NewNotFoundError(fmt.Sprintf("comment %d not found", log.Comment))
+	}
+	comment := &comments[0]
+
+	targets, err := cs.socialNetworkService.getPublishTargets(ctx, []int{comment.Page})
+	if err != nil {
+		return nil, err
+	}
+	target := targets[0]
+	client := cs.socialNetworkService.getClient(target.account.SocialNetwork)
+	remoteComment := toRemoteComment(comment, target.pages[0])
+
+	switch log.Action {
+	case model.CommentModerationActionHide:
+		moderator, ok := client.(social_network_client.CommentsModerator)
+		if !ok {
+			return nil, unsupportedCommentModerationError(target.account.SocialNetwork, "hiding comments")
+		}
+		err = moderator.SetCommentHidden(target.account.Credentials, target.accessToken, remoteComment, false)
+	case model.CommentModerationActionDelete:
+		restorer, ok := client.(social_network_client.CommentsRestorer)
+		if !ok {
+			return nil, unsupportedCommentModerationError(target.account.SocialNetwork, "restoring deleted comments")
+		}
+		err = restorer.RestoreComment(target.account.Credentials, target.accessToken, remoteComment)
+	}
+	if err != nil {
+		return nil, domain.NewInternalError(fmt.Sprintf("failed to revert moderation of comment %d: %s", comment.ID, err))
+	}
+
+	revertedAt := time.Now()
+	if err := cs.commentsRepository.MarkCommentRestored(ctx, comment.ID); err != nil {
+		return nil, ewrap.Errorf("failed to mark comment %d restored: %w", comment.ID, err)
+	}
+	if err := cs.commentModerationRepository.MarkLogReverted(ctx, log.ID, revertedAt); err != nil {
+		return nil, ewrap.Errorf("failed to mark comment moderation log %d reverted: %w", log.ID, err)
+	}
+	log.RevertedAt = revertedAt
+
+	return log, nil
+}
+
+// getCommentModerationMatchers правила проектов страниц по id страницы
+func (cs *CommentsService) getCommentModerationMatchers(
+	ctx context.Context,
+	pagesIDs []int,
+) (map[int][]*commentModerationMatcher, error) {
+	pages, err := cs.socialNetworkService.socialNetworkPagesRepository.FindPages(ctx, postgres.FindSocialNetworkPageQuery{
+		IDAnyOf: pagesIDs,
+	})
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find pages %v: %w", pagesIDs, err)
+	}
+	projects := make([]string, 0, len(pages))
+	for _, page := range pages {
+		projects = append(projects, page.Project)
+	}
+
+	rules, err := cs.commentModerationRepository.FindRules(ctx, postgres.FindCommentModerationRulesQuery{
+		ProjectAnyOf: projects,
+	})
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find comment moderation rules: %w", err)
+	}
+	matchersByProject := map[string][]*commentModerationMatcher{}
+	for _, rule := range rules {
+		matcher, err := newCommentModerationMatcher(rule)
+		if err != nil {
+			cs.logger.Warn("invalid comment moderation rule", slog.Int("rule", rule.ID), slog.Any("err", err))
+			continue
+		}
+		matchersByProject[rule.Project] = append(matchersByProject[rule.Project], matcher)
+	}
+
+	matchersByPage := make(map[int][]*commentModerationMatcher, len(pages))
+	for _, page := range pages {
+		matchersByPage[page.ID] = matchersByProject[page.Project]
+	}
+	return matchersByPage, nil
+}
+
+// matchComment возвращает, что совпало с правилом, пустая строка - комментарий правилу не подходит
+func (cs *CommentsService) matchComment(
+	ctx context.Context,
+	matcher *commentModerationMatcher,
+	comment *model.Comment,
+) (string, error) {
+	switch matcher.rule.Kind {
+	case model.CommentModerationRuleKeywords:
+		text := strings.ToLower(comment.Text)
+		for _, keyword := range matcher.keywords {
+			if strings.Contains(text, keyword) {
+				return fmt.Sprintf("keyword %q", keyword), nil
+			}
+		}
+	case model.CommentModerationRuleRegex:
+		for _, pattern := range matcher.patterns {
+			if pattern.MatchString(comment.Text) {
+				return fmt.Sprintf("pattern %q", pattern.String()), nil
+			}
+		}
+	case model.CommentModerationRuleLinkOnly:
+		if !commentLinkRegexp.MatchString(comment.Text) {
+			return "", nil
+		}
+		rest := commentLinkRegexp.ReplaceAllString(comment.Text, "")
+		if strings.IndexFunc(rest, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) == -1 {
+			return "link only", nil
+		}
+	case model.CommentModerationRuleRepeatPoster:
+		window := time.Duration(matcher.rule.RepeatWindowMinutes) * time.Minute
+		count, err := cs.commentsRepository.CountAuthorComments(
+			ctx,
+			comment.Page,
+			comment.AuthorID,
+			comment.CreatedAt.Add(-window),
+		)
+		if err != nil {
+			return "", ewrap.Errorf("failed to count comments of author %s: %w", comment.AuthorID, err)
+		}
+		if count > matcher.rule.RepeatLimit {
+			return fmt.Sprintf("%d comments in %d minutes", count, matcher.rule.RepeatWindowMinutes), nil
+		}
+	}
+	return "", nil
+}
+
+// applyCommentModeration выполняет действие правила в соц сети, записывает его в журнал и помечает комментарий.
+// applied - действие выполнено в соц сети, даже если записать его не удалось
+func (cs *CommentsService) applyCommentModeration(
+	ctx context.Context,
+	comment *model.Comment,
+	rule model.CommentModerationRule,
+	reason string,
+) (bool, error) {
+	targets, err := cs.socialNetworkService.getPublishTargets(ctx, []int{comment.Page})
+	if err != nil {
+		return false, err
+	}
+	target := targets[0]
+	client := cs.socialNetworkService.getClient(target.account.SocialNetwork)
+	remoteComment := toRemoteComment(comment, target.pages[0])
+	now := time.Now()
+
+	switch rule.Action {
+	case model.CommentModerationActionHide:
+		moderator, ok := client.(social_network_client.CommentsModerator)
+		if !ok {
+			return false, unsupportedCommentModerationError(target.account.SocialNetwork, "hiding comments")
+		}
+		err := moderator.SetCommentHidden(target.account.Credentials, target.accessToken, remoteComment, true)
+		if err != nil {
+			return false, domain.NewInternalError(fmt.Sprintf("failed to hide comment %d: %s", comment.ID, err))
+		}
+	case model.CommentModerationActionDelete:
+		// Страница могла появиться в проекте после создания правила
+		if err := checkCommentModerationSupported(client, target.account.SocialNetwork, rule); err != nil {
+			return false, err
+		}
+		err := client.(social_network_client.CommentsManager).DeleteComment(target.account.Credentials, target.accessToken, remoteComment)
+		if err != nil {
+			return false, domain.NewInternalError(fmt.Sprintf("failed to delete comment %d: %s", comment.ID, err))
+		}
+	}
+
+	// Журнал пишется первым: по нему действие можно отменить, даже если пометка комментария не сохранится
+	err = cs.commentModerationRepository.CreateLog(ctx, &model.CommentModerationLog{
+		Comment:   comment.ID,
+		Rule:      &rule.ID,
+		Action:    rule.Action,
+		Reason:    reason,
+		CreatedAt: now,
+	})
+	if err != nil {
+		return true, ewrap.Errorf("failed to log moderation of comment %d: %w", comment.ID, err)
+	}
+
+	switch rule.Action {
+	case model.CommentModerationActionHide:
+		if err := cs.commentsRepository.MarkCommentHidden(ctx, comment.ID, now); err != nil {
+			return true, ewrap.Errorf("failed to mark comment %d hidden: %w", comment.ID, err)
+		}
+	case model.CommentModerationActionDelete:
+		if err := cs.commentsRepository.MarkCommentDeleted(ctx, comment.ID, now); err != nil {
+			return true, ewrap.Errorf("failed to mark comment %d deleted: %w", comment.ID, err)
+		}
+	}
+	return true, nil
+}
+
+// newCommentModerationMatcher проверяет параметры правила и компилирует выражения
+func newCommentModerationMatcher(rule model.CommentModerationRule) (*commentModerationMatcher, error) {
+	matcher := &commentModerationMatcher{rule: rule}
+	switch rule.Kind {
+	case model.CommentModerationRuleKeywords:
+		for _, keyword := range rule.Values {
+			if keyword = strings.ToLower(strings.TrimSpace(keyword)); keyword != "" {
+				matcher.keywords = append(matcher.keywords, keyword)
+			}
+		}
+		if len(matcher.keywords) == 0 {
+			return nil, domain.NewValidationError("keywords rule must have at least one keyword", "values", "required")
+		}
+	case model.CommentModerationRuleRegex:
+		for _, value := range rule.Values {
+			pattern, err := regexp.Compile(value)
+			if err != nil {
+				return nil, domain.NewValidationError(fmt.Sprintf("invalid pattern %q: %s", value, err), "values", "regex")
+			}
+			matcher.patterns = append(matcher.patterns, pattern)
+		}
+		if len(matcher.patterns) == 0 {
+			return nil, domain.NewValidationError("regex rule must have at least one pattern", "values", "required")
+		}
+	case model.CommentModerationRuleLinkOnly:
+	case model.CommentModerationRuleRepeatPoster:
+		if rule.RepeatLimit <= 0 {
+			return nil, domain.NewValidationError("repeatLimit must be positive", "repeatLimit", "min")
+		}
+		if rule.RepeatWindowMinutes <= 0 {
+			return nil, domain.NewValidationError("repeatWindowMinutes must be positive", "repeatWindowMinutes", "min")
+		}
+	default:
+		return nil, domain.NewValidationError(fmt.Sprintf("unknown moderation rule kind %q", rule.Kind), "kind", "oneOf")
+	}
+	return matcher, nil
+}
+
+// checkCommentModerationSupported действие правила должно быть выполнимым и, без AllowIrreversible, отменяемым
+func checkCommentModerationSupported(
+	client social_network_client.SocialNetworkClient,
+	socialNetwork model.SocialNetworkName,
+	rule model.CommentModerationRule,
+) error {
+	switch rule.Action {
+	case model.CommentModerationActionHide:
+		if _, ok := client.(social_network_client.CommentsModerator); !ok {
+			return unsupportedCommentModerationError(socialNetwork, "hiding comments")
+		}
+	case model.CommentModerationActionDelete:
+		if _, ok := client.(social_network_client.CommentsManager); !ok {
+			return unsupportedCommentModerationError(socialNetwork, "deleting comments")
+		}
+		if _, ok := client.(social_network_client.CommentsRestorer); !ok && !rule.AllowIrreversible {
+			return domain.NewValidationError(
+				fmt.Sprintf("social network %s cannot restore deleted comments, set allowIrreversible to delete them", socialNetwork),
+				"allowIrreversible",
+				"reversible",
+			)
+		}
+	}
+	return nil
+}
+
+func unsupportedCommentModerationError(socialNetwork model.SocialNetworkName, action string) error {
+	return domain.NewValidationError(
+		fmt.Sprintf("social network %s does not support %s", socialNetwork, action),
+		"socialNetwork",
+		"supported",
+	)
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"autoposting/internal/domain"
+	"autoposting/internal/domain/model"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewCommentModerationMatcher(t *testing.T) {
+	tests := []struct {
+		name      string
+		rule      model.CommentModerationRule
+		wantField string
+	}{
+		{
+			name: "keywords",
+			rule: model.CommentModerationRule{Kind: model.CommentModerationRuleKeywords, Values: []string{" Спам "}},
+		},
+		{
+			name:      "keywords without values",
+			rule:      model.CommentModerationRule{Kind: model.CommentModerationRuleKeywords, Values: []string{" ", ""}},
+			wantField: "values",
+		},
+		{
+			name:      "invalid regex",
+			rule:      model.CommentModerationRule{Kind: model.CommentModerationRuleRegex, Values: []string{"(unclosed"}},
+			wantField: "values",
+		},
+		{
+			name:      "regex without values",
+			rule:      model.CommentModerationRule{Kind: model.CommentModerationRuleRegex},
+			wantField: "values",
+		},
+		{
+			name: "link only",
+			rule: model.CommentModerationRule{Kind: model.CommentModerationRuleLinkOnly},
+		},
+		{
+			name: "repeat poster",
+			rule: model.CommentModerationRule{
+				Kind:                model.CommentModerationRuleRepeatPoster,
+				RepeatLimit:         3,
+				RepeatWindowMinutes: 10,
+			},
+		},
+		{
+			name:      "repeat poster without limit",
+			rule:      model.CommentModerationRule{Kind: model.CommentModerationRuleRepeatPoster, RepeatWindowMinutes: 10},
+			wantField: "repeatLimit",
+		},
+		{
+			name:      "repeat poster without window",
+			rule:      model.CommentModerationRule{Kind: model.CommentModerationRuleRepeatPoster, RepeatLimit: 3},
+			wantField: "repeatWindowMinutes",
+		},
+		{
+			name:      "unknown kind",
+			rule:      model.CommentModerationRule{Kind: "OTHER"},
+			wantField: "kind",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newCommentModerationMatcher(tt.rule)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("got error %v, want nil", err)
+				}
+				return
+			}
+			var validationErr *domain.ValidationError
+			if !errors.As(err, &validationErr) || validationErr.Field != tt.wantField {
+				t.Fatalf("got error %v, want validation error of field %s", err, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestMatchComment(t *testing.T) {
+	keywords := model.CommentModerationRule{Kind: model.CommentModerationRuleKeywords, Values: []string{"Спам", "casino"}}
+	regex := model.CommentModerationRule{Kind: model.CommentModerationRuleRegex, Values: []string{`\d{3}-\d{2}-\d{2}`}}
+	linkOnly := model.CommentModerationRule{Kind: model.CommentModerationRuleLinkOnly}
+	repeatPoster := model.CommentModerationRule{
+		Kind:                model.CommentModerationRuleRepeatPoster,
+		RepeatLimit:         3,
+		RepeatWindowMinutes: 10,
+	}
+
+	tests := []struct {
+		name           string
+		rule           model.CommentModerationRule
+		text           string
+		authorComments int
+		want           string
+	}{
+		{name: "keyword ignores case", rule: keywords, text: "Тут СПАМ!", want: `keyword "спам"`},
+		{name: "keyword inside word", rule: keywords, text: "best CASINOS", want: `keyword "casino"`},
+		{name: "no keyword", rule: keywords, text: "обычный комментарий"},
+		{name: "regex matches", rule: regex, text: "звони 123-45-67", want: `pattern "\\d{3}-\\d{2}-\\d{2}"`},
+		{name: "regex does not match", rule: regex, text: "звони 12-34"},
+		{name: "single link", rule: linkOnly, text: "https://spam.example/path", want: "link only"},
+		{name: "links with punctuation and emoji", rule: linkOnly, text: "www.a.example, http://b.example 🔥!", want: "link only"},
+		{name: "link with text", rule: linkOnly, text: "смотрите https://example.com"},
+		{name: "link with number", rule: linkOnly, text: "https://example.com 2"},
+		{name: "no link", rule: linkOnly, text: "!!!"},
+		{name: "repeat poster at limit", rule: repeatPoster, text: "hi", authorComments: 3},
+		{name: "repeat poster over limit", rule: repeatPoster, text: "hi", authorComments: 4, want: "4 comments in 10 minutes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher, err := newCommentModerationMatcher(tt.rule)
+			if err != nil {
+				t.Fatalf("newCommentModerationMatcher: %v", err)
+			}
+			cs := newCommentsTestService(
+				&fakeCommentsRepository{authorComments: tt.authorComments},
+				&fakeCommentModerationRepository{},
+				&fakeCommentAutoRepliesRepository{},
+				&fakeCommentsClient{},
+			)
+
+			got, err := cs.matchComment(context.Background(), matcher, &model.Comment{
+				Page:      1,
+				AuthorID:  "1",
+				Text:      tt.text,
+				CreatedAt: time.Now(),
+			})
+			if err != nil {
+				t.Fatalf("matchComment: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("got reason %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestProcessCommentsMarksModeratedCommentProcessed скрытый в соц сети комментарий не обрабатывается повторно,
+// даже если журнал модерации не сохранился
+func TestProcessCommentsMarksModeratedCommentProcessed(t *testing.T) {
+	comments := &fakeCommentsRepository{
+		comments: []model.Comment{{ID: 7, Page: 1, RemoteCommentID: "70", AuthorID: "1", Text: "spam"}},
+	}
+	moderation := &fakeCommentModerationRepository{
+		rules: []model.CommentModerationRule{{
+			ID:      1,
+			Project: "test",
+			Kind:    model.CommentModerationRuleKeywords,
+			Values:  []string{"spam"},
+			Action:  model.CommentModerationActionHide,
+		}},
+		createLogErr: errors.New("connection lost"),
+	}
+	client := &fakeCommentsClient{}
+	cs := newCommentsTestService(comments, moderation, &fakeCommentAutoRepliesRepository{}, client)
+
+	result, err := cs.ProcessComments(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ProcessComments: %v", err)
+	}
+	if len(client.hidden) != 1 || client.hidden[0] != "70" {
+		t.Fatalf("got hidden comments %v, want [70]", client.hidden)
+	}
+	if result.Failed != 1 {
+		t.Fatalf("got %d failed comments, want 1", result.Failed)
+	}
+	if len(comments.processed) != 1 || comments.processed[0] != 7 {
+		t.Fatalf("got processed comments %v, want [7]", comments.processed)
+	}
+}
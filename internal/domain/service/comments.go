@@ -0,0 +1,481 @@
+package service
+
+import (
+	"autoposting/internal/domain"
+	"autoposting/internal/domain/model"
+	"autoposting/internal/domain/repository"
+	"autoposting/internal/infrastructure/postgres"
+	"autoposting/internal/infrastructure/social_network_client"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// CommentsService сбор, модерация комментариев и автоответы на них
+type CommentsService struct {
+	logger                       *slog.Logger
+	socialNetworkService         *SocialNetworkService
+	commentsRepository           repository.CommentsRepository
+	commentModerationRepository  repository.CommentModerationRepository
+	commentAutoRepliesRepository repository.CommentAutoRepliesRepository
+}
+
+func NewCommentsService(
+	logger *slog.Logger,
+	socialNetworkService *SocialNetworkService,
+	commentsRepository repository.CommentsRepository,
+	commentModerationRepository repository.CommentModerationRepository,
+	commentAutoRepliesRepository repository.CommentAutoRepliesRepository,
+) *CommentsService {
+	return &CommentsService{
+		logger:                       logger,
+		socialNetworkService:         socialNetworkService,
+		commentsRepository:           commentsRepository,
+		commentModerationRepository:  commentModerationRepository,
+		commentAutoRepliesRepository: commentAutoRepliesRepository,
+	}
+}
+
+// CollectCommentsResult итог сбора комментариев. Failed - посты, комментарии которых не удалось получить,
+// Deleted - комментарии, которых больше нет в соц сети
+type CollectCommentsResult struct {
+	Posts     int
+	Collected int
+	Deleted   int
+	Failed    int
+}
+
+// CollectComments сохраняет комментарии постов, опубликованных после publishedAfter.
+// Комментарий считается отвеченным, если на него есть ответ от имени страницы.
+// Соц сети без CommentsManager пропускаются
+func (cs *CommentsService) CollectComments(
+	ctx context.Context,
+	publishedAfter time.Time,
+) (*CollectCommentsResult, error) {
+	posts, err := cs.socialNetworkService.postsRepository.FindPosts(ctx, postgres.FindPostsQuery{
+		PublishedAfter: publishedAfter,
+		RemoteStateAnyOf: []model.PostRemoteState{
+			model.PostRemoteStatePublished,
+			model.PostRemoteStateModified,
+		},
+	})
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find posts to collect comments: %w", err)
+	}
+
+	targets, err := cs.socialNetworkService.getRemotePostsTargets(ctx, posts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CollectCommentsResult{}
+	collectedAt := time.Now()
+	for _, target := range targets {
+		manager, ok := cs.socialNetworkService.getClient(target.account.SocialNetwork).(social_network_client.CommentsManager)
+		if !ok {
+			continue
+		}
+
+		for _, remotePost := range target.remotePosts {
+			post := target.postsByRemotePost[remotePost]
+			remoteComments, err := manager.GetPostComments(target.account.Credentials, target.accessToken, remotePost)
+			if err != nil {
+				cs.logger.Warn(
+					"failed to get post comments",
+					slog.String("socialNetwork", string(target.account.SocialNetwork)),
+					slog.Int64("post", post.ID),
+					slog.Any("err", err),
+				)
+				result.Failed++
+				continue
+			}
+
+			comments := newPostComments(post, target.account.SocialNetwork, remotePost, remoteComments, collectedAt)
+			if err := cs.commentsRepository.UpsertComments(ctx, comments); err != nil {
+				return result, ewrap.Errorf("failed to save post %d comments: %w", post.ID, err)
+			}
+			remoteCommentsIDs := make([]string, 0, len(comments))
+			for _, comment := range comments {
+				remoteCommentsIDs = append(remoteCommentsIDs, comment.RemoteCommentID)
+			}
+			deleted, err := cs.commentsRepository.MarkMissingCommentsDeleted(ctx, post.ID, remoteCommentsIDs, collectedAt)
+			if err != nil {
+				return result, ewrap.Errorf("failed to mark deleted post %d comments: %w", post.ID, err)
+			}
+
+			result.Posts++
+			result.Collected += len(comments)
+			result.Deleted += deleted
+		}
+	}
+
+	return result, nil
+}
+
+// ProcessCommentsResult итог обработки новых комментариев. Failed - комментарии, действие над которыми не удалось,
+// они обрабатываются повторно, если соц сеть поддерживает действие
+type ProcessCommentsResult struct {
+	Checked int
+	Hidden  int
+	Deleted int
+	Replied int
+	Failed  int
+}
+
+// ProcessComments проверяет еще не обработанные комментарии подписчиков правилами модерации проектов,
+// на оставшиеся видимыми отвечает правилами автоответов.
+// commentsIDs ограничивает обработку, например комментариями из уведомлений, nil - все необработанные
+func (cs *CommentsService) ProcessComments(
+	ctx context.Context,
+	commentsIDs []int64,
+) (*ProcessCommentsResult, error) {
+	comments, err := cs.commentsRepository.FindComments(ctx, postgres.FindCommentsQuery{
+		IDAnyOf:     commentsIDs,
+		Unprocessed: true,
+	})
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find unprocessed comments: %w", err)
+	}
+	result := &ProcessCommentsResult{}
+	if len(comments) == 0 {
+		return result, nil
+	}
+
+	var pagesIDs []int
+	seenPages := map[int]bool{}
+	for _, comment := range comments {
+		if !seenPages[comment.Page] {
+			seenPages[comment.Page] = true
+			pagesIDs = append(pagesIDs, comment.Page)
+		}
+	}
+	moderationMatchers, err := cs.getCommentModerationMatchers(ctx, pagesIDs)
+	if err != nil {
+		return nil, err
+	}
+	autoReplyMatchers, err := cs.getCommentAutoReplyMatchers(ctx, pagesIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	processedAt := time.Now()
+	processedIDs := make([]int64, 0, len(comments))
+	// От старых к новым, чтобы паузы автоответов отсчитывались от первого комментария автора
+	for i := len(comments) - 1; i >= 0; i-- {
+		comment := &comments[i]
+		if comment.ByPage {
+			processedIDs = append(processedIDs, comment.ID)
+			continue
+		}
+
+		result.Checked++
+		replied := false
+		action, err := cs.moderateComment(ctx, moderationMatchers[comment.Page], comment)
+		if err == nil && action == "" {
+			replied, err = cs.autoReplyComment(ctx, autoReplyMatchers[comment.Page], comment)
+		}
+		switch {
+		case errors.Is(err, errCommentAutoReplyDeferred):
+			continue
+		case err != nil:
+			cs.logger.Warn("failed to process comment", slog.Int64("comment", comment.ID), slog.Any("err", err))
+			result.Failed++
+			// Неподдерживаемое соц сетью действие не выполнится и при следующей обработке,
+			// а выполненное в соц сети не должно повториться
+			if action == "" && !replied && !domain.IsValidationError(err) {
+				continue
+			}
+		case action == model.CommentModerationActionHide:
+			result.Hidden++
+		case action == model.CommentModerationActionDelete:
+			result.Deleted++
+		case replied:
+			result.Replied++
+		}
+		processedIDs = append(processedIDs, comment.ID)
+	}
+
+	if err := cs.commentsRepository.MarkCommentsProcessed(ctx, processedIDs, processedAt); err != nil {
+		return result, ewrap.Errorf("failed to mark comments processed: %w", err)
+	}
+	return result, nil
+}
+
+// newPostComments временем ответа на комментарий считается самый ранний ответ страницы
+func newPostComments(
+	post *model.Post,
+	socialNetwork model.SocialNetworkName,
+	remotePost social_network_client.RemotePost,
+	remoteComments []social_network_client.Comment,
+	collectedAt time.Time,
+) []model.Comment {
+	answeredAt := map[string]time.Time{}
+	for _, remoteComment := range remoteComments {
+		if !remoteComment.ByPage || remoteComment.ParentID == "" {
+			continue
+		}
+		if at, ok := answeredAt[remoteComment.ParentID]; !ok || remoteComment.CreatedAt.Before(at) {
+			answeredAt[remoteComment.ParentID] = remoteComment.CreatedAt
+		}
+	}
+
+	comments := make([]model.Comment, 0, len(remoteComments))
+	for _, remoteComment := range remoteComments {
+		comments = append(comments, model.Comment{
+			Page:                  post.Page,
+			Post:                  post.ID,
+			SocialNetwork:         socialNetwork,
+			RemotePostID:          remotePost.PostID,
+			RemoteCommentID:       remoteComment.CommentID,
+			ParentRemoteCommentID: remoteComment.ParentID,
+			AuthorID:              remoteComment.AuthorID,
+			AuthorName:            remoteComment.AuthorName,
+			ByPage:                remoteComment.ByPage,
+			Text:                  remoteComment.Text,
+			CreatedAt:             remoteComment.CreatedAt,
+			AnsweredAt:            answeredAt[remoteComment.CommentID],
+			CollectedAt:           collectedAt,
+		})
+	}
+	return comments
+}
+
+func (cs *CommentsService) GetComments(
+	ctx context.Context,
+	query postgres.FindCommentsQuery,
+) ([]model.Comment, error) {
+	comments, err := cs.commentsRepository.FindComments(ctx, query)
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find comments: %w", err)
+	}
+	return comments, nil
+}
+
+// ReplyToComment отвечает на комментарий от имени страницы. Ответ сохраняется сразу,
+// не дожидаясь следующего сбора комментариев
+func (cs *CommentsService) ReplyToComment(
+	ctx context.Context,
+	commentID int64,
+	text string,
+) (*model.Comment, error) {
+	reply, _, err := cs.replyToComment(ctx, commentID, text)
+	if err != nil {
+		return nil, err
+	}
+	return reply, nil
+}
+
+// replyToComment sent - ответ опубликован в соц сети. Тогда ответ возвращается, даже если сохранить его не удалось
+func (cs *CommentsService) replyToComment(
+	ctx context.Context,
+	commentID int64,
+	text string,
+) (*model.Comment, bool, error) {
+	comment, target, manager, err := cs.getCommentTarget(ctx, commentID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	replyID, err := manager.ReplyToComment(
+		target.account.Credentials,
+		target.accessToken,
+		toRemoteComment(comment, target.pages[0]),
+		text,
+	)
+	if err != nil {
+		return nil, false, domain.NewInternalError(fmt.Sprintf("failed to reply to comment %d: %s", commentID, err))
+	}
+
+	now := time.Now()
+	reply := model.Comment{
+		Page:                  comment.Page,
+		Post:                  comment.Post,
+		SocialNetwork:         comment.SocialNetwork,
+		RemotePostID:          comment.RemotePostID,
+		RemoteCommentID:       replyID,
+		ParentRemoteCommentID: comment.RemoteCommentID,
+		AuthorID:              target.pages[0].PageID,
+		ByPage:                true,
+		Text:                  text,
+		CreatedAt:             now,
+		CollectedAt:           now,
+	}
+	if target.pages[0].PageInfo != nil {
+		reply.AuthorName = target.pages[0].PageInfo.Name
+	}
+	replies := []model.Comment{reply}
+	if err := cs.commentsRepository.UpsertComments(ctx, replies); err != nil {
+		return &reply, true, ewrap.Errorf("failed to save reply to comment %d: %w", commentID, err)
+	}
+	if err := cs.commentsRepository.MarkCommentAnswered(ctx, comment.ID, now); err != nil {
+		return &replies[0], true, ewrap.Errorf("failed to mark comment %d answered: %w", commentID, err)
+	}
+
+	return &replies[0], true, nil
+}
+
+// DeleteComment удаляет комментарий в соц сети, в comments он остается помеченным удаленным
+func (cs *CommentsService) DeleteComment(ctx context.Context, commentID int64) error {
+	comment, target, manager, err := cs.getCommentTarget(ctx, commentID)
+	if err != nil {
+		return err
+	}
+
+	err = manager.DeleteComment(target.account.Credentials, target.accessToken, toRemoteComment(comment, target.pages[0]))
+	if err != nil {
+		return domain.NewInternalError(fmt.Sprintf("failed to delete comment %d: %s", commentID, err))
+	}
+
+	if err := cs.commentsRepository.MarkCommentDeleted(ctx, comment.ID, time.Now()); err != nil {
+		return ewrap.Errorf("failed to mark comment %d deleted: %w", commentID, err)
+	}
+	return nil
+}
+
+// getCommentTarget комментарий вместе с аккаунтом и токеном его страницы
+func (cs *CommentsService) getCommentTarget(
+	ctx context.Context,
+	commentID int64,
+) (*model.Comment, *publishTarget, social_network_client.CommentsManager, error) {
+	comments, err := cs.commentsRepository.FindComments(ctx, postgres.FindCommentsQuery{
+		IDAnyOf: []int64{commentID},
+	})
+	if err != nil {
+		return nil, nil, nil, ewrap.Errorf("failed to find comment %d: %w", commentID, err)
+	}
+	if len(comments) == 0 {
+		return nil, nil, nil, domain.NewNotFoundError(fmt.Sprintf("comment %d not found", commentID))
+	}
+	comment := &comments[0]
+
+	targets, err := cs.socialNetworkService.getPublishTargets(ctx, []int{comment.Page})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	target := targets[0]
+
+	manager, ok := cs.socialNetworkService.getClient(target.account.SocialNetwork).(social_network_client.CommentsManager)
+	if !ok {
+		return nil, nil, nil, domain.NewValidationError(
+			fmt.Sprintf("social network %s does not support comments", target.account.SocialNetwork),
+			"commentId",
+			"supported",
+		)
+	}
+
+	return comment, target, manager, nil
+}
+
+func toRemoteComment(comment *model.Comment, page model.SocialNetworkPage) social_network_client.RemoteComment {
+	return social_network_client.RemoteComment{
+		PageID:    page.PageID,
+		PostID:    comment.RemotePostID,
+		CommentID: comment.RemoteCommentID,
+		ParentID:  comment.ParentRemoteCommentID,
+	}
+}
+
+// IngestEventsComments сохраняет комментарии из событий к известным постам и сразу их обрабатывает.
+// Имена авторов в уведомлениях не приходят, их заполнит следующий сбор комментариев
+func (cs *CommentsService) IngestEventsComments(
+	ctx context.Context,
+	socialNetwork model.SocialNetworkName,
+	events []social_network_client.PageEvent,
+) error {
+	var commentEvents []social_network_client.PageEvent
+	var remotePagesIDs []string
+	var remotePostsIDs []string
+	for _, event := range events {
+		switch event.Type {
+		case social_network_client.PageEventCommentNew, social_network_client.PageEventCommentEdited:
+			if event.PostID != "" && event.CommentID != "" {
+				commentEvents = append(commentEvents, event)
+				remotePagesIDs = append(remotePagesIDs, event.PageID)
+				remotePostsIDs = append(remotePostsIDs, event.PostID)
+			}
+		}
+	}
+	if len(commentEvents) == 0 {
+		return nil
+	}
+
+	pages, err := cs.socialNetworkService.socialNetworkPagesRepository.FindPages(ctx, postgres.FindSocialNetworkPageQuery{
+		PageIDAnyOf:   remotePagesIDs,
+		SocialNetwork: socialNetwork,
+	})
+	if err != nil {
+		return ewrap.Errorf("failed to find pages %v: %w", remotePagesIDs, err)
+	}
+	if len(pages) == 0 {
+		return nil
+	}
+
+	pagesIDs := make([]int, 0, len(pages))
+	for _, page := range pages {
+		pagesIDs = append(pagesIDs, page.ID)
+	}
+	posts, err := cs.socialNetworkService.postsRepository.FindPosts(ctx, postgres.FindPostsQuery{
+		PagesIDAnyOf:      pagesIDs,
+		RemotePostIDAnyOf: remotePostsIDs,
+	})
+	if err != nil {
+		return ewrap.Errorf("failed to find posts of %s comment events: %w", socialNetwork, err)
+	}
+	// Страница может быть в нескольких проектах, у каждой свой пост и свой комментарий
+	postsByRemotePost := map[social_network_client.RemotePost][]*model.Post{}
+	remotePagesByID := make(map[int]string, len(pages))
+	for _, page := range pages {
+		remotePagesByID[page.ID] = page.PageID
+	}
+	for i := range posts {
+		remotePost := social_network_client.RemotePost{
+			PageID: remotePagesByID[posts[i].Page],
+			PostID: posts[i].RemotePostID,
+		}
+		postsByRemotePost[remotePost] = append(postsByRemotePost[remotePost], &posts[i])
+	}
+
+	collectedAt := time.Now()
+	var comments []model.Comment
+	for _, event := range commentEvents {
+		remotePost := social_network_client.RemotePost{PageID: event.PageID, PostID: event.PostID}
+		for _, post := range postsByRemotePost[remotePost] {
+			comments = append(comments, model.Comment{
+				Page:                  post.Page,
+				Post:                  post.ID,
+				SocialNetwork:         socialNetwork,
+				RemotePostID:          post.RemotePostID,
+				RemoteCommentID:       event.CommentID,
+				ParentRemoteCommentID: event.ParentCommentID,
+				AuthorID:              event.AuthorID,
+				ByPage:                event.ByPage,
+				Text:                  event.Text,
+				CreatedAt:             event.OccurredAt,
+				CollectedAt:           collectedAt,
+			})
+		}
+	}
+	if len(comments) == 0 {
+		return nil
+	}
+	if err := cs.commentsRepository.UpsertComments(ctx, comments); err != nil {
+		return ewrap.Errorf("failed to save %s event comments: %w", socialNetwork, err)
+	}
+
+	commentsIDs := make([]int64, 0, len(comments))
+	for _, comment := range comments {
+		commentsIDs = append(commentsIDs, comment.ID)
+	}
+	// Необработанные из-за ошибки комментарии обработает сбор комментариев
+	if _, err := cs.ProcessComments(ctx, commentsIDs); err != nil {
+		cs.logger.Warn(
+			"failed to process event comments",
+			slog.String("socialNetwork", string(socialNetwork)),
+			slog.Any("err", err),
+		)
+	}
+	return nil
+}
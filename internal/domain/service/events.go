@@ -0,0 +1,82 @@
+package service
+
+import (
+	"autoposting/internal/domain/model"
+	"autoposting/internal/infrastructure/postgres"
+	"autoposting/internal/infrastructure/social_network_client"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"log/slog"
+	"time"
+)
+
+// SavePageEvents сохраняет события страниц в общий поток, повторные доставки пропускаются.
+// События страниц, которых нет в проектах, тоже сохраняются, страница появится в них позже
+func (sns *SocialNetworkService) SavePageEvents(
+	ctx context.Context,
+	socialNetwork model.SocialNetworkName,
+	events []social_network_client.PageEvent,
+) (int, error) {
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	remotePagesIDs := make([]string, 0, len(events))
+	for _, event := range events {
+		remotePagesIDs = append(remotePagesIDs, event.PageID)
+	}
+	pages, err := sns.socialNetworkPagesRepository.FindPages(ctx, postgres.FindSocialNetworkPageQuery{
+		PageIDAnyOf:   remotePagesIDs,
+		SocialNetwork: socialNetwork,
+	})
+	if err != nil {
+		return 0, ewrap.Errorf("failed to find pages %v: %w", remotePagesIDs, err)
+	}
+	// Страница может быть в нескольких проектах, событие привязывается к первой
+	pagesByRemoteID := make(map[string]int, len(pages))
+	for _, page := range pages {
+		if _, ok := pagesByRemoteID[page.PageID]; !ok {
+			pagesByRemoteID[page.PageID] = page.ID
+		}
+	}
+
+	receivedAt := time.Now()
+	modelEvents := make([]model.SocialNetworkEvent, 0, len(events))
+	for _, event := range events {
+		modelEvent := model.SocialNetworkEvent{
+			SocialNetwork:   socialNetwork,
+			EventID:         event.EventID,
+			RemotePageID:    event.PageID,
+			Type:            string(event.Type),
+			RemotePostID:    event.PostID,
+			RemoteCommentID: event.CommentID,
+			AuthorID:        event.AuthorID,
+			ByPage:          event.ByPage,
+			Text:            event.Text,
+			Payload:         event.Payload,
+			OccurredAt:      event.OccurredAt,
+			ReceivedAt:      receivedAt,
+		}
+		if len(modelEvent.Payload) == 0 {
+			modelEvent.Payload = []byte("{}")
+		}
+		if pageID, ok := pagesByRemoteID[event.PageID]; ok {
+			modelEvent.Page = &pageID
+		}
+		modelEvents = append(modelEvents, modelEvent)
+	}
+
+	created, err := sns.socialNetworkEventsRepository.CreateEvents(ctx, modelEvents)
+	if err != nil {
+		return 0, ewrap.Errorf("failed to save %s page events: %w", socialNetwork, err)
+	}
+	if skipped := len(events) - created; skipped > 0 {
+		sns.logger.Debug(
+			"skipped duplicate page events",
+			slog.String("socialNetwork", string(socialNetwork)),
+			slog.Int("skipped", skipped),
+		)
+	}
+
+	return created, nil
+}
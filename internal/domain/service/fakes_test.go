@@ -0,0 +1,412 @@
+package service
+
+import (
+	"autoposting/internal/domain/model"
+	"autoposting/internal/domain/repository"
+	"autoposting/internal/infrastructure/postgres"
+	"autoposting/internal/infrastructure/social_network_client"
+	"context"
+	"io"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+// Фейки реализуют только методы, которые вызывает тестируемый код, остальные методы интерфейсов паникуют
+
+type fakeCommentsRepository struct {
+	repository.CommentsRepository
+	comments       []model.Comment
+	authorComments int
+	processed      []int64
+	hidden         []int64
+	answered       []int64
+	upserted       []model.Comment
+	upsertErr      error
+}
+
+func (f *fakeCommentsRepository) FindComments(
+	ctx context.Context,
+	query postgres.FindCommentsQuery,
+) ([]model.Comment, error) {
+	if len(query.IDAnyOf) == 0 {
+		return f.comments, nil
+	}
+	var comments []model.Comment
+	for _, comment := range f.comments {
+		for _, id := range query.IDAnyOf {
+			if comment.ID == id {
+				comments = append(comments, comment)
+			}
+		}
+	}
+	return comments, nil
+}
+
+func (f *fakeCommentsRepository) CountAuthorComments(context.Context, int, string, time.Time) (int, error) {
+	return f.authorComments, nil
+}
+
+func (f *fakeCommentsRepository) MarkCommentsProcessed(ctx context.Context, ids []int64, at time.Time) error {
+	f.processed = append(f.processed, ids...)
+	return nil
+}
+
+func (f *fakeCommentsRepository) MarkCommentHidden(ctx context.Context, id int64, at time.Time) error {
+	f.hidden = append(f.hidden, id)
+	return nil
+}
+
+func (f *fakeCommentsRepository) MarkCommentAnswered(ctx context.Context, id int64, at time.Time) error {
+	f.answered = append(f.answered, id)
+	return nil
+}
+
+func (f *fakeCommentsRepository) UpsertComments(ctx context.Context, comments []model.Comment) error {
+	if f.upsertErr != nil {
+		return f.upsertErr
+	}
+	f.upserted = append(f.upserted, comments...)
+	return nil
+}
+
+type fakePagesRepository struct {
+	repository.SocialNetworkPagesRepository
+	pages []model.SocialNetworkPage
+}
+
+func (f *fakePagesRepository) FindPages(
+	context.Context,
+	postgres.FindSocialNetworkPageQuery,
+) ([]model.SocialNetworkPage, error) {
+	return f.pages, nil
+}
+
+type fakeAccountsRepository struct {
+	repository.SocialNetworkAccountsRepository
+	accounts []model.SocialNetworkAccount
+}
+
+func (f *fakeAccountsRepository) FindAccounts(
+	context.Context,
+	postgres.FindSocialNetworkAccountQuery,
+) ([]model.SocialNetworkAccount, error) {
+	return f.accounts, nil
+}
+
+type fakeCommentModerationRepository struct {
+	repository.CommentModerationRepository
+	rules        []model.CommentModerationRule
+	logs         []model.CommentModerationLog
+	createLogErr error
+}
+
+func (f *fakeCommentModerationRepository) FindRules(
+	context.Context,
+	postgres.FindCommentModerationRulesQuery,
+) ([]model.CommentModerationRule, error) {
+	return f.rules, nil
+}
+
+func (f *fakeCommentModerationRepository) CreateLog(ctx context.Context, log *model.CommentModerationLog) error {
+	if f.createLogErr != nil {
+		return f.createLogErr
+	}
+	f.logs = append(f.logs, *log)
+	return nil
+}
+
+type fakeCommentAutoRepliesRepository struct {
+	repository.CommentAutoRepliesRepository
+	rules []model.CommentAutoReplyRule
+	// dailyReplies и authorReplies ответы страницы и ответы автору, которые вернет CountReplies
+	dailyReplies   int
+	authorReplies  int
+	replies        []model.CommentAutoReply
+	createReplyErr error
+}
+
+func (f *fakeCommentAutoRepliesRepository) FindRules(
+	context.Context,
+	postgres.FindCommentAutoReplyRulesQuery,
+) ([]model.CommentAutoReplyRule, error) {
+	return f.rules, nil
+}
+
+func (f *fakeCommentAutoRepliesRepository) CountReplies(
+	ctx context.Context,
+	query postgres.CountCommentAutoRepliesQuery,
+) (int, error) {
+	if query.AuthorID != "" {
+		return f.authorReplies, nil
+	}
+	return f.dailyReplies, nil
+}
+
+func (f *fakeCommentAutoRepliesRepository) CreateReply(ctx context.Context, reply *model.CommentAutoReply) error {
+	if f.createReplyErr != nil {
+		return f.createReplyErr
+	}
+	f.replies = append(f.replies, *reply)
+	return nil
+}
+
+type fakeProjectSettingsRepository struct {
+	repository.ProjectSettingsRepository
+	timezone string
+}
+
+func (f *fakeProjectSettingsRepository) FindSettings(ctx context.Context, project string) (*model.ProjectSettings, error) {
+	return &model.ProjectSettings{Project: project, Timezone: f.timezone}, nil
+}
+
+type fakePostMetricsRepository struct {
+	repository.PostMetricsRepository
+	metrics []model.PublishedPostMetrics
+	created []model.PostMetrics
+}
+
+func (f *fakePostMetricsRepository) CreateMetrics(ctx context.Context, metrics []model.PostMetrics) error {
+	f.created = append(f.created, metrics...)
+	return nil
+}
+
+func (f *fakePostMetricsRepository) FindLatestMetrics(
+	context.Context,
+	postgres.FindLatestPostMetricsQuery,
+) ([]model.PublishedPostMetrics, error) {
+	return f.metrics, nil
+}
+
+// fakeCommentsClient клиент соц сети с комментариями, запоминает выполненные действия
+type fakeCommentsClient struct {
+	social_network_client.SocialNetworkClient
+	hidden  []string
+	replies []string
+}
+
+func (f *fakeCommentsClient) GetPostComments(
+	string,
+	string,
+	social_network_client.RemotePost,
+) ([]social_network_client.Comment, error) {
+	return nil, nil
+}
+
+func (f *fakeCommentsClient) ReplyToComment(
+	credentials string,
+	accessToken string,
+	comment social_network_client.RemoteComment,
+	text string,
+) (string, error) {
+	f.replies = append(f.replies, text)
+	return "reply-" + comment.CommentID, nil
+}
+
+func (f *fakeCommentsClient) DeleteComment(string, string, social_network_client.RemoteComment) error {
+	return nil
+}
+
+func (f *fakeCommentsClient) SetCommentHidden(
+	credentials string,
+	accessToken string,
+	comment social_network_client.RemoteComment,
+	hidden bool,
+) error {
+	f.hidden = append(f.hidden, comment.CommentID)
+	return nil
+}
+
+type fakePostsRepository struct {
+	repository.PostsRepository
+	posts    []model.Post
+	imported []model.Post
+}
+
+func (f *fakePostsRepository) FindPosts(context.Context, postgres.FindPostsQuery) ([]model.Post, error) {
+	return f.posts, nil
+}
+
+func (f *fakePostsRepository) CreateImportedPosts(ctx context.Context, posts []model.Post) (int, error) {
+	f.imported = append(f.imported, posts...)
+	return len(posts), nil
+}
+
+type fakePageHistoryImportsRepository struct {
+	repository.PageHistoryImportsRepository
+	pending  []model.PageHistoryImport
+	finished []model.PageHistoryImport
+}
+
+func (f *fakePageHistoryImportsRepository) TakePendingImport(
+	ctx context.Context,
+	startedAt time.Time,
+) (*model.PageHistoryImport, error) {
+	if len(f.pending) == 0 {
+		return nil, nil
+	}
+	pageHistoryImport := f.pending[0]
+	f.pending = f.pending[1:]
+	pageHistoryImport.Status = model.PageHistoryImportStatusRunning
+	pageHistoryImport.StartedAt = startedAt
+	return &pageHistoryImport, nil
+}
+
+func (f *fakePageHistoryImportsRepository) FinishImport(
+	ctx context.Context,
+	pageHistoryImport *model.PageHistoryImport,
+) error {
+	f.finished = append(f.finished, *pageHistoryImport)
+	return nil
+}
+
+type fakePageAudienceRepository struct {
+	repository.PageAudienceRepository
+	created []model.PageAudience
+}
+
+func (f *fakePageAudienceRepository) CreateAudience(ctx context.Context, audience []model.PageAudience) error {
+	f.created = append(f.created, audience...)
+	return nil
+}
+
+type fakeWatchedPagesRepository struct {
+	repository.WatchedPagesRepository
+	watchedPages []model.WatchedPage
+	audience     []model.WatchedPageAudience
+	posts        []model.WatchedPagePost
+}
+
+func (f *fakeWatchedPagesRepository) FindWatchedPages(
+	context.Context,
+	postgres.FindWatchedPagesQuery,
+) ([]model.WatchedPage, error) {
+	return f.watchedPages, nil
+}
+
+func (f *fakeWatchedPagesRepository) CreateWatchedPagesAudience(
+	ctx context.Context,
+	audience []model.WatchedPageAudience,
+) error {
+	f.audience = append(f.audience, audience...)
+	return nil
+}
+
+func (f *fakeWatchedPagesRepository) UpsertWatchedPagePosts(ctx context.Context, posts []model.WatchedPagePost) error {
+	f.posts = append(f.posts, posts...)
+	return nil
+}
+
+// fakeAnalyticsClient клиент соц сети с историей страниц, метриками постов и подписчиками.
+// Порции истории отдаются по номеру в курсоре, historyErr возвращается вместо порции после последней.
+// Метрики и подписчики ищутся по id поста и страницы, metricsErr и audienceErr - ошибки всего запроса
+type fakeAnalyticsClient struct {
+	social_network_client.SocialNetworkClient
+	history     map[string][]social_network_client.PageHistoryChunk
+	historyErr  error
+	metrics     map[string]social_network_client.PostMetricsResult
+	metricsErr  error
+	audience    map[string]social_network_client.PageAudienceResult
+	audienceErr error
+}
+
+func (f *fakeAnalyticsClient) GetPagesAudience(
+	credentials string,
+	accessToken string,
+	pagesIDs []string,
+) ([]social_network_client.PageAudienceResult, error) {
+	if f.audienceErr != nil {
+		return nil, f.audienceErr
+	}
+	results := make([]social_network_client.PageAudienceResult, 0, len(pagesIDs))
+	for _, pageID := range pagesIDs {
+		result := f.audience[pageID]
+		result.PageID = pageID
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (f *fakeAnalyticsClient) GetPostsMetrics(
+	credentials string,
+	accessToken string,
+	posts []social_network_client.RemotePost,
+) ([]social_network_client.PostMetricsResult, error) {
+	if f.metricsErr != nil {
+		return nil, f.metricsErr
+	}
+	results := make([]social_network_client.PostMetricsResult, 0, len(posts))
+	for _, post := range posts {
+		result := f.metrics[post.PostID]
+		result.Post = post
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (f *fakeAnalyticsClient) GetPageHistoryChunk(
+	credentials string,
+	accessToken string,
+	pageID string,
+	since time.Time,
+	cursor social_network_client.PagesCursor,
+) (*social_network_client.PageHistoryChunk, error) {
+	index := 0
+	if cursor.Cursor != "" {
+		index, _ = strconv.Atoi(cursor.Cursor)
+	}
+	chunks := f.history[pageID]
+	if index == len(chunks) {
+		if f.historyErr != nil {
+			return nil, f.historyErr
+		}
+		return &social_network_client.PageHistoryChunk{}, nil
+	}
+
+	chunk := chunks[index]
+	if index+1 < len(chunks) || f.historyErr != nil {
+		chunk.NextCursor = strconv.Itoa(index + 1)
+	}
+	return &chunk, nil
+}
+
+// newPagesTestService сервис со страницами VK id 1 и 2 одного аккаунта
+func newPagesTestService(client *fakeAnalyticsClient) *SocialNetworkService {
+	return &SocialNetworkService{
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		socialNetworkAccountsRepository: &fakeAccountsRepository{
+			accounts: []model.SocialNetworkAccount{{ID: 1, SocialNetwork: "VK", Credentials: "{}"}},
+		},
+		socialNetworkPagesRepository: &fakePagesRepository{
+			pages: []model.SocialNetworkPage{
+				{ID: 1, AccountID: 1, Project: "test", PageID: "100"},
+				{ID: 2, AccountID: 1, Project: "test", PageID: "200"},
+			},
+		},
+		socialNetworkClients: map[model.SocialNetworkName]social_network_client.SocialNetworkClient{
+			"VK": client,
+		},
+	}
+}
+
+// newCommentsTestService сервис с одной страницей VK id 1 в проекте test и фейковыми хранилищами
+func newCommentsTestService(
+	comments *fakeCommentsRepository,
+	moderation *fakeCommentModerationRepository,
+	autoReplies *fakeCommentAutoRepliesRepository,
+	client *fakeCommentsClient,
+) *CommentsService {
+	sns := &SocialNetworkService{
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		socialNetworkAccountsRepository: &fakeAccountsRepository{
+			accounts: []model.SocialNetworkAccount{{ID: 1, SocialNetwork: "VK", Credentials: "{}"}},
+		},
+		socialNetworkPagesRepository: &fakePagesRepository{
+			pages: []model.SocialNetworkPage{{ID: 1, AccountID: 1, Project: "test", PageID: "100"}},
+		},
+		socialNetworkClients: map[model.SocialNetworkName]social_network_client.SocialNetworkClient{
+			"VK": client,
+		},
+	}
+	return NewCommentsService(sns.logger, sns, comments, moderation, autoReplies)
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"autoposting/internal/domain/model"
+	"autoposting/internal/infrastructure/postgres"
+	"autoposting/internal/infrastructure/social_network_client"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"log/slog"
+	"time"
+)
+
+// CollectMetricsResult итог сбора метрик, Failed - посты, метрики которых не удалось получить
+type CollectMetricsResult struct {
+	Collected int
+	Failed    int
+}
+
+// CollectPostsMetrics сохраняет снимок метрик постов, опубликованных после publishedAfter.
+// Посты, удаленные в соц сети, пропускаются
+func (as *AnalyticsService) CollectPostsMetrics(
+	ctx context.Context,
+	publishedAfter time.Time,
+) (*CollectMetricsResult, error) {
+	posts, err := as.socialNetworkService.postsRepository.FindPosts(ctx, postgres.FindPostsQuery{
+		PublishedAfter: publishedAfter,
+		RemoteStateAnyOf: []model.PostRemoteState{
+			model.PostRemoteStatePublished,
+			model.PostRemoteStateModified,
+		},
+	})
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find posts to collect metrics: %w", err)
+	}
+
+	targets, err := as.socialNetworkService.getRemotePostsTargets(ctx, posts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CollectMetricsResult{}
+	var metrics []model.PostMetrics
+	collectedAt := time.Now()
+	for _, target := range targets {
+		client := as.socialNetworkService.getClient(target.account.SocialNetwork)
+		fetcher, ok := client.(social_network_client.PostMetricsFetcher)
+		if !ok {
+			continue
+		}
+
+		metricsResults, err := fetcher.GetPostsMetrics(target.account.Credentials, target.accessToken, target.remotePosts)
+		if err != nil {
+			as.logger.Error(
+				"failed to get posts metrics",
+				slog.String("socialNetwork", string(target.account.SocialNetwork)),
+				slog.Any("err", err),
+			)
+			result.Failed += len(target.remotePosts)
+			continue
+		}
+
+		for _, metricsResult := range metricsResults {
+			post, ok := target.postsByRemotePost[metricsResult.Post]
+			if !ok || metricsResult.Deleted {
+				continue
+			}
+			if metricsResult.Err != nil {
+				as.logger.Warn(
+					"failed to get post metrics",
+					slog.String("socialNetwork", string(target.account.SocialNetwork)),
+					slog.Int64("post", post.ID),
+					slog.Any("err", metricsResult.Err),
+				)
+				result.Failed++
+				continue
+			}
+			if metricsResult.ReachErr != nil {
+				as.logger.Warn(
+					"failed to get post reach",
+					slog.String("socialNetwork", string(target.account.SocialNetwork)),
+					slog.Int64("post", post.ID),
+					slog.Any("err", metricsResult.ReachErr),
+				)
+			}
+
+			metrics = append(metrics, model.PostMetrics{
+				Post:        post.ID,
+				CollectedAt: collectedAt,
+				Likes:       metricsResult.Metrics.Likes,
+				Reposts:     metricsResult.Metrics.Reposts,
+				Comments:    metricsResult.Metrics.Comments,
+				Views:       metricsResult.Metrics.Views,
+				Reach:       metricsResult.Metrics.Reach,
+			})
+			result.Collected++
+		}
+	}
+
+	if err := as.postMetricsRepository.CreateMetrics(ctx, metrics); err != nil {
+		return result, ewrap.Errorf("failed to save posts metrics: %w", err)
+	}
+
+	return result, nil
+}
+
+func (as *AnalyticsService) GetPostMetrics(
+	ctx context.Context,
+	query postgres.FindPostMetricsQuery,
+) ([]model.PostMetrics, error) {
+	metrics, err := as.postMetricsRepository.FindMetrics(ctx, query)
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find post %d metrics: %w", query.PostID, err)
+	}
+	return metrics, nil
+}
+
+func (as *AnalyticsService) AggregatePostMetrics(
+	ctx context.Context,
+	query postgres.AggregatePostMetricsQuery,
+) ([]model.PostMetricsAggregate, error) {
+	aggregates, err := as.postMetricsRepository.AggregateMetrics(ctx, query)
+	if err != nil {
+		return nil, ewrap.Errorf("failed to aggregate posts metrics by %s: %w", query.GroupBy, err)
+	}
+	return aggregates, nil
+}
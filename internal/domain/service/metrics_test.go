@@ -0,0 +1,79 @@
+package service
+
+import (
+	"autoposting/internal/domain/model"
+	"autoposting/internal/infrastructure/social_network_client"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCollectPostsMetrics(t *testing.T) {
+	posts := []model.Post{
+		{ID: 1, Page: 1, RemotePostID: "11"},
+		{ID: 2, Page: 1, RemotePostID: "12"},
+		{ID: 3, Page: 2, RemotePostID: "21"},
+		{ID: 4, Page: 2, RemotePostID: "22"},
+		// Посты без id в соц сети не собираются
+		{ID: 5, Page: 2},
+	}
+
+	tests := []struct {
+		name          string
+		metrics       map[string]social_network_client.PostMetricsResult
+		metricsErr    error
+		wantCollected int
+		wantFailed    int
+		wantMetrics   map[int64]int
+	}{
+		{
+			name: "collected",
+			metrics: map[string]social_network_client.PostMetricsResult{
+				"11": {Metrics: social_network_client.PostMetrics{Likes: 10, Reach: 100}},
+				"12": {Err: errors.New("access denied")},
+				"21": {Deleted: true},
+				"22": {Metrics: social_network_client.PostMetrics{Likes: 7}, ReachErr: errors.New("no stats")},
+			},
+			wantCollected: 2,
+			wantFailed:    1,
+			wantMetrics:   map[int64]int{1: 10, 4: 7},
+		},
+		{
+			name:       "request fails",
+			metricsErr: errors.New("rate limit"),
+			wantFailed: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			metricsRepository := &fakePostMetricsRepository{}
+			sns := newPagesTestService(&fakeAnalyticsClient{metrics: tt.metrics, metricsErr: tt.metricsErr})
+			sns.postsRepository = &fakePostsRepository{posts: posts}
+			as := NewAnalyticsService(sns.logger, sns, metricsRepository, nil, nil)
+
+			result, err := as.CollectPostsMetrics(context.Background(), time.Now().Add(-time.Hour))
+			if err != nil {
+				t.Fatalf("CollectPostsMetrics: %v", err)
+			}
+			if result.Collected != tt.wantCollected || result.Failed != tt.wantFailed {
+				t.Fatalf(
+					"got collected %d, failed %d, want %d, %d",
+					result.Collected,
+					result.Failed,
+					tt.wantCollected,
+					tt.wantFailed,
+				)
+			}
+			if len(metricsRepository.created) != len(tt.wantMetrics) {
+				t.Fatalf("got %d saved metrics, want %d", len(metricsRepository.created), len(tt.wantMetrics))
+			}
+			for _, metrics := range metricsRepository.created {
+				if likes, ok := tt.wantMetrics[metrics.Post]; !ok || metrics.Likes != likes {
+					t.Fatalf("got metrics %+v, want likes %v", metrics, tt.wantMetrics)
+				}
+			}
+		})
+	}
+}
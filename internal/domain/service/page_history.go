@@ -0,0 +1,180 @@
+package service
+
+import (
+	"autoposting/internal/domain"
+	"autoposting/internal/domain/model"
+	"autoposting/internal/domain/repository"
+	"autoposting/internal/infrastructure/social_network_client"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// PostsHistoryService импорт истории страниц и сверка опубликованных постов с соц сетями
+type PostsHistoryService struct {
+	logger                       *slog.Logger
+	socialNetworkService         *SocialNetworkService
+	pageHistoryImportsRepository repository.PageHistoryImportsRepository
+}
+
+func NewPostsHistoryService(
+	logger *slog.Logger,
+	socialNetworkService *SocialNetworkService,
+	pageHistoryImportsRepository repository.PageHistoryImportsRepository,
+) *PostsHistoryService {
+	return &PostsHistoryService{
+		logger:                       logger,
+		socialNetworkService:         socialNetworkService,
+		pageHistoryImportsRepository: pageHistoryImportsRepository,
+	}
+}
+
+// pageHistoryImportFinishTimeout итог задачи сохраняется и после отмены контекста задачи
+const pageHistoryImportFinishTimeout = 10 * time.Second
+
+// CreatePageHistoryImport ставит импорт истории страницы в очередь, выполняет его фоновая задача
+func (phs *PostsHistoryService) CreatePageHistoryImport(
+	ctx context.Context,
+	pageID int,
+	since time.Time,
+) (*model.PageHistoryImport, error) {
+	targets, err := phs.socialNetworkService.getPublishTargets(ctx, []int{pageID})
+	if err != nil {
+		return nil, err
+	}
+	socialNetwork := targets[0].account.SocialNetwork
+	if _, ok := phs.socialNetworkService.getClient(socialNetwork).(social_network_client.PageHistoryFetcher); !ok {
+		return nil, domain.NewValidationError(
+			fmt.Sprintf("social network %s does not support importing page history", socialNetwork),
+			"pageId",
+			"pageHistory",
+		)
+	}
+
+	pageHistoryImport := &model.PageHistoryImport{
+		Page:      pageID,
+		Since:     since,
+		Status:    model.PageHistoryImportStatusPending,
+		CreatedAt: time.Now(),
+	}
+	if err := phs.pageHistoryImportsRepository.CreateImport(ctx, pageHistoryImport); err != nil {
+		return nil, ewrap.Errorf("failed to create page history import: %w", err)
+	}
+
+	return pageHistoryImport, nil
+}
+
+// RunPendingPageHistoryImport выполняет самую старую задачу из очереди, nil - очередь пуста
+func (phs *PostsHistoryService) RunPendingPageHistoryImport(
+	ctx context.Context,
+) (*model.PageHistoryImport, error) {
+	pageHistoryImport, err := phs.pageHistoryImportsRepository.TakePendingImport(ctx, time.Now())
+	if err != nil {
+		return nil, ewrap.Errorf("failed to take page history import: %w", err)
+	}
+	if pageHistoryImport == nil {
+		return nil, nil
+	}
+
+	err = phs.importPageHistory(ctx, pageHistoryImport)
+	switch {
+	case err == nil:
+		pageHistoryImport.Status = model.PageHistoryImportStatusDone
+		pageHistoryImport.FinishedAt = time.Now()
+	case ctx.Err() != nil:
+		// Задачу прервала остановка приложения, посты дедуплицируются, поэтому она просто повторится
+		pageHistoryImport.Status = model.PageHistoryImportStatusPending
+		pageHistoryImport.StartedAt = time.Time{}
+	default:
+		phs.logger.Error(
+			"failed to import page history",
+			slog.Int64("import", pageHistoryImport.ID),
+			slog.Int("page", pageHistoryImport.Page),
+			slog.Any("err", err),
+		)
+		pageHistoryImport.Status = model.PageHistoryImportStatusFailed
+		pageHistoryImport.Error = err.Error()
+		pageHistoryImport.FinishedAt = time.Now()
+	}
+
+	finishCtx, cancel := context.WithTimeout(context.Background(), pageHistoryImportFinishTimeout)
+	defer cancel()
+	if err := phs.pageHistoryImportsRepository.FinishImport(finishCtx, pageHistoryImport); err != nil {
+		return pageHistoryImport, ewrap.Errorf("failed to save page history import result: %w", err)
+	}
+
+	return pageHistoryImport, nil
+}
+
+// RequeueRunningPageHistoryImports возвращает в очередь задачи, которые остались RUNNING после аварийной остановки
+func (phs *PostsHistoryService) RequeueRunningPageHistoryImports(ctx context.Context) (int, error) {
+	requeued, err := phs.pageHistoryImportsRepository.RequeueRunningImports(ctx)
+	if err != nil {
+		return 0, ewrap.Errorf("failed to requeue running page history imports: %w", err)
+	}
+	return requeued, nil
+}
+
+// importPageHistory сохраняет посты порциями, уже сохраненные порции не откатываются при ошибке
+func (phs *PostsHistoryService) importPageHistory(
+	ctx context.Context,
+	pageHistoryImport *model.PageHistoryImport,
+) error {
+	targets, err := phs.socialNetworkService.getPublishTargets(ctx, []int{pageHistoryImport.Page})
+	if err != nil {
+		return err
+	}
+	target := targets[0]
+	page := target.pages[0]
+
+	client := phs.socialNetworkService.getClient(target.account.SocialNetwork)
+	fetcher, ok := client.(social_network_client.PageHistoryFetcher)
+	if !ok {
+		return domain.NewInternalError(
+			fmt.Sprintf("social network %s does not support importing page history", target.account.SocialNetwork),
+		)
+	}
+
+	var cursor social_network_client.PagesCursor
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		chunk, err := fetcher.GetPageHistoryChunk(
+			target.account.Credentials,
+			target.accessToken,
+			page.PageID,
+			pageHistoryImport.Since,
+			cursor,
+		)
+		if err != nil {
+			return ewrap.Errorf("failed to get %s page %s history: %w", target.account.SocialNetwork, page.PageID, err)
+		}
+
+		posts := make([]model.Post, 0, len(chunk.Posts))
+		for _, historyPost := range chunk.Posts {
+			posts = append(posts, model.Post{
+				Page:         page.ID,
+				RemotePostID: historyPost.PostID,
+				PostData:     toModelPostData(historyPost.Post),
+				PublishedAt:  historyPost.PublishedAt,
+				RemoteState:  model.PostRemoteStatePublished,
+				Imported:     true,
+			})
+		}
+		imported, err := phs.socialNetworkService.postsRepository.CreateImportedPosts(ctx, posts)
+		if err != nil {
+			return ewrap.Errorf("failed to save imported posts: %w", err)
+		}
+		pageHistoryImport.Fetched += len(posts)
+		pageHistoryImport.Imported += imported
+
+		if chunk.NextCursor == "" {
+			return nil
+		}
+		cursor.Cursor = chunk.NextCursor
+	}
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"autoposting/internal/domain/model"
+	"autoposting/internal/infrastructure/social_network_client"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRunPendingPageHistoryImport(t *testing.T) {
+	chunks := []social_network_client.PageHistoryChunk{
+		{Posts: []social_network_client.PageHistoryPost{
+			{PostID: "3", Post: social_network_client.Post{Text: "third"}},
+			{PostID: "2", Post: social_network_client.Post{Text: "second"}},
+		}},
+		{Posts: []social_network_client.PageHistoryPost{
+			{PostID: "1", Post: social_network_client.Post{Text: "first"}},
+		}},
+	}
+
+	tests := []struct {
+		name         string
+		historyErr   error
+		cancelled    bool
+		wantStatus   model.PageHistoryImportStatus
+		wantImported int
+	}{
+		{name: "all chunks", wantStatus: model.PageHistoryImportStatusDone, wantImported: 3},
+		{
+			name:         "chunk fails",
+			historyErr:   errors.New("rate limit"),
+			wantStatus:   model.PageHistoryImportStatusFailed,
+			wantImported: 3,
+		},
+		{name: "interrupted", cancelled: true, wantStatus: model.PageHistoryImportStatusPending},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			posts := &fakePostsRepository{}
+			imports := &fakePageHistoryImportsRepository{
+				pending: []model.PageHistoryImport{{ID: 5, Page: 1, Status: model.PageHistoryImportStatusPending}},
+			}
+			sns := newPagesTestService(&fakeAnalyticsClient{
+				history:    map[string][]social_network_client.PageHistoryChunk{"100": chunks},
+				historyErr: tt.historyErr,
+			})
+			sns.postsRepository = posts
+			phs := NewPostsHistoryService(sns.logger, sns, imports)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancelled {
+				cancel()
+			}
+
+			got, err := phs.RunPendingPageHistoryImport(ctx)
+			if err != nil {
+				t.Fatalf("RunPendingPageHistoryImport: %v", err)
+			}
+			if len(imports.finished) != 1 || imports.finished[0].ID != 5 {
+				t.Fatalf("got finished imports %+v, want import 5", imports.finished)
+			}
+			if got.Status != tt.wantStatus {
+				t.Fatalf("got status %s, want %s", got.Status, tt.wantStatus)
+			}
+			if got.Fetched != tt.wantImported || got.Imported != tt.wantImported || len(posts.imported) != tt.wantImported {
+				t.Fatalf(
+					"got fetched %d, imported %d, saved %d, want %d",
+					got.Fetched,
+					got.Imported,
+					len(posts.imported),
+					tt.wantImported,
+				)
+			}
+			for _, post := range posts.imported {
+				if post.Page != 1 || !post.Imported || post.PostData == nil || post.PostData.Text == "" {
+					t.Fatalf("got imported post %+v, want imported post of page 1", post)
+				}
+			}
+
+			switch tt.wantStatus {
+			case model.PageHistoryImportStatusFailed:
+				if got.Error == "" || got.FinishedAt.IsZero() {
+					t.Fatalf("got error %q, finished at %v, want error and finish time", got.Error, got.FinishedAt)
+				}
+			case model.PageHistoryImportStatusPending:
+				if !got.StartedAt.IsZero() || !got.FinishedAt.IsZero() {
+					t.Fatalf("got started at %v, finished at %v, want requeued import", got.StartedAt, got.FinishedAt)
+				}
+			}
+		})
+	}
+}
+
+func TestRunPendingPageHistoryImportEmptyQueue(t *testing.T) {
+	sns := newPagesTestService(&fakeAnalyticsClient{})
+	phs := NewPostsHistoryService(sns.logger, sns, &fakePageHistoryImportsRepository{})
+
+	got, err := phs.RunPendingPageHistoryImport(context.Background())
+	if err != nil || got != nil {
+		t.Fatalf("got import %+v, error %v, want nil", got, err)
+	}
+}
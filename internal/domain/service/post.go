@@ -0,0 +1,454 @@
+package service
+
+import (
+	"autoposting/internal/domain"
+	"autoposting/internal/domain/model"
+	"autoposting/internal/infrastructure/postgres"
+	"autoposting/internal/infrastructure/social_network_client"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// PublishResult результат публикации поста в страницу, Err не прерывает публикацию в остальные страницы
+type PublishResult struct {
+	Page          model.SocialNetworkPage
+	SocialNetwork model.SocialNetworkName
+	PostID        string
+	Err           error
+}
+
+// DryRunRequest запрос к соц сети, который отправила бы публикация, секреты скрыты
+type DryRunRequest struct {
+	SocialNetwork model.SocialNetworkName
+	Pages         []model.SocialNetworkPage
+	Request       social_network_client.PreparedRequest
+}
+
+// publishTarget страницы одного аккаунта, публикуемые с одним токеном
+type publishTarget struct {
+	account     *model.SocialNetworkAccount
+	accessToken string
+	pages       []model.SocialNetworkPage
+}
+
+func (sns *SocialNetworkService) CreatePost(
+	ctx context.Context,
+	pagesIDs []int,
+	post social_network_client.Post,
+) ([]PublishResult, error) {
+	targets, err := sns.getPublishTargets(ctx, pagesIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	post = preparePost(post)
+	if err := sns.validatePost(targets, post); err != nil {
+		return nil, err
+	}
+
+	var (
+		results    []PublishResult
+		posts      []model.Post
+		deliveries []model.WebhookDelivery
+	)
+	for _, target := range targets {
+		pagesByRemoteID := make(map[string]model.SocialNetworkPage, len(target.pages))
+		remotePagesIDs := make([]string, 0, len(target.pages))
+		for _, page := range target.pages {
+			pagesByRemoteID[page.PageID] = page
+			remotePagesIDs = append(remotePagesIDs, page.PageID)
+		}
+
+		postResults, err := sns.PublishPost(ctx, target.account, target.accessToken, remotePagesIDs, post)
+		if err != nil {
+			sns.logger.Error(
+				"failed to publish post",
+				slog.String("socialNetwork", string(target.account.SocialNetwork)),
+				slog.Any("err", err),
+			)
+			for _, page := range target.pages {
+				results = append(results, PublishResult{
+					Page:          page,
+					SocialNetwork: target.account.SocialNetwork,
+					Err:           err,
+				})
+			}
+			continue
+		}
+
+		for _, postResult := range postResults {
+			page := pagesByRemoteID[postResult.PageID]
+			results = append(results, PublishResult{
+				Page:          page,
+				SocialNetwork: target.account.SocialNetwork,
+				PostID:        postResult.PostID,
+				Err:           postResult.Err,
+			})
+			deliveries = append(deliveries, toModelWebhookDeliveries(page.ID, postResult.Attempts)...)
+			if postResult.Err == nil {
+				posts = append(posts, model.Post{
+					Page:         page.ID,
+					RemotePostID: postResult.PostID,
+					PostData:     toModelPostData(post),
+					PublishedAt:  time.Now(),
+					RemoteState:  model.PostRemoteStatePublished,
+				})
+			}
+		}
+	}
+
+	// Попытки доставки нужны только для разбора, их потеря не отменяет публикацию
+	if err := sns.webhookDeliveriesRepository.CreateDeliveries(ctx, deliveries); err != nil {
+		sns.logger.Error(
+			"failed to save webhook deliveries",
+			slog.Int("deliveries", len(deliveries)),
+			slog.Any("err", err),
+		)
+	}
+
+	if err := sns.postsRepository.CreatePosts(ctx, posts); err != nil {
+		sns.logger.Error(
+			"failed to save published posts",
+			slog.Int("posts", len(posts)),
+			slog.Any("err", err),
+		)
+		return results, ewrap.Errorf("failed to save published posts: %w", err)
+	}
+
+	return results, nil
+}
+
+func (sns *SocialNetworkService) GetPosts(
+	ctx context.Context,
+	query postgres.FindPostsQuery,
+) ([]model.Post, error) {
+	posts, err := sns.postsRepository.FindPosts(ctx, query)
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find posts: %w", err)
+	}
+	return posts, nil
+}
+
+// PreparePost проходит те же шаги, что и CreatePost, но вместо публикации возвращает запросы к соц сетям
+func (sns *SocialNetworkService) PreparePost(
+	ctx context.Context,
+	pagesIDs []int,
+	post social_network_client.Post,
+) ([]DryRunRequest, error) {
+	targets, err := sns.getPublishTargets(ctx, pagesIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	post = preparePost(post)
+	if err := sns.validatePost(targets, post); err != nil {
+		return nil, err
+	}
+
+	var dryRunRequests []DryRunRequest
+	for _, target := range targets {
+		pagesByRemoteID := make(map[string]model.SocialNetworkPage, len(target.pages))
+		remotePagesIDs := make([]string, 0, len(target.pages))
+		for _, page := range target.pages {
+			pagesByRemoteID[page.PageID] = page
+			remotePagesIDs = append(remotePagesIDs, page.PageID)
+		}
+
+		client := sns.socialNetworkClients[target.account.SocialNetwork]
+		requests, err := client.PreparePost(target.account.Credentials, target.accessToken, remotePagesIDs, post)
+		if err != nil {
+			return nil, ewrap.Errorf(
+				"failed to prepare post for social network %s: %w",
+				target.account.SocialNetwork,
+				err,
+			)
+		}
+
+		for _, request := range requests {
+			dryRunRequest := DryRunRequest{
+				SocialNetwork: target.account.SocialNetwork,
+				Request:       request.Redacted(),
+			}
+			for _, pageID := range request.PagesIDs {
+				dryRunRequest.Pages = append(dryRunRequest.Pages, pagesByRemoteID[pageID])
+			}
+			dryRunRequests = append(dryRunRequests, dryRunRequest)
+		}
+	}
+
+	return dryRunRequests, nil
+}
+
+// PublishPost публикует пост в страницы аккаунта, одним запросом если клиент это поддерживает
+func (sns *SocialNetworkService) PublishPost(
+	ctx context.Context,
+	socialNetworkAccount *model.SocialNetworkAccount,
+	accessToken string,
+	pagesIDs []string,
+	post social_network_client.Post,
+) ([]social_network_client.PostResult, error) {
+	client := sns.socialNetworkClients[socialNetworkAccount.SocialNetwork]
+
+	if batchClient, ok := client.(social_network_client.BatchPostCreator); ok {
+		results, err := batchClient.CreatePosts(ctx, socialNetworkAccount.Credentials, accessToken, pagesIDs, post)
+		if err != nil {
+			return nil, ewrap.Errorf(
+				"failed to create posts in social network %s: %w",
+				socialNetworkAccount.SocialNetwork,
+				err,
+			)
+		}
+		return results, nil
+	}
+
+	results := make([]social_network_client.PostResult, 0, len(pagesIDs))
+	for _, pageID := range pagesIDs {
+		postID, err := client.CreatePost(ctx, socialNetworkAccount.Credentials, accessToken, pageID, post)
+		results = append(results, social_network_client.PostResult{
+			PageID: pageID,
+			PostID: postID,
+			Err:    err,
+		})
+	}
+
+	return results, nil
+}
+
+// getPublishTargets группирует страницы по аккаунту и токену публикации.
+// Токен страницы приоритетнее токена аккаунта, без токенов клиент берет токен из credentials
+func (sns *SocialNetworkService) getPublishTargets(
+	ctx context.Context,
+	pagesIDs []int,
+) ([]*publishTarget, error) {
+	pages, err := sns.socialNetworkPagesRepository.FindPages(ctx, postgres.FindSocialNetworkPageQuery{
+		IDAnyOf: pagesIDs,
+	})
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find pages %v: %w", pagesIDs, err)
+	}
+
+	foundPages := make(map[int]bool, len(pages))
+	accountsIDs := make([]int, 0, len(pages))
+	for _, page := range pages {
+		foundPages[page.ID] = true
+		accountsIDs = append(accountsIDs, page.AccountID)
+	}
+	for _, pageID := range pagesIDs {
+		if !foundPages[pageID] {
+			return nil, domain.NewNotFoundError(fmt.Sprintf("social network page %d not found", pageID))
+		}
+	}
+
+	accounts, err := sns.socialNetworkAccountsRepository.FindAccounts(ctx, postgres.FindSocialNetworkAccountQuery{
+		IDAnyOf: accountsIDs,
+	})
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find accounts %v: %w", accountsIDs, err)
+	}
+	accountsByID := make(map[int]*model.SocialNetworkAccount, len(accounts))
+	for i := range accounts {
+		accountsByID[accounts[i].ID] = &accounts[i]
+	}
+
+	var targets []*publishTarget
+	targetsByKey := map[string]*publishTarget{}
+	for _, page := range pages {
+		account, ok := accountsByID[page.AccountID]
+		if !ok {
+			return nil, domain.NewNotFoundError(
+				fmt.Sprintf("social network account %d of page %d not found", page.AccountID, page.ID),
+			)
+		}
+		if _, ok := sns.socialNetworkClients[account.SocialNetwork]; !ok {
+			return nil, domain.NewInternalError(
+				fmt.Sprintf("social network %s of page %d is not registered", account.SocialNetwork, page.ID),
+			)
+		}
+
+		accessToken := ""
+		switch {
+		case page.AccessToken != nil && page.AccessToken.Token != "":
+			accessToken = page.AccessToken.Token
+		case account.AccessToken != nil:
+			accessToken = account.AccessToken.Token
+		}
+
+		key := fmt.Sprintf("%d:%s", account.ID, accessToken)
+		target, ok := targetsByKey[key]
+		if !ok {
+			target = &publishTarget{
+				account:     account,
+				accessToken: accessToken,
+			}
+			targetsByKey[key] = target
+			targets = append(targets, target)
+		}
+		target.pages = append(target.pages, page)
+	}
+
+	return targets, nil
+}
+
+// remotePostsTarget посты одного аккаунта и токена с их идентификаторами в соц сети
+type remotePostsTarget struct {
+	*publishTarget
+	remotePosts       []social_network_client.RemotePost
+	postsByRemotePost map[social_network_client.RemotePost]*model.Post
+}
+
+// getRemotePostsTargets группирует посты как getPublishTargets страницы.
+// Посты без идентификатора в соц сети не найти, например сообщения вебхуков Slack, они пропускаются
+func (sns *SocialNetworkService) getRemotePostsTargets(
+	ctx context.Context,
+	posts []model.Post,
+) ([]*remotePostsTarget, error) {
+	postsByPage := map[int][]*model.Post{}
+	var pagesIDs []int
+	for i := range posts {
+		if posts[i].RemotePostID == "" {
+			continue
+		}
+		if _, ok := postsByPage[posts[i].Page]; !ok {
+			pagesIDs = append(pagesIDs, posts[i].Page)
+		}
+		postsByPage[posts[i].Page] = append(postsByPage[posts[i].Page], &posts[i])
+	}
+	if len(pagesIDs) == 0 {
+		return nil, nil
+	}
+
+	publishTargets, err := sns.getPublishTargets(ctx, pagesIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]*remotePostsTarget, 0, len(publishTargets))
+	for _, publishTarget := range publishTargets {
+		target := &remotePostsTarget{
+			publishTarget:     publishTarget,
+			postsByRemotePost: map[social_network_client.RemotePost]*model.Post{},
+		}
+		for _, page := range publishTarget.pages {
+			for _, post := range postsByPage[page.ID] {
+				remotePost := social_network_client.RemotePost{
+					PageID: page.PageID,
+					PostID: post.RemotePostID,
+				}
+				target.remotePosts = append(target.remotePosts, remotePost)
+				target.postsByRemotePost[remotePost] = post
+			}
+		}
+		targets = append(targets, target)
+	}
+
+	return targets, nil
+}
+
+// validatePost проверяет пост на ограничения каждой соц сети, в которую он публикуется
+func (sns *SocialNetworkService) validatePost(
+	targets []*publishTarget,
+	post social_network_client.Post,
+) error {
+	var validationErrors []*domain.ValidationError
+	checkedNetworks := map[model.SocialNetworkName]bool{}
+	for _, target := range targets {
+		socialNetwork := target.account.SocialNetwork
+		if checkedNetworks[socialNetwork] {
+			continue
+		}
+		checkedNetworks[socialNetwork] = true
+
+		client := sns.socialNetworkClients[socialNetwork]
+		checkedPost := post
+		if composer, ok := client.(social_network_client.TextComposer); ok {
+			checkedPost.Text = composer.ComposeText(post)
+		}
+		for _, violation := range client.Capabilities().Check(checkedPost) {
+			validationErrors = append(validationErrors, domain.NewValidationError(
+				fmt.Sprintf("%s: %s", socialNetwork, violation.Message),
+				fmt.Sprintf("postData.%s", violation.Field),
+				fmt.Sprintf("%s.%s", socialNetwork, violation.Rule),
+			))
+		}
+	}
+
+	if len(validationErrors) != 0 {
+		return domain.NewValidationErrors(validationErrors)
+	}
+
+	return nil
+}
+
+// preparePost приводит текст и медиа поста к виду, в котором он уходит в соц сети
+func preparePost(post social_network_client.Post) social_network_client.Post {
+	post.Text = strings.TrimSpace(strings.ReplaceAll(post.Text, "\r\n", "\n"))
+	post.Video = strings.TrimSpace(post.Video)
+	post.Link = strings.TrimSpace(post.Link)
+
+	post.ContentWarning = strings.TrimSpace(post.ContentWarning)
+
+	images := make([]string, 0, len(post.Images))
+	// Описания выравниваются по оставшимся изображениям
+	imagesAlt := make([]string, 0, len(post.Images))
+	seenImages := make(map[string]bool, len(post.Images))
+	for index, image := range post.Images {
+		image = strings.TrimSpace(image)
+		if image == "" || seenImages[image] {
+			continue
+		}
+		seenImages[image] = true
+		images = append(images, image)
+
+		alt := ""
+		if index < len(post.ImagesAlt) {
+			alt = strings.TrimSpace(post.ImagesAlt[index])
+		}
+		imagesAlt = append(imagesAlt, alt)
+	}
+	post.Images = images
+	post.ImagesAlt = imagesAlt
+
+	return post
+}
+
+func toModelPostData(post social_network_client.Post) *model.PostData {
+	postData := &model.PostData{
+		Text:           post.Text,
+		Images:         post.Images,
+		ImagesAlt:      post.ImagesAlt,
+		Video:          post.Video,
+		Link:           post.Link,
+		ContentWarning: post.ContentWarning,
+		Visibility:     string(post.Visibility),
+	}
+	if post.Poll != nil {
+		postData.Poll = &model.PostPoll{
+			Question: post.Poll.Question,
+			Answers:  post.Poll.Answers,
+		}
+	}
+	return postData
+}
+
+func toClientPost(postData *model.PostData) social_network_client.Post {
+	post := social_network_client.Post{
+		Text:           postData.Text,
+		Images:         postData.Images,
+		ImagesAlt:      postData.ImagesAlt,
+		Video:          postData.Video,
+		Link:           postData.Link,
+		ContentWarning: postData.ContentWarning,
+		Visibility:     social_network_client.PostVisibility(postData.Visibility),
+	}
+	if postData.Poll != nil {
+		post.Poll = &social_network_client.Poll{
+			Question: postData.Poll.Question,
+			Answers:  postData.Poll.Answers,
+		}
+	}
+	return post
+}
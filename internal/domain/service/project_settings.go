@@ -0,0 +1,67 @@
+package service
+
+import (
+	"autoposting/internal/domain"
+	"autoposting/internal/domain/model"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"fmt"
+	"time"
+	// Часовые пояса встроены в бинарник, в образе может не быть tzdata
+	_ "time/tzdata"
+)
+
+// SetProjectTimezone сохраняет часовой пояс IANA, в котором считаются окна публикации проекта
+func (sns *SocialNetworkService) SetProjectTimezone(
+	ctx context.Context,
+	project string,
+	timezone string,
+) (*model.ProjectSettings, error) {
+	if project == "" {
+		return nil, domain.NewValidationError("project is required", "project", "empty")
+	}
+	// Пустое имя LoadLocation считает UTC, а Local зависит от сервера
+	if _, err := time.LoadLocation(timezone); err != nil || timezone == "" || timezone == "Local" {
+		return nil, domain.NewValidationError(fmt.Sprintf("unknown timezone %s", timezone), "timezone", "format")
+	}
+
+	settings := &model.ProjectSettings{
+		Project:  project,
+		Timezone: timezone,
+	}
+	if err := sns.projectSettingsRepository.SaveSettings(ctx, settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// GetProjectSettings у проекта без сохраненных настроек часовой пояс UTC
+func (sns *SocialNetworkService) GetProjectSettings(
+	ctx context.Context,
+	project string,
+) (*model.ProjectSettings, error) {
+	settings, err := sns.projectSettingsRepository.FindSettings(ctx, project)
+	if err != nil {
+		if domain.IsNotFoundError(err) {
+			return &model.ProjectSettings{
+				Project:  project,
+				Timezone: time.UTC.String(),
+			}, nil
+		}
+		return nil, err
+	}
+	return settings, nil
+}
+
+func (sns *SocialNetworkService) getProjectLocation(ctx context.Context, project string) (*time.Location, error) {
+	settings, err := sns.GetProjectSettings(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+
+	location, err := time.LoadLocation(settings.Timezone)
+	if err != nil {
+		return nil, ewrap.Errorf("failed to load project %s timezone %s: %w", project, settings.Timezone, err)
+	}
+	return location, nil
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"autoposting/internal/domain"
+	"autoposting/internal/domain/model"
+	"autoposting/internal/infrastructure/postgres"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// recommendationsWindow анализируются посты, опубликованные за этот срок
+	recommendationsWindow = 90 * 24 * time.Hour
+	// recommendationPriorPosts вес средней вовлеченности страницы в оценке окна, в постах.
+	// Окно с несколькими удачными постами не обгоняет окно с устойчиво хорошей историей
+	recommendationPriorPosts = 3
+)
+
+// RecommendedSlot часовое окно публикации. Score - ожидаемая вовлеченность относительно средней
+// по странице, 1 - средняя. Confidence растет с числом постов окна от 0 к 1.
+// NextStartsAt - ближайшее начало окна, к нему планировщик может сдвинуть публикацию
+type RecommendedSlot struct {
+	Weekday      time.Weekday
+	Hour         int
+	Posts        int
+	Score        float64
+	Confidence   float64
+	NextStartsAt time.Time
+}
+
+type RecommendedSlotsResult struct {
+	Location      *time.Location
+	AnalyzedPosts int
+	Slots         []RecommendedSlot
+}
+
+// RecommendSlots ранжирует окна по дню недели и часу публикации в часовом поясе проекта страницы.
+// Вовлеченность поста - сумма лайков, репостов и комментариев из последнего снимка метрик
+func (as *AnalyticsService) RecommendSlots(
+	ctx context.Context,
+	pageID int,
+	limit int,
+) (*RecommendedSlotsResult, error) {
+	pages, err := as.socialNetworkService.socialNetworkPagesRepository.FindPages(ctx, postgres.FindSocialNetworkPageQuery{
+		IDAnyOf: []int{pageID},
+	})
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find page %d: %w", pageID, err)
+	}
+	if len(pages) == 0 {
+		return nil, domain.NewNotFoundError(fmt.Sprintf("social network page %d not found", pageID))
+	}
+
+	location, err := as.socialNetworkService.getProjectLocation(ctx, pages[0].Project)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics, err := as.postMetricsRepository.FindLatestMetrics(ctx, postgres.FindLatestPostMetricsQuery{
+		PagesIDAnyOf:   []int{pageID},
+		PublishedAfter: time.Now().Add(-recommendationsWindow),
+	})
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find page %d posts metrics: %w", pageID, err)
+	}
+
+	result := &RecommendedSlotsResult{
+		Location:      location,
+		AnalyzedPosts: len(metrics),
+	}
+	totalEngagement := 0
+	for _, postMetrics := range metrics {
+		totalEngagement += postEngagement(postMetrics)
+	}
+	// Без вовлеченности сравнивать окна не с чем
+	if totalEngagement == 0 {
+		return result, nil
+	}
+	meanEngagement := float64(totalEngagement) / float64(len(metrics))
+
+	type slotKey struct {
+		weekday time.Weekday
+		hour    int
+	}
+	type slotStats struct {
+		posts              int
+		relativeEngagement float64
+	}
+	statsBySlot := map[slotKey]*slotStats{}
+	for _, postMetrics := range metrics {
+		publishedAt := postMetrics.PublishedAt.In(location)
+		key := slotKey{weekday: publishedAt.Weekday(), hour: publishedAt.Hour()}
+		stats, ok := statsBySlot[key]
+		if !ok {
+			stats = &slotStats{}
+			statsBySlot[key] = stats
+		}
+		stats.posts++
+		stats.relativeEngagement += float64(postEngagement(postMetrics)) / meanEngagement
+	}
+
+	now := time.Now()
+	for key, stats := range statsBySlot {
+		result.Slots = append(result.Slots, RecommendedSlot{
+			Weekday:      key.weekday,
+			Hour:         key.hour,
+			Posts:        stats.posts,
+			Score:        (stats.relativeEngagement + recommendationPriorPosts) / float64(stats.posts+recommendationPriorPosts),
+			Confidence:   float64(stats.posts) / float64(stats.posts+recommendationPriorPosts),
+			NextStartsAt: nextSlotStart(now.In(location), key.weekday, key.hour),
+		})
+	}
+	sort.Slice(result.Slots, func(i, j int) bool {
+		a, b := result.Slots[i], result.Slots[j]
+		switch {
+		case a.Score != b.Score:
+			return a.Score > b.Score
+		case a.Confidence != b.Confidence:
+			return a.Confidence > b.Confidence
+		case a.Weekday != b.Weekday:
+			return a.Weekday < b.Weekday
+		default:
+			return a.Hour < b.Hour
+		}
+	})
+	if limit > 0 && len(result.Slots) > limit {
+		result.Slots = result.Slots[:limit]
+	}
+
+	return result, nil
+}
+
+// nextSlotStart ближайшее после now начало окна в часовом поясе now
+func nextSlotStart(now time.Time, weekday time.Weekday, hour int) time.Time {
+	days := (int(weekday) - int(now.Weekday()) + 7) % 7
+	start := time.Date(now.Year(), now.Month(), now.Day()+days, hour, 0, 0, 0, now.Location())
+	if !start.After(now) {
+		start = start.AddDate(0, 0, 7)
+	}
+	return start
+}
+
+func postEngagement(postMetrics model.PublishedPostMetrics) int {
+	return postMetrics.Likes + postMetrics.Reposts + postMetrics.Comments
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"autoposting/internal/domain/model"
+	"autoposting/internal/infrastructure/postgres"
+	"autoposting/internal/infrastructure/social_network_client"
+	ewrap "autoposting/pkg/err-wrapper"
+	textdiff "autoposting/pkg/text-diff"
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// ReconcileResult итог сверки, Failed - посты, которые не удалось проверить
+type ReconcileResult struct {
+	Checked  int
+	Modified int
+	Deleted  int
+	Failed   int
+}
+
+// ReconcilePosts сверяет посты, опубликованные после publishedAfter, с соц сетями и отмечает
+// удаленные и измененные в соц сети. Удаленные посты больше не проверяются
+func (phs *PostsHistoryService) ReconcilePosts(
+	ctx context.Context,
+	publishedAfter time.Time,
+) (*ReconcileResult, error) {
+	posts, err := phs.socialNetworkService.postsRepository.FindPosts(ctx, postgres.FindPostsQuery{
+		PublishedAfter: publishedAfter,
+		RemoteStateAnyOf: []model.PostRemoteState{
+			model.PostRemoteStatePublished,
+			model.PostRemoteStateModified,
+		},
+	})
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find posts to reconcile: %w", err)
+	}
+
+	targets, err := phs.socialNetworkService.getRemotePostsTargets(ctx, posts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReconcileResult{}
+	var reconciledPosts []model.Post
+	now := time.Now()
+	for _, target := range targets {
+		client := phs.socialNetworkService.getClient(target.account.SocialNetwork)
+		fetcher, ok := client.(social_network_client.RemotePostFetcher)
+		if !ok {
+			continue
+		}
+
+		states, err := fetcher.GetRemotePosts(target.account.Credentials, target.accessToken, target.remotePosts)
+		if err != nil {
+			phs.logger.Error(
+				"failed to get remote posts",
+				slog.String("socialNetwork", string(target.account.SocialNetwork)),
+				slog.Any("err", err),
+			)
+			result.Failed += len(target.remotePosts)
+			continue
+		}
+
+		for _, state := range states {
+			post, ok := target.postsByRemotePost[state.Post]
+			if !ok {
+				continue
+			}
+			if state.Err != nil {
+				phs.logger.Warn(
+					"failed to get remote post",
+					slog.String("socialNetwork", string(target.account.SocialNetwork)),
+					slog.Int64("post", post.ID),
+					slog.Any("err", state.Err),
+				)
+				result.Failed++
+				continue
+			}
+
+			applyRemotePostState(client, post, state, now)
+			result.Checked++
+			switch post.RemoteState {
+			case model.PostRemoteStateModified:
+				result.Modified++
+			case model.PostRemoteStateDeleted:
+				result.Deleted++
+			}
+			reconciledPosts = append(reconciledPosts, *post)
+		}
+	}
+
+	if err := phs.socialNetworkService.postsRepository.UpdatePostsRemoteState(ctx, reconciledPosts); err != nil {
+		return result, ewrap.Errorf("failed to save reconciled posts: %w", err)
+	}
+
+	return result, nil
+}
+
+// applyRemotePostState текст сравнивается после той же нормализации и сборки, что и при публикации
+func applyRemotePostState(
+	client social_network_client.SocialNetworkClient,
+	post *model.Post,
+	state social_network_client.RemotePostState,
+	reconciledAt time.Time,
+) {
+	post.ReconciledAt = reconciledAt
+	post.RemoteText = ""
+	post.RemoteDiff = ""
+
+	if state.Deleted {
+		post.RemoteState = model.PostRemoteStateDeleted
+		return
+	}
+
+	publishedText := ""
+	if post.PostData != nil {
+		publishedText = post.PostData.Text
+		if composer, ok := client.(social_network_client.TextComposer); ok {
+			publishedText = composer.ComposeText(toClientPost(post.PostData))
+		}
+	}
+	publishedText = normalizePostText(publishedText)
+	remoteText := normalizePostText(state.Text)
+	if remoteText == publishedText {
+		post.RemoteState = model.PostRemoteStatePublished
+		return
+	}
+
+	post.RemoteState = model.PostRemoteStateModified
+	post.RemoteText = remoteText
+	post.RemoteDiff = textdiff.Lines(publishedText, remoteText)
+}
+
+// normalizePostText соц сети по-разному хранят переводы строк и пробелы по краям текста
+func normalizePostText(text string) string {
+	return strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"autoposting/internal/domain/model"
+	"autoposting/internal/infrastructure/social_network_client"
+	"testing"
+	"time"
+)
+
+func TestApplyRemotePostState(t *testing.T) {
+	tests := []struct {
+		name      string
+		published string
+		state     social_network_client.RemotePostState
+		want      model.PostRemoteState
+	}{
+		{name: "same text", published: "Привет", state: social_network_client.RemotePostState{Text: "Привет"}, want: model.PostRemoteStatePublished},
+		{name: "published with CRLF", published: "a\r\nb", state: social_network_client.RemotePostState{Text: "a\nb"}, want: model.PostRemoteStatePublished},
+		{name: "remote with CRLF", published: "a\nb", state: social_network_client.RemotePostState{Text: "a\r\nb"}, want: model.PostRemoteStatePublished},
+		{name: "published with spaces", published: " text\n", state: social_network_client.RemotePostState{Text: "text"}, want: model.PostRemoteStatePublished},
+		{name: "edited", published: "text", state: social_network_client.RemotePostState{Text: "new text"}, want: model.PostRemoteStateModified},
+		{name: "deleted", published: "text", state: social_network_client.RemotePostState{Deleted: true}, want: model.PostRemoteStateDeleted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := &model.Post{PostData: &model.PostData{Text: tt.published}}
+
+			applyRemotePostState(nil, post, tt.state, time.Now())
+			if post.RemoteState != tt.want {
+				t.Fatalf("got state %s, want %s (diff %q)", post.RemoteState, tt.want, post.RemoteDiff)
+			}
+			if tt.want != model.PostRemoteStateModified && post.RemoteDiff != "" {
+				t.Fatalf("got diff %q, want none", post.RemoteDiff)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"autoposting/internal/domain/model"
+	"autoposting/internal/domain/repository"
+	"autoposting/internal/infrastructure/postgres"
+	"autoposting/internal/infrastructure/social_network_client"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"log/slog"
+	"time"
+)
+
+// ScheduledPostsService отложенная публикация постов
+type ScheduledPostsService struct {
+	logger                   *slog.Logger
+	socialNetworkService     *SocialNetworkService
+	analyticsService         *AnalyticsService
+	scheduledPostsRepository repository.ScheduledPostsRepository
+}
+
+func NewScheduledPostsService(
+	logger *slog.Logger,
+	socialNetworkService *SocialNetworkService,
+	analyticsService *AnalyticsService,
+	scheduledPostsRepository repository.ScheduledPostsRepository,
+) *ScheduledPostsService {
+	return &ScheduledPostsService{
+		logger:                   logger,
+		socialNetworkService:     socialNetworkService,
+		analyticsService:         analyticsService,
+		scheduledPostsRepository: scheduledPostsRepository,
+	}
+}
+
+const (
+	// scheduledPostAutoSlots auto пост сдвигается к ближайшему из стольких лучших окон страницы
+	scheduledPostAutoSlots = 3
+	// scheduledPostFinishTimeout итог публикации сохраняется и после отмены контекста задачи
+	scheduledPostFinishTimeout = 10 * time.Second
+)
+
+// SchedulePost ставит пост в очередь публикации, по одной публикации на страницу.
+// С auto время публикации сдвигается к ближайшему после publishAt из лучших окон страницы,
+// которые лучше средней вовлеченности. Без таких окон пост выходит в publishAt
+func (sps *ScheduledPostsService) SchedulePost(
+	ctx context.Context,
+	pagesIDs []int,
+	post social_network_client.Post,
+	publishAt time.Time,
+	auto bool,
+) ([]model.ScheduledPost, error) {
+	targets, err := sps.socialNetworkService.getPublishTargets(ctx, pagesIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	post = preparePost(post)
+	if err := sps.socialNetworkService.validatePost(targets, post); err != nil {
+		return nil, err
+	}
+
+	createdAt := time.Now()
+	var scheduledPosts []model.ScheduledPost
+	for _, target := range targets {
+		for _, page := range target.pages {
+			scheduledPost := model.ScheduledPost{
+				Page:        page.ID,
+				PostData:    toModelPostData(post),
+				RequestedAt: publishAt,
+				PublishAt:   publishAt,
+				Status:      model.ScheduledPostStatusPending,
+				CreatedAt:   createdAt,
+			}
+			if auto {
+				slotStart, err := sps.getAutoSlotStart(ctx, page.ID, publishAt)
+				if err != nil {
+					return nil, err
+				}
+				if !slotStart.IsZero() {
+					scheduledPost.PublishAt = slotStart
+					scheduledPost.Auto = true
+				}
+			}
+			scheduledPosts = append(scheduledPosts, scheduledPost)
+		}
+	}
+
+	if err := sps.scheduledPostsRepository.CreateScheduledPosts(ctx, scheduledPosts); err != nil {
+		return nil, ewrap.Errorf("failed to save scheduled posts: %w", err)
+	}
+	return scheduledPosts, nil
+}
+
+func (sps *ScheduledPostsService) GetScheduledPosts(
+	ctx context.Context,
+	query postgres.FindScheduledPostsQuery,
+) ([]model.ScheduledPost, error) {
+	scheduledPosts, err := sps.scheduledPostsRepository.FindScheduledPosts(ctx, query)
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find scheduled posts: %w", err)
+	}
+	return scheduledPosts, nil
+}
+
+// RunDueScheduledPost публикует самый ранний пост, время которого наступило, nil - таких постов нет.
+// Опубликованный пост остается PUBLISHED, даже если его не удалось сохранить в posts
+func (sps *ScheduledPostsService) RunDueScheduledPost(ctx context.Context) (*model.ScheduledPost, error) {
+	scheduledPost, err := sps.scheduledPostsRepository.TakeDuePost(ctx, time.Now())
+	if err != nil {
+		return nil, ewrap.Errorf("failed to take due scheduled post: %w", err)
+	}
+	if scheduledPost == nil {
+		return nil, nil
+	}
+
+	results, err := sps.socialNetworkService.CreatePost(
+		ctx,
+		[]int{scheduledPost.Page},
+		toClientPost(scheduledPost.PostData),
+	)
+	switch {
+	case len(results) == 1 && results[0].Err == nil:
+		scheduledPost.Status = model.ScheduledPostStatusPublished
+		scheduledPost.RemotePostID = results[0].PostID
+		scheduledPost.PublishedAt = time.Now()
+		if err != nil {
+			scheduledPost.Error = err.Error()
+		}
+	case len(results) == 1:
+		scheduledPost.Status = model.ScheduledPostStatusFailed
+		scheduledPost.Error = results[0].Err.Error()
+	default:
+		scheduledPost.Status = model.ScheduledPostStatusFailed
+		scheduledPost.Error = err.Error()
+	}
+	if scheduledPost.Status == model.ScheduledPostStatusFailed {
+		sps.logger.Error(
+			"failed to publish scheduled post",
+			slog.Int64("scheduledPost", scheduledPost.ID),
+			slog.Int("page", scheduledPost.Page),
+			slog.String("err", scheduledPost.Error),
+		)
+	}
+
+	finishCtx, cancel := context.WithTimeout(context.Background(), scheduledPostFinishTimeout)
+	defer cancel()
+	if err := sps.scheduledPostsRepository.FinishScheduledPost(finishCtx, scheduledPost); err != nil {
+		return scheduledPost, ewrap.Errorf("failed to save scheduled post %d result: %w", scheduledPost.ID, err)
+	}
+
+	return scheduledPost, nil
+}
+
+// FailInterruptedScheduledPosts отмечает неудачными посты, публикацию которых прервала аварийная остановка.
+// Повторно они не публикуются, чтобы не выйти в соц сети дважды
+func (sps *ScheduledPostsService) FailInterruptedScheduledPosts(ctx context.Context) (int, error) {
+	failed, err := sps.scheduledPostsRepository.FailPublishingPosts(ctx, "publishing was interrupted by shutdown")
+	if err != nil {
+		return 0, ewrap.Errorf("failed to fail interrupted scheduled posts: %w", err)
+	}
+	return failed, nil
+}
+
+// getAutoSlotStart ближайшее после notBefore начало одного из лучших окон страницы, нулевое - таких окон нет
+func (sps *ScheduledPostsService) getAutoSlotStart(
+	ctx context.Context,
+	pageID int,
+	notBefore time.Time,
+) (time.Time, error) {
+	recommendations, err := sps.analyticsService.RecommendSlots(ctx, pageID, scheduledPostAutoSlots)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var start time.Time
+	for _, slot := range recommendations.Slots {
+		if slot.Score <= 1 {
+			continue
+		}
+		slotStart := nextSlotStart(notBefore.In(recommendations.Location), slot.Weekday, slot.Hour)
+		if start.IsZero() || slotStart.Before(start) {
+			start = slotStart
+		}
+	}
+	return start, nil
+}
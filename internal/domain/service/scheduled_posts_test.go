@@ -0,0 +1,71 @@
+package service
+
+import (
+	"autoposting/internal/domain/model"
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestGetAutoSlotStart(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 19.10.2026 - понедельник
+	monday := func(hour int) time.Time { return time.Date(2026, 10, 19, hour, 0, 0, 0, moscow) }
+	wednesday := func(hour int) time.Time { return time.Date(2026, 10, 21, hour, 0, 0, 0, moscow) }
+	engaging := []model.PublishedPostMetrics{
+		{PublishedAt: monday(10).AddDate(0, 0, -7), Likes: 100},
+		{PublishedAt: monday(10).AddDate(0, 0, -14), Likes: 100},
+		{PublishedAt: monday(10).AddDate(0, 0, -21), Likes: 100},
+		{PublishedAt: wednesday(18).AddDate(0, 0, -7), Likes: 10},
+		{PublishedAt: wednesday(18).AddDate(0, 0, -14), Likes: 10},
+		{PublishedAt: wednesday(18).AddDate(0, 0, -21), Likes: 10},
+	}
+
+	tests := []struct {
+		name      string
+		metrics   []model.PublishedPostMetrics
+		notBefore time.Time
+		want      time.Time
+	}{
+		{name: "later the same day", metrics: engaging, notBefore: monday(9), want: monday(10)},
+		{name: "next week", metrics: engaging, notBefore: monday(12), want: monday(10).AddDate(0, 0, 7)},
+		{name: "below average slot skipped", metrics: engaging, notBefore: wednesday(12), want: monday(10).AddDate(0, 0, 7)},
+		{
+			name:      "average slot only",
+			metrics:   []model.PublishedPostMetrics{{PublishedAt: monday(10).AddDate(0, 0, -7), Likes: 5}},
+			notBefore: monday(9),
+		},
+		{
+			name:      "no engagement",
+			metrics:   []model.PublishedPostMetrics{{PublishedAt: monday(10).AddDate(0, 0, -7)}},
+			notBefore: monday(9),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sns := &SocialNetworkService{
+				logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+				socialNetworkPagesRepository: &fakePagesRepository{
+					pages: []model.SocialNetworkPage{{ID: 1, AccountID: 1, Project: "test", PageID: "100"}},
+				},
+				projectSettingsRepository: &fakeProjectSettingsRepository{timezone: "Europe/Moscow"},
+			}
+			as := NewAnalyticsService(sns.logger, sns, &fakePostMetricsRepository{metrics: tt.metrics}, nil, nil)
+			sps := NewScheduledPostsService(sns.logger, sns, as, nil)
+
+			got, err := sps.getAutoSlotStart(context.Background(), 1, tt.notBefore)
+			if err != nil {
+				t.Fatalf("getAutoSlotStart: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Fatalf("got slot start %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
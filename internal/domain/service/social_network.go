@@ -8,6 +8,7 @@ import (
 	"autoposting/internal/presentation/graphql/gen"
 	ewrap "autoposting/pkg/err-wrapper"
 	"context"
+	"fmt"
 	"log/slog"
 	"time"
 )
@@ -16,6 +17,10 @@ type SocialNetworkService struct {
 	logger                          *slog.Logger
 	socialNetworkAccountsRepository repository.SocialNetworkAccountsRepository
 	socialNetworkPagesRepository    repository.SocialNetworkPagesRepository
+	postsRepository                 repository.PostsRepository
+	socialNetworkEventsRepository   repository.SocialNetworkEventsRepository
+	projectSettingsRepository       repository.ProjectSettingsRepository
+	webhookDeliveriesRepository     repository.WebhookDeliveriesRepository
 	socialNetworkClients            map[model.SocialNetworkName]social_network_client.SocialNetworkClient
 }
 
@@ -23,16 +28,42 @@ func NewService(
 	logger *slog.Logger,
 	socialNetworkAccountsRepository repository.SocialNetworkAccountsRepository,
 	socialNetworkPagesRepository repository.SocialNetworkPagesRepository,
+	postsRepository repository.PostsRepository,
+	socialNetworkEventsRepository repository.SocialNetworkEventsRepository,
+	projectSettingsRepository repository.ProjectSettingsRepository,
+	webhookDeliveriesRepository repository.WebhookDeliveriesRepository,
 	socialNetworkClients map[model.SocialNetworkName]social_network_client.SocialNetworkClient,
 ) *SocialNetworkService {
 	return &SocialNetworkService{
 		logger:                          logger,
 		socialNetworkAccountsRepository: socialNetworkAccountsRepository,
 		socialNetworkPagesRepository:    socialNetworkPagesRepository,
+		postsRepository:                 postsRepository,
+		socialNetworkEventsRepository:   socialNetworkEventsRepository,
+		projectSettingsRepository:       projectSettingsRepository,
+		webhookDeliveriesRepository:     webhookDeliveriesRepository,
 		socialNetworkClients:            socialNetworkClients,
 	}
 }
 
+// getClient клиент соц сети, nil если он не создан
+func (sns *SocialNetworkService) getClient(
+	socialNetwork model.SocialNetworkName,
+) social_network_client.SocialNetworkClient {
+	return sns.socialNetworkClients[socialNetwork]
+}
+
+// GetSocialNetworks зарегистрированные соц сети, для которых создан клиент
+func (sns *SocialNetworkService) GetSocialNetworks() []social_network_client.Network {
+	var networks []social_network_client.Network
+	for _, network := range social_network_client.Networks() {
+		if _, ok := sns.socialNetworkClients[model.SocialNetworkName(network.Name)]; ok {
+			networks = append(networks, network)
+		}
+	}
+	return networks
+}
+
 func (sns *SocialNetworkService) CreateSocialNetworkAccount(
 	ctx context.Context,
 	socialNetwork string,
@@ -48,6 +79,15 @@ func (sns *SocialNetworkService) CreateSocialNetworkAccount(
 		SocialNetwork: socialNetworkName,
 		Credentials:   credential,
 	}
+	if err := sns.linkAuthAccount(ctx, socialNetworkAccount); err != nil {
+		return err
+	}
+	if registrar, ok := sns.socialNetworkClients[socialNetworkName].(social_network_client.AppRegistrar); ok {
+		socialNetworkAccount.Credentials, err = registrar.RegisterApp(ctx, socialNetworkAccount.Credentials)
+		if err != nil {
+			return domain.NewInternalError(fmt.Sprintf("failed to register %s app: %s", socialNetworkName, err))
+		}
+	}
 
 	return sns.socialNetworkAccountsRepository.CreateAccount(ctx, socialNetworkAccount)
 }
@@ -92,6 +132,10 @@ func (sns *SocialNetworkService) GetAuthURL(
 	socialNetworkName model.SocialNetworkName,
 	credentials string,
 ) (string, error) {
+	if !usesOAuth(socialNetworkName) {
+		return "", newNoOAuthError(socialNetworkName)
+	}
+
 	authUrl, err := sns.socialNetworkClients[socialNetworkName].GetAuthURL(credentials)
 	if err != nil {
 		return "", domain.NewInternalError(err.Error())
@@ -104,13 +148,17 @@ func (sns *SocialNetworkService) GetAccessToken(
 	ctx context.Context,
 	socialNetworkAccount *model.SocialNetworkAccount,
 	params map[string][]string,
-) (string, error) {
+) (*social_network_client.AccessToken, error) {
+	if !usesOAuth(socialNetworkAccount.SocialNetwork) {
+		return nil, newNoOAuthError(socialNetworkAccount.SocialNetwork)
+	}
+
 	token, err := sns.socialNetworkClients[socialNetworkAccount.SocialNetwork].GetAccessToken(
 		socialNetworkAccount.Credentials,
 		params,
 	)
 	if err != nil {
-		return "", ewrap.Errorf(
+		return nil, ewrap.Errorf(
 			"failed to get access token from social network %s: %w",
 			socialNetworkAccount.SocialNetwork,
 			err,
@@ -122,13 +170,10 @@ func (sns *SocialNetworkService) GetAccessToken(
 
 func (sns *SocialNetworkService) SaveAccessToken(
 	ctx context.Context,
-	token string,
+	token *social_network_client.AccessToken,
 	socialNetworkAccount *model.SocialNetworkAccount,
 ) error {
-	socialNetworkAccount.AccessToken = &model.AccessToken{
-		Token:     token,
-		ExpiresIn: getTokenExpires(&socialNetworkAccount.SocialNetwork),
-	}
+	socialNetworkAccount.AccessToken = toModelAccessToken(token)
 
 	if _, err := sns.socialNetworkAccountsRepository.UpdateAccount(ctx, socialNetworkAccount); err != nil {
 		return ewrap.Errorf(
@@ -146,7 +191,7 @@ func (sns *SocialNetworkService) GetPagesFromSocialNetwork(
 ) ([]social_network_client.SocialNetworkPage, error) {
 	pages, err := sns.socialNetworkClients[socialNetworkAccount.SocialNetwork].GetAccountPages(
 		socialNetworkAccount.Credentials,
-		socialNetworkAccount.AccessToken.Token,
+		accountAccessToken(socialNetworkAccount),
 	)
 
 	if err != nil {
@@ -160,28 +205,102 @@ func (sns *SocialNetworkService) GetPagesFromSocialNetwork(
 	return pages, nil
 }
 
-func (sns *SocialNetworkService) CreatePost(network, project, post string) error {
+func (sns *SocialNetworkService) GetPagesChunkFromSocialNetwork(
+	socialNetworkAccount *model.SocialNetworkAccount,
+	cursor social_network_client.PagesCursor,
+) (*social_network_client.SocialNetworkPagesChunk, error) {
+	chunk, err := sns.socialNetworkClients[socialNetworkAccount.SocialNetwork].GetAccountPagesChunk(
+		socialNetworkAccount.Credentials,
+		accountAccessToken(socialNetworkAccount),
+		cursor,
+	)
+
+	if err != nil {
+		return nil, ewrap.Errorf(
+			"failed to get pages chunk from social network %s: %w",
+			socialNetworkAccount.SocialNetwork,
+			err,
+		)
+	}
+
+	return chunk, nil
+}
+
+// linkAuthAccount привязывает аккаунт соц сети без своего OAuth к аккаунту AuthNetwork,
+// собственные credentials такому аккаунту не нужны
+func (sns *SocialNetworkService) linkAuthAccount(
+	ctx context.Context,
+	socialNetworkAccount *model.SocialNetworkAccount,
+) error {
+	network, _ := social_network_client.LookupNetwork(string(socialNetworkAccount.SocialNetwork))
+	if network.AuthNetwork == "" {
+		return nil
+	}
+
+	authAccount, err := sns.socialNetworkAccountsRepository.FindBySocialNetwork(
+		ctx,
+		model.SocialNetworkName(network.AuthNetwork),
+	)
+	if err != nil {
+		if domain.IsNotFoundError(err) {
+			return domain.NewValidationError(
+				fmt.Sprintf(
+					"social network %s works through %s account, create it first",
+					socialNetworkAccount.SocialNetwork,
+					network.AuthNetwork,
+				),
+				"socialNetwork",
+				"authAccount",
+			)
+		}
+		return err
+	}
+
+	socialNetworkAccount.AuthAccount = &authAccount.ID
+	socialNetworkAccount.Credentials = "{}"
 	return nil
 }
 
+func newNoOAuthError(socialNetworkName model.SocialNetworkName) error {
+	return domain.NewValidationError(
+		fmt.Sprintf("social network %s does not use OAuth, account is authorized by credentials", socialNetworkName),
+		"socialNetwork",
+		"oauth",
+	)
+}
+
+// accountAccessToken пустой для соц сетей без OAuth, их клиенты авторизуются по credentials
+func accountAccessToken(socialNetworkAccount *model.SocialNetworkAccount) string {
+	if socialNetworkAccount.AccessToken == nil {
+		return ""
+	}
+	return socialNetworkAccount.AccessToken.Token
+}
+
+// getSocialNetworkName имя соц сети, зарегистрированной в social_network_client
 func getSocialNetworkName(socialNetwork string) (model.SocialNetworkName, error) {
-	socialNetworkName := model.SocialNetworkName(socialNetwork)
-	if err := socialNetworkName.Validate(); err != nil {
+	if _, ok := social_network_client.LookupNetwork(socialNetwork); !ok {
 		return "", domain.NewValidationError(
-			err.Error(),
+			fmt.Sprintf("social network %s is not valid", socialNetwork),
 			"socialNetworkName",
 			"empty",
 		)
 	}
-	return socialNetworkName, nil
+	return model.SocialNetworkName(socialNetwork), nil
 }
 
-func getTokenExpires(socialNetworkName *model.SocialNetworkName) string {
-	switch *socialNetworkName {
-	case model.FB:
-		// У FACEBOOK longLiveToken живет 60 дней
-		return time.Now().Add(time.Hour * 24 * 60).Format(time.RFC3339)
-	default:
-		return ""
+// usesOAuth false для соц сетей, которые подключаются только по credentials, например вебхуки
+func usesOAuth(socialNetworkName model.SocialNetworkName) bool {
+	network, ok := social_network_client.LookupNetwork(string(socialNetworkName))
+	return ok && network.OAuth != nil
+}
+
+func toModelAccessToken(token *social_network_client.AccessToken) *model.AccessToken {
+	accessToken := &model.AccessToken{
+		Token: token.Token,
+	}
+	if !token.ExpiresAt.IsZero() {
+		accessToken.ExpiresIn = token.ExpiresAt.Format(time.RFC3339)
 	}
+	return accessToken
 }
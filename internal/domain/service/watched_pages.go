@@ -0,0 +1,470 @@
+package service
+
+import (
+	"autoposting/internal/domain"
+	"autoposting/internal/domain/model"
+	"autoposting/internal/infrastructure/postgres"
+	"autoposting/internal/infrastructure/social_network_client"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// watchedPagePostsWindow при каждом сборе перечитываются посты отслеживаемых страниц за этот срок
+const watchedPagePostsWindow = 7 * 24 * time.Hour
+
+// CollectWatchedPagesResult итог сбора, Failed - страницы, данные которых собраны не полностью
+type CollectWatchedPagesResult struct {
+	Collected int
+	Failed    int
+}
+
+// PageComparison показатели страницы проекта или отслеживаемой страницы за период.
+// EngagementRate - средняя вовлеченность поста в процентах от подписчиков на конец периода
+type PageComparison struct {
+	Watched        bool
+	ID             int
+	SocialNetwork  model.SocialNetworkName
+	PageID         string
+	Name           string
+	StartFollowers int
+	EndFollowers   int
+	FollowersDelta int
+	Posts          int
+	Engagement     int
+	EngagementRate float64
+}
+
+// watchedPageFetcher возможности клиента, без которых страницу не отследить
+type watchedPageFetcher interface {
+	social_network_client.PageAudienceFetcher
+	social_network_client.PageHistoryFetcher
+	social_network_client.PostMetricsFetcher
+}
+
+func (as *AnalyticsService) AddWatchedPage(
+	ctx context.Context,
+	project string,
+	socialNetwork string,
+	pageID string,
+	name string,
+) (*model.WatchedPage, error) {
+	socialNetworkName, err := getSocialNetworkName(socialNetwork)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := as.socialNetworkService.getClient(socialNetworkName).(watchedPageFetcher); !ok {
+		return nil, domain.NewValidationError(
+			fmt.Sprintf("social network %s does not support watching pages", socialNetworkName),
+			"socialNetwork",
+			"watchedPages",
+		)
+	}
+
+	watchedPage := &model.WatchedPage{
+		Project:       project,
+		SocialNetwork: socialNetworkName,
+		PageID:        pageID,
+		Name:          name,
+		CreatedAt:     time.Now(),
+	}
+	if err := as.watchedPagesRepository.CreateWatchedPage(ctx, watchedPage); err != nil {
+		return nil, err
+	}
+	return watchedPage, nil
+}
+
+func (as *AnalyticsService) RemoveWatchedPage(ctx context.Context, id int) error {
+	return as.watchedPagesRepository.DeleteWatchedPage(ctx, id)
+}
+
+// CollectWatchedPages сохраняет снимок подписчиков отслеживаемых страниц и счетчики их свежих постов.
+// Публичные страницы читаются токеном любого аккаунта той же соц сети
+func (as *AnalyticsService) CollectWatchedPages(ctx context.Context) (*CollectWatchedPagesResult, error) {
+	watchedPages, err := as.watchedPagesRepository.FindWatchedPages(ctx, postgres.FindWatchedPagesQuery{})
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find watched pages: %w", err)
+	}
+
+	var socialNetworks []model.SocialNetworkName
+	pagesBySocialNetwork := map[model.SocialNetworkName][]model.WatchedPage{}
+	for _, watchedPage := range watchedPages {
+		if _, ok := pagesBySocialNetwork[watchedPage.SocialNetwork]; !ok {
+			socialNetworks = append(socialNetworks, watchedPage.SocialNetwork)
+		}
+		pagesBySocialNetwork[watchedPage.SocialNetwork] = append(pagesBySocialNetwork[watchedPage.SocialNetwork], watchedPage)
+	}
+
+	result := &CollectWatchedPagesResult{}
+	for _, socialNetwork := range socialNetworks {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		pages := pagesBySocialNetwork[socialNetwork]
+		collected, err := as.collectWatchedPages(ctx, socialNetwork, pages)
+		if err != nil {
+			as.logger.Error(
+				"failed to collect watched pages",
+				slog.String("socialNetwork", string(socialNetwork)),
+				slog.Any("err", err),
+			)
+		}
+		result.Collected += collected
+		result.Failed += len(pages) - collected
+	}
+
+	return result, nil
+}
+
+// collectWatchedPages страницы одной соц сети, возвращает число полностью собранных страниц
+func (as *AnalyticsService) collectWatchedPages(
+	ctx context.Context,
+	socialNetwork model.SocialNetworkName,
+	watchedPages []model.WatchedPage,
+) (int, error) {
+	fetcher, ok := as.socialNetworkService.getClient(socialNetwork).(watchedPageFetcher)
+	if !ok {
+		return 0, domain.NewInternalError(fmt.Sprintf("social network %s does not support watching pages", socialNetwork))
+	}
+	account, err := as.getWatchingAccount(ctx, socialNetwork)
+	if err != nil {
+		return 0, err
+	}
+	accessToken := ""
+	if account.AccessToken != nil {
+		accessToken = account.AccessToken.Token
+	}
+
+	remotePagesIDs := make([]string, 0, len(watchedPages))
+	for _, watchedPage := range watchedPages {
+		remotePagesIDs = append(remotePagesIDs, watchedPage.PageID)
+	}
+	audienceResults, err := fetcher.GetPagesAudience(account.Credentials, accessToken, remotePagesIDs)
+	if err != nil {
+		return 0, ewrap.Errorf("failed to get %s watched pages audience: %w", socialNetwork, err)
+	}
+	audienceByPage := make(map[string]social_network_client.PageAudienceResult, len(audienceResults))
+	for _, audienceResult := range audienceResults {
+		audienceByPage[audienceResult.PageID] = audienceResult
+	}
+
+	collected := 0
+	collectedAt := time.Now()
+	var audience []model.WatchedPageAudience
+	for _, watchedPage := range watchedPages {
+		complete := true
+		if audienceResult, ok := audienceByPage[watchedPage.PageID]; ok && audienceResult.Err == nil {
+			audience = append(audience, model.WatchedPageAudience{
+				WatchedPage: watchedPage.ID,
+				CollectedAt: collectedAt,
+				Followers:   audienceResult.Followers,
+			})
+		} else {
+			complete = false
+			as.logger.Warn(
+				"failed to get watched page audience",
+				slog.Int("watchedPage", watchedPage.ID),
+				slog.Any("err", audienceResult.Err),
+			)
+		}
+
+		posts, err := as.getWatchedPagePosts(fetcher, account.Credentials, accessToken, watchedPage, collectedAt)
+		if err != nil {
+			complete = false
+			as.logger.Warn(
+				"failed to get watched page posts",
+				slog.Int("watchedPage", watchedPage.ID),
+				slog.Any("err", err),
+			)
+		}
+		if err := as.watchedPagesRepository.UpsertWatchedPagePosts(ctx, posts); err != nil {
+			return collected, ewrap.Errorf("failed to save watched page %d posts: %w", watchedPage.ID, err)
+		}
+
+		if complete {
+			collected++
+		}
+	}
+
+	if err := as.watchedPagesRepository.CreateWatchedPagesAudience(ctx, audience); err != nil {
+		return 0, ewrap.Errorf("failed to save %s watched pages audience: %w", socialNetwork, err)
+	}
+
+	return collected, nil
+}
+
+// getWatchedPagePosts посты за watchedPagePostsWindow со счетчиками, посты без счетчиков пропускаются
+func (as *AnalyticsService) getWatchedPagePosts(
+	fetcher watchedPageFetcher,
+	credentials string,
+	accessToken string,
+	watchedPage model.WatchedPage,
+	collectedAt time.Time,
+) ([]model.WatchedPagePost, error) {
+	var (
+		remotePosts []social_network_client.RemotePost
+		cursor      social_network_client.PagesCursor
+	)
+	publishedAtByPost := map[string]time.Time{}
+	for {
+		chunk, err := fetcher.GetPageHistoryChunk(
+			credentials,
+			accessToken,
+			watchedPage.PageID,
+			collectedAt.Add(-watchedPagePostsWindow),
+			cursor,
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, historyPost := range chunk.Posts {
+			remotePosts = append(remotePosts, social_network_client.RemotePost{
+				PageID: watchedPage.PageID,
+				PostID: historyPost.PostID,
+			})
+			publishedAtByPost[historyPost.PostID] = historyPost.PublishedAt
+		}
+		if chunk.NextCursor == "" {
+			break
+		}
+		cursor.Cursor = chunk.NextCursor
+	}
+	if len(remotePosts) == 0 {
+		return nil, nil
+	}
+
+	metricsResults, err := fetcher.GetPostsMetrics(credentials, accessToken, remotePosts)
+	if err != nil {
+		return nil, err
+	}
+	posts := make([]model.WatchedPagePost, 0, len(metricsResults))
+	for _, metricsResult := range metricsResults {
+		if metricsResult.Err != nil || metricsResult.Deleted {
+			continue
+		}
+		posts = append(posts, model.WatchedPagePost{
+			WatchedPage:  watchedPage.ID,
+			RemotePostID: metricsResult.Post.PostID,
+			PublishedAt:  publishedAtByPost[metricsResult.Post.PostID],
+			Likes:        metricsResult.Metrics.Likes,
+			Reposts:      metricsResult.Metrics.Reposts,
+			Comments:     metricsResult.Metrics.Comments,
+			Views:        metricsResult.Metrics.Views,
+			CollectedAt:  collectedAt,
+		})
+	}
+	return posts, nil
+}
+
+// getWatchingAccount предпочитается аккаунт с токеном
+func (as *AnalyticsService) getWatchingAccount(
+	ctx context.Context,
+	socialNetwork model.SocialNetworkName,
+) (*model.SocialNetworkAccount, error) {
+	accountsRepository := as.socialNetworkService.socialNetworkAccountsRepository
+	accounts, err := accountsRepository.FindAccounts(ctx, postgres.FindSocialNetworkAccountQuery{
+		SocialNetworkAnyOf: []model.SocialNetworkName{socialNetwork},
+	})
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find %s accounts: %w", socialNetwork, err)
+	}
+	if len(accounts) == 0 {
+		return nil, domain.NewNotFoundError(fmt.Sprintf("social network %s accounts not found", socialNetwork))
+	}
+	for i := range accounts {
+		if accounts[i].AccessToken != nil && accounts[i].AccessToken.Token != "" {
+			return &accounts[i], nil
+		}
+	}
+	return &accounts[0], nil
+}
+
+// ComparePages показатели страниц проекта и отслеживаемых им страниц за период [after, before).
+// Вовлеченность - сумма лайков, репостов и комментариев постов, опубликованных в периоде
+func (as *AnalyticsService) ComparePages(
+	ctx context.Context,
+	project string,
+	after time.Time,
+	before time.Time,
+) ([]PageComparison, error) {
+	ownComparisons, err := as.compareOwnPages(ctx, project, after, before)
+	if err != nil {
+		return nil, err
+	}
+	watchedComparisons, err := as.compareWatchedPages(ctx, project, after, before)
+	if err != nil {
+		return nil, err
+	}
+	return append(ownComparisons, watchedComparisons...), nil
+}
+
+func (as *AnalyticsService) compareOwnPages(
+	ctx context.Context,
+	project string,
+	after time.Time,
+	before time.Time,
+) ([]PageComparison, error) {
+	pages, err := as.socialNetworkService.socialNetworkPagesRepository.FindPages(ctx, postgres.FindSocialNetworkPageQuery{
+		ProjectAnyOf: []string{project},
+	})
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find project %s pages: %w", project, err)
+	}
+	if len(pages) == 0 {
+		return nil, nil
+	}
+	sort.Slice(pages, func(i, j int) bool {
+		return pages[i].ID < pages[j].ID
+	})
+
+	accountsIDs := make([]int, 0, len(pages))
+	for _, page := range pages {
+		accountsIDs = append(accountsIDs, page.AccountID)
+	}
+	accountsRepository := as.socialNetworkService.socialNetworkAccountsRepository
+	accounts, err := accountsRepository.FindAccounts(ctx, postgres.FindSocialNetworkAccountQuery{
+		IDAnyOf: accountsIDs,
+	})
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find accounts %v: %w", accountsIDs, err)
+	}
+	socialNetworkByAccount := make(map[int]model.SocialNetworkName, len(accounts))
+	for _, account := range accounts {
+		socialNetworkByAccount[account.ID] = account.SocialNetwork
+	}
+
+	audience, err := as.pageAudienceRepository.FindDailyAudience(ctx, postgres.FindPageAudienceQuery{
+		ProjectAnyOf:    []string{project},
+		CollectedAfter:  after,
+		CollectedBefore: before,
+	})
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find project %s pages audience: %w", project, err)
+	}
+	pointsByPage := map[int][]model.AudiencePoint{}
+	for _, snapshot := range audience {
+		pointsByPage[snapshot.Page] = append(pointsByPage[snapshot.Page], model.AudiencePoint{
+			Date:      audienceDay(snapshot.CollectedAt),
+			Followers: snapshot.Followers,
+		})
+	}
+
+	aggregates, err := as.postMetricsRepository.AggregateMetrics(ctx, postgres.AggregatePostMetricsQuery{
+		GroupBy:         model.PostMetricsGroupByPage,
+		ProjectAnyOf:    []string{project},
+		PublishedAfter:  after,
+		PublishedBefore: before,
+	})
+	if err != nil {
+		return nil, ewrap.Errorf("failed to aggregate project %s posts metrics: %w", project, err)
+	}
+	aggregatesByKey := make(map[string]model.PostMetricsAggregate, len(aggregates))
+	for _, aggregate := range aggregates {
+		aggregatesByKey[aggregate.Key] = aggregate
+	}
+
+	comparisons := make([]PageComparison, 0, len(pages))
+	for _, page := range pages {
+		comparison := PageComparison{
+			ID:            page.ID,
+			SocialNetwork: socialNetworkByAccount[page.AccountID],
+			PageID:        page.PageID,
+		}
+		if page.PageInfo != nil {
+			comparison.Name = page.PageInfo.Name
+		}
+		comparisons = append(comparisons, newPageComparison(
+			comparison,
+			pointsByPage[page.ID],
+			aggregatesByKey[strconv.Itoa(page.ID)],
+		))
+	}
+	return comparisons, nil
+}
+
+func (as *AnalyticsService) compareWatchedPages(
+	ctx context.Context,
+	project string,
+	after time.Time,
+	before time.Time,
+) ([]PageComparison, error) {
+	watchedPages, err := as.watchedPagesRepository.FindWatchedPages(ctx, postgres.FindWatchedPagesQuery{
+		ProjectAnyOf: []string{project},
+	})
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find project %s watched pages: %w", project, err)
+	}
+	if len(watchedPages) == 0 {
+		return nil, nil
+	}
+
+	watchedPagesIDs := make([]int, 0, len(watchedPages))
+	for _, watchedPage := range watchedPages {
+		watchedPagesIDs = append(watchedPagesIDs, watchedPage.ID)
+	}
+	statsQuery := postgres.FindWatchedPagesStatsQuery{
+		WatchedPagesIDAnyOf: watchedPagesIDs,
+		After:               after,
+		Before:              before,
+	}
+
+	audience, err := as.watchedPagesRepository.FindDailyWatchedPagesAudience(ctx, statsQuery)
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find project %s watched pages audience: %w", project, err)
+	}
+	pointsByPage := map[int][]model.AudiencePoint{}
+	for _, snapshot := range audience {
+		pointsByPage[snapshot.WatchedPage] = append(pointsByPage[snapshot.WatchedPage], model.AudiencePoint{
+			Date:      audienceDay(snapshot.CollectedAt),
+			Followers: snapshot.Followers,
+		})
+	}
+
+	aggregates, err := as.watchedPagesRepository.AggregateWatchedPagePosts(ctx, statsQuery)
+	if err != nil {
+		return nil, ewrap.Errorf("failed to aggregate project %s watched pages posts: %w", project, err)
+	}
+	aggregatesByKey := make(map[string]model.PostMetricsAggregate, len(aggregates))
+	for _, aggregate := range aggregates {
+		aggregatesByKey[aggregate.Key] = aggregate
+	}
+
+	comparisons := make([]PageComparison, 0, len(watchedPages))
+	for _, watchedPage := range watchedPages {
+		comparisons = append(comparisons, newPageComparison(
+			PageComparison{
+				Watched:       true,
+				ID:            watchedPage.ID,
+				SocialNetwork: watchedPage.SocialNetwork,
+				PageID:        watchedPage.PageID,
+				Name:          watchedPage.Name,
+			},
+			pointsByPage[watchedPage.ID],
+			aggregatesByKey[strconv.Itoa(watchedPage.ID)],
+		))
+	}
+	return comparisons, nil
+}
+
+func newPageComparison(
+	comparison PageComparison,
+	points []model.AudiencePoint,
+	aggregate model.PostMetricsAggregate,
+) PageComparison {
+	growth := newAudienceGrowth(0, "", points)
+	comparison.StartFollowers = growth.StartFollowers
+	comparison.EndFollowers = growth.EndFollowers
+	comparison.FollowersDelta = growth.Delta
+	comparison.Posts = aggregate.Posts
+	comparison.Engagement = aggregate.Likes + aggregate.Reposts + aggregate.Comments
+	if comparison.Posts > 0 && comparison.EndFollowers > 0 {
+		comparison.EngagementRate = float64(comparison.Engagement) / float64(comparison.Posts) /
+			float64(comparison.EndFollowers) * 100
+	}
+	return comparison
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"autoposting/internal/domain/model"
+	"autoposting/internal/infrastructure/social_network_client"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCollectWatchedPages(t *testing.T) {
+	publishedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	watchedPages := &fakeWatchedPagesRepository{
+		watchedPages: []model.WatchedPage{
+			{ID: 1, SocialNetwork: "VK", PageID: "300"},
+			{ID: 2, SocialNetwork: "VK", PageID: "400"},
+			// Клиента OK нет, страница не собирается
+			{ID: 3, SocialNetwork: "OK", PageID: "500"},
+		},
+	}
+	sns := newPagesTestService(&fakeAnalyticsClient{
+		audience: map[string]social_network_client.PageAudienceResult{
+			"300": {Followers: 1000},
+			"400": {Err: errors.New("group is private")},
+		},
+		history: map[string][]social_network_client.PageHistoryChunk{
+			"300": {{Posts: []social_network_client.PageHistoryPost{
+				{PostID: "31", PublishedAt: publishedAt},
+				{PostID: "32", PublishedAt: publishedAt},
+			}}},
+			"400": {{Posts: []social_network_client.PageHistoryPost{{PostID: "41", PublishedAt: publishedAt}}}},
+		},
+		metrics: map[string]social_network_client.PostMetricsResult{
+			"31": {Metrics: social_network_client.PostMetrics{Likes: 5}},
+			"32": {Deleted: true},
+			"41": {Metrics: social_network_client.PostMetrics{Likes: 2}},
+		},
+	})
+	as := NewAnalyticsService(sns.logger, sns, nil, nil, watchedPages)
+
+	result, err := as.CollectWatchedPages(context.Background())
+	if err != nil {
+		t.Fatalf("CollectWatchedPages: %v", err)
+	}
+	if result.Collected != 1 || result.Failed != 2 {
+		t.Fatalf("got collected %d, failed %d, want 1, 2", result.Collected, result.Failed)
+	}
+	audience := watchedPages.audience
+	if len(audience) != 1 || audience[0].WatchedPage != 1 || audience[0].Followers != 1000 {
+		t.Fatalf("got audience %+v, want 1000 followers of watched page 1", audience)
+	}
+
+	wantLikes := map[string]int{"31": 5, "41": 2}
+	if len(watchedPages.posts) != len(wantLikes) {
+		t.Fatalf("got %d saved posts, want %d", len(watchedPages.posts), len(wantLikes))
+	}
+	for _, post := range watchedPages.posts {
+		likes, ok := wantLikes[post.RemotePostID]
+		if !ok || post.Likes != likes || !post.PublishedAt.Equal(publishedAt) {
+			t.Fatalf("got post %+v, want likes %v published at %v", post, wantLikes, publishedAt)
+		}
+	}
+}
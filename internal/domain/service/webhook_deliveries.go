@@ -0,0 +1,46 @@
+package service
+
+import (
+	"autoposting/internal/domain/model"
+	"autoposting/internal/infrastructure/postgres"
+	"autoposting/internal/infrastructure/social_network_client"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+)
+
+func (sns *SocialNetworkService) GetWebhookDeliveries(
+	ctx context.Context,
+	query postgres.FindWebhookDeliveriesQuery,
+) ([]model.WebhookDelivery, error) {
+	deliveries, err := sns.webhookDeliveriesRepository.FindDeliveries(ctx, query)
+	if err != nil {
+		return nil, ewrap.Errorf("failed to find webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func toModelWebhookDeliveries(
+	pageID int,
+	attempts []social_network_client.DeliveryAttempt,
+) []model.WebhookDelivery {
+	deliveries := make([]model.WebhookDelivery, 0, len(attempts))
+	for _, attempt := range attempts {
+		delivery := model.WebhookDelivery{
+			Page:         pageID,
+			DeliveryID:   attempt.DeliveryID,
+			Attempt:      attempt.Attempt,
+			ResponseBody: attempt.ResponseBody,
+			AttemptedAt:  attempt.AttemptedAt,
+		}
+		if attempt.StatusCode != 0 {
+			statusCode := attempt.StatusCode
+			delivery.StatusCode = &statusCode
+		}
+		if attempt.Err != nil {
+			errText := attempt.Err.Error()
+			delivery.Error = &errText
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries
+}
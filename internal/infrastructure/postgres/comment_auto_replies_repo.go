@@ -0,0 +1,127 @@
+package postgres
+
+import (
+	"autoposting/internal/domain"
+	"autoposting/internal/domain/model"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+type CommentAutoRepliesRepository struct {
+	db *bun.DB
+}
+
+type FindCommentAutoReplyRulesQuery struct {
+	IDAnyOf      []int
+	PagesIDAnyOf []int
+	PostsIDAnyOf []int64
+}
+
+// CountCommentAutoRepliesQuery пустой AuthorID - ответы всем авторам
+type CountCommentAutoRepliesQuery struct {
+	Page     int
+	AuthorID string
+	Since    time.Time
+}
+
+func NewCommentAutoRepliesRepository(db *bun.DB) *CommentAutoRepliesRepository {
+	return &CommentAutoRepliesRepository{
+		db: db,
+	}
+}
+
+func (c CommentAutoRepliesRepository) CreateRule(
+	ctx context.Context,
+	rule *model.CommentAutoReplyRule,
+) error {
+	_, err := c.db.NewInsert().
+		Model(rule).
+		Returning("id").
+		Exec(ctx)
+	if err != nil {
+		return ewrap.Errorf("failed to create comment auto reply rule: %w", err)
+	}
+	return nil
+}
+
+// DeleteRule история отправленных правилом ответов остается без ссылки на правило
+func (c CommentAutoRepliesRepository) DeleteRule(ctx context.Context, id int) error {
+	result, err := c.db.NewDelete().
+		Model((*model.CommentAutoReplyRule)(nil)).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return ewrap.Errorf("failed to delete comment auto reply rule %d: %w", id, err)
+	}
+	if deleted, err := result.RowsAffected(); err == nil && deleted == 0 {
+		return domain.NewNotFoundError(fmt.Sprintf("comment auto reply rule %d not found", id))
+	}
+	return nil
+}
+
+func (c CommentAutoRepliesRepository) FindRules(
+	ctx context.Context,
+	query FindCommentAutoReplyRulesQuery,
+) ([]model.CommentAutoReplyRule, error) {
+	var ruleRows []model.CommentAutoReplyRule
+	q := c.db.NewSelect().
+		Model(&ruleRows).
+		Order("id")
+
+	if len(query.IDAnyOf) != 0 {
+		q.Where("id IN (?)", bun.In(query.IDAnyOf))
+	}
+	if len(query.PagesIDAnyOf) != 0 {
+		q.Where("page IN (?)", bun.In(query.PagesIDAnyOf))
+	}
+	if len(query.PostsIDAnyOf) != 0 {
+		q.Where("post IN (?)", bun.In(query.PostsIDAnyOf))
+	}
+
+	if err := q.Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ruleRows, nil
+		}
+		return nil, ewrap.Errorf("failed to select comment auto reply rules: %w", err)
+	}
+	return ruleRows, nil
+}
+
+func (c CommentAutoRepliesRepository) CreateReply(
+	ctx context.Context,
+	reply *model.CommentAutoReply,
+) error {
+	_, err := c.db.NewInsert().
+		Model(reply).
+		Returning("id").
+		Exec(ctx)
+	if err != nil {
+		return ewrap.Errorf("failed to create comment auto reply: %w", err)
+	}
+	return nil
+}
+
+func (c CommentAutoRepliesRepository) CountReplies(
+	ctx context.Context,
+	query CountCommentAutoRepliesQuery,
+) (int, error) {
+	q := c.db.NewSelect().
+		Model((*model.CommentAutoReply)(nil)).
+		Where("page = ?", query.Page).
+		Where("created_at >= ?", query.Since)
+
+	if query.AuthorID != "" {
+		q.Where("author_id = ?", query.AuthorID)
+	}
+
+	count, err := q.Count(ctx)
+	if err != nil {
+		return 0, ewrap.Errorf("failed to count auto replies on page %d: %w", query.Page, err)
+	}
+	return count, nil
+}
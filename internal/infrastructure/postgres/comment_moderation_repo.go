@@ -0,0 +1,154 @@
+package postgres
+
+import (
+	"autoposting/internal/domain"
+	"autoposting/internal/domain/model"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+type CommentModerationRepository struct {
+	db *bun.DB
+}
+
+type FindCommentModerationRulesQuery struct {
+	IDAnyOf      []int
+	ProjectAnyOf []string
+}
+
+// FindCommentModerationLogQuery действия отдаются от новых к старым
+type FindCommentModerationLogQuery struct {
+	IDAnyOf      []int64
+	ProjectAnyOf []string
+	Limit        int
+	Offset       int
+}
+
+func NewCommentModerationRepository(db *bun.DB) *CommentModerationRepository {
+	return &CommentModerationRepository{
+		db: db,
+	}
+}
+
+func (c CommentModerationRepository) CreateRule(
+	ctx context.Context,
+	rule *model.CommentModerationRule,
+) error {
+	_, err := c.db.NewInsert().
+		Model(rule).
+		Returning("id").
+		Exec(ctx)
+	if err != nil {
+		return ewrap.Errorf("failed to create comment moderation rule: %w", err)
+	}
+	return nil
+}
+
+// DeleteRule действия правила остаются в журнале без ссылки на правило
+func (c CommentModerationRepository) DeleteRule(ctx context.Context, id int) error {
+	result, err := c.db.NewDelete().
+		Model((*model.CommentModerationRule)(nil)).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return ewrap.Errorf("failed to delete comment moderation rule %d: %w", id, err)
+	}
+	if deleted, err := result.RowsAffected(); err == nil && deleted == 0 {
+		return domain.NewNotFoundError(fmt.Sprintf("comment moderation rule %d not found", id))
+	}
+	return nil
+}
+
+func (c CommentModerationRepository) FindRules(
+	ctx context.Context,
+	query FindCommentModerationRulesQuery,
+) ([]model.CommentModerationRule, error) {
+	var ruleRows []model.CommentModerationRule
+	q := c.db.NewSelect().
+		Model(&ruleRows).
+		Order("id")
+
+	if len(query.IDAnyOf) != 0 {
+		q.Where("id IN (?)", bun.In(query.IDAnyOf))
+	}
+	if len(query.ProjectAnyOf) != 0 {
+		q.Where("project IN (?)", bun.In(query.ProjectAnyOf))
+	}
+
+	if err := q.Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ruleRows, nil
+		}
+		return nil, ewrap.Errorf("failed to select comment moderation rules: %w", err)
+	}
+	return ruleRows, nil
+}
+
+func (c CommentModerationRepository) CreateLog(
+	ctx context.Context,
+	log *model.CommentModerationLog,
+) error {
+	_, err := c.db.NewInsert().
+		Model(log).
+		Returning("id").
+		Exec(ctx)
+	if err != nil {
+		return ewrap.Errorf("failed to create comment moderation log: %w", err)
+	}
+	return nil
+}
+
+// MarkLogReverted отмененное действие повторно не отменяется
+func (c CommentModerationRepository) MarkLogReverted(ctx context.Context, id int64, revertedAt time.Time) error {
+	result, err := c.db.NewUpdate().
+		Model((*model.CommentModerationLog)(nil)).
+		Set("reverted_at = ?", revertedAt).
+		Where("id = ?", id).
+		Where("reverted_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return ewrap.Errorf("failed to mark comment moderation log %d reverted: %w", id, err)
+	}
+	if updated, err := result.RowsAffected(); err == nil && updated == 0 {
+		return domain.NewNotFoundError(fmt.Sprintf("comment moderation log %d not found", id))
+	}
+	return nil
+}
+
+func (c CommentModerationRepository) FindLog(
+	ctx context.Context,
+	query FindCommentModerationLogQuery,
+) ([]model.CommentModerationLog, error) {
+	var logRows []model.CommentModerationLog
+	q := c.db.NewSelect().
+		Model(&logRows).
+		Order("log.created_at DESC", "log.id DESC")
+
+	if len(query.IDAnyOf) != 0 {
+		q.Where("log.id IN (?)", bun.In(query.IDAnyOf))
+	}
+	if len(query.ProjectAnyOf) != 0 {
+		q.Join("JOIN comments AS comment ON comment.id = log.comment").
+			Join("JOIN social_network_pages AS page ON page.id = comment.page").
+			Where("page.project IN (?)", bun.In(query.ProjectAnyOf))
+	}
+	if query.Limit > 0 {
+		q.Limit(query.Limit)
+	}
+	if query.Offset > 0 {
+		q.Offset(query.Offset)
+	}
+
+	if err := q.Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return logRows, nil
+		}
+		return nil, ewrap.Errorf("failed to select comment moderation log: %w", err)
+	}
+	return logRows, nil
+}
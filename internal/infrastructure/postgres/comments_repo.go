@@ -0,0 +1,236 @@
+package postgres
+
+import (
+	"autoposting/internal/domain"
+	"autoposting/internal/domain/model"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+type CommentsRepository struct {
+	db *bun.DB
+}
+
+// FindCommentsQuery комментарии отдаются от новых к старым, удаленные отдаются только с WithDeleted.
+// Unanswered - только видимые комментарии подписчиков без ответа страницы,
+// Unprocessed - комментарии, которые еще не обработаны правилами модерации и автоответов
+type FindCommentsQuery struct {
+	IDAnyOf            []int64
+	PagesIDAnyOf       []int
+	ProjectAnyOf       []string
+	SocialNetworkAnyOf []model.SocialNetworkName
+	Unanswered         bool
+	Unprocessed        bool
+	WithDeleted        bool
+	Limit              int
+	Offset             int
+}
+
+func NewCommentsRepository(db *bun.DB) *CommentsRepository {
+	return &CommentsRepository{
+		db: db,
+	}
+}
+
+// UpsertComments новые комментарии добавляются, у известных обновляются автор, родитель и текст.
+// Пустые автор и родитель, например из уведомлений соц сетей, известные значения не затирают.
+// Время ответа сохраняет первое известное значение, комментарий, снова найденный в соц сети, перестает быть удаленным,
+// измененный текст снова обрабатывается правилами
+func (c CommentsRepository) UpsertComments(
+	ctx context.Context,
+	comments []model.Comment,
+) error {
+	if len(comments) == 0 {
+		return nil
+	}
+
+	_, err := c.db.NewInsert().
+		Model(&comments).
+		On(`CONFLICT ON CONSTRAINT "COMMENTS_UNIQUE" DO UPDATE`).
+		Set("author_name = COALESCE(NULLIF(EXCLUDED.author_name, ''), comment.author_name)").
+		Set("parent_remote_comment_id = COALESCE(EXCLUDED.parent_remote_comment_id, comment.parent_remote_comment_id)").
+		Set("text = EXCLUDED.text").
+		Set("answered_at = COALESCE(comment.answered_at, EXCLUDED.answered_at)").
+		Set("deleted_at = NULL").
+		Set("processed_at = CASE WHEN comment.text = EXCLUDED.text THEN comment.processed_at END").
+		Set("collected_at = EXCLUDED.collected_at").
+		Returning("id").
+		Exec(ctx)
+	if err != nil {
+		return ewrap.Errorf("failed to upsert comments: %w", err)
+	}
+	return nil
+}
+
+// MarkMissingCommentsDeleted помечает удаленными комментарии поста, которых нет среди remoteCommentsIDs.
+// Возвращается число помеченных
+func (c CommentsRepository) MarkMissingCommentsDeleted(
+	ctx context.Context,
+	post int64,
+	remoteCommentsIDs []string,
+	deletedAt time.Time,
+) (int, error) {
+	q := c.db.NewUpdate().
+		Model((*model.Comment)(nil)).
+		Set("deleted_at = ?", deletedAt).
+		Where("post = ?", post).
+		Where("deleted_at IS NULL")
+	if len(remoteCommentsIDs) != 0 {
+		q.Where("remote_comment_id NOT IN (?)", bun.In(remoteCommentsIDs))
+	}
+
+	res, err := q.Exec(ctx)
+	if err != nil {
+		return 0, ewrap.Errorf("failed to mark deleted comments of post %d: %w", post, err)
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, ewrap.Errorf("failed to count deleted comments of post %d: %w", post, err)
+	}
+	return int(deleted), nil
+}
+
+func (c CommentsRepository) MarkCommentDeleted(ctx context.Context, id int64, deletedAt time.Time) error {
+	res, err := c.db.NewUpdate().
+		Model((*model.Comment)(nil)).
+		Set("deleted_at = ?", deletedAt).
+		Where("id = ?", id).
+		Where("deleted_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return ewrap.Errorf("failed to mark comment %d deleted: %w", id, err)
+	}
+	if updated, err := res.RowsAffected(); err == nil && updated == 0 {
+		return domain.NewNotFoundError(fmt.Sprintf("comment %d not found", id))
+	}
+	return nil
+}
+
+// MarkCommentAnswered у комментария, на который уже отвечали, время ответа не меняется
+func (c CommentsRepository) MarkCommentAnswered(ctx context.Context, id int64, answeredAt time.Time) error {
+	_, err := c.db.NewUpdate().
+		Model((*model.Comment)(nil)).
+		Set("answered_at = ?", answeredAt).
+		Where("id = ?", id).
+		Where("answered_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return ewrap.Errorf("failed to mark comment %d answered: %w", id, err)
+	}
+	return nil
+}
+
+func (c CommentsRepository) MarkCommentHidden(ctx context.Context, id int64, hiddenAt time.Time) error {
+	_, err := c.db.NewUpdate().
+		Model((*model.Comment)(nil)).
+		Set("hidden_at = ?", hiddenAt).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return ewrap.Errorf("failed to mark comment %d hidden: %w", id, err)
+	}
+	return nil
+}
+
+// MarkCommentRestored комментарий снова виден и не удален
+func (c CommentsRepository) MarkCommentRestored(ctx context.Context, id int64) error {
+	_, err := c.db.NewUpdate().
+		Model((*model.Comment)(nil)).
+		Set("hidden_at = NULL").
+		Set("deleted_at = NULL").
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return ewrap.Errorf("failed to mark comment %d restored: %w", id, err)
+	}
+	return nil
+}
+
+func (c CommentsRepository) MarkCommentsProcessed(ctx context.Context, ids []int64, processedAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := c.db.NewUpdate().
+		Model((*model.Comment)(nil)).
+		Set("processed_at = ?", processedAt).
+		Where("id IN (?)", bun.In(ids)).
+		Exec(ctx)
+	if err != nil {
+		return ewrap.Errorf("failed to mark comments %v processed: %w", ids, err)
+	}
+	return nil
+}
+
+// CountAuthorComments число комментариев автора на странице с момента since, удаленные тоже считаются
+func (c CommentsRepository) CountAuthorComments(
+	ctx context.Context,
+	page int,
+	authorID string,
+	since time.Time,
+) (int, error) {
+	count, err := c.db.NewSelect().
+		Model((*model.Comment)(nil)).
+		Where("page = ?", page).
+		Where("author_id = ?", authorID).
+		Where("created_at >= ?", since).
+		Count(ctx)
+	if err != nil {
+		return 0, ewrap.Errorf("failed to count comments of author %s on page %d: %w", authorID, page, err)
+	}
+	return count, nil
+}
+
+func (c CommentsRepository) FindComments(
+	ctx context.Context,
+	query FindCommentsQuery,
+) ([]model.Comment, error) {
+	var commentRows []model.Comment
+	q := c.db.NewSelect().
+		Model(&commentRows).
+		Order("comment.created_at DESC", "comment.id DESC")
+
+	if !query.WithDeleted {
+		q.Where("comment.deleted_at IS NULL")
+	}
+	if len(query.IDAnyOf) != 0 {
+		q.Where("comment.id IN (?)", bun.In(query.IDAnyOf))
+	}
+	if len(query.PagesIDAnyOf) != 0 {
+		q.Where("comment.page IN (?)", bun.In(query.PagesIDAnyOf))
+	}
+	if len(query.ProjectAnyOf) != 0 {
+		q.Join("JOIN social_network_pages AS page ON page.id = comment.page").
+			Where("page.project IN (?)", bun.In(query.ProjectAnyOf))
+	}
+	if len(query.SocialNetworkAnyOf) != 0 {
+		q.Where("comment.social_network IN (?)", bun.In(query.SocialNetworkAnyOf))
+	}
+	if query.Unanswered {
+		q.Where("NOT comment.by_page").
+			Where("comment.answered_at IS NULL").
+			Where("comment.hidden_at IS NULL")
+	}
+	if query.Unprocessed {
+		q.Where("comment.processed_at IS NULL")
+	}
+	if query.Limit > 0 {
+		q.Limit(query.Limit)
+	}
+	if query.Offset > 0 {
+		q.Offset(query.Offset)
+	}
+
+	if err := q.Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return commentRows, nil
+		}
+		return nil, ewrap.Errorf("failed to select comments: %w", err)
+	}
+	return commentRows, nil
+}
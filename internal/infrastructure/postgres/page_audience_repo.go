@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"autoposting/internal/domain/model"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+// pageAudienceDayExpr день снимка, границы дней по UTC
+const pageAudienceDayExpr = "(collected_at AT TIME ZONE 'UTC')::date"
+
+type PageAudienceRepository struct {
+	db *bun.DB
+}
+
+type FindPageAudienceQuery struct {
+	PagesIDAnyOf    []int
+	ProjectAnyOf    []string
+	CollectedAfter  time.Time
+	CollectedBefore time.Time
+}
+
+func NewPageAudienceRepository(db *bun.DB) *PageAudienceRepository {
+	return &PageAudienceRepository{
+		db: db,
+	}
+}
+
+func (p PageAudienceRepository) CreateAudience(
+	ctx context.Context,
+	audience []model.PageAudience,
+) error {
+	if len(audience) == 0 {
+		return nil
+	}
+
+	_, err := p.db.NewInsert().
+		Model(&audience).
+		Returning("id").
+		Exec(ctx)
+	if err != nil {
+		return ewrap.Errorf("failed to create page audience: %w", err)
+	}
+	return nil
+}
+
+// FindDailyAudience последний снимок каждой страницы за день, по страницам и от старых дней к новым
+func (p PageAudienceRepository) FindDailyAudience(
+	ctx context.Context,
+	query FindPageAudienceQuery,
+) ([]model.PageAudience, error) {
+	var audienceRows []model.PageAudience
+	q := p.db.NewSelect().
+		Model(&audienceRows).
+		DistinctOn("page, " + pageAudienceDayExpr).
+		OrderExpr("page, " + pageAudienceDayExpr + ", collected_at DESC")
+
+	if len(query.PagesIDAnyOf) != 0 {
+		q.Where("page IN (?)", bun.In(query.PagesIDAnyOf))
+	}
+	if len(query.ProjectAnyOf) != 0 {
+		q.Where(
+			"page IN (?)",
+			p.db.NewSelect().
+				Model((*model.SocialNetworkPage)(nil)).
+				Column("id").
+				Where("project IN (?)", bun.In(query.ProjectAnyOf)),
+		)
+	}
+	if !query.CollectedAfter.IsZero() {
+		q.Where("collected_at >= ?", query.CollectedAfter)
+	}
+	if !query.CollectedBefore.IsZero() {
+		q.Where("collected_at < ?", query.CollectedBefore)
+	}
+
+	if err := q.Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return audienceRows, nil
+		}
+		return nil, ewrap.Errorf("failed to select page audience: %w", err)
+	}
+	return audienceRows, nil
+}
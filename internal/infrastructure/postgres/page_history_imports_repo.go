@@ -0,0 +1,101 @@
+package postgres
+
+import (
+	"autoposting/internal/domain/model"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+type PageHistoryImportsRepository struct {
+	db *bun.DB
+}
+
+func NewPageHistoryImportsRepository(db *bun.DB) *PageHistoryImportsRepository {
+	return &PageHistoryImportsRepository{
+		db: db,
+	}
+}
+
+func (p PageHistoryImportsRepository) CreateImport(
+	ctx context.Context,
+	pageHistoryImport *model.PageHistoryImport,
+) error {
+	_, err := p.db.NewInsert().
+		Model(pageHistoryImport).
+		Returning("id").
+		Exec(ctx)
+	if err != nil {
+		return ewrap.Errorf("failed to create page history import: %w", err)
+	}
+	return nil
+}
+
+// TakePendingImport переводит самую старую ожидающую задачу в RUNNING и возвращает ее, nil - задач нет.
+// Задачу, взятую другим экземпляром приложения, SKIP LOCKED пропускает
+func (p PageHistoryImportsRepository) TakePendingImport(
+	ctx context.Context,
+	startedAt time.Time,
+) (*model.PageHistoryImport, error) {
+	pageHistoryImport := &model.PageHistoryImport{}
+	err := p.db.NewUpdate().
+		Model(pageHistoryImport).
+		Set("status = ?", model.PageHistoryImportStatusRunning).
+		Set("started_at = ?", startedAt).
+		Where(
+			"id = (?)",
+			p.db.NewSelect().
+				Model((*model.PageHistoryImport)(nil)).
+				Column("id").
+				Where("status = ?", model.PageHistoryImportStatusPending).
+				Order("id").
+				Limit(1).
+				For("UPDATE SKIP LOCKED"),
+		).
+		Returning("*").
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, ewrap.Errorf("failed to take pending page history import: %w", err)
+	}
+	return pageHistoryImport, nil
+}
+
+// FinishImport сохраняет итог задачи, задача в статусе PENDING возвращается в очередь
+func (p PageHistoryImportsRepository) FinishImport(
+	ctx context.Context,
+	pageHistoryImport *model.PageHistoryImport,
+) error {
+	_, err := p.db.NewUpdate().
+		Model(pageHistoryImport).
+		Column("status", "fetched", "imported", "error", "started_at", "finished_at").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return ewrap.Errorf("failed to finish page history import %d: %w", pageHistoryImport.ID, err)
+	}
+	return nil
+}
+
+// RequeueRunningImports возвращает задачи RUNNING в очередь, вызывается до запуска обработки очереди
+func (p PageHistoryImportsRepository) RequeueRunningImports(ctx context.Context) (int, error) {
+	result, err := p.db.NewUpdate().
+		Model((*model.PageHistoryImport)(nil)).
+		Set("status = ?", model.PageHistoryImportStatusPending).
+		Set("started_at = NULL").
+		Where("status = ?", model.PageHistoryImportStatusRunning).
+		Exec(ctx)
+	if err != nil {
+		return 0, ewrap.Errorf("failed to requeue running page history imports: %w", err)
+	}
+	requeued, err := result.RowsAffected()
+	if err != nil {
+		return 0, ewrap.Errorf("failed to count requeued page history imports: %w", err)
+	}
+	return int(requeued), nil
+}
@@ -0,0 +1,188 @@
+package postgres
+
+import (
+	"autoposting/internal/domain/model"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+type PostMetricsRepository struct {
+	db *bun.DB
+}
+
+// FindPostMetricsQuery снимки отдаются от старых к новым
+type FindPostMetricsQuery struct {
+	PostID          int64
+	CollectedAfter  time.Time
+	CollectedBefore time.Time
+}
+
+// AggregatePostMetricsQuery фильтры по времени относятся к публикации постов
+type AggregatePostMetricsQuery struct {
+	GroupBy            model.PostMetricsGroupBy
+	PagesIDAnyOf       []int
+	ProjectAnyOf       []string
+	SocialNetworkAnyOf []model.SocialNetworkName
+	PublishedAfter     time.Time
+	PublishedBefore    time.Time
+}
+
+type FindLatestPostMetricsQuery struct {
+	PagesIDAnyOf   []int
+	PublishedAfter time.Time
+}
+
+func NewPostMetricsRepository(db *bun.DB) *PostMetricsRepository {
+	return &PostMetricsRepository{
+		db: db,
+	}
+}
+
+func (p PostMetricsRepository) CreateMetrics(
+	ctx context.Context,
+	metrics []model.PostMetrics,
+) error {
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	_, err := p.db.NewInsert().
+		Model(&metrics).
+		Returning("id").
+		Exec(ctx)
+	if err != nil {
+		return ewrap.Errorf("failed to create post metrics: %w", err)
+	}
+	return nil
+}
+
+func (p PostMetricsRepository) FindMetrics(
+	ctx context.Context,
+	query FindPostMetricsQuery,
+) ([]model.PostMetrics, error) {
+	var metricsRows []model.PostMetrics
+	q := p.db.NewSelect().
+		Model(&metricsRows).
+		Where("post = ?", query.PostID).
+		Order("collected_at")
+
+	if !query.CollectedAfter.IsZero() {
+		q.Where("collected_at >= ?", query.CollectedAfter)
+	}
+	if !query.CollectedBefore.IsZero() {
+		q.Where("collected_at < ?", query.CollectedBefore)
+	}
+
+	if err := q.Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return metricsRows, nil
+		}
+		return nil, ewrap.Errorf("failed to select post %d metrics: %w", query.PostID, err)
+	}
+	return metricsRows, nil
+}
+
+// AggregateMetrics суммирует последний снимок каждого поста, посты без снимков не учитываются
+func (p PostMetricsRepository) AggregateMetrics(
+	ctx context.Context,
+	query AggregatePostMetricsQuery,
+) ([]model.PostMetricsAggregate, error) {
+	var keyExpr string
+	switch query.GroupBy {
+	case model.PostMetricsGroupByPage:
+		keyExpr = "page.id::text"
+	case model.PostMetricsGroupByProject:
+		keyExpr = "page.project"
+	case model.PostMetricsGroupBySocialNetwork:
+		keyExpr = "account.social_network"
+	default:
+		return nil, ewrap.Errorf("unknown post metrics group by %q", query.GroupBy)
+	}
+
+	latestMetrics := p.db.NewSelect().
+		Model((*model.PostMetrics)(nil)).
+		DistinctOn("post").
+		Order("post", "collected_at DESC")
+
+	var aggregates []model.PostMetricsAggregate
+	q := p.db.NewSelect().
+		With("latest_metrics", latestMetrics).
+		TableExpr("latest_metrics AS metrics").
+		Join("JOIN posts AS post ON post.id = metrics.post").
+		Join("JOIN social_network_pages AS page ON page.id = post.page").
+		Join("JOIN social_network_accounts AS account ON account.id = page.account_id").
+		ColumnExpr(keyExpr + " AS key").
+		ColumnExpr("count(*) AS posts").
+		ColumnExpr("sum(metrics.likes) AS likes").
+		ColumnExpr("sum(metrics.reposts) AS reposts").
+		ColumnExpr("sum(metrics.comments) AS comments").
+		ColumnExpr("sum(metrics.views) AS views").
+		ColumnExpr("sum(metrics.reach) AS reach").
+		GroupExpr(keyExpr).
+		OrderExpr(keyExpr)
+
+	if len(query.PagesIDAnyOf) != 0 {
+		q.Where("page.id IN (?)", bun.In(query.PagesIDAnyOf))
+	}
+	if len(query.ProjectAnyOf) != 0 {
+		q.Where("page.project IN (?)", bun.In(query.ProjectAnyOf))
+	}
+	if len(query.SocialNetworkAnyOf) != 0 {
+		q.Where("account.social_network IN (?)", bun.In(query.SocialNetworkAnyOf))
+	}
+	if !query.PublishedAfter.IsZero() {
+		q.Where("post.published_at >= ?", query.PublishedAfter)
+	}
+	if !query.PublishedBefore.IsZero() {
+		q.Where("post.published_at < ?", query.PublishedBefore)
+	}
+
+	if err := q.Scan(ctx, &aggregates); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return aggregates, nil
+		}
+		return nil, ewrap.Errorf("failed to aggregate post metrics: %w", err)
+	}
+	return aggregates, nil
+}
+
+// FindLatestMetrics последний снимок каждого поста, посты без снимков не попадают в результат
+func (p PostMetricsRepository) FindLatestMetrics(
+	ctx context.Context,
+	query FindLatestPostMetricsQuery,
+) ([]model.PublishedPostMetrics, error) {
+	// Посты отбираются до DISTINCT ON, иначе он проходит по снимкам всех постов
+	latestMetrics := p.db.NewSelect().
+		TableExpr("post_metrics AS metrics").
+		Join("JOIN posts AS post ON post.id = metrics.post").
+		DistinctOn("metrics.post").
+		ColumnExpr("metrics.post, post.published_at").
+		ColumnExpr("metrics.likes, metrics.reposts, metrics.comments, metrics.views, metrics.reach").
+		OrderExpr("metrics.post, metrics.collected_at DESC")
+
+	if len(query.PagesIDAnyOf) != 0 {
+		latestMetrics.Where("post.page IN (?)", bun.In(query.PagesIDAnyOf))
+	}
+	if !query.PublishedAfter.IsZero() {
+		latestMetrics.Where("post.published_at >= ?", query.PublishedAfter)
+	}
+
+	var metricsRows []model.PublishedPostMetrics
+	q := p.db.NewSelect().
+		With("latest_metrics", latestMetrics).
+		TableExpr("latest_metrics").
+		ColumnExpr("*").
+		OrderExpr("published_at")
+
+	if err := q.Scan(ctx, &metricsRows); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return metricsRows, nil
+		}
+		return nil, ewrap.Errorf("failed to select latest post metrics: %w", err)
+	}
+	return metricsRows, nil
+}
@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"autoposting/internal/domain/model"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+type PostsRepository struct {
+	db *bun.DB
+}
+
+// FindPostsQuery посты отдаются от новых к старым
+type FindPostsQuery struct {
+	IDAnyOf           []int64
+	PagesIDAnyOf      []int
+	RemotePostIDAnyOf []string
+	PublishedAfter    time.Time
+	RemoteStateAnyOf  []model.PostRemoteState
+	Limit             int
+	Offset            int
+}
+
+func NewPostsRepository(db *bun.DB) *PostsRepository {
+	return &PostsRepository{
+		db: db,
+	}
+}
+
+func (p PostsRepository) CreatePosts(
+	ctx context.Context,
+	posts []model.Post,
+) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	_, err := p.db.NewInsert().
+		Model(&posts).
+		Returning("id").
+		Exec(ctx)
+	if err != nil {
+		return ewrap.Errorf("failed to create posts: %w", err)
+	}
+	return nil
+}
+
+// CreateImportedPosts посты, уже сохраненные для страницы с тем же remote_post_id, пропускаются.
+// Возвращается число сохраненных
+func (p PostsRepository) CreateImportedPosts(
+	ctx context.Context,
+	posts []model.Post,
+) (int, error) {
+	if len(posts) == 0 {
+		return 0, nil
+	}
+
+	res, err := p.db.NewInsert().
+		Model(&posts).
+		On(`CONFLICT ("page", "remote_post_id") WHERE "remote_post_id" <> '' DO NOTHING`).
+		// Пропущенные дубли не возвращают строк, RETURNING не сопоставить с моделями
+		Returning("NULL").
+		Exec(ctx)
+	if err != nil {
+		return 0, ewrap.Errorf("failed to create imported posts: %w", err)
+	}
+
+	created, err := res.RowsAffected()
+	if err != nil {
+		return 0, ewrap.Errorf("failed to count created imported posts: %w", err)
+	}
+	return int(created), nil
+}
+
+func (p PostsRepository) FindPosts(
+	ctx context.Context,
+	query FindPostsQuery,
+) ([]model.Post, error) {
+	var postRows []model.Post
+	q := p.db.NewSelect().
+		Model(&postRows).
+		Order("published_at DESC", "id DESC")
+
+	if len(query.IDAnyOf) != 0 {
+		q.Where("id IN (?)", bun.In(query.IDAnyOf))
+	}
+	if len(query.PagesIDAnyOf) != 0 {
+		q.Where("page IN (?)", bun.In(query.PagesIDAnyOf))
+	}
+	if len(query.RemotePostIDAnyOf) != 0 {
+		q.Where("remote_post_id IN (?)", bun.In(query.RemotePostIDAnyOf))
+	}
+	if !query.PublishedAfter.IsZero() {
+		q.Where("published_at > ?", query.PublishedAfter)
+	}
+	if len(query.RemoteStateAnyOf) != 0 {
+		q.Where("remote_state IN (?)", bun.In(query.RemoteStateAnyOf))
+	}
+	if query.Limit > 0 {
+		q.Limit(query.Limit)
+	}
+	if query.Offset > 0 {
+		q.Offset(query.Offset)
+	}
+
+	if err := q.Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return postRows, nil
+		}
+		return nil, ewrap.Errorf("failed to select posts: %w", err)
+	}
+	return postRows, nil
+}
+
+// UpdatePostsRemoteState сохраняет результат сверки, остальные поля постов не меняются
+func (p PostsRepository) UpdatePostsRemoteState(
+	ctx context.Context,
+	posts []model.Post,
+) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	_, err := p.db.NewUpdate().
+		Model(&posts).
+		Column("remote_state", "remote_text", "remote_diff", "reconciled_at").
+		Bulk().
+		Exec(ctx)
+	if err != nil {
+		return ewrap.Errorf("failed to update posts remote state: %w", err)
+	}
+	return nil
+}
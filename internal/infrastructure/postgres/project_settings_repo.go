@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"autoposting/internal/domain"
+	"autoposting/internal/domain/model"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/uptrace/bun"
+)
+
+type ProjectSettingsRepository struct {
+	db *bun.DB
+}
+
+func NewProjectSettingsRepository(db *bun.DB) *ProjectSettingsRepository {
+	return &ProjectSettingsRepository{
+		db: db,
+	}
+}
+
+func (p ProjectSettingsRepository) SaveSettings(ctx context.Context, settings *model.ProjectSettings) error {
+	_, err := p.db.NewInsert().
+		Model(settings).
+		On(`CONFLICT ("project") DO UPDATE`).
+		Set("timezone = EXCLUDED.timezone").
+		Exec(ctx)
+	if err != nil {
+		return ewrap.Errorf("failed to save project %s settings: %w", settings.Project, err)
+	}
+	return nil
+}
+
+func (p ProjectSettingsRepository) FindSettings(ctx context.Context, project string) (*model.ProjectSettings, error) {
+	settings := &model.ProjectSettings{}
+	err := p.db.NewSelect().
+		Model(settings).
+		Where("project = ?", project).
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.NewNotFoundError(fmt.Sprintf("project %s settings not found", project))
+		}
+		return nil, ewrap.Errorf("failed to select project %s settings: %w", project, err)
+	}
+	return settings, nil
+}
@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"autoposting/internal/domain/model"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+type ScheduledPostsRepository struct {
+	db *bun.DB
+}
+
+// FindScheduledPostsQuery посты отдаются по времени публикации, ближайшие первыми
+type FindScheduledPostsQuery struct {
+	PagesIDAnyOf []int
+	StatusAnyOf  []model.ScheduledPostStatus
+	Limit        int
+	Offset       int
+}
+
+func NewScheduledPostsRepository(db *bun.DB) *ScheduledPostsRepository {
+	return &ScheduledPostsRepository{
+		db: db,
+	}
+}
+
+func (s ScheduledPostsRepository) CreateScheduledPosts(
+	ctx context.Context,
+	scheduledPosts []model.ScheduledPost,
+) error {
+	if len(scheduledPosts) == 0 {
+		return nil
+	}
+
+	_, err := s.db.NewInsert().
+		Model(&scheduledPosts).
+		Returning("id").
+		Exec(ctx)
+	if err != nil {
+		return ewrap.Errorf("failed to create scheduled posts: %w", err)
+	}
+	return nil
+}
+
+func (s ScheduledPostsRepository) FindScheduledPosts(
+	ctx context.Context,
+	query FindScheduledPostsQuery,
+) ([]model.ScheduledPost, error) {
+	var scheduledPosts []model.ScheduledPost
+	q := s.db.NewSelect().
+		Model(&scheduledPosts).
+		Order("scheduled.publish_at", "scheduled.id")
+
+	if len(query.PagesIDAnyOf) != 0 {
+		q.Where("scheduled.page IN (?)", bun.In(query.PagesIDAnyOf))
+	}
+	if len(query.StatusAnyOf) != 0 {
+		q.Where("scheduled.status IN (?)", bun.In(query.StatusAnyOf))
+	}
+	if query.Limit > 0 {
+		q.Limit(query.Limit)
+	}
+	if query.Offset > 0 {
+		q.Offset(query.Offset)
+	}
+
+	if err := q.Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return scheduledPosts, nil
+		}
+		return nil, ewrap.Errorf("failed to select scheduled posts: %w", err)
+	}
+	return scheduledPosts, nil
+}
+
+// TakeDuePost переводит в PUBLISHING самый ранний пост, время публикации которого наступило к now,
+// nil - таких постов нет. Пост, взятый другим экземпляром приложения, SKIP LOCKED пропускает
+func (s ScheduledPostsRepository) TakeDuePost(
+	ctx context.Context,
+	now time.Time,
+) (*model.ScheduledPost, error) {
+	scheduledPost := &model.ScheduledPost{}
+	err := s.db.NewUpdate().
+		Model(scheduledPost).
+		Set("status = ?", model.ScheduledPostStatusPublishing).
+		Where(
+			"id = (?)",
+			s.db.NewSelect().
+				Model((*model.ScheduledPost)(nil)).
+				Column("id").
+				Where("status = ?", model.ScheduledPostStatusPending).
+				Where("publish_at <= ?", now).
+				Order("publish_at", "id").
+				Limit(1).
+				For("UPDATE SKIP LOCKED"),
+		).
+		Returning("*").
+		Scan(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, ewrap.Errorf("failed to take due scheduled post: %w", err)
+	}
+	return scheduledPost, nil
+}
+
+func (s ScheduledPostsRepository) FinishScheduledPost(
+	ctx context.Context,
+	scheduledPost *model.ScheduledPost,
+) error {
+	_, err := s.db.NewUpdate().
+		Model(scheduledPost).
+		Column("status", "remote_post_id", "error", "published_at").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return ewrap.Errorf("failed to finish scheduled post %d: %w", scheduledPost.ID, err)
+	}
+	return nil
+}
+
+// FailPublishingPosts отмечает неудачными посты, публикацию которых прервала остановка приложения.
+// В очередь они не возвращаются: пост мог успеть выйти в соц сети
+func (s ScheduledPostsRepository) FailPublishingPosts(ctx context.Context, reason string) (int, error) {
+	result, err := s.db.NewUpdate().
+		Model((*model.ScheduledPost)(nil)).
+		Set("status = ?", model.ScheduledPostStatusFailed).
+		Set("error = ?", reason).
+		Where("status = ?", model.ScheduledPostStatusPublishing).
+		Exec(ctx)
+	if err != nil {
+		return 0, ewrap.Errorf("failed to fail publishing scheduled posts: %w", err)
+	}
+	failed, err := result.RowsAffected()
+	if err != nil {
+		return 0, ewrap.Errorf("failed to count failed scheduled posts: %w", err)
+	}
+	return int(failed), nil
+}
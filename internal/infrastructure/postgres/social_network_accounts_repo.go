@@ -16,6 +16,7 @@ type SocialNetworkAccountsRepository struct {
 }
 
 type FindSocialNetworkAccountQuery struct {
+	IDAnyOf            []int
 	SocialNetworkAnyOf []model.SocialNetworkName
 }
 
@@ -54,10 +55,22 @@ func (s SocialNetworkAccountsRepository) CreateAccount(
 
 func (s SocialNetworkAccountsRepository) FindAccounts(ctx context.Context, query FindSocialNetworkAccountQuery) ([]model.SocialNetworkAccount, error) {
 	var accountRows []model.SocialNetworkAccount
-	q := s.db.NewSelect().Model(&accountRows)
+	// Аккаунт с auth_account работает с credentials и токеном того аккаунта
+	q := s.db.NewSelect().
+		Model(&accountRows).
+		ColumnExpr(`"social_network_account"."id"`).
+		ColumnExpr(`"social_network_account"."social_network"`).
+		ColumnExpr(`"social_network_account"."auth_account"`).
+		ColumnExpr(`COALESCE("auth"."credentials", "social_network_account"."credentials") AS "credentials"`).
+		ColumnExpr(`COALESCE("auth"."access_token", "social_network_account"."access_token") AS "access_token"`).
+		Join(`LEFT JOIN "social_network_accounts" AS "auth" ON "auth"."id" = "social_network_account"."auth_account"`)
+
+	if len(query.IDAnyOf) != 0 {
+		q.Where(`"social_network_account"."id" IN (?)`, bun.In(query.IDAnyOf))
+	}
 
 	if len(query.SocialNetworkAnyOf) != 0 {
-		q.Where("social_network IN (?)", bun.In(query.SocialNetworkAnyOf))
+		q.Where(`"social_network_account"."social_network" IN (?)`, bun.In(query.SocialNetworkAnyOf))
 	}
 
 	if err := q.Scan(ctx); err != nil {
@@ -94,13 +107,13 @@ func (s SocialNetworkAccountsRepository) FindBySocialNetwork(
 	})
 
 	if err != nil {
-		if len(accounts) == 0 {
-			return nil, domain.NewNotFoundError(
-				fmt.Sprintf("social network %v accounts not found", socialNetwork),
-			)
-		}
 		return nil, err
 	}
+	if len(accounts) == 0 {
+		return nil, domain.NewNotFoundError(
+			fmt.Sprintf("social network %v accounts not found", socialNetwork),
+		)
+	}
 
 	return &accounts[0], nil
 }
@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"autoposting/internal/domain/model"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"github.com/uptrace/bun"
+)
+
+type SocialNetworkEventsRepository struct {
+	db *bun.DB
+}
+
+func NewSocialNetworkEventsRepository(db *bun.DB) *SocialNetworkEventsRepository {
+	return &SocialNetworkEventsRepository{
+		db: db,
+	}
+}
+
+// CreateEvents повторно доставленные события пропускаются, возвращается число сохраненных
+func (s SocialNetworkEventsRepository) CreateEvents(
+	ctx context.Context,
+	events []model.SocialNetworkEvent,
+) (int, error) {
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	res, err := s.db.NewInsert().
+		Model(&events).
+		On(`CONFLICT ON CONSTRAINT "SOCIAL_NETWORK_EVENTS_UNIQUE" DO NOTHING`).
+		// Пропущенные дубли не возвращают строк, RETURNING не сопоставить с моделями
+		Returning("NULL").
+		Exec(ctx)
+	if err != nil {
+		return 0, ewrap.Errorf("failed to create social network events: %w", err)
+	}
+
+	created, err := res.RowsAffected()
+	if err != nil {
+		return 0, ewrap.Errorf("failed to count created social network events: %w", err)
+	}
+	return int(created), nil
+}
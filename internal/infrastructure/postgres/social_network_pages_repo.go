@@ -16,6 +16,14 @@ type SocialNetworkPagesRepository struct {
 	db *bun.DB
 }
 
+type FindSocialNetworkPageQuery struct {
+	IDAnyOf []int
+	// PageIDAnyOf идентификаторы страниц в соц сети, имеют смысл вместе с SocialNetwork
+	PageIDAnyOf   []string
+	SocialNetwork model.SocialNetworkName
+	ProjectAnyOf  []string
+}
+
 func NewSocialNetworkPagesRepository(db *bun.DB) *SocialNetworkPagesRepository {
 	return &SocialNetworkPagesRepository{
 		db: db,
@@ -52,7 +60,37 @@ func (s SocialNetworkPagesRepository) CreatePage(
 	return nil
 }
 
-func (s SocialNetworkPagesRepository) FindPage() {
-	//TODO implement me
-	panic("implement me")
+func (s SocialNetworkPagesRepository) FindPages(
+	ctx context.Context,
+	query FindSocialNetworkPageQuery,
+) ([]model.SocialNetworkPage, error) {
+	var pageRows []model.SocialNetworkPage
+	q := s.db.NewSelect().Model(&pageRows)
+
+	if len(query.IDAnyOf) != 0 {
+		q.Where("id IN (?)", bun.In(query.IDAnyOf))
+	}
+	if len(query.PageIDAnyOf) != 0 {
+		q.Where("page_id IN (?)", bun.In(query.PageIDAnyOf))
+	}
+	if len(query.ProjectAnyOf) != 0 {
+		q.Where("project IN (?)", bun.In(query.ProjectAnyOf))
+	}
+	if query.SocialNetwork != "" {
+		q.Where(
+			"account_id IN (?)",
+			s.db.NewSelect().
+				Model((*model.SocialNetworkAccount)(nil)).
+				Column("id").
+				Where("social_network = ?", query.SocialNetwork),
+		)
+	}
+
+	if err := q.Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return pageRows, nil
+		}
+		return nil, ewrap.Errorf("failed to select social network pages: %w", err)
+	}
+	return pageRows, nil
 }
@@ -0,0 +1,212 @@
+package postgres
+
+import (
+	"autoposting/internal/domain"
+	"autoposting/internal/domain/model"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/uptrace/bun"
+	"time"
+)
+
+type WatchedPagesRepository struct {
+	db *bun.DB
+}
+
+type FindWatchedPagesQuery struct {
+	IDAnyOf      []int
+	ProjectAnyOf []string
+}
+
+// FindWatchedPagesStatsQuery фильтр по времени относится к снимкам подписчиков и к публикации постов
+type FindWatchedPagesStatsQuery struct {
+	WatchedPagesIDAnyOf []int
+	After               time.Time
+	Before              time.Time
+}
+
+func NewWatchedPagesRepository(db *bun.DB) *WatchedPagesRepository {
+	return &WatchedPagesRepository{
+		db: db,
+	}
+}
+
+func (w WatchedPagesRepository) CreateWatchedPage(
+	ctx context.Context,
+	watchedPage *model.WatchedPage,
+) error {
+	result, err := w.db.NewInsert().
+		Model(watchedPage).
+		On(`CONFLICT ON CONSTRAINT "WATCHED_PAGES_UNIQUE" DO NOTHING`).
+		Returning("id").
+		Exec(ctx)
+	if err != nil {
+		return ewrap.Errorf("failed to create watched page: %w", err)
+	}
+	if inserted, err := result.RowsAffected(); err == nil && inserted == 0 {
+		return domain.NewValidationError(
+			fmt.Sprintf(
+				"%s page %s is already watched by project %s",
+				watchedPage.SocialNetwork,
+				watchedPage.PageID,
+				watchedPage.Project,
+			),
+			"pageId",
+			"unique",
+		)
+	}
+	return nil
+}
+
+// DeleteWatchedPage снимки и посты страницы удаляются каскадно
+func (w WatchedPagesRepository) DeleteWatchedPage(ctx context.Context, id int) error {
+	result, err := w.db.NewDelete().
+		Model((*model.WatchedPage)(nil)).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return ewrap.Errorf("failed to delete watched page %d: %w", id, err)
+	}
+	if deleted, err := result.RowsAffected(); err == nil && deleted == 0 {
+		return domain.NewNotFoundError(fmt.Sprintf("watched page %d not found", id))
+	}
+	return nil
+}
+
+func (w WatchedPagesRepository) FindWatchedPages(
+	ctx context.Context,
+	query FindWatchedPagesQuery,
+) ([]model.WatchedPage, error) {
+	var watchedPageRows []model.WatchedPage
+	q := w.db.NewSelect().
+		Model(&watchedPageRows).
+		Order("id")
+
+	if len(query.IDAnyOf) != 0 {
+		q.Where("id IN (?)", bun.In(query.IDAnyOf))
+	}
+	if len(query.ProjectAnyOf) != 0 {
+		q.Where("project IN (?)", bun.In(query.ProjectAnyOf))
+	}
+
+	if err := q.Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return watchedPageRows, nil
+		}
+		return nil, ewrap.Errorf("failed to select watched pages: %w", err)
+	}
+	return watchedPageRows, nil
+}
+
+func (w WatchedPagesRepository) CreateWatchedPagesAudience(
+	ctx context.Context,
+	audience []model.WatchedPageAudience,
+) error {
+	if len(audience) == 0 {
+		return nil
+	}
+
+	_, err := w.db.NewInsert().
+		Model(&audience).
+		Returning("id").
+		Exec(ctx)
+	if err != nil {
+		return ewrap.Errorf("failed to create watched pages audience: %w", err)
+	}
+	return nil
+}
+
+// UpsertWatchedPagePosts новые посты добавляются, у известных обновляются счетчики
+func (w WatchedPagesRepository) UpsertWatchedPagePosts(
+	ctx context.Context,
+	posts []model.WatchedPagePost,
+) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	_, err := w.db.NewInsert().
+		Model(&posts).
+		On(`CONFLICT ON CONSTRAINT "WATCHED_PAGE_POSTS_UNIQUE" DO UPDATE`).
+		Set("likes = EXCLUDED.likes").
+		Set("reposts = EXCLUDED.reposts").
+		Set("comments = EXCLUDED.comments").
+		Set("views = EXCLUDED.views").
+		Set("collected_at = EXCLUDED.collected_at").
+		Returning("id").
+		Exec(ctx)
+	if err != nil {
+		return ewrap.Errorf("failed to upsert watched page posts: %w", err)
+	}
+	return nil
+}
+
+// FindDailyWatchedPagesAudience последний снимок каждой страницы за день, по страницам и от старых дней к новым
+func (w WatchedPagesRepository) FindDailyWatchedPagesAudience(
+	ctx context.Context,
+	query FindWatchedPagesStatsQuery,
+) ([]model.WatchedPageAudience, error) {
+	var audienceRows []model.WatchedPageAudience
+	q := w.db.NewSelect().
+		Model(&audienceRows).
+		DistinctOn("watched_page, " + pageAudienceDayExpr).
+		OrderExpr("watched_page, " + pageAudienceDayExpr + ", collected_at DESC")
+
+	if len(query.WatchedPagesIDAnyOf) != 0 {
+		q.Where("watched_page IN (?)", bun.In(query.WatchedPagesIDAnyOf))
+	}
+	if !query.After.IsZero() {
+		q.Where("collected_at >= ?", query.After)
+	}
+	if !query.Before.IsZero() {
+		q.Where("collected_at < ?", query.Before)
+	}
+
+	if err := q.Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return audienceRows, nil
+		}
+		return nil, ewrap.Errorf("failed to select watched pages audience: %w", err)
+	}
+	return audienceRows, nil
+}
+
+// AggregateWatchedPagePosts суммирует счетчики постов по страницам, Key - id отслеживаемой страницы
+func (w WatchedPagesRepository) AggregateWatchedPagePosts(
+	ctx context.Context,
+	query FindWatchedPagesStatsQuery,
+) ([]model.PostMetricsAggregate, error) {
+	var aggregates []model.PostMetricsAggregate
+	q := w.db.NewSelect().
+		Model((*model.WatchedPagePost)(nil)).
+		ColumnExpr("watched_page::text AS key").
+		ColumnExpr("count(*) AS posts").
+		ColumnExpr("sum(likes) AS likes").
+		ColumnExpr("sum(reposts) AS reposts").
+		ColumnExpr("sum(comments) AS comments").
+		ColumnExpr("sum(views) AS views").
+		ColumnExpr("0 AS reach").
+		GroupExpr("watched_page").
+		OrderExpr("watched_page")
+
+	if len(query.WatchedPagesIDAnyOf) != 0 {
+		q.Where("watched_page IN (?)", bun.In(query.WatchedPagesIDAnyOf))
+	}
+	if !query.After.IsZero() {
+		q.Where("published_at >= ?", query.After)
+	}
+	if !query.Before.IsZero() {
+		q.Where("published_at < ?", query.Before)
+	}
+
+	if err := q.Scan(ctx, &aggregates); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return aggregates, nil
+		}
+		return nil, ewrap.Errorf("failed to aggregate watched page posts: %w", err)
+	}
+	return aggregates, nil
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"autoposting/internal/domain/model"
+	ewrap "autoposting/pkg/err-wrapper"
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/uptrace/bun"
+)
+
+type WebhookDeliveriesRepository struct {
+	db *bun.DB
+}
+
+// FindWebhookDeliveriesQuery попытки отдаются от новых к старым
+type FindWebhookDeliveriesQuery struct {
+	PageAnyOf       []int
+	DeliveryIDAnyOf []string
+	Limit           int
+	Offset          int
+}
+
+func NewWebhookDeliveriesRepository(db *bun.DB) *WebhookDeliveriesRepository {
+	return &WebhookDeliveriesRepository{
+		db: db,
+	}
+}
+
+func (w WebhookDeliveriesRepository) CreateDeliveries(ctx context.Context, deliveries []model.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	_, err := w.db.NewInsert().
+		Model(&deliveries).
+		Exec(ctx)
+	if err != nil {
+		return ewrap.Errorf("failed to create webhook deliveries: %w", err)
+	}
+	return nil
+}
+
+func (w WebhookDeliveriesRepository) FindDeliveries(
+	ctx context.Context,
+	query FindWebhookDeliveriesQuery,
+) ([]model.WebhookDelivery, error) {
+	var deliveries []model.WebhookDelivery
+	q := w.db.NewSelect().
+		Model(&deliveries).
+		Order("delivery.attempted_at DESC", "delivery.id DESC")
+
+	if len(query.PageAnyOf) != 0 {
+		q.Where("delivery.page IN (?)", bun.In(query.PageAnyOf))
+	}
+	if len(query.DeliveryIDAnyOf) != 0 {
+		q.Where("delivery.delivery_id IN (?)", bun.In(query.DeliveryIDAnyOf))
+	}
+	if query.Limit > 0 {
+		q.Limit(query.Limit)
+	}
+	if query.Offset > 0 {
+		q.Offset(query.Offset)
+	}
+
+	if err := q.Scan(ctx); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return deliveries, nil
+		}
+		return nil, ewrap.Errorf("failed to select webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
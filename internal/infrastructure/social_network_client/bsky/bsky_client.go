@@ -0,0 +1,590 @@
+package bsky
+
+import (
+	"autoposting/internal/infrastructure/social_network_client"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ztrue/tracerr"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	bskyPostCollection = "app.bsky.feed.post"
+	// bskyBlobPlaceholder подставляется в dry-run вместо blob, который возвращает uploadBlob
+	bskyBlobPlaceholder = "{blob_%d}"
+	// bskyMentionPlaceholder подставляется в dry-run вместо DID упомянутого handle
+	bskyMentionPlaceholder = "{did:%s}"
+	bskyTimeout            = 30 * time.Second
+)
+
+type BSKYCredentials struct {
+	// Service адрес PDS аккаунта, по умолчанию адрес из настроек клиента
+	Service     string `json:"service"`
+	Identifier  string `json:"identifier"`
+	AppPassword string `json:"app_password"`
+}
+
+// bskySession сессия пароля приложения: accessJwt живет несколько часов, refreshJwt - месяцы
+type bskySession struct {
+	DID        string `json:"did"`
+	Handle     string `json:"handle"`
+	AccessJwt  string `json:"accessJwt"`
+	RefreshJwt string `json:"refreshJwt"`
+}
+
+// bskyXRPCError ошибка XRPC метода, ErrorCode - код ошибки, например ExpiredToken
+type bskyXRPCError struct {
+	StatusCode int
+	ErrorCode  string `json:"error"`
+	Message    string `json:"message"`
+}
+
+func (e *bskyXRPCError) Error() string {
+	return fmt.Sprintf("xrpc response status is %d: %s %s", e.StatusCode, e.ErrorCode, e.Message)
+}
+
+type bskyProfileResponse struct {
+	DID         string `json:"did"`
+	Handle      string `json:"handle"`
+	DisplayName string `json:"displayName"`
+	Description string `json:"description"`
+	Avatar      string `json:"avatar"`
+}
+
+type bskyResolveHandleResponse struct {
+	DID string `json:"did"`
+}
+
+type bskyUploadBlobResponse struct {
+	Blob json.RawMessage `json:"blob"`
+}
+
+type bskyCreateRecordRequest struct {
+	Repo       string     `json:"repo"`
+	Collection string     `json:"collection"`
+	Record     bskyRecord `json:"record"`
+}
+
+type bskyRecord struct {
+	Type      string      `json:"$type"`
+	Text      string      `json:"text"`
+	CreatedAt string      `json:"createdAt"`
+	Facets    []bskyFacet `json:"facets,omitempty"`
+	Embed     *bskyEmbed  `json:"embed,omitempty"`
+}
+
+type bskyEmbed struct {
+	Type   string           `json:"$type"`
+	Images []bskyEmbedImage `json:"images"`
+}
+
+type bskyEmbedImage struct {
+	Alt   string          `json:"alt"`
+	Image json.RawMessage `json:"image"`
+}
+
+type bskyCreateRecordResponse struct {
+	URI string `json:"uri"`
+	CID string `json:"cid"`
+}
+
+type bskyClient struct {
+	httpClient *http.Client
+	workApiUrl string
+
+	mu sync.Mutex
+	// sessions сессии по адресу PDS и identifier, чтобы не создавать сессию на каждый запрос
+	sessions map[string]*bskySession
+}
+
+func (b *bskyClient) GetAuthURL(credentials string) (string, error) {
+	return "", tracerr.Errorf("bluesky uses app password from credentials, oauth is not supported")
+}
+
+func (b *bskyClient) GetAccessToken(
+	credentials string,
+	queryParams map[string][]string,
+) (*social_network_client.AccessToken, error) {
+	return nil, tracerr.Errorf("bluesky uses app password from credentials, oauth is not supported")
+}
+
+func (b *bskyClient) GetAccountPages(
+	credentials string,
+	accessToken string,
+) ([]social_network_client.SocialNetworkPage, error) {
+	return social_network_client.GetAllAccountPages(b, credentials, accessToken)
+}
+
+// GetAccountPagesChunk страница - handle аккаунта, которому принадлежит пароль приложения.
+// Токены Bluesky клиент хранит сам, accessToken не используется
+func (b *bskyClient) GetAccountPagesChunk(
+	credentials string,
+	accessToken string,
+	cursor social_network_client.PagesCursor,
+) (*social_network_client.SocialNetworkPagesChunk, error) {
+	var data bskyProfileResponse
+
+	bskyCredentials, err := b.stringToBSKYCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := b.getSession(bskyCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	err = b.doAuthorized(bskyCredentials, &data, func(accessJwt string) (*http.Request, error) {
+		return b.newQueryRequest(bskyCredentials, accessJwt, "app.bsky.actor.getProfile", url.Values{
+			"actor": []string{session.DID},
+		})
+	})
+	if err != nil {
+		return nil, tracerr.Errorf("cannot get bluesky profile %s:\n%s", session.Handle, err)
+	}
+
+	name := data.DisplayName
+	if name == "" {
+		name = data.Handle
+	}
+
+	return &social_network_client.SocialNetworkPagesChunk{
+		Pages: []social_network_client.SocialNetworkPage{{
+			ID:          data.DID,
+			Name:        name,
+			Description: data.Description,
+			Image:       data.Avatar,
+		}},
+	}, nil
+}
+
+// CreatePost загружает изображения через uploadBlob и создает запись app.bsky.feed.post в репозитории did
+func (b *bskyClient) CreatePost(
+	ctx context.Context,
+	credentials string,
+	accessToken string,
+	did string,
+	post social_network_client.Post,
+) (string, error) {
+	var data bskyCreateRecordResponse
+
+	bskyCredentials, err := b.stringToBSKYCredentials(credentials)
+	if err != nil {
+		return "", err
+	}
+
+	var blobs []json.RawMessage
+	for _, image := range post.Images {
+		blob, err := b.uploadBlob(bskyCredentials, image)
+		if err != nil {
+			return "", err
+		}
+		blobs = append(blobs, blob)
+	}
+
+	record := newPostRecord(post, blobs, func(handle string) (string, error) {
+		return b.resolveHandle(bskyCredentials, handle)
+	})
+	err = b.doAuthorized(bskyCredentials, &data, func(accessJwt string) (*http.Request, error) {
+		return b.newProcedureRequest(bskyCredentials, accessJwt, "com.atproto.repo.createRecord", bskyCreateRecordRequest{
+			Repo:       did,
+			Collection: bskyPostCollection,
+			Record:     record,
+		})
+	})
+	if err != nil {
+		return "", tracerr.Errorf("cannot create bluesky post:\n%s", err)
+	}
+
+	return data.URI, nil
+}
+
+// PreparePost собирает запросы uploadBlob и createRecord, тела uploadBlob заменены ссылками на изображения,
+// blob в записи - заглушками. Сеть не используется, поэтому вместо DID упоминаний тоже заглушки
+func (b *bskyClient) PreparePost(
+	credentials string,
+	accessToken string,
+	dids []string,
+	post social_network_client.Post,
+) ([]social_network_client.PreparedRequest, error) {
+	bskyCredentials, err := b.stringToBSKYCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	var preparedRequests []social_network_client.PreparedRequest
+	for _, did := range dids {
+		var (
+			requests []*http.Request
+			blobs    []json.RawMessage
+		)
+		for index, image := range post.Images {
+			req, err := http.NewRequest(
+				"POST",
+				fmt.Sprintf("%s/xrpc/com.atproto.repo.uploadBlob", bskyCredentials.Service),
+				strings.NewReader(image),
+			)
+			if err != nil {
+				return nil, tracerr.Errorf("cannot create upload blob request:\n%s", err)
+			}
+			req.Header.Set("Content-Type", "text/uri-list")
+			setAuthorization(req, accessToken)
+			requests = append(requests, req)
+
+			blob, _ := json.Marshal(fmt.Sprintf(bskyBlobPlaceholder, index+1))
+			blobs = append(blobs, blob)
+		}
+
+		req, err := b.newProcedureRequest(bskyCredentials, accessToken, "com.atproto.repo.createRecord", bskyCreateRecordRequest{
+			Repo:       did,
+			Collection: bskyPostCollection,
+			Record: newPostRecord(post, blobs, func(handle string) (string, error) {
+				return fmt.Sprintf(bskyMentionPlaceholder, handle), nil
+			}),
+		})
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, req)
+
+		for _, req := range requests {
+			preparedRequest, err := social_network_client.NewPreparedRequest([]string{did}, req)
+			if err != nil {
+				return nil, err
+			}
+			preparedRequests = append(preparedRequests, preparedRequest)
+		}
+	}
+
+	return preparedRequests, nil
+}
+
+// DeletePost удаляет запись по at:// uri, который вернул CreatePost
+func (b *bskyClient) DeletePost(credentials, accessToken, postURI string) error {
+	bskyCredentials, err := b.stringToBSKYCredentials(credentials)
+	if err != nil {
+		return err
+	}
+
+	// at://{did}/{collection}/{rkey}
+	parts := strings.Split(strings.TrimPrefix(postURI, "at://"), "/")
+	if !strings.HasPrefix(postURI, "at://") || len(parts) != 3 {
+		return tracerr.Errorf("invalid bluesky post uri %s", postURI)
+	}
+
+	err = b.doAuthorized(bskyCredentials, nil, func(accessJwt string) (*http.Request, error) {
+		return b.newProcedureRequest(bskyCredentials, accessJwt, "com.atproto.repo.deleteRecord", map[string]string{
+			"repo":       parts[0],
+			"collection": parts[1],
+			"rkey":       parts[2],
+		})
+	})
+	if err != nil {
+		return tracerr.Errorf("cannot delete bluesky post %s:\n%s", postURI, err)
+	}
+
+	return nil
+}
+
+func (b *bskyClient) Capabilities() social_network_client.Capabilities {
+	return capabilities
+}
+
+// ComposeText текст записи со ссылкой
+func (b *bskyClient) ComposeText(post social_network_client.Post) string {
+	return bskyPostText(post)
+}
+
+// bskyPostText ссылка, которой нет в тексте, дописывается в конец, чтобы получить facet
+func bskyPostText(post social_network_client.Post) string {
+	if post.Link != "" && !strings.Contains(post.Text, post.Link) {
+		return strings.TrimSpace(post.Text + "\n\n" + post.Link)
+	}
+	return post.Text
+}
+
+func newPostRecord(
+	post social_network_client.Post,
+	blobs []json.RawMessage,
+	resolveHandle func(handle string) (string, error),
+) bskyRecord {
+	text := bskyPostText(post)
+
+	record := bskyRecord{
+		Type:      bskyPostCollection,
+		Text:      text,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339Nano),
+		Facets:    bskyFacets(text, resolveHandle),
+	}
+	if len(blobs) > 0 {
+		record.Embed = &bskyEmbed{
+			Type: "app.bsky.embed.images",
+		}
+		for index, blob := range blobs {
+			image := bskyEmbedImage{Image: blob}
+			if index < len(post.ImagesAlt) {
+				image.Alt = post.ImagesAlt[index]
+			}
+			record.Embed.Images = append(record.Embed.Images, image)
+		}
+	}
+
+	return record
+}
+
+// uploadBlob скачивает изображение по ссылке и загружает его в PDS
+func (b *bskyClient) uploadBlob(bskyCredentials *BSKYCredentials, imageURL string) (json.RawMessage, error) {
+	var data bskyUploadBlobResponse
+
+	resp, err := b.httpClient.Get(imageURL)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot download image %s:\n%s", imageURL, err)
+	}
+	image, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, tracerr.Errorf("cannot read image %s:\n%s", imageURL, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, tracerr.Errorf("download image %s response status is %d", imageURL, resp.StatusCode)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(image)
+	}
+
+	err = b.doAuthorized(bskyCredentials, &data, func(accessJwt string) (*http.Request, error) {
+		req, err := http.NewRequest(
+			"POST",
+			fmt.Sprintf("%s/xrpc/com.atproto.repo.uploadBlob", bskyCredentials.Service),
+			bytes.NewReader(image),
+		)
+		if err != nil {
+			return nil, tracerr.Errorf("cannot create upload blob request:\n%s", err)
+		}
+		req.Header.Set("Content-Type", contentType)
+		setAuthorization(req, accessJwt)
+		return req, nil
+	})
+	if err != nil {
+		return nil, tracerr.Errorf("cannot upload image %s:\n%s", imageURL, err)
+	}
+
+	return data.Blob, nil
+}
+
+func (b *bskyClient) resolveHandle(bskyCredentials *BSKYCredentials, handle string) (string, error) {
+	var data bskyResolveHandleResponse
+
+	req, err := b.newQueryRequest(bskyCredentials, "", "com.atproto.identity.resolveHandle", url.Values{
+		"handle": []string{handle},
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := b.doXRPC(req, &data); err != nil {
+		return "", err
+	}
+
+	return data.DID, nil
+}
+
+// getSession сессия из кэша, при ее отсутствии создается новая по паролю приложения
+func (b *bskyClient) getSession(bskyCredentials *BSKYCredentials) (*bskySession, error) {
+	b.mu.Lock()
+	session, ok := b.sessions[sessionKey(bskyCredentials)]
+	b.mu.Unlock()
+	if ok {
+		return session, nil
+	}
+
+	return b.createSession(bskyCredentials)
+}
+
+func (b *bskyClient) createSession(bskyCredentials *BSKYCredentials) (*bskySession, error) {
+	var session bskySession
+
+	req, err := b.newProcedureRequest(bskyCredentials, "", "com.atproto.server.createSession", map[string]string{
+		"identifier": bskyCredentials.Identifier,
+		"password":   bskyCredentials.AppPassword,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := b.doXRPC(req, &session); err != nil {
+		return nil, tracerr.Errorf("cannot create bluesky session for %s:\n%s", bskyCredentials.Identifier, err)
+	}
+
+	b.saveSession(bskyCredentials, &session)
+	return &session, nil
+}
+
+// refreshSession обновляет истекший accessJwt, если истек и refreshJwt - создает новую сессию
+func (b *bskyClient) refreshSession(bskyCredentials *BSKYCredentials, expired *bskySession) (*bskySession, error) {
+	var session bskySession
+
+	req, err := http.NewRequest(
+		"POST",
+		fmt.Sprintf("%s/xrpc/com.atproto.server.refreshSession", bskyCredentials.Service),
+		nil,
+	)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot create refresh session request:\n%s", err)
+	}
+	setAuthorization(req, expired.RefreshJwt)
+	if err := b.doXRPC(req, &session); err != nil {
+		return b.createSession(bskyCredentials)
+	}
+
+	b.saveSession(bskyCredentials, &session)
+	return &session, nil
+}
+
+func (b *bskyClient) saveSession(bskyCredentials *BSKYCredentials, session *bskySession) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.sessions[sessionKey(bskyCredentials)] = session
+}
+
+// doAuthorized выполняет запрос с accessJwt сессии и повторяет его один раз после обновления истекшей сессии
+func (b *bskyClient) doAuthorized(
+	bskyCredentials *BSKYCredentials,
+	v interface{},
+	newRequest func(accessJwt string) (*http.Request, error),
+) error {
+	session, err := b.getSession(bskyCredentials)
+	if err != nil {
+		return err
+	}
+
+	req, err := newRequest(session.AccessJwt)
+	if err != nil {
+		return err
+	}
+	err = b.doXRPC(req, v)
+	xrpcErr, ok := err.(*bskyXRPCError)
+	if !ok || (xrpcErr.ErrorCode != "ExpiredToken" && xrpcErr.ErrorCode != "InvalidToken") {
+		return err
+	}
+
+	session, err = b.refreshSession(bskyCredentials, session)
+	if err != nil {
+		return err
+	}
+	req, err = newRequest(session.AccessJwt)
+	if err != nil {
+		return err
+	}
+	return b.doXRPC(req, v)
+}
+
+func (b *bskyClient) newQueryRequest(
+	bskyCredentials *BSKYCredentials,
+	accessJwt string,
+	nsid string,
+	params url.Values,
+) (*http.Request, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/xrpc/%s", bskyCredentials.Service, nsid), nil)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot create %s request:\n%s", nsid, err)
+	}
+	req.URL.RawQuery = params.Encode()
+	if accessJwt != "" {
+		setAuthorization(req, accessJwt)
+	}
+
+	return req, nil
+}
+
+func (b *bskyClient) newProcedureRequest(
+	bskyCredentials *BSKYCredentials,
+	accessJwt string,
+	nsid string,
+	input interface{},
+) (*http.Request, error) {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot marshal %s input:\n%s", nsid, err)
+	}
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/xrpc/%s", bskyCredentials.Service, nsid), bytes.NewReader(body))
+	if err != nil {
+		return nil, tracerr.Errorf("cannot create %s request:\n%s", nsid, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if accessJwt != "" {
+		setAuthorization(req, accessJwt)
+	}
+
+	return req, nil
+}
+
+// doXRPC ошибки XRPC возвращаются как *bskyXRPCError без обертки, чтобы doAuthorized распознал истекший токен
+func (b *bskyClient) doXRPC(req *http.Request, v interface{}) error {
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return tracerr.Errorf("cannot do xrpc request:\n%s", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return tracerr.Errorf("cannot read xrpc response:\n%s", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		xrpcErr := &bskyXRPCError{StatusCode: resp.StatusCode}
+		_ = json.Unmarshal(respBody, xrpcErr)
+		return xrpcErr
+	}
+
+	if v == nil {
+		return nil
+	}
+	err = json.Unmarshal(respBody, v)
+	if err != nil {
+		return tracerr.Errorf("cannot unmarshal xrpc response body:\n%s", err)
+	}
+
+	return nil
+}
+
+func setAuthorization(req *http.Request, token string) {
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+}
+
+func sessionKey(bskyCredentials *BSKYCredentials) string {
+	return bskyCredentials.Service + "|" + bskyCredentials.Identifier
+}
+
+func (b *bskyClient) stringToBSKYCredentials(credentials string) (*BSKYCredentials, error) {
+	bskyCredentials := &BSKYCredentials{}
+	err := json.Unmarshal([]byte(credentials), bskyCredentials)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot unmarshal bsky credentials:\n%s", err)
+	}
+	if bskyCredentials.Identifier == "" || bskyCredentials.AppPassword == "" {
+		return nil, tracerr.Errorf("bsky credentials must contain identifier and app_password")
+	}
+	if bskyCredentials.Service == "" {
+		bskyCredentials.Service = b.workApiUrl
+	}
+	bskyCredentials.Service = strings.TrimSuffix(bskyCredentials.Service, "/")
+	return bskyCredentials, nil
+}
+
+func NewBSKYClient(config social_network_client.ClientConfig) social_network_client.SocialNetworkClient {
+	return &bskyClient{
+		httpClient: &http.Client{Timeout: bskyTimeout},
+		workApiUrl: config.WorkApiUrl,
+		sessions:   map[string]*bskySession{},
+	}
+}
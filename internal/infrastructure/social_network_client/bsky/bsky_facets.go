@@ -0,0 +1,77 @@
+package bsky
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	bskyLinkRegexp = regexp.MustCompile(`https?://[^\s<>"]+`)
+	// bskyMentionRegexp упоминание @handle, где handle - доменное имя
+	bskyMentionRegexp = regexp.MustCompile(
+		`(?:^|[\s(])(@([a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)+))`,
+	)
+)
+
+const (
+	bskyFacetLinkType    = "app.bsky.richtext.facet#link"
+	bskyFacetMentionType = "app.bsky.richtext.facet#mention"
+)
+
+// bskyFacet разметка фрагмента текста, границы - смещения в байтах UTF-8
+type bskyFacet struct {
+	Index    bskyFacetIndex     `json:"index"`
+	Features []bskyFacetFeature `json:"features"`
+}
+
+type bskyFacetIndex struct {
+	ByteStart int `json:"byteStart"`
+	ByteEnd   int `json:"byteEnd"`
+}
+
+type bskyFacetFeature struct {
+	Type string `json:"$type"`
+	URI  string `json:"uri,omitempty"`
+	DID  string `json:"did,omitempty"`
+}
+
+// bskyFacets находит в тексте ссылки и упоминания. Упоминания, handle которых resolveHandle
+// не нашел, остаются простым текстом
+func bskyFacets(text string, resolveHandle func(handle string) (string, error)) []bskyFacet {
+	var facets []bskyFacet
+
+	// Индексы regexp в Go - байтовые смещения, они и нужны Bluesky
+	for _, match := range bskyLinkRegexp.FindAllStringIndex(text, -1) {
+		link := strings.TrimRight(text[match[0]:match[1]], ".,;:!?)'")
+		facets = append(facets, bskyFacet{
+			Index: bskyFacetIndex{
+				ByteStart: match[0],
+				ByteEnd:   match[0] + len(link),
+			},
+			Features: []bskyFacetFeature{{
+				Type: bskyFacetLinkType,
+				URI:  link,
+			}},
+		})
+	}
+
+	for _, match := range bskyMentionRegexp.FindAllStringSubmatchIndex(text, -1) {
+		handle := strings.ToLower(text[match[4]:match[5]])
+		did, err := resolveHandle(handle)
+		if err != nil || did == "" {
+			continue
+		}
+		facets = append(facets, bskyFacet{
+			Index: bskyFacetIndex{
+				ByteStart: match[2],
+				ByteEnd:   match[3],
+			},
+			Features: []bskyFacetFeature{{
+				Type: bskyFacetMentionType,
+				DID:  did,
+			}},
+		})
+	}
+
+	return facets
+}
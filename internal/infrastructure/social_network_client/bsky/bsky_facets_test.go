@@ -0,0 +1,102 @@
+package bsky
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestBSKYFacetsByteOffsets(t *testing.T) {
+	dids := map[string]string{
+		"alice.bsky.social": "did:plc:alice",
+		"bob.test":          "did:plc:bob",
+	}
+	resolveHandle := func(handle string) (string, error) {
+		did, ok := dids[handle]
+		if !ok {
+			return "", errors.New("handle not found")
+		}
+		return did, nil
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want []bskyFacet
+	}{
+		{
+			name: "ascii link",
+			text: "see https://example.com now",
+			want: []bskyFacet{linkFacet(4, 23, "https://example.com")},
+		},
+		{
+			// Кириллическая буква занимает 2 байта
+			name: "link after cyrillic",
+			text: "Привет https://example.com",
+			want: []bskyFacet{linkFacet(13, 32, "https://example.com")},
+		},
+		{
+			name: "cyrillic path without trailing dot",
+			text: "Смотри: https://example.com/путь.",
+			want: []bskyFacet{linkFacet(14, 42, "https://example.com/путь")},
+		},
+		{
+			// Эмодзи занимает 4 байта
+			name: "mention after emoji",
+			text: "🎉 @alice.bsky.social hi",
+			want: []bskyFacet{mentionFacet(5, 23, "did:plc:alice")},
+		},
+		{
+			name: "mention in parentheses after cyrillic and emoji",
+			text: "Спасибо 🙏 (@Bob.test)",
+			want: []bskyFacet{mentionFacet(21, 30, "did:plc:bob")},
+		},
+		{
+			name: "unresolved mention stays text",
+			text: "привет @nobody.example",
+			want: nil,
+		},
+		{
+			name: "email is not a mention",
+			text: "mail me at bob@bob.test",
+			want: nil,
+		},
+		{
+			name: "link and mention",
+			text: "Новость 🔥 https://example.com/a?b=1 от @bob.test",
+			want: []bskyFacet{
+				linkFacet(20, 45, "https://example.com/a?b=1"),
+				mentionFacet(51, 60, "did:plc:bob"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := bskyFacets(tt.text, resolveHandle)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got facets %+v, want %+v", got, tt.want)
+			}
+			for _, facet := range got {
+				fragment := tt.text[facet.Index.ByteStart:facet.Index.ByteEnd]
+				if feature := facet.Features[0]; feature.Type == bskyFacetLinkType && fragment != feature.URI {
+					t.Errorf("link facet covers %q, want %q", fragment, feature.URI)
+				}
+			}
+		})
+	}
+}
+
+func linkFacet(byteStart, byteEnd int, uri string) bskyFacet {
+	return bskyFacet{
+		Index:    bskyFacetIndex{ByteStart: byteStart, ByteEnd: byteEnd},
+		Features: []bskyFacetFeature{{Type: bskyFacetLinkType, URI: uri}},
+	}
+}
+
+func mentionFacet(byteStart, byteEnd int, did string) bskyFacet {
+	return bskyFacet{
+		Index:    bskyFacetIndex{ByteStart: byteStart, ByteEnd: byteEnd},
+		Features: []bskyFacetFeature{{Type: bskyFacetMentionType, DID: did}},
+	}
+}
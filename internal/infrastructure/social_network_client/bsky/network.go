@@ -0,0 +1,27 @@
+package bsky
+
+import "autoposting/internal/infrastructure/social_network_client"
+
+const Name = "BSKY"
+
+var capabilities = social_network_client.Capabilities{
+	MaxTextLength: 300,
+	Images:        true,
+	MaxImages:     4,
+	Video:         false,
+	Links:         true,
+	Polls:         false,
+}
+
+func init() {
+	// Bluesky авторизуется паролем приложения из credentials, OAuth не используется
+	social_network_client.Register(social_network_client.Network{
+		Name:         Name,
+		DisplayName:  "Bluesky",
+		Capabilities: capabilities,
+		DefaultConfig: social_network_client.ClientConfig{
+			WorkApiUrl: "https://bsky.social",
+		},
+		NewClient: NewBSKYClient,
+	})
+}
@@ -0,0 +1,167 @@
+package social_network_client_test
+
+import (
+	"autoposting/internal/infrastructure/social_network_client"
+	"autoposting/internal/infrastructure/social_network_client/fb"
+	"autoposting/internal/infrastructure/social_network_client/ok"
+	"autoposting/internal/infrastructure/social_network_client/vk"
+	"autoposting/pkg/fakesn"
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// TestClientsAgainstFakeSN проходит авторизацию, получение страниц и публикацию через эмулятор соц сетей
+func TestClientsAgainstFakeSN(t *testing.T) {
+	server, testServer := fakesn.NewTestServer(fakesn.Options{TokenTTL: time.Hour})
+	defer testServer.Close()
+
+	env := fakesn.Env(testServer.URL)
+	tests := []struct {
+		network string
+		client  social_network_client.SocialNetworkClient
+	}{
+		{
+			network: vk.Name,
+			client: vk.NewVKClient(social_network_client.ClientConfig{
+				AuthApiUrl:  env["VK_AUTH_API_URL"],
+				WorkApiUrl:  env["VK_API_URL"],
+				ApiVersion:  "5.131",
+				RedirectUrl: "http://localhost/callback",
+			}),
+		},
+		{
+			network: ok.Name,
+			client: ok.NewOKClient(social_network_client.ClientConfig{
+				AuthApiUrl:  env["OK_AUTH_API_URL"],
+				WorkApiUrl:  env["OK_API_URL"],
+				RedirectUrl: "http://localhost/callback",
+			}),
+		},
+		{
+			network: fb.Name,
+			client: fb.NewFBClient(social_network_client.ClientConfig{
+				AuthApiUrl:  env["FB_AUTH_API_URL"],
+				WorkApiUrl:  env["FB_API_URL"],
+				ApiVersion:  "v16.0",
+				RedirectUrl: "http://localhost/callback",
+			}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.network, func(t *testing.T) {
+			token := authorize(t, tt.client)
+
+			pages, err := tt.client.GetAccountPages(testCredentials, token.Token)
+			if err != nil {
+				t.Fatalf("GetAccountPages: %v", err)
+			}
+			if len(pages) != len(server.Groups(tt.network)) {
+				t.Fatalf("got %d pages, want %d", len(pages), len(server.Groups(tt.network)))
+			}
+
+			pageAccessToken := token.Token
+			if pages[0].AccessToken != nil {
+				pageAccessToken = pages[0].AccessToken.Token
+			}
+			postID, err := tt.client.CreatePost(context.Background(), testCredentials, pageAccessToken, pages[0].ID, social_network_client.Post{
+				Text: "hello from " + tt.network,
+			})
+			if err != nil {
+				t.Fatalf("CreatePost: %v", err)
+			}
+
+			posts := server.Posts(tt.network, pages[0].ID)
+			if len(posts) != 1 || posts[0].ID != postID || posts[0].Text != "hello from "+tt.network {
+				t.Fatalf("got posts %+v, want one post %s", posts, postID)
+			}
+		})
+	}
+}
+
+// TestVKCreatePostsKeepsPublishedChunks посты порций execute, выполненных до ошибки, остаются успешными
+func TestVKCreatePostsKeepsPublishedChunks(t *testing.T) {
+	server, testServer := fakesn.NewTestServer(fakesn.Options{GroupsPerNetwork: 30})
+	defer testServer.Close()
+
+	env := fakesn.Env(testServer.URL)
+	client := vk.NewVKClient(social_network_client.ClientConfig{
+		AuthApiUrl:  env["VK_AUTH_API_URL"],
+		WorkApiUrl:  env["VK_API_URL"],
+		ApiVersion:  "5.131",
+		RedirectUrl: "http://localhost/callback",
+	})
+	token := authorize(t, client)
+
+	var groupIDs []string
+	for _, group := range server.Groups(fakesn.VK) {
+		groupIDs = append(groupIDs, group.ID)
+	}
+	server.InjectFault(fakesn.Fault{
+		Network: fakesn.VK,
+		Method:  "execute",
+		Kind:    fakesn.FaultServerError,
+		Times:   1,
+		After:   1,
+	})
+
+	results, err := client.(social_network_client.BatchPostCreator).CreatePosts(
+		context.Background(),
+		testCredentials,
+		token.Token,
+		groupIDs,
+		social_network_client.Post{Text: "batch"},
+	)
+	if err != nil {
+		t.Fatalf("CreatePosts: %v", err)
+	}
+	if len(results) != len(groupIDs) {
+		t.Fatalf("got %d results, want %d", len(results), len(groupIDs))
+	}
+	for i, result := range results {
+		published := len(server.Posts(fakesn.VK, result.PageID)) == 1
+		// Первая порция execute - 25 вызовов wall.post
+		if i < 25 && (result.Err != nil || result.PostID == "" || !published) {
+			t.Errorf("group %s: got err %v, post %q, published %v, want published post", result.PageID, result.Err, result.PostID, published)
+		}
+		if i >= 25 && (result.Err == nil || published) {
+			t.Errorf("group %s: got err %v, published %v, want error", result.PageID, result.Err, published)
+		}
+	}
+}
+
+const testCredentials = `{"app_id":"1","client_secret":"secret","secret_key":"secret","public_key":"public"}`
+
+// authorize проходит OAuth эмулятора: он сразу редиректит на RedirectUrl с кодом, сам редирект не выполняется
+func authorize(t *testing.T, client social_network_client.SocialNetworkClient) *social_network_client.AccessToken {
+	t.Helper()
+
+	httpClient := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	authURL, err := client.GetAuthURL(testCredentials)
+	if err != nil {
+		t.Fatalf("GetAuthURL: %v", err)
+	}
+	resp, err := httpClient.Get(authURL)
+	if err != nil {
+		t.Fatalf("open auth url: %v", err)
+	}
+	resp.Body.Close()
+	redirect, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("parse redirect: %v", err)
+	}
+
+	token, err := client.GetAccessToken(testCredentials, redirect.Query())
+	if err != nil {
+		t.Fatalf("GetAccessToken: %v", err)
+	}
+	return token
+}
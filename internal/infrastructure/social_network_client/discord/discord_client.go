@@ -0,0 +1,286 @@
+package discord
+
+import (
+	"autoposting/internal/infrastructure/social_network_client"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ztrue/tracerr"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+const discordTimeout = 10 * time.Second
+
+// DiscordCredentials адрес входящего вебхука, которым публикуются посты аккаунта
+type DiscordCredentials struct {
+	WebhookURL string `json:"webhook_url"`
+}
+
+type discordWebhookResponse struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	ChannelID string `json:"channel_id"`
+	GuildID   string `json:"guild_id"`
+}
+
+type discordMessage struct {
+	Content string         `json:"content,omitempty"`
+	Embeds  []discordEmbed `json:"embeds,omitempty"`
+}
+
+type discordEmbed struct {
+	Description string             `json:"description,omitempty"`
+	Image       *discordEmbedImage `json:"image,omitempty"`
+}
+
+type discordEmbedImage struct {
+	URL string `json:"url"`
+}
+
+type discordMessageResponse struct {
+	ID string `json:"id"`
+}
+
+// discordClient публикует посты через входящие вебхуки Discord. Вебхук аккаунта задается в credentials,
+// вебхуки других каналов - токенами их страниц. Вебхук пишет только в свой канал, поэтому страница - один вебхук
+type discordClient struct {
+	httpClient *http.Client
+}
+
+func (d *discordClient) GetAuthURL(credentials string) (string, error) {
+	return "", tracerr.Errorf("discord webhooks do not use oauth")
+}
+
+func (d *discordClient) GetAccessToken(
+	credentials string,
+	queryParams map[string][]string,
+) (*social_network_client.AccessToken, error) {
+	return nil, tracerr.Errorf("discord webhooks do not use oauth")
+}
+
+func (d *discordClient) GetAccountPages(
+	credentials string,
+	accessToken string,
+) ([]social_network_client.SocialNetworkPage, error) {
+	return social_network_client.GetAllAccountPages(d, credentials, accessToken)
+}
+
+// GetAccountPagesChunk страница - канал, в который пишет вебхук из credentials
+func (d *discordClient) GetAccountPagesChunk(
+	credentials string,
+	accessToken string,
+	cursor social_network_client.PagesCursor,
+) (*social_network_client.SocialNetworkPagesChunk, error) {
+	webhookURL, err := getWebhookURL(credentials, "")
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := d.getWebhook(context.Background(), webhookURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &social_network_client.SocialNetworkPagesChunk{
+		Pages: []social_network_client.SocialNetworkPage{{
+			ID:   data.ChannelID,
+			Name: data.Name,
+		}},
+	}, nil
+}
+
+// CreatePost отправляет пост embed-ом с изображением, ссылка идет в content, чтобы Discord ее развернул.
+// Пост в канал, в который вебхук страницы не пишет, отклоняется до отправки
+func (d *discordClient) CreatePost(
+	ctx context.Context,
+	credentials string,
+	accessToken string,
+	channelID string,
+	post social_network_client.Post,
+) (string, error) {
+	var data discordMessageResponse
+
+	webhookURL, err := getWebhookURL(credentials, accessToken)
+	if err != nil {
+		return "", err
+	}
+	webhook, err := d.getWebhook(ctx, webhookURL)
+	if err != nil {
+		return "", err
+	}
+	if webhook.ChannelID != channelID {
+		return "", tracerr.Errorf("discord webhook posts to channel %s, not to channel %s", webhook.ChannelID, channelID)
+	}
+
+	req, err := d.newCreatePostRequest(credentials, accessToken, post)
+	if err != nil {
+		return "", err
+	}
+	if err := d.doJSONRequest(req.WithContext(ctx), &data); err != nil {
+		return "", tracerr.Errorf("cannot execute discord webhook:\n%s", err)
+	}
+
+	return data.ID, nil
+}
+
+func (d *discordClient) PreparePost(
+	credentials string,
+	accessToken string,
+	channelIDs []string,
+	post social_network_client.Post,
+) ([]social_network_client.PreparedRequest, error) {
+	requests := make([]social_network_client.PreparedRequest, 0, len(channelIDs))
+	for _, channelID := range channelIDs {
+		req, err := d.newCreatePostRequest(credentials, accessToken, post)
+		if err != nil {
+			return nil, err
+		}
+		preparedRequest, err := social_network_client.NewPreparedRequest([]string{channelID}, req)
+		if err != nil {
+			return nil, err
+		}
+		preparedRequest.Secrets = []string{webhookToken(req.URL)}
+		requests = append(requests, preparedRequest)
+	}
+
+	return requests, nil
+}
+
+func (d *discordClient) DeletePost(credentials, accessToken, messageID string) error {
+	webhookURL, err := getWebhookURL(credentials, accessToken)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("DELETE", fmt.Sprintf("%s/messages/%s", webhookURL, url.PathEscape(messageID)), nil)
+	if err != nil {
+		return tracerr.Errorf("cannot create delete message request:\n%s", err)
+	}
+	if err := d.doJSONRequest(req, nil); err != nil {
+		return tracerr.Errorf("cannot delete discord message %s:\n%s", messageID, err)
+	}
+
+	return nil
+}
+
+func (d *discordClient) Capabilities() social_network_client.Capabilities {
+	return capabilities
+}
+
+// getWebhook канал, в который пишет вебхук
+func (d *discordClient) getWebhook(ctx context.Context, webhookURL string) (*discordWebhookResponse, error) {
+	var data discordWebhookResponse
+
+	req, err := http.NewRequestWithContext(ctx, "GET", webhookURL, nil)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot create get webhook request:\n%s", err)
+	}
+	if err := d.doJSONRequest(req, &data); err != nil {
+		return nil, tracerr.Errorf("cannot get discord webhook:\n%s", err)
+	}
+
+	return &data, nil
+}
+
+func (d *discordClient) newCreatePostRequest(
+	credentials string,
+	accessToken string,
+	post social_network_client.Post,
+) (*http.Request, error) {
+	webhookURL, err := getWebhookURL(credentials, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	message := discordMessage{
+		Content: post.Link,
+	}
+	embed := discordEmbed{
+		Description: post.Text,
+	}
+	if len(post.Images) > 0 {
+		embed.Image = &discordEmbedImage{URL: post.Images[0]}
+	}
+	if embed.Description != "" || embed.Image != nil {
+		message.Embeds = append(message.Embeds, embed)
+	}
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot marshal discord message:\n%s", err)
+	}
+
+	// wait=true, чтобы Discord вернул созданное сообщение с идентификатором
+	req, err := http.NewRequest("POST", webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, tracerr.Errorf("cannot create execute webhook request:\n%s", err)
+	}
+	req.URL.RawQuery = url.Values{"wait": []string{"true"}}.Encode()
+	req.Header.Set("Content-Type", "application/json")
+
+	return req, nil
+}
+
+func (d *discordClient) doJSONRequest(req *http.Request, v interface{}) error {
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return tracerr.Errorf("cannot read response:\n%s", err)
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return tracerr.Errorf("response status is %d\nresponse:%s", resp.StatusCode, string(respBody))
+	}
+
+	if v == nil {
+		return nil
+	}
+	err = json.Unmarshal(respBody, v)
+	if err != nil {
+		return tracerr.Errorf("cannot unmarshal response body:\n%s", err)
+	}
+
+	return nil
+}
+
+// getWebhookURL вебхук страницы приоритетнее вебхука аккаунта
+func getWebhookURL(credentials, accessToken string) (string, error) {
+	webhookURL := strings.TrimSpace(accessToken)
+	if webhookURL == "" {
+		discordCredentials := &DiscordCredentials{}
+		err := json.Unmarshal([]byte(credentials), discordCredentials)
+		if err != nil {
+			return "", tracerr.Errorf("cannot unmarshal discord credentials:\n%s", err)
+		}
+		webhookURL = strings.TrimSpace(discordCredentials.WebhookURL)
+	}
+
+	parsedURL, err := url.Parse(webhookURL)
+	if err != nil || parsedURL.Scheme == "" || parsedURL.Host == "" {
+		return "", tracerr.Errorf("discord webhook url is not valid")
+	}
+
+	return strings.TrimSuffix(webhookURL, "/"), nil
+}
+
+// webhookToken токен вебхука - последний сегмент адреса /api/webhooks/{id}/{token}
+func webhookToken(webhookURL *url.URL) string {
+	return path.Base(webhookURL.Path)
+}
+
+func NewDiscordClient(config social_network_client.ClientConfig) social_network_client.SocialNetworkClient {
+	return &discordClient{
+		httpClient: &http.Client{Timeout: discordTimeout},
+	}
+}
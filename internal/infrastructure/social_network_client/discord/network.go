@@ -0,0 +1,25 @@
+package discord
+
+import "autoposting/internal/infrastructure/social_network_client"
+
+const Name = "DISCORD"
+
+// capabilities ограничения embed: описание до 4096 символов и одно изображение
+var capabilities = social_network_client.Capabilities{
+	MaxTextLength: 4096,
+	Images:        true,
+	MaxImages:     1,
+	Video:         false,
+	Links:         true,
+	Polls:         false,
+}
+
+func init() {
+	// Credentials аккаунта - JSON с адресом входящего вебхука, OAuth не используется
+	social_network_client.Register(social_network_client.Network{
+		Name:         Name,
+		DisplayName:  "Discord",
+		Capabilities: capabilities,
+		NewClient:    NewDiscordClient,
+	})
+}
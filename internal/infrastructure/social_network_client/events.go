@@ -0,0 +1,37 @@
+package social_network_client
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// PageEventType тип события страницы, общий для всех соц сетей
+type PageEventType string
+
+const (
+	PageEventPostNew        PageEventType = "POST_NEW"
+	PageEventPostEdited     PageEventType = "POST_EDITED"
+	PageEventPostDeleted    PageEventType = "POST_DELETED"
+	PageEventCommentNew     PageEventType = "COMMENT_NEW"
+	PageEventCommentEdited  PageEventType = "COMMENT_EDITED"
+	PageEventCommentDeleted PageEventType = "COMMENT_DELETED"
+)
+
+// PageEvent событие страницы, нормализованное из уведомления соц сети
+type PageEvent struct {
+	// EventID уникален в пределах соц сети, по нему отбрасываются повторные доставки
+	EventID   string
+	PageID    string
+	Type      PageEventType
+	PostID    string
+	CommentID string
+	// ParentCommentID комментарий, на который это ответ
+	ParentCommentID string
+	AuthorID        string
+	// ByPage автор - сама страница, например пост администратора от имени сообщества
+	ByPage     bool
+	Text       string
+	OccurredAt time.Time
+	// Payload исходное уведомление соц сети
+	Payload json.RawMessage
+}
@@ -0,0 +1,80 @@
+package fb
+
+import (
+	"autoposting/internal/infrastructure/social_network_client"
+	"github.com/ztrue/tracerr"
+)
+
+type fbPageAudienceResponse struct {
+	ID             string `json:"id"`
+	FanCount       int    `json:"fan_count"`
+	FollowersCount int    `json:"followers_count"`
+}
+
+// GetPagesAudience число отметок "Нравится" страницы из fan_count
+func (f *fbClient) GetPagesAudience(
+	credentials string,
+	accessToken string,
+	pageIDs []string,
+) ([]social_network_client.PageAudienceResult, error) {
+	return f.getPagesAudience(
+		credentials,
+		accessToken,
+		pageIDs,
+		"id,fan_count",
+		func(data fbPageAudienceResponse) int {
+			return data.FanCount
+		},
+	)
+}
+
+// GetPagesAudience число подписчиков Instagram аккаунта из followers_count
+func (i *igClient) GetPagesAudience(
+	credentials string,
+	accessToken string,
+	pageIDs []string,
+) ([]social_network_client.PageAudienceResult, error) {
+	return i.getPagesAudience(
+		credentials,
+		accessToken,
+		pageIDs,
+		"id,followers_count",
+		func(data fbPageAudienceResponse) int {
+			return data.FollowersCount
+		},
+	)
+}
+
+func (f *fbClient) getPagesAudience(
+	credentials string,
+	accessToken string,
+	pageIDs []string,
+	fields string,
+	toFollowers func(fbPageAudienceResponse) int,
+) ([]social_network_client.PageAudienceResult, error) {
+	fbCredentials, err := f.stringToFBCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+	if accessToken == "" {
+		accessToken = fbCredentials.AccessToken
+	}
+
+	results := make([]social_network_client.PageAudienceResult, 0, len(pageIDs))
+	for _, pageID := range pageIDs {
+		var data fbPageAudienceResponse
+		result := social_network_client.PageAudienceResult{PageID: pageID}
+		deleted, err := f.getObject(fbCredentials, accessToken, pageID, fields, &data)
+		switch {
+		case err != nil:
+			result.Err = err
+		case deleted:
+			result.Err = tracerr.Errorf("page %s not found", pageID)
+		default:
+			result.Followers = toFollowers(data)
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,614 @@
+package fb
+
+import (
+	"autoposting/internal/infrastructure/social_network_client"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/ztrue/tracerr"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const fbPagesChunkLimit = 100
+
+type fbCreatePostResponse struct {
+	PostID string `json:"id"`
+}
+
+type FBCredentials struct {
+	AppID        string `json:"app_id"`
+	AccessToken  string `json:"access_token"`
+	ClientSecret string `json:"client_secret"`
+}
+
+type fbClient struct {
+	httpClient  *http.Client
+	authApiUrl  string
+	workApiUrl  string
+	redirectUrl string
+	apiVersion  string
+	scope       string
+}
+
+// fbPostResponse пост страницы отдает текст в message, медиа Instagram - в caption
+type fbPostResponse struct {
+	ID      string `json:"id"`
+	Message string `json:"message"`
+	Caption string `json:"caption"`
+}
+
+type fbErrorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+		Code    int    `json:"code"`
+	} `json:"error"`
+}
+
+// fbObjectNotFoundCode код ошибки Graph API для несуществующего или недоступного объекта
+const fbObjectNotFoundCode = 100
+
+type fbAccessTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+}
+
+type fbDebugTokenResponse struct {
+	Data struct {
+		AppID     string `json:"app_id"`
+		IsValid   bool   `json:"is_valid"`
+		ExpiresAt int64  `json:"expires_at"`
+	} `json:"data"`
+}
+
+type fbGetAccountPagesResponse struct {
+	Data []struct {
+		AccessToken string `json:"access_token"`
+		Name        string `json:"name"`
+		Id          string `json:"id"`
+	} `json:"data"`
+	Paging struct {
+		Cursors struct {
+			Before string `json:"before"`
+			After  string `json:"after"`
+		} `json:"cursors"`
+		Next string `json:"next"`
+	} `json:"paging"`
+}
+
+func (f *fbClient) GetAuthURL(credentials string) (string, error) {
+	fbCredentials, err := f.stringToFBCredentials(credentials)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/%s/dialog/oauth", f.authApiUrl, f.apiVersion), nil)
+	if err != nil {
+		return "", tracerr.Errorf("cannot create auth url request")
+	}
+
+	q := url.Values{
+		"client_id":     []string{fbCredentials.AppID},
+		"redirect_uri":  []string{f.redirectUrl},
+		"response_type": []string{"code"},
+		"scope":         []string{f.scope},
+		"display":       []string{"popup"},
+	}
+	req.URL.RawQuery = q.Encode()
+
+	return req.URL.String(), nil
+}
+
+func (f *fbClient) GetAccessToken(credentials string, queryParams map[string][]string) (*social_network_client.AccessToken, error) {
+	fbCredentials, err := f.stringToFBCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	shortLivedToken, err := f.requestAccessToken(url.Values{
+		"client_id":     []string{fbCredentials.AppID},
+		"client_secret": []string{fbCredentials.ClientSecret},
+		"redirect_uri":  []string{f.redirectUrl},
+		"code":          []string{queryParams["code"][0]},
+	})
+	if err != nil {
+		return nil, tracerr.Errorf("cannot exchange code for access token:\n%s", err)
+	}
+
+	// Токен из code живет около часа, меняем его на долгоживущий
+	longLivedToken, err := f.requestAccessToken(url.Values{
+		"grant_type":        []string{"fb_exchange_token"},
+		"client_id":         []string{fbCredentials.AppID},
+		"client_secret":     []string{fbCredentials.ClientSecret},
+		"fb_exchange_token": []string{shortLivedToken},
+	})
+	if err != nil {
+		return nil, tracerr.Errorf("cannot exchange short-lived token for long-lived:\n%s", err)
+	}
+
+	expiresAt, err := f.getTokenExpiresAt(fbCredentials, longLivedToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return &social_network_client.AccessToken{
+		Token:     longLivedToken,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func (f *fbClient) requestAccessToken(q url.Values) (string, error) {
+	var data fbAccessTokenResponse
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/%s/oauth/access_token", f.workApiUrl, f.apiVersion), nil)
+	if err != nil {
+		return "", tracerr.Errorf("cannot create access token request:\n%s", err)
+	}
+	req.URL.RawQuery = q.Encode()
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return "", tracerr.Errorf("cannot get access token:\n%s", err)
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", tracerr.Errorf("cannot read access token response:\n%s", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", tracerr.Errorf(
+			"access token response status is %d\ntokenResponse:%s",
+			resp.StatusCode,
+			string(respBody),
+		)
+	}
+
+	err = json.Unmarshal(respBody, &data)
+	if err != nil {
+		return "", tracerr.Errorf("cannot unmarshal access token body:\n%s", err)
+	}
+
+	return data.AccessToken, nil
+}
+
+// getTokenExpiresAt реальное время жизни токена, нулевое время для бессрочных токенов
+func (f *fbClient) getTokenExpiresAt(fbCredentials *FBCredentials, accessToken string) (time.Time, error) {
+	var data fbDebugTokenResponse
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/%s/debug_token", f.workApiUrl, f.apiVersion), nil)
+	if err != nil {
+		return time.Time{}, tracerr.Errorf("cannot create debug token request:\n%s", err)
+	}
+	q := url.Values{
+		"input_token": []string{accessToken},
+	}
+	req.URL.RawQuery = q.Encode()
+	appAccessToken := fmt.Sprintf("%s|%s", fbCredentials.AppID, fbCredentials.ClientSecret)
+	resp, err := f.doGraphRequest(req, fbCredentials, appAccessToken)
+	if err != nil {
+		return time.Time{}, tracerr.Errorf("cannot debug access token:\n%s", err)
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return time.Time{}, tracerr.Errorf("cannot read debug token response:\n%s", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return time.Time{}, tracerr.Errorf(
+			"debug token response status is %d\nresponse:%s",
+			resp.StatusCode,
+			string(respBody),
+		)
+	}
+
+	err = json.Unmarshal(respBody, &data)
+	if err != nil {
+		return time.Time{}, tracerr.Errorf("cannot unmarshal debug token body:\n%s", err)
+	}
+
+	if !data.Data.IsValid {
+		return time.Time{}, tracerr.Errorf("access token is not valid:\n%s", string(respBody))
+	}
+
+	if data.Data.ExpiresAt == 0 {
+		return time.Time{}, nil
+	}
+
+	return time.Unix(data.Data.ExpiresAt, 0), nil
+}
+
+func (f *fbClient) GetAccountPages(credentials, accessToken string) ([]social_network_client.SocialNetworkPage, error) {
+	return social_network_client.GetAllAccountPages(f, credentials, accessToken)
+}
+
+func (f *fbClient) GetAccountPagesChunk(
+	credentials string,
+	accessToken string,
+	cursor social_network_client.PagesCursor,
+) (*social_network_client.SocialNetworkPagesChunk, error) {
+	var (
+		pages []social_network_client.SocialNetworkPage
+		data  fbGetAccountPagesResponse
+	)
+
+	if cursor.Limit == 0 {
+		cursor.Limit = fbPagesChunkLimit
+	}
+
+	fbCredentials, err := f.stringToFBCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/%s/me/accounts", f.workApiUrl, f.apiVersion), nil)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot create getting account pages request:\n%s", err)
+	}
+	q := url.Values{
+		"fields": []string{"id,name,access_token"},
+		"limit":  []string{strconv.Itoa(cursor.Limit)},
+	}
+	if cursor.Cursor != "" {
+		q.Set("after", cursor.Cursor)
+	}
+	req.URL.RawQuery = q.Encode()
+	resp, err := f.doGraphRequest(req, fbCredentials, accessToken)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot get account pages:\n%s", err)
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot read getting pages response:\n%s", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, tracerr.Errorf(
+			"get account pages response status %d\npagesResponse:%s",
+			resp.StatusCode,
+			string(respBody),
+		)
+	}
+
+	err = json.Unmarshal(respBody, &data)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot unmarshal getting pages body:\n%s", err)
+	}
+
+	for _, page := range data.Data {
+		// Токен страницы, полученный по долгоживущему токену пользователя, бессрочный
+		pages = append(pages, social_network_client.SocialNetworkPage{
+			ID:   page.Id,
+			Name: page.Name,
+			AccessToken: &social_network_client.AccessToken{
+				Token: page.AccessToken,
+			},
+		})
+	}
+
+	chunk := &social_network_client.SocialNetworkPagesChunk{
+		Pages: pages,
+	}
+	// Ссылка next отсутствует на последней порции
+	if data.Paging.Next != "" {
+		chunk.NextCursor = data.Paging.Cursors.After
+	}
+
+	return chunk, nil
+}
+
+func (f *fbClient) CreatePost(
+	ctx context.Context,
+	credentials string,
+	accessToken string,
+	groupID string,
+	post social_network_client.Post,
+) (string, error) {
+	var (
+		data fbCreatePostResponse
+	)
+
+	req, err := f.newCreatePostRequest(credentials, accessToken, groupID, post)
+	if err != nil {
+		return "", err
+	}
+	resp, err := f.httpClient.Do(req)
+	if err != nil {
+		return "", tracerr.Errorf("cannot create post:\n%s", err)
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", tracerr.Errorf("cannot read create post response:\n%s", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", tracerr.Errorf(
+			"create post response status is %d\nresponse:%s",
+			resp.StatusCode,
+			string(respBody),
+		)
+	}
+
+	err = json.Unmarshal(respBody, &data)
+	if err != nil {
+		return "", tracerr.Errorf("cannot unmarshal access token body:\n%s", err)
+	}
+
+	return data.PostID, nil
+}
+
+// PreparePost собирает подписанные запросы в /feed, которые отправил бы CreatePost
+func (f *fbClient) PreparePost(
+	credentials string,
+	accessToken string,
+	groupIDs []string,
+	post social_network_client.Post,
+) ([]social_network_client.PreparedRequest, error) {
+	requests := make([]social_network_client.PreparedRequest, 0, len(groupIDs))
+	for _, groupID := range groupIDs {
+		req, err := f.newCreatePostRequest(credentials, accessToken, groupID, post)
+		if err != nil {
+			return nil, err
+		}
+		preparedRequest, err := social_network_client.NewPreparedRequest([]string{groupID}, req)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, preparedRequest)
+	}
+
+	return requests, nil
+}
+
+func (f *fbClient) newCreatePostRequest(
+	credentials string,
+	accessToken string,
+	groupID string,
+	post social_network_client.Post,
+) (*http.Request, error) {
+	fbCredentials, err := f.stringToFBCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+	// Публикация от имени страницы требует токен страницы
+	if accessToken == "" {
+		accessToken = fbCredentials.AccessToken
+	}
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/%s/%s/feed", f.workApiUrl, f.apiVersion, groupID), nil)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot create createPost request:\n%s", err)
+	}
+
+	q := req.URL.Query()
+	q.Add("message", post.Text)
+	if post.Link != "" {
+		q.Add("link", post.Link)
+	}
+	req.URL.RawQuery = q.Encode()
+	f.signGraphRequest(req, fbCredentials, accessToken)
+
+	return req, nil
+}
+
+func (f *fbClient) Capabilities() social_network_client.Capabilities {
+	return capabilities
+}
+
+// DeletePost id поста Graph API глобальный, удаляется токеном страницы
+func (f *fbClient) DeletePost(credentials, accessToken, postID string) error {
+	var data struct {
+		Success bool `json:"success"`
+	}
+
+	fbCredentials, err := f.stringToFBCredentials(credentials)
+	if err != nil {
+		return err
+	}
+	if accessToken == "" {
+		accessToken = fbCredentials.AccessToken
+	}
+
+	req, err := http.NewRequest("DELETE", fmt.Sprintf("%s/%s/%s", f.workApiUrl, f.apiVersion, postID), nil)
+	if err != nil {
+		return tracerr.Errorf("cannot create delete post request:\n%s", err)
+	}
+	if err := f.doGraphJSONRequest(req, fbCredentials, accessToken, &data); err != nil {
+		return tracerr.Errorf("cannot delete post %s:\n%s", postID, err)
+	}
+	if !data.Success {
+		return tracerr.Errorf("post %s was not deleted", postID)
+	}
+
+	return nil
+}
+
+func (f *fbClient) GetRemotePosts(
+	credentials string,
+	accessToken string,
+	posts []social_network_client.RemotePost,
+) ([]social_network_client.RemotePostState, error) {
+	return f.getRemotePosts(credentials, accessToken, posts, "message")
+}
+
+// getRemotePosts Graph API не отдает удаленные посты по ids, поэтому посты запрашиваются по одному
+func (f *fbClient) getRemotePosts(
+	credentials string,
+	accessToken string,
+	posts []social_network_client.RemotePost,
+	textField string,
+) ([]social_network_client.RemotePostState, error) {
+	fbCredentials, err := f.stringToFBCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+	if accessToken == "" {
+		accessToken = fbCredentials.AccessToken
+	}
+
+	states := make([]social_network_client.RemotePostState, 0, len(posts))
+	for _, post := range posts {
+		state := social_network_client.RemotePostState{Post: post}
+		state.Text, state.Deleted, state.Err = f.getPostText(fbCredentials, accessToken, post.PostID, textField)
+		states = append(states, state)
+	}
+
+	return states, nil
+}
+
+// getPostText второе значение - пост удален
+func (f *fbClient) getPostText(
+	fbCredentials *FBCredentials,
+	accessToken string,
+	postID string,
+	textField string,
+) (string, bool, error) {
+	var data fbPostResponse
+
+	deleted, err := f.getObject(fbCredentials, accessToken, postID, "id,"+textField, &data)
+	if err != nil || deleted {
+		return "", deleted, err
+	}
+
+	if textField == "caption" {
+		return data.Caption, false, nil
+	}
+	return data.Message, false, nil
+}
+
+// getObject читает поля объекта Graph API в data, первое значение - объект удален или недоступен
+func (f *fbClient) getObject(
+	fbCredentials *FBCredentials,
+	accessToken string,
+	objectID string,
+	fields string,
+	data interface{},
+) (bool, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/%s/%s", f.workApiUrl, f.apiVersion, objectID), nil)
+	if err != nil {
+		return false, tracerr.Errorf("cannot create get object request:\n%s", err)
+	}
+	req.URL.RawQuery = url.Values{"fields": []string{fields}}.Encode()
+
+	resp, err := f.doGraphRequest(req, fbCredentials, accessToken)
+	if err != nil {
+		return false, tracerr.Errorf("cannot get object %s:\n%s", objectID, err)
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return false, tracerr.Errorf("cannot read get object response:\n%s", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errorData fbErrorResponse
+		if json.Unmarshal(respBody, &errorData) == nil && errorData.Error.Code == fbObjectNotFoundCode {
+			return true, nil
+		}
+		return false, tracerr.Errorf(
+			"get object response status is %d\nresponse:%s",
+			resp.StatusCode,
+			string(respBody),
+		)
+	}
+
+	err = json.Unmarshal(respBody, data)
+	if err != nil {
+		return false, tracerr.Errorf("cannot unmarshal get object body:\n%s", err)
+	}
+
+	return false, nil
+}
+
+func (f *fbClient) doGraphRequest(
+	req *http.Request,
+	fbCredentials *FBCredentials,
+	accessToken string,
+) (*http.Response, error) {
+	f.signGraphRequest(req, fbCredentials, accessToken)
+
+	return f.httpClient.Do(req)
+}
+
+func (f *fbClient) doGraphJSONRequest(
+	req *http.Request,
+	fbCredentials *FBCredentials,
+	accessToken string,
+	v interface{},
+) error {
+	resp, err := f.doGraphRequest(req, fbCredentials, accessToken)
+	if err != nil {
+		return tracerr.Errorf("cannot do graph request:\n%s", err)
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return tracerr.Errorf("cannot read graph response:\n%s", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return tracerr.Errorf(
+			"graph response status is %d\nresponse:%s",
+			resp.StatusCode,
+			string(respBody),
+		)
+	}
+
+	err = json.Unmarshal(respBody, v)
+	if err != nil {
+		return tracerr.Errorf("cannot unmarshal graph response body:\n%s", err)
+	}
+
+	return nil
+}
+
+// signGraphRequest передает токен в заголовке и подписывает запрос appsecret_proof,
+// без которого приложения с включенным "Require App Secret" отклоняют вызовы
+func (f *fbClient) signGraphRequest(
+	req *http.Request,
+	fbCredentials *FBCredentials,
+	accessToken string,
+) {
+	q := req.URL.Query()
+	q.Set("appsecret_proof", appSecretProof(accessToken, fbCredentials.ClientSecret))
+	req.URL.RawQuery = q.Encode()
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+}
+
+func appSecretProof(accessToken, clientSecret string) string {
+	mac := hmac.New(sha256.New, []byte(clientSecret))
+	mac.Write([]byte(accessToken))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (f *fbClient) stringToFBCredentials(credentials string) (*FBCredentials, error) {
+	fbCredentials := &FBCredentials{}
+	err := json.Unmarshal([]byte(credentials), fbCredentials)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot unmarshal fb credentials {%s}:\n%s", credentials, err)
+	}
+	return fbCredentials, nil
+}
+
+func NewFBClient(config social_network_client.ClientConfig) social_network_client.SocialNetworkClient {
+	return newFBClient(config, oauthScope)
+}
+
+func newFBClient(config social_network_client.ClientConfig, scope string) *fbClient {
+	return &fbClient{
+		httpClient:  &http.Client{},
+		authApiUrl:  config.AuthApiUrl,
+		workApiUrl:  config.WorkApiUrl,
+		redirectUrl: config.RedirectUrl,
+		apiVersion:  config.ApiVersion,
+		scope:       scope,
+	}
+}
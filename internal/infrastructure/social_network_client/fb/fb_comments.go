@@ -0,0 +1,300 @@
+package fb
+
+import (
+	"autoposting/internal/infrastructure/social_network_client"
+	"fmt"
+	"github.com/ztrue/tracerr"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// fbCommentsChunkLimit максимальный limit комментариев
+const fbCommentsChunkLimit = 100
+
+// fbComment комментарий Facebook отдает автора в from.name и текст в message,
+// комментарий Instagram - from.username, text и timestamp
+type fbComment struct {
+	ID   string `json:"id"`
+	From struct {
+		ID       string `json:"id"`
+		Name     string `json:"name"`
+		Username string `json:"username"`
+	} `json:"from"`
+	Message     string `json:"message"`
+	Text        string `json:"text"`
+	CreatedTime string `json:"created_time"`
+	Timestamp   string `json:"timestamp"`
+	Parent      struct {
+		ID string `json:"id"`
+	} `json:"parent"`
+	Replies struct {
+		Data []fbComment `json:"data"`
+	} `json:"replies"`
+}
+
+type fbCommentsResponse struct {
+	Data   []fbComment `json:"data"`
+	Paging struct {
+		Cursors struct {
+			After string `json:"after"`
+		} `json:"cursors"`
+		Next string `json:"next"`
+	} `json:"paging"`
+}
+
+type fbCommentResponse struct {
+	ID string `json:"id"`
+}
+
+// GetPostComments все комментарии поста с ответами одним списком через filter=stream
+func (f *fbClient) GetPostComments(
+	credentials string,
+	accessToken string,
+	post social_network_client.RemotePost,
+) ([]social_network_client.Comment, error) {
+	fbComments, err := f.getComments(credentials, accessToken, post.PostID, url.Values{
+		"fields": []string{"id,from{id,name},message,created_time,parent{id}"},
+		"filter": []string{"stream"},
+		"order":  []string{"chronological"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	comments := make([]social_network_client.Comment, 0, len(fbComments))
+	for _, comment := range fbComments {
+		createdAt, err := time.Parse(fbTimeLayout, comment.CreatedTime)
+		if err != nil {
+			return nil, tracerr.Errorf("cannot parse created_time of comment %s:\n%s", comment.ID, err)
+		}
+		comments = append(comments, social_network_client.Comment{
+			CommentID:  comment.ID,
+			ParentID:   comment.Parent.ID,
+			AuthorID:   comment.From.ID,
+			AuthorName: comment.From.Name,
+			ByPage:     comment.From.ID == post.PageID,
+			Text:       comment.Message,
+			CreatedAt:  createdAt,
+		})
+	}
+
+	return comments, nil
+}
+
+// GetPostComments комментарии первого уровня медиа вместе с ответами из replies
+func (i *igClient) GetPostComments(
+	credentials string,
+	accessToken string,
+	post social_network_client.RemotePost,
+) ([]social_network_client.Comment, error) {
+	commentFields := "id,from{id,username},text,timestamp"
+	fbComments, err := i.getComments(credentials, accessToken, post.PostID, url.Values{
+		"fields": []string{commentFields + ",replies{" + commentFields + "}"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var comments []social_network_client.Comment
+	for _, comment := range fbComments {
+		for _, igComment := range append([]fbComment{comment}, comment.Replies.Data...) {
+			createdAt, err := time.Parse(fbTimeLayout, igComment.Timestamp)
+			if err != nil {
+				return nil, tracerr.Errorf("cannot parse timestamp of comment %s:\n%s", igComment.ID, err)
+			}
+			result := social_network_client.Comment{
+				CommentID:  igComment.ID,
+				AuthorID:   igComment.From.ID,
+				AuthorName: igComment.From.Username,
+				ByPage:     igComment.From.ID == post.PageID,
+				Text:       igComment.Text,
+				CreatedAt:  createdAt,
+			}
+			if igComment.ID != comment.ID {
+				result.ParentID = comment.ID
+			}
+			comments = append(comments, result)
+		}
+	}
+
+	return comments, nil
+}
+
+// getComments последовательно выбирает все порции /{object}/comments
+func (f *fbClient) getComments(
+	credentials string,
+	accessToken string,
+	objectID string,
+	q url.Values,
+) ([]fbComment, error) {
+	fbCredentials, err := f.stringToFBCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+	if accessToken == "" {
+		accessToken = fbCredentials.AccessToken
+	}
+
+	var comments []fbComment
+	q.Set("limit", strconv.Itoa(fbCommentsChunkLimit))
+	for {
+		var data fbCommentsResponse
+
+		req, err := http.NewRequest("GET", fmt.Sprintf("%s/%s/%s/comments", f.workApiUrl, f.apiVersion, objectID), nil)
+		if err != nil {
+			return nil, tracerr.Errorf("cannot create getting comments request:\n%s", err)
+		}
+		req.URL.RawQuery = q.Encode()
+		if err := f.doGraphJSONRequest(req, fbCredentials, accessToken, &data); err != nil {
+			return nil, tracerr.Errorf("cannot get comments of %s:\n%s", objectID, err)
+		}
+		comments = append(comments, data.Data...)
+
+		// Ссылка next отсутствует на последней порции
+		if data.Paging.Next == "" || len(data.Data) == 0 {
+			return comments, nil
+		}
+		q.Set("after", data.Paging.Cursors.After)
+	}
+}
+
+// ReplyToComment отвечает от имени страницы, ответ на ответ Graph API сам помещает в ветку комментария
+func (f *fbClient) ReplyToComment(
+	credentials string,
+	accessToken string,
+	comment social_network_client.RemoteComment,
+	text string,
+) (string, error) {
+	return f.createReply(credentials, accessToken, comment.CommentID, "comments", text)
+}
+
+// ReplyToComment ответы в Instagram одноуровневые, поэтому ответ на ответ уходит в ветку родителя
+func (i *igClient) ReplyToComment(
+	credentials string,
+	accessToken string,
+	comment social_network_client.RemoteComment,
+	text string,
+) (string, error) {
+	commentID := comment.CommentID
+	if comment.ParentID != "" {
+		commentID = comment.ParentID
+	}
+	return i.createReply(credentials, accessToken, commentID, "replies", text)
+}
+
+func (f *fbClient) createReply(
+	credentials string,
+	accessToken string,
+	commentID string,
+	edge string,
+	text string,
+) (string, error) {
+	var data fbCommentResponse
+
+	fbCredentials, err := f.stringToFBCredentials(credentials)
+	if err != nil {
+		return "", err
+	}
+	if accessToken == "" {
+		accessToken = fbCredentials.AccessToken
+	}
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/%s/%s/%s", f.workApiUrl, f.apiVersion, commentID, edge), nil)
+	if err != nil {
+		return "", tracerr.Errorf("cannot create reply request:\n%s", err)
+	}
+	req.URL.RawQuery = url.Values{"message": []string{text}}.Encode()
+	if err := f.doGraphJSONRequest(req, fbCredentials, accessToken, &data); err != nil {
+		return "", tracerr.Errorf("cannot reply to comment %s:\n%s", commentID, err)
+	}
+
+	return data.ID, nil
+}
+
+// DeleteComment удаляет комментарий Facebook или Instagram
+func (f *fbClient) DeleteComment(
+	credentials string,
+	accessToken string,
+	comment social_network_client.RemoteComment,
+) error {
+	var data struct {
+		Success bool `json:"success"`
+	}
+
+	fbCredentials, err := f.stringToFBCredentials(credentials)
+	if err != nil {
+		return err
+	}
+	if accessToken == "" {
+		accessToken = fbCredentials.AccessToken
+	}
+
+	req, err := http.NewRequest("DELETE", fmt.Sprintf("%s/%s/%s", f.workApiUrl, f.apiVersion, comment.CommentID), nil)
+	if err != nil {
+		return tracerr.Errorf("cannot create delete comment request:\n%s", err)
+	}
+	if err := f.doGraphJSONRequest(req, fbCredentials, accessToken, &data); err != nil {
+		return tracerr.Errorf("cannot delete comment %s:\n%s", comment.CommentID, err)
+	}
+	if !data.Success {
+		return tracerr.Errorf("comment %s was not deleted", comment.CommentID)
+	}
+
+	return nil
+}
+
+// SetCommentHidden скрывает комментарий Facebook через is_hidden
+func (f *fbClient) SetCommentHidden(
+	credentials string,
+	accessToken string,
+	comment social_network_client.RemoteComment,
+	hidden bool,
+) error {
+	return f.updateCommentVisibility(credentials, accessToken, comment.CommentID, "is_hidden", hidden)
+}
+
+// SetCommentHidden у комментариев Instagram вместо is_hidden параметр hide
+func (i *igClient) SetCommentHidden(
+	credentials string,
+	accessToken string,
+	comment social_network_client.RemoteComment,
+	hidden bool,
+) error {
+	return i.updateCommentVisibility(credentials, accessToken, comment.CommentID, "hide", hidden)
+}
+
+func (f *fbClient) updateCommentVisibility(
+	credentials string,
+	accessToken string,
+	commentID string,
+	param string,
+	hidden bool,
+) error {
+	var data struct {
+		Success bool `json:"success"`
+	}
+
+	fbCredentials, err := f.stringToFBCredentials(credentials)
+	if err != nil {
+		return err
+	}
+	if accessToken == "" {
+		accessToken = fbCredentials.AccessToken
+	}
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/%s/%s", f.workApiUrl, f.apiVersion, commentID), nil)
+	if err != nil {
+		return tracerr.Errorf("cannot create comment visibility request:\n%s", err)
+	}
+	req.URL.RawQuery = url.Values{param: []string{strconv.FormatBool(hidden)}}.Encode()
+	if err := f.doGraphJSONRequest(req, fbCredentials, accessToken, &data); err != nil {
+		return tracerr.Errorf("cannot change comment %s visibility:\n%s", commentID, err)
+	}
+	if !data.Success {
+		return tracerr.Errorf("comment %s visibility was not changed", commentID)
+	}
+
+	return nil
+}
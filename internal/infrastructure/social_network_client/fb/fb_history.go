@@ -0,0 +1,188 @@
+package fb
+
+import (
+	"autoposting/internal/infrastructure/social_network_client"
+	"encoding/json"
+	"fmt"
+	"github.com/ztrue/tracerr"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// fbHistoryChunkLimit максимальный limit ленты страницы
+	fbHistoryChunkLimit = 100
+	// fbTimeLayout формат дат Graph API
+	fbTimeLayout = "2006-01-02T15:04:05-0700"
+)
+
+// fbHistoryResponse посты страницы отдают message и created_time, медиа Instagram - caption и timestamp
+type fbHistoryResponse struct {
+	Data []struct {
+		ID          string `json:"id"`
+		Message     string `json:"message"`
+		Caption     string `json:"caption"`
+		CreatedTime string `json:"created_time"`
+		Timestamp   string `json:"timestamp"`
+		FullPicture string `json:"full_picture"`
+		MediaType   string `json:"media_type"`
+		MediaURL    string `json:"media_url"`
+	} `json:"data"`
+	Paging struct {
+		Cursors struct {
+			After string `json:"after"`
+		} `json:"cursors"`
+		Next string `json:"next"`
+	} `json:"paging"`
+}
+
+// GetPageHistoryChunk лента /{page}/posts, since фильтрует сам Graph API
+func (f *fbClient) GetPageHistoryChunk(
+	credentials string,
+	accessToken string,
+	pageID string,
+	since time.Time,
+	cursor social_network_client.PagesCursor,
+) (*social_network_client.PageHistoryChunk, error) {
+	data, err := f.getHistory(credentials, accessToken, pageID, "posts", url.Values{
+		"fields": []string{"id,message,created_time,full_picture"},
+		"since":  []string{strconv.FormatInt(since.Unix(), 10)},
+	}, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	chunk := &social_network_client.PageHistoryChunk{}
+	for _, item := range data.Data {
+		publishedAt, err := time.Parse(fbTimeLayout, item.CreatedTime)
+		if err != nil {
+			return nil, tracerr.Errorf("cannot parse created_time of post %s:\n%s", item.ID, err)
+		}
+		post := social_network_client.Post{
+			Text: item.Message,
+		}
+		if item.FullPicture != "" {
+			post.Images = []string{item.FullPicture}
+		}
+		chunk.Posts = append(chunk.Posts, social_network_client.PageHistoryPost{
+			PostID:      item.ID,
+			Post:        post,
+			PublishedAt: publishedAt,
+		})
+	}
+	// Ссылка next отсутствует на последней порции
+	if data.Paging.Next != "" {
+		chunk.NextCursor = data.Paging.Cursors.After
+	}
+
+	return chunk, nil
+}
+
+// getHistory запрашивает порцию ленты edge страницы или аккаунта Instagram
+func (f *fbClient) getHistory(
+	credentials string,
+	accessToken string,
+	objectID string,
+	edge string,
+	q url.Values,
+	cursor social_network_client.PagesCursor,
+) (*fbHistoryResponse, error) {
+	var data fbHistoryResponse
+
+	if cursor.Limit == 0 || cursor.Limit > fbHistoryChunkLimit {
+		cursor.Limit = fbHistoryChunkLimit
+	}
+	fbCredentials, err := f.stringToFBCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+	if accessToken == "" {
+		accessToken = fbCredentials.AccessToken
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/%s/%s/%s", f.workApiUrl, f.apiVersion, objectID, edge), nil)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot create getting %s request:\n%s", edge, err)
+	}
+	q.Set("limit", strconv.Itoa(cursor.Limit))
+	if cursor.Cursor != "" {
+		q.Set("after", cursor.Cursor)
+	}
+	req.URL.RawQuery = q.Encode()
+	resp, err := f.doGraphRequest(req, fbCredentials, accessToken)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot get %s of %s:\n%s", edge, objectID, err)
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot read getting %s response:\n%s", edge, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, tracerr.Errorf(
+			"get %s response status %d\nresponse:%s",
+			edge,
+			resp.StatusCode,
+			string(respBody),
+		)
+	}
+
+	err = json.Unmarshal(respBody, &data)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot unmarshal getting %s body:\n%s", edge, err)
+	}
+
+	return &data, nil
+}
+
+// GetPageHistoryChunk лента /{ig-user}/media, медиа старше since отбрасываются на стороне клиента
+func (i *igClient) GetPageHistoryChunk(
+	credentials string,
+	accessToken string,
+	igUserID string,
+	since time.Time,
+	cursor social_network_client.PagesCursor,
+) (*social_network_client.PageHistoryChunk, error) {
+	data, err := i.getHistory(credentials, accessToken, igUserID, "media", url.Values{
+		"fields": []string{"id,caption,timestamp,media_type,media_url"},
+	}, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	chunk := &social_network_client.PageHistoryChunk{}
+	for _, item := range data.Data {
+		publishedAt, err := time.Parse(fbTimeLayout, item.Timestamp)
+		if err != nil {
+			return nil, tracerr.Errorf("cannot parse timestamp of media %s:\n%s", item.ID, err)
+		}
+		if publishedAt.Before(since) {
+			return chunk, nil
+		}
+		post := social_network_client.Post{
+			Text: item.Caption,
+		}
+		switch item.MediaType {
+		case "IMAGE", "CAROUSEL_ALBUM":
+			if item.MediaURL != "" {
+				post.Images = []string{item.MediaURL}
+			}
+		case "VIDEO":
+			post.Video = item.MediaURL
+		}
+		chunk.Posts = append(chunk.Posts, social_network_client.PageHistoryPost{
+			PostID:      item.ID,
+			Post:        post,
+			PublishedAt: publishedAt,
+		})
+	}
+	if data.Paging.Next != "" {
+		chunk.NextCursor = data.Paging.Cursors.After
+	}
+
+	return chunk, nil
+}
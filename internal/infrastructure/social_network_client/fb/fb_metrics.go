@@ -0,0 +1,123 @@
+package fb
+
+import (
+	"autoposting/internal/infrastructure/social_network_client"
+)
+
+const (
+	// fbPostMetricsFields реакции и комментарии считаются по summary без выгрузки самих объектов
+	fbPostMetricsFields = "id,reactions.summary(total_count).limit(0),comments.summary(total_count).limit(0)," +
+		"shares,insights.metric(post_impressions,post_impressions_unique)"
+	igMediaMetricsFields = "id,like_count,comments_count,insights.metric(impressions,reach)"
+)
+
+type fbPostMetricsResponse struct {
+	ID        string    `json:"id"`
+	Reactions fbSummary `json:"reactions"`
+	Comments  fbSummary `json:"comments"`
+	Shares    struct {
+		Count int `json:"count"`
+	} `json:"shares"`
+	LikeCount     int        `json:"like_count"`
+	CommentsCount int        `json:"comments_count"`
+	Insights      fbInsights `json:"insights"`
+}
+
+type fbSummary struct {
+	Summary struct {
+		TotalCount int `json:"total_count"`
+	} `json:"summary"`
+}
+
+type fbInsights struct {
+	Data []struct {
+		Name   string `json:"name"`
+		Values []struct {
+			Value int `json:"value"`
+		} `json:"values"`
+	} `json:"data"`
+}
+
+// metric последнее значение метрики insights, 0 - метрики нет в ответе
+func (i fbInsights) metric(name string) int {
+	for _, data := range i.Data {
+		if data.Name == name && len(data.Values) > 0 {
+			return data.Values[len(data.Values)-1].Value
+		}
+	}
+	return 0
+}
+
+// GetPostsMetrics insights поста доступны с правом read_insights, посты запрашиваются по одному
+func (f *fbClient) GetPostsMetrics(
+	credentials string,
+	accessToken string,
+	posts []social_network_client.RemotePost,
+) ([]social_network_client.PostMetricsResult, error) {
+	return f.getPostsMetrics(
+		credentials,
+		accessToken,
+		posts,
+		fbPostMetricsFields,
+		func(data fbPostMetricsResponse) social_network_client.PostMetrics {
+			return social_network_client.PostMetrics{
+				Likes:    data.Reactions.Summary.TotalCount,
+				Reposts:  data.Shares.Count,
+				Comments: data.Comments.Summary.TotalCount,
+				Views:    data.Insights.metric("post_impressions"),
+				Reach:    data.Insights.metric("post_impressions_unique"),
+			}
+		},
+	)
+}
+
+// GetPostsMetrics у медиа Instagram нет репостов
+func (i *igClient) GetPostsMetrics(
+	credentials string,
+	accessToken string,
+	posts []social_network_client.RemotePost,
+) ([]social_network_client.PostMetricsResult, error) {
+	return i.getPostsMetrics(
+		credentials,
+		accessToken,
+		posts,
+		igMediaMetricsFields,
+		func(data fbPostMetricsResponse) social_network_client.PostMetrics {
+			return social_network_client.PostMetrics{
+				Likes:    data.LikeCount,
+				Comments: data.CommentsCount,
+				Views:    data.Insights.metric("impressions"),
+				Reach:    data.Insights.metric("reach"),
+			}
+		},
+	)
+}
+
+func (f *fbClient) getPostsMetrics(
+	credentials string,
+	accessToken string,
+	posts []social_network_client.RemotePost,
+	fields string,
+	toMetrics func(fbPostMetricsResponse) social_network_client.PostMetrics,
+) ([]social_network_client.PostMetricsResult, error) {
+	fbCredentials, err := f.stringToFBCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+	if accessToken == "" {
+		accessToken = fbCredentials.AccessToken
+	}
+
+	results := make([]social_network_client.PostMetricsResult, 0, len(posts))
+	for _, post := range posts {
+		var data fbPostMetricsResponse
+		result := social_network_client.PostMetricsResult{Post: post}
+		result.Deleted, result.Err = f.getObject(fbCredentials, accessToken, post.PostID, fields, &data)
+		if result.Err == nil && !result.Deleted {
+			result.Metrics = toMetrics(data)
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
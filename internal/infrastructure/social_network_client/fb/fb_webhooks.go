@@ -0,0 +1,146 @@
+package fb
+
+import (
+	"autoposting/internal/infrastructure/social_network_client"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/ztrue/tracerr"
+	"net/url"
+	"time"
+)
+
+// WebhookSignatureHeader подпись тела уведомления секретом приложения
+const WebhookSignatureHeader = "X-Hub-Signature-256"
+
+type fbWebhookNotification struct {
+	Object string `json:"object"`
+	Entry  []struct {
+		ID      string `json:"id"`
+		Time    int64  `json:"time"`
+		Changes []struct {
+			Field string          `json:"field"`
+			Value json.RawMessage `json:"value"`
+		} `json:"changes"`
+	} `json:"entry"`
+}
+
+type fbFeedChange struct {
+	Item      string `json:"item"`
+	Verb      string `json:"verb"`
+	PostID    string `json:"post_id"`
+	CommentID string `json:"comment_id"`
+	// ParentID у комментария первого уровня совпадает с PostID
+	ParentID string `json:"parent_id"`
+	From     struct {
+		ID string `json:"id"`
+	} `json:"from"`
+	Message     string `json:"message"`
+	CreatedTime int64  `json:"created_time"`
+}
+
+// VerifyWebhookSubscription проверяет запрос подписки и возвращает hub.challenge, который нужно вернуть Facebook
+func VerifyWebhookSubscription(verifyToken string, query url.Values) (string, bool) {
+	if verifyToken == "" || query.Get("hub.mode") != "subscribe" {
+		return "", false
+	}
+	if subtle.ConstantTimeCompare([]byte(query.Get("hub.verify_token")), []byte(verifyToken)) != 1 {
+		return "", false
+	}
+	return query.Get("hub.challenge"), true
+}
+
+// VerifyWebhookSignature без секрета приложения уведомления не принимаются
+func VerifyWebhookSignature(appSecret string, body []byte, signature string) bool {
+	if appSecret == "" {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(appSecret))
+	mac.Write(body)
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(signature), []byte(expected))
+}
+
+// ParseWebhookEvents нормализует изменения поля feed страниц, остальные поля и скрытия комментариев пропускаются
+func ParseWebhookEvents(body []byte) ([]social_network_client.PageEvent, error) {
+	var notification fbWebhookNotification
+	if err := json.Unmarshal(body, &notification); err != nil {
+		return nil, tracerr.Errorf("cannot unmarshal fb webhook notification:\n%s", err)
+	}
+	if notification.Object != "page" {
+		return nil, nil
+	}
+
+	var events []social_network_client.PageEvent
+	for _, entry := range notification.Entry {
+		for _, change := range entry.Changes {
+			if change.Field != "feed" {
+				continue
+			}
+
+			var feedChange fbFeedChange
+			if err := json.Unmarshal(change.Value, &feedChange); err != nil {
+				return nil, tracerr.Errorf("cannot unmarshal fb feed change of page %s:\n%s", entry.ID, err)
+			}
+			eventType, ok := fbPageEventType(feedChange.Item, feedChange.Verb)
+			if !ok {
+				continue
+			}
+
+			// Facebook не присылает id уведомления, повторная доставка совпадает с исходной по содержимому
+			hash := sha256.New()
+			hash.Write([]byte(entry.ID))
+			hash.Write(change.Value)
+
+			occurredAt := feedChange.CreatedTime
+			if occurredAt == 0 {
+				occurredAt = entry.Time
+			}
+
+			event := social_network_client.PageEvent{
+				EventID:    hex.EncodeToString(hash.Sum(nil)),
+				PageID:     entry.ID,
+				Type:       eventType,
+				PostID:     feedChange.PostID,
+				CommentID:  feedChange.CommentID,
+				AuthorID:   feedChange.From.ID,
+				ByPage:     feedChange.From.ID == entry.ID,
+				Text:       feedChange.Message,
+				OccurredAt: time.Unix(occurredAt, 0),
+				Payload:    change.Value,
+			}
+			if feedChange.ParentID != feedChange.PostID {
+				event.ParentCommentID = feedChange.ParentID
+			}
+			events = append(events, event)
+		}
+	}
+
+	return events, nil
+}
+
+func fbPageEventType(item, verb string) (social_network_client.PageEventType, bool) {
+	switch item {
+	case "comment":
+		switch verb {
+		case "add":
+			return social_network_client.PageEventCommentNew, true
+		case "edited":
+			return social_network_client.PageEventCommentEdited, true
+		case "remove":
+			return social_network_client.PageEventCommentDeleted, true
+		}
+	case "post", "status", "photo", "video", "share":
+		switch verb {
+		case "add":
+			return social_network_client.PageEventPostNew, true
+		case "edited":
+			return social_network_client.PageEventPostEdited, true
+		case "remove":
+			return social_network_client.PageEventPostDeleted, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,74 @@
+package fb
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	body := []byte(`{"object":"page","entry":[]}`)
+	mac := hmac.New(sha256.New, []byte("app-secret"))
+	mac.Write(body)
+	validSignature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	tests := []struct {
+		name      string
+		appSecret string
+		body      []byte
+		signature string
+		want      bool
+	}{
+		{
+			name:      "valid signature",
+			appSecret: "app-secret",
+			body:      body,
+			signature: validSignature,
+			want:      true,
+		},
+		{
+			name:      "signed with other secret",
+			appSecret: "other-secret",
+			body:      body,
+			signature: validSignature,
+			want:      false,
+		},
+		{
+			name:      "body changed",
+			appSecret: "app-secret",
+			body:      []byte(`{"object":"page","entry":[{}]}`),
+			signature: validSignature,
+			want:      false,
+		},
+		{
+			name:      "signature without prefix",
+			appSecret: "app-secret",
+			body:      body,
+			signature: validSignature[len("sha256="):],
+			want:      false,
+		},
+		{
+			name:      "missing header",
+			appSecret: "app-secret",
+			body:      body,
+			signature: "",
+			want:      false,
+		},
+		{
+			name:      "empty app secret",
+			appSecret: "",
+			body:      body,
+			signature: validSignature,
+			want:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyWebhookSignature(tt.appSecret, tt.body, tt.signature); got != tt.want {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
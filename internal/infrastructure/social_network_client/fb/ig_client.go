@@ -0,0 +1,374 @@
+package fb
+
+import (
+	"autoposting/internal/infrastructure/social_network_client"
+	"context"
+	"fmt"
+	"github.com/ztrue/tracerr"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// igCreationIDPlaceholder подставляется в dry-run вместо идентификаторов контейнеров,
+// которые Graph API выдает только при настоящей публикации
+const igCreationIDPlaceholder = "{creation_id}"
+
+const (
+	// igContainerStatusInterval пауза между проверками статуса контейнера
+	igContainerStatusInterval = 2 * time.Second
+	// igPublishTimeout общий срок публикации вместе с ожиданием всех контейнеров
+	igPublishTimeout = 5 * time.Minute
+)
+
+type igPageResponse struct {
+	ID                       string `json:"id"`
+	InstagramBusinessAccount *struct {
+		ID                string `json:"id"`
+		Username          string `json:"username"`
+		Name              string `json:"name"`
+		Biography         string `json:"biography"`
+		ProfilePictureURL string `json:"profile_picture_url"`
+	} `json:"instagram_business_account"`
+}
+
+type igMediaResponse struct {
+	ID string `json:"id"`
+}
+
+type igContainerStatusResponse struct {
+	StatusCode string `json:"status_code"`
+}
+
+// igClient публикует в Instagram Business аккаунты страниц Facebook через Graph API
+// с credentials и токеном приложения FB
+type igClient struct {
+	*fbClient
+}
+
+func (i *igClient) GetAccountPages(credentials, accessToken string) ([]social_network_client.SocialNetworkPage, error) {
+	return social_network_client.GetAllAccountPages(i, credentials, accessToken)
+}
+
+// GetAccountPagesChunk порция страниц FB, из которой остаются только страницы с подключенным Instagram
+func (i *igClient) GetAccountPagesChunk(
+	credentials string,
+	accessToken string,
+	cursor social_network_client.PagesCursor,
+) (*social_network_client.SocialNetworkPagesChunk, error) {
+	fbCredentials, err := i.stringToFBCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	fbChunk, err := i.fbClient.GetAccountPagesChunk(credentials, accessToken, cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	chunk := &social_network_client.SocialNetworkPagesChunk{
+		NextCursor: fbChunk.NextCursor,
+	}
+	for _, fbPage := range fbChunk.Pages {
+		var data igPageResponse
+
+		pageAccessToken := accessToken
+		if fbPage.AccessToken != nil {
+			pageAccessToken = fbPage.AccessToken.Token
+		}
+
+		req, err := http.NewRequest("GET", fmt.Sprintf("%s/%s/%s", i.workApiUrl, i.apiVersion, fbPage.ID), nil)
+		if err != nil {
+			return nil, tracerr.Errorf("cannot create getting instagram account request:\n%s", err)
+		}
+		q := url.Values{
+			"fields": []string{"instagram_business_account{id,username,name,biography,profile_picture_url}"},
+		}
+		req.URL.RawQuery = q.Encode()
+		if err := i.doGraphJSONRequest(req, fbCredentials, pageAccessToken, &data); err != nil {
+			return nil, tracerr.Errorf("cannot get instagram account of page %s:\n%s", fbPage.ID, err)
+		}
+
+		if data.InstagramBusinessAccount == nil {
+			continue
+		}
+		name := data.InstagramBusinessAccount.Name
+		if name == "" {
+			name = data.InstagramBusinessAccount.Username
+		}
+		chunk.Pages = append(chunk.Pages, social_network_client.SocialNetworkPage{
+			ID:          data.InstagramBusinessAccount.ID,
+			Name:        name,
+			Description: data.InstagramBusinessAccount.Biography,
+			Image:       data.InstagramBusinessAccount.ProfilePictureURL,
+			// Вызовы от имени Instagram аккаунта выполняются с токеном связанной страницы
+			AccessToken: fbPage.AccessToken,
+		})
+	}
+
+	return chunk, nil
+}
+
+// CreatePost публикует изображение или карусель в два шага: создание контейнера в /media и /media_publish,
+// между ними дожидается окончания обработки контейнера
+func (i *igClient) CreatePost(
+	ctx context.Context,
+	credentials string,
+	accessToken string,
+	igUserID string,
+	post social_network_client.Post,
+) (string, error) {
+	var data igMediaResponse
+
+	fbCredentials, err := i.stringToFBCredentials(credentials)
+	if err != nil {
+		return "", err
+	}
+	if accessToken == "" {
+		accessToken = fbCredentials.AccessToken
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, igPublishTimeout)
+	defer cancel()
+
+	creationID, err := i.createMediaContainer(ctx, fbCredentials, accessToken, igUserID, post)
+	if err != nil {
+		return "", err
+	}
+
+	if err := i.waitMediaContainer(ctx, fbCredentials, accessToken, creationID); err != nil {
+		return "", err
+	}
+
+	req, err := i.newMediaPublishRequest(igUserID, creationID)
+	if err != nil {
+		return "", err
+	}
+	req = req.WithContext(ctx)
+	if err := i.doGraphJSONRequest(req, fbCredentials, accessToken, &data); err != nil {
+		return "", tracerr.Errorf("cannot publish instagram media container %s:\n%s", creationID, err)
+	}
+
+	return data.ID, nil
+}
+
+// PreparePost собирает запросы контейнеров и публикации, идентификаторы контейнеров заменены заглушками
+func (i *igClient) PreparePost(
+	credentials string,
+	accessToken string,
+	igUserIDs []string,
+	post social_network_client.Post,
+) ([]social_network_client.PreparedRequest, error) {
+	fbCredentials, err := i.stringToFBCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+	if accessToken == "" {
+		accessToken = fbCredentials.AccessToken
+	}
+
+	var preparedRequests []social_network_client.PreparedRequest
+	for _, igUserID := range igUserIDs {
+		var userRequests []*http.Request
+		var childrenIDs []string
+		if len(post.Images) > 1 {
+			for index, image := range post.Images {
+				req, err := i.newCarouselItemRequest(igUserID, image)
+				if err != nil {
+					return nil, err
+				}
+				userRequests = append(userRequests, req)
+				childrenIDs = append(childrenIDs, fmt.Sprintf("{item_%d}", index+1))
+			}
+		}
+		containerRequest, err := i.newMediaRequest(igUserID, igContainerParams(post, childrenIDs))
+		if err != nil {
+			return nil, err
+		}
+		publishRequest, err := i.newMediaPublishRequest(igUserID, igCreationIDPlaceholder)
+		if err != nil {
+			return nil, err
+		}
+		userRequests = append(userRequests, containerRequest, publishRequest)
+
+		for _, req := range userRequests {
+			i.signGraphRequest(req, fbCredentials, accessToken)
+			preparedRequest, err := social_network_client.NewPreparedRequest([]string{igUserID}, req)
+			if err != nil {
+				return nil, err
+			}
+			preparedRequests = append(preparedRequests, preparedRequest)
+		}
+	}
+
+	return preparedRequests, nil
+}
+
+// GetRemotePosts текст медиа Instagram лежит в caption
+func (i *igClient) GetRemotePosts(
+	credentials string,
+	accessToken string,
+	posts []social_network_client.RemotePost,
+) ([]social_network_client.RemotePostState, error) {
+	return i.getRemotePosts(credentials, accessToken, posts, "caption")
+}
+
+// DeletePost Graph API не удаляет публикации Instagram
+func (i *igClient) DeletePost(credentials, accessToken, postID string) error {
+	return tracerr.Errorf("instagram graph api does not support deleting posts")
+}
+
+func (i *igClient) Capabilities() social_network_client.Capabilities {
+	return igCapabilities
+}
+
+func (i *igClient) createMediaContainer(
+	ctx context.Context,
+	fbCredentials *FBCredentials,
+	accessToken string,
+	igUserID string,
+	post social_network_client.Post,
+) (string, error) {
+	var data igMediaResponse
+
+	if len(post.Images) == 0 {
+		return "", tracerr.Errorf("instagram post requires an image")
+	}
+
+	// Элементы карусели создаются заранее, их идентификаторы передаются в children
+	var childrenIDs []string
+	if len(post.Images) > 1 {
+		for index, image := range post.Images {
+			var item igMediaResponse
+
+			req, err := i.newCarouselItemRequest(igUserID, image)
+			if err != nil {
+				return "", err
+			}
+			req = req.WithContext(ctx)
+			if err := i.doGraphJSONRequest(req, fbCredentials, accessToken, &item); err != nil {
+				return "", tracerr.Errorf("cannot create instagram carousel item %d:\n%s", index, err)
+			}
+			if err := i.waitMediaContainer(ctx, fbCredentials, accessToken, item.ID); err != nil {
+				return "", err
+			}
+			childrenIDs = append(childrenIDs, item.ID)
+		}
+	}
+
+	req, err := i.newMediaRequest(igUserID, igContainerParams(post, childrenIDs))
+	if err != nil {
+		return "", err
+	}
+	req = req.WithContext(ctx)
+	if err := i.doGraphJSONRequest(req, fbCredentials, accessToken, &data); err != nil {
+		return "", tracerr.Errorf("cannot create instagram media container:\n%s", err)
+	}
+
+	return data.ID, nil
+}
+
+// waitMediaContainer опрашивает status_code контейнера до FINISHED, раньше media_publish и карусель его не принимают.
+// Ожидание ограничено сроком ctx
+func (i *igClient) waitMediaContainer(
+	ctx context.Context,
+	fbCredentials *FBCredentials,
+	accessToken string,
+	containerID string,
+) error {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		var data igContainerStatusResponse
+
+		select {
+		case <-ctx.Done():
+			return tracerr.Errorf("instagram media container %s is not finished:\n%s", containerID, ctx.Err())
+		case <-timer.C:
+		}
+
+		req, err := http.NewRequestWithContext(
+			ctx,
+			"GET",
+			fmt.Sprintf("%s/%s/%s", i.workApiUrl, i.apiVersion, containerID),
+			nil,
+		)
+		if err != nil {
+			return tracerr.Errorf("cannot create instagram container status request:\n%s", err)
+		}
+		q := url.Values{
+			"fields": []string{"status_code"},
+		}
+		req.URL.RawQuery = q.Encode()
+		if err := i.doGraphJSONRequest(req, fbCredentials, accessToken, &data); err != nil {
+			return tracerr.Errorf("cannot get status of instagram media container %s:\n%s", containerID, err)
+		}
+
+		switch data.StatusCode {
+		case "FINISHED":
+			return nil
+		case "ERROR", "EXPIRED":
+			return tracerr.Errorf("instagram media container %s has status %s", containerID, data.StatusCode)
+		}
+		timer.Reset(igContainerStatusInterval)
+	}
+}
+
+func (i *igClient) newMediaRequest(igUserID string, q url.Values) (*http.Request, error) {
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/%s/%s/media", i.workApiUrl, i.apiVersion, igUserID), nil)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot create instagram media request:\n%s", err)
+	}
+	req.URL.RawQuery = q.Encode()
+
+	return req, nil
+}
+
+func (i *igClient) newCarouselItemRequest(igUserID, image string) (*http.Request, error) {
+	return i.newMediaRequest(igUserID, url.Values{
+		"image_url":        []string{image},
+		"is_carousel_item": []string{"true"},
+	})
+}
+
+func (i *igClient) newMediaPublishRequest(igUserID, creationID string) (*http.Request, error) {
+	req, err := http.NewRequest(
+		"POST",
+		fmt.Sprintf("%s/%s/%s/media_publish", i.workApiUrl, i.apiVersion, igUserID),
+		nil,
+	)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot create instagram media publish request:\n%s", err)
+	}
+	q := url.Values{
+		"creation_id": []string{creationID},
+	}
+	req.URL.RawQuery = q.Encode()
+
+	return req, nil
+}
+
+// igContainerParams контейнер одного изображения или карусели из childrenIDs
+func igContainerParams(post social_network_client.Post, childrenIDs []string) url.Values {
+	q := url.Values{}
+	if post.Text != "" {
+		q.Set("caption", post.Text)
+	}
+	if len(childrenIDs) == 0 {
+		if len(post.Images) > 0 {
+			q.Set("image_url", post.Images[0])
+		}
+		return q
+	}
+	q.Set("media_type", "CAROUSEL")
+	q.Set("children", strings.Join(childrenIDs, ","))
+	return q
+}
+
+func NewIGClient(config social_network_client.ClientConfig) social_network_client.SocialNetworkClient {
+	return &igClient{
+		fbClient: newFBClient(config, oauthScope),
+	}
+}
@@ -0,0 +1,58 @@
+package fb
+
+import "autoposting/internal/infrastructure/social_network_client"
+
+const (
+	Name = "FB"
+	// oauthScope сразу включает разрешения Instagram, IG работает через подключение FB
+	oauthScope = "pages_show_list,pages_read_engagement,pages_manage_posts,instagram_basic,instagram_content_publish"
+
+	// IGName Instagram Business, подключенный к страницам Facebook, работает через аккаунт FB
+	IGName = "IG"
+)
+
+// capabilities клиент публикует только текст и ссылку
+var capabilities = social_network_client.Capabilities{
+	MaxTextLength: 63206,
+	Images:        false,
+	Video:         false,
+	Links:         true,
+	Polls:         false,
+}
+
+var igCapabilities = social_network_client.Capabilities{
+	MaxTextLength: 2200,
+	Images:        true,
+	MaxImages:     10,
+	MinImages:     1,
+	Video:         false,
+	Links:         false,
+	Polls:         false,
+}
+
+var defaultConfig = social_network_client.ClientConfig{
+	AuthApiUrl: "https://www.facebook.com",
+	WorkApiUrl: "https://graph.facebook.com",
+	ApiVersion: "v16.0",
+}
+
+func init() {
+	social_network_client.Register(social_network_client.Network{
+		Name:        Name,
+		DisplayName: "Facebook",
+		OAuth: &social_network_client.OAuthSettings{
+			Scope: oauthScope,
+		},
+		Capabilities:  capabilities,
+		DefaultConfig: defaultConfig,
+		NewClient:     NewFBClient,
+	})
+	social_network_client.Register(social_network_client.Network{
+		Name:          IGName,
+		DisplayName:   "Instagram",
+		AuthNetwork:   Name,
+		Capabilities:  igCapabilities,
+		DefaultConfig: defaultConfig,
+		NewClient:     NewIGClient,
+	})
+}
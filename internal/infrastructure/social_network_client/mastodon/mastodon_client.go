@@ -0,0 +1,557 @@
+package mastodon
+
+import (
+	"autoposting/internal/infrastructure/social_network_client"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ztrue/tracerr"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// mastodonPollExpiresIn время голосования, без него инстанс не принимает опрос
+	mastodonPollExpiresIn = 24 * time.Hour
+	// mastodonMediaProcessingAttempts число проверок обработки видео и больших изображений
+	mastodonMediaProcessingAttempts = 10
+	mastodonMediaProcessingInterval = time.Second
+	// mastodonTimeout с запасом на скачивание и загрузку видео
+	mastodonTimeout = 2 * time.Minute
+	// mastodonAppName имя приложения, под которым пользователь видит доступ в настройках инстанса
+	mastodonAppName = "Autoposting"
+)
+
+// MastodonCredentials без client_id и client_secret приложение регистрируется на инстансе при создании аккаунта
+type MastodonCredentials struct {
+	// InstanceURL адрес инстанса, например https://mastodon.social
+	InstanceURL  string `json:"instance_url"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	AccessToken  string `json:"access_token"`
+}
+
+type mastodonAppResponse struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+}
+
+type mastodonAccessTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	Scope       string `json:"scope"`
+}
+
+type mastodonAccountResponse struct {
+	ID          string `json:"id"`
+	Acct        string `json:"acct"`
+	DisplayName string `json:"display_name"`
+	Note        string `json:"note"`
+	Avatar      string `json:"avatar"`
+}
+
+type mastodonMediaResponse struct {
+	ID  string  `json:"id"`
+	URL *string `json:"url"`
+}
+
+type mastodonStatusResponse struct {
+	ID string `json:"id"`
+}
+
+type mastodonClient struct {
+	httpClient  *http.Client
+	redirectUrl string
+	scope       string
+}
+
+// RegisterApp регистрирует приложение через /api/v1/apps, если в credentials еще нет client_id
+func (m *mastodonClient) RegisterApp(ctx context.Context, credentials string) (string, error) {
+	var data mastodonAppResponse
+
+	mastodonCredentials, err := m.stringToMastodonCredentials(credentials)
+	if err != nil {
+		return "", err
+	}
+	if mastodonCredentials.ClientID != "" {
+		return credentials, nil
+	}
+
+	form := url.Values{
+		"client_name":   []string{mastodonAppName},
+		"redirect_uris": []string{m.redirectUrl},
+		"scopes":        []string{m.scope},
+	}
+	req, err := m.newFormRequest("POST", fmt.Sprintf("%s/api/v1/apps", mastodonCredentials.InstanceURL), form)
+	if err != nil {
+		return "", tracerr.Errorf("cannot create register app request:\n%s", err)
+	}
+	if err := m.doJSONRequest(req.WithContext(ctx), &data); err != nil {
+		return "", tracerr.Errorf("cannot register app on %s:\n%s", mastodonCredentials.InstanceURL, err)
+	}
+	if data.ClientID == "" || data.ClientSecret == "" {
+		return "", tracerr.Errorf("instance %s returned app without client credentials", mastodonCredentials.InstanceURL)
+	}
+
+	mastodonCredentials.ClientID = data.ClientID
+	mastodonCredentials.ClientSecret = data.ClientSecret
+	registeredCredentials, err := json.Marshal(mastodonCredentials)
+	if err != nil {
+		return "", tracerr.Errorf("cannot marshal mastodon credentials:\n%s", err)
+	}
+
+	return string(registeredCredentials), nil
+}
+
+func (m *mastodonClient) GetAuthURL(credentials string) (string, error) {
+	mastodonCredentials, err := m.stringToMastodonCredentials(credentials)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/oauth/authorize", mastodonCredentials.InstanceURL), nil)
+	if err != nil {
+		return "", tracerr.Errorf("cannot create auth url request")
+	}
+
+	q := url.Values{
+		"client_id":     []string{mastodonCredentials.ClientID},
+		"redirect_uri":  []string{m.redirectUrl},
+		"response_type": []string{"code"},
+		"scope":         []string{m.scope},
+	}
+	req.URL.RawQuery = q.Encode()
+
+	return req.URL.String(), nil
+}
+
+// GetAccessToken токены Mastodon бессрочные, пока пользователь не отзовет доступ приложения
+func (m *mastodonClient) GetAccessToken(
+	credentials string,
+	queryParams map[string][]string,
+) (*social_network_client.AccessToken, error) {
+	var data mastodonAccessTokenResponse
+
+	mastodonCredentials, err := m.stringToMastodonCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    []string{"authorization_code"},
+		"client_id":     []string{mastodonCredentials.ClientID},
+		"client_secret": []string{mastodonCredentials.ClientSecret},
+		"redirect_uri":  []string{m.redirectUrl},
+		"scope":         []string{m.scope},
+		"code":          []string{queryParams["code"][0]},
+	}
+	req, err := m.newFormRequest("POST", fmt.Sprintf("%s/oauth/token", mastodonCredentials.InstanceURL), form)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot create access token request:\n%s", err)
+	}
+	if err := m.doJSONRequest(req, &data); err != nil {
+		return nil, tracerr.Errorf("cannot get access token:\n%s", err)
+	}
+
+	return social_network_client.NewAccessToken(data.AccessToken, 0), nil
+}
+
+func (m *mastodonClient) GetAccountPages(
+	credentials string,
+	accessToken string,
+) ([]social_network_client.SocialNetworkPage, error) {
+	return social_network_client.GetAllAccountPages(m, credentials, accessToken)
+}
+
+// GetAccountPagesChunk в Mastodon публикация идет только в ленту самого аккаунта, он и есть единственная страница
+func (m *mastodonClient) GetAccountPagesChunk(
+	credentials string,
+	accessToken string,
+	cursor social_network_client.PagesCursor,
+) (*social_network_client.SocialNetworkPagesChunk, error) {
+	var data mastodonAccountResponse
+
+	mastodonCredentials, err := m.stringToMastodonCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+	if accessToken == "" {
+		accessToken = mastodonCredentials.AccessToken
+	}
+
+	req, err := http.NewRequest(
+		"GET",
+		fmt.Sprintf("%s/api/v1/accounts/verify_credentials", mastodonCredentials.InstanceURL),
+		nil,
+	)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot create verify credentials request:\n%s", err)
+	}
+	setAuthorization(req, accessToken)
+	if err := m.doJSONRequest(req, &data); err != nil {
+		return nil, tracerr.Errorf("cannot verify account credentials:\n%s", err)
+	}
+
+	name := data.DisplayName
+	if name == "" {
+		name = data.Acct
+	}
+
+	return &social_network_client.SocialNetworkPagesChunk{
+		Pages: []social_network_client.SocialNetworkPage{{
+			ID:          data.ID,
+			Name:        name,
+			Description: data.Note,
+			Image:       data.Avatar,
+		}},
+	}, nil
+}
+
+// CreatePost загружает медиа с alt text и публикует статус в ленту аккаунта, accountID не используется
+func (m *mastodonClient) CreatePost(
+	ctx context.Context,
+	credentials string,
+	accessToken string,
+	accountID string,
+	post social_network_client.Post,
+) (string, error) {
+	var data mastodonStatusResponse
+
+	mastodonCredentials, err := m.stringToMastodonCredentials(credentials)
+	if err != nil {
+		return "", err
+	}
+	if accessToken == "" {
+		accessToken = mastodonCredentials.AccessToken
+	}
+
+	var mediaIDs []string
+	for _, media := range mastodonPostMedia(post) {
+		mediaID, err := m.uploadMedia(ctx, mastodonCredentials, accessToken, media)
+		if err != nil {
+			return "", err
+		}
+		mediaIDs = append(mediaIDs, mediaID)
+	}
+
+	req, err := m.newCreateStatusRequest(mastodonCredentials, accessToken, post, mediaIDs)
+	if err != nil {
+		return "", err
+	}
+	if err := m.doJSONRequest(req.WithContext(ctx), &data); err != nil {
+		return "", tracerr.Errorf("cannot create status:\n%s", err)
+	}
+
+	return data.ID, nil
+}
+
+// PreparePost собирает запросы загрузки медиа и публикации статуса,
+// вместо файлов в запросах загрузки указаны их адреса, вместо идентификаторов медиа - заглушки
+func (m *mastodonClient) PreparePost(
+	credentials string,
+	accessToken string,
+	accountIDs []string,
+	post social_network_client.Post,
+) ([]social_network_client.PreparedRequest, error) {
+	mastodonCredentials, err := m.stringToMastodonCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+	if accessToken == "" {
+		accessToken = mastodonCredentials.AccessToken
+	}
+
+	var requests []*http.Request
+	var mediaIDs []string
+	for index, media := range mastodonPostMedia(post) {
+		form := url.Values{
+			"file": []string{media.url},
+		}
+		if media.description != "" {
+			form.Set("description", media.description)
+		}
+		req, err := m.newFormRequest("POST", fmt.Sprintf("%s/api/v2/media", mastodonCredentials.InstanceURL), form)
+		if err != nil {
+			return nil, tracerr.Errorf("cannot create upload media request:\n%s", err)
+		}
+		setAuthorization(req, accessToken)
+		requests = append(requests, req)
+		mediaIDs = append(mediaIDs, fmt.Sprintf("{media_%d}", index+1))
+	}
+
+	req, err := m.newCreateStatusRequest(mastodonCredentials, accessToken, post, mediaIDs)
+	if err != nil {
+		return nil, err
+	}
+	requests = append(requests, req)
+
+	preparedRequests := make([]social_network_client.PreparedRequest, 0, len(requests))
+	for _, req := range requests {
+		preparedRequest, err := social_network_client.NewPreparedRequest(accountIDs, req)
+		if err != nil {
+			return nil, err
+		}
+		preparedRequests = append(preparedRequests, preparedRequest)
+	}
+
+	return preparedRequests, nil
+}
+
+func (m *mastodonClient) DeletePost(credentials, accessToken, statusID string) error {
+	mastodonCredentials, err := m.stringToMastodonCredentials(credentials)
+	if err != nil {
+		return err
+	}
+	if accessToken == "" {
+		accessToken = mastodonCredentials.AccessToken
+	}
+
+	req, err := http.NewRequest(
+		"DELETE",
+		fmt.Sprintf("%s/api/v1/statuses/%s", mastodonCredentials.InstanceURL, url.PathEscape(statusID)),
+		nil,
+	)
+	if err != nil {
+		return tracerr.Errorf("cannot create delete status request:\n%s", err)
+	}
+	setAuthorization(req, accessToken)
+	if err := m.doJSONRequest(req, nil); err != nil {
+		return tracerr.Errorf("cannot delete status %s:\n%s", statusID, err)
+	}
+
+	return nil
+}
+
+func (m *mastodonClient) Capabilities() social_network_client.Capabilities {
+	return capabilities
+}
+
+// ComposeText текст статуса с вопросом опроса и ссылкой
+func (m *mastodonClient) ComposeText(post social_network_client.Post) string {
+	return mastodonStatusText(post)
+}
+
+func (m *mastodonClient) newCreateStatusRequest(
+	mastodonCredentials *MastodonCredentials,
+	accessToken string,
+	post social_network_client.Post,
+	mediaIDs []string,
+) (*http.Request, error) {
+	form := url.Values{
+		"status": []string{mastodonStatusText(post)},
+	}
+	if post.Visibility != "" {
+		form.Set("visibility", string(post.Visibility))
+	}
+	if post.ContentWarning != "" {
+		form.Set("spoiler_text", post.ContentWarning)
+		form.Set("sensitive", "true")
+	}
+	for _, mediaID := range mediaIDs {
+		form.Add("media_ids[]", mediaID)
+	}
+	if post.Poll != nil {
+		for _, answer := range post.Poll.Answers {
+			form.Add("poll[options][]", answer)
+		}
+		form.Set("poll[expires_in]", strconv.Itoa(int(mastodonPollExpiresIn.Seconds())))
+	}
+
+	req, err := m.newFormRequest("POST", fmt.Sprintf("%s/api/v1/statuses", mastodonCredentials.InstanceURL), form)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot create status request:\n%s", err)
+	}
+	setAuthorization(req, accessToken)
+
+	return req, nil
+}
+
+// mastodonMedia вложение статуса: изображение или видео по ссылке
+type mastodonMedia struct {
+	url         string
+	description string
+}
+
+func mastodonPostMedia(post social_network_client.Post) []mastodonMedia {
+	var media []mastodonMedia
+	for index, image := range post.Images {
+		item := mastodonMedia{url: image}
+		if index < len(post.ImagesAlt) {
+			item.description = post.ImagesAlt[index]
+		}
+		media = append(media, item)
+	}
+	if post.Video != "" {
+		media = append(media, mastodonMedia{url: post.Video})
+	}
+	return media
+}
+
+// mastodonStatusText у опросов Mastodon нет вопроса, а ссылки пишутся в тексте, поэтому они дописываются к тексту
+func mastodonStatusText(post social_network_client.Post) string {
+	parts := []string{post.Text}
+	if post.Poll != nil && post.Poll.Question != "" {
+		parts = append(parts, post.Poll.Question)
+	}
+	if post.Link != "" && !strings.Contains(post.Text, post.Link) {
+		parts = append(parts, post.Link)
+	}
+	return strings.TrimSpace(strings.Join(parts, "\n\n"))
+}
+
+// uploadMedia скачивает файл по ссылке и загружает его в инстанс, дожидаясь окончания обработки.
+// Ожидание прерывается отменой ctx
+func (m *mastodonClient) uploadMedia(
+	ctx context.Context,
+	mastodonCredentials *MastodonCredentials,
+	accessToken string,
+	media mastodonMedia,
+) (string, error) {
+	var data mastodonMediaResponse
+
+	downloadReq, err := http.NewRequestWithContext(ctx, "GET", media.url, nil)
+	if err != nil {
+		return "", tracerr.Errorf("cannot create download media request:\n%s", err)
+	}
+	resp, err := m.httpClient.Do(downloadReq)
+	if err != nil {
+		return "", tracerr.Errorf("cannot download media %s:\n%s", media.url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", tracerr.Errorf("download media %s response status is %d", media.url, resp.StatusCode)
+	}
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	fileWriter, err := writer.CreateFormFile("file", path.Base(resp.Request.URL.Path))
+	if err != nil {
+		return "", tracerr.Errorf("cannot create media form file:\n%s", err)
+	}
+	if _, err := io.Copy(fileWriter, resp.Body); err != nil {
+		return "", tracerr.Errorf("cannot read media %s:\n%s", media.url, err)
+	}
+	if media.description != "" {
+		if err := writer.WriteField("description", media.description); err != nil {
+			return "", tracerr.Errorf("cannot write media description:\n%s", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return "", tracerr.Errorf("cannot close media form:\n%s", err)
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		"POST",
+		fmt.Sprintf("%s/api/v2/media", mastodonCredentials.InstanceURL),
+		body,
+	)
+	if err != nil {
+		return "", tracerr.Errorf("cannot create upload media request:\n%s", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	setAuthorization(req, accessToken)
+	if err := m.doJSONRequest(req, &data); err != nil {
+		return "", tracerr.Errorf("cannot upload media %s:\n%s", media.url, err)
+	}
+
+	// Пока url пустой, инстанс обрабатывает файл и не даст прикрепить его к статусу
+	for attempt := 0; data.URL == nil; attempt++ {
+		if attempt == mastodonMediaProcessingAttempts {
+			return "", tracerr.Errorf("media %s is still processing", data.ID)
+		}
+		timer := time.NewTimer(mastodonMediaProcessingInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return "", tracerr.Errorf("media %s processing wait canceled:\n%s", data.ID, ctx.Err())
+		case <-timer.C:
+		}
+
+		req, err := http.NewRequestWithContext(
+			ctx,
+			"GET",
+			fmt.Sprintf("%s/api/v1/media/%s", mastodonCredentials.InstanceURL, url.PathEscape(data.ID)),
+			nil,
+		)
+		if err != nil {
+			return "", tracerr.Errorf("cannot create get media request:\n%s", err)
+		}
+		setAuthorization(req, accessToken)
+		if err := m.doJSONRequest(req, &data); err != nil {
+			return "", tracerr.Errorf("cannot get media %s:\n%s", data.ID, err)
+		}
+	}
+
+	return data.ID, nil
+}
+
+func (m *mastodonClient) newFormRequest(method, requestURL string, form url.Values) (*http.Request, error) {
+	req, err := http.NewRequest(method, requestURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req, nil
+}
+
+// doJSONRequest успешными считаются все ответы 2xx, 202 инстанс возвращает для медиа в обработке
+func (m *mastodonClient) doJSONRequest(req *http.Request, v interface{}) error {
+	resp, err := m.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return tracerr.Errorf("cannot read response:\n%s", err)
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return tracerr.Errorf("response status is %d\nresponse:%s", resp.StatusCode, string(respBody))
+	}
+
+	if v == nil {
+		return nil
+	}
+	err = json.Unmarshal(respBody, v)
+	if err != nil {
+		return tracerr.Errorf("cannot unmarshal response body:\n%s", err)
+	}
+
+	return nil
+}
+
+func setAuthorization(req *http.Request, accessToken string) {
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
+}
+
+func (m *mastodonClient) stringToMastodonCredentials(credentials string) (*MastodonCredentials, error) {
+	mastodonCredentials := &MastodonCredentials{}
+	err := json.Unmarshal([]byte(credentials), mastodonCredentials)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot unmarshal mastodon credentials {%s}:\n%s", credentials, err)
+	}
+	if mastodonCredentials.InstanceURL == "" {
+		return nil, tracerr.Errorf("mastodon credentials have no instance_url")
+	}
+	mastodonCredentials.InstanceURL = strings.TrimSuffix(mastodonCredentials.InstanceURL, "/")
+	return mastodonCredentials, nil
+}
+
+func NewMastodonClient(config social_network_client.ClientConfig) social_network_client.SocialNetworkClient {
+	return &mastodonClient{
+		httpClient:  &http.Client{Timeout: mastodonTimeout},
+		redirectUrl: config.RedirectUrl,
+		scope:       oauthScope,
+	}
+}
@@ -0,0 +1,33 @@
+package mastodon
+
+import "autoposting/internal/infrastructure/social_network_client"
+
+const (
+	Name       = "MASTODON"
+	oauthScope = "read:accounts write:statuses write:media"
+)
+
+// capabilities лимиты инстанса по умолчанию, инстансы могут их увеличивать
+var capabilities = social_network_client.Capabilities{
+	MaxTextLength: 500,
+	Images:        true,
+	MaxImages:     4,
+	Video:         true,
+	Links:         true,
+	Polls:         true,
+	// Статус с опросом и вложениями инстанс отклоняет с 422
+	PollExcludesMedia: true,
+}
+
+func init() {
+	// Адрес инстанса задается в credentials аккаунта, поэтому в настройках клиента только redirect url
+	social_network_client.Register(social_network_client.Network{
+		Name:        Name,
+		DisplayName: "Mastodon",
+		OAuth: &social_network_client.OAuthSettings{
+			Scope: oauthScope,
+		},
+		Capabilities: capabilities,
+		NewClient:    NewMastodonClient,
+	})
+}
@@ -0,0 +1,33 @@
+package ok
+
+import "autoposting/internal/infrastructure/social_network_client"
+
+const (
+	Name       = "OK"
+	oauthScope = "VALUABLE_ACCESS;LONG_ACCESS_TOKEN;PHOTO_CONTENT;GROUP_CONTENT;VIDEO_CONTENT"
+)
+
+// capabilities клиент публикует текст, ссылку и опрос
+var capabilities = social_network_client.Capabilities{
+	MaxTextLength: 10000,
+	Images:        false,
+	Video:         false,
+	Links:         true,
+	Polls:         true,
+}
+
+func init() {
+	social_network_client.Register(social_network_client.Network{
+		Name:        Name,
+		DisplayName: "Одноклассники",
+		OAuth: &social_network_client.OAuthSettings{
+			Scope: oauthScope,
+		},
+		Capabilities: capabilities,
+		DefaultConfig: social_network_client.ClientConfig{
+			AuthApiUrl: "https://connect.ok.ru",
+			WorkApiUrl: "https://api.ok.ru",
+		},
+		NewClient: NewOKClient,
+	})
+}
@@ -0,0 +1,115 @@
+package ok
+
+import (
+	"autoposting/internal/infrastructure/social_network_client"
+	"encoding/json"
+	"fmt"
+	"github.com/ztrue/tracerr"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// okGroupsInfoLimit максимальное число uids group.getInfo
+const okGroupsInfoLimit = 100
+
+type okGroupMembersResponse struct {
+	ID           string `json:"uid"`
+	MembersCount int    `json:"members_count"`
+}
+
+// GetPagesAudience число участников групп из members_count group.getInfo
+func (o *okClient) GetPagesAudience(
+	credentials string,
+	accessToken string,
+	groupIDs []string,
+) ([]social_network_client.PageAudienceResult, error) {
+	okCredentials, err := o.stringToOKCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+	if accessToken == "" {
+		accessToken = okCredentials.AccessToken
+	}
+
+	membersByGroup := make(map[string]int, len(groupIDs))
+	for start := 0; start < len(groupIDs); start += okGroupsInfoLimit {
+		end := start + okGroupsInfoLimit
+		if end > len(groupIDs) {
+			end = len(groupIDs)
+		}
+		if err := o.getGroupsMembers(okCredentials, accessToken, groupIDs[start:end], membersByGroup); err != nil {
+			return nil, err
+		}
+	}
+
+	results := make([]social_network_client.PageAudienceResult, 0, len(groupIDs))
+	for _, groupID := range groupIDs {
+		result := social_network_client.PageAudienceResult{PageID: groupID}
+		if members, ok := membersByGroup[groupID]; ok {
+			result.Followers = members
+		} else {
+			result.Err = tracerr.Errorf("group %s not found", groupID)
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+func (o *okClient) getGroupsMembers(
+	okCredentials *OKCredentials,
+	accessToken string,
+	groupIDs []string,
+	membersByGroup map[string]int,
+) error {
+	var data []okGroupMembersResponse
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/group/getInfo", o.workApiUrl), nil)
+	if err != nil {
+		return tracerr.Errorf("cannot create getting groups members request:\n%s", err)
+	}
+	q := url.Values{
+		"application_key":    []string{okCredentials.PublicKey},
+		"access_token":       []string{accessToken},
+		"session_secret_key": []string{okCredentials.SecretKey},
+		"format":             []string{"json"},
+		"uids":               []string{strings.Join(groupIDs, ",")},
+		"fields":             []string{"uid,members_count"},
+	}
+	req.URL.RawQuery = q.Encode()
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return tracerr.Errorf("cannot get groups members:\n%s", err)
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return tracerr.Errorf("cannot read getting groups members response:\n%s", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return tracerr.Errorf(
+			"get groups members response status %d\nresponse:%s",
+			resp.StatusCode,
+			string(respBody),
+		)
+	}
+
+	// Успешный ответ - массив групп, ошибка приходит объектом
+	var errorData okError
+	if json.Unmarshal(respBody, &errorData) == nil && errorData.ErrorCode != 0 {
+		return tracerr.Errorf("get groups members failed with code %d: %s", errorData.ErrorCode, errorData.ErrorMsg)
+	}
+
+	err = json.Unmarshal(respBody, &data)
+	if err != nil {
+		return tracerr.Errorf("cannot unmarshal getting groups members body:\n%s", err)
+	}
+
+	for _, group := range data {
+		membersByGroup[group.ID] = group.MembersCount
+	}
+	return nil
+}
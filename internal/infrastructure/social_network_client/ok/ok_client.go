@@ -0,0 +1,688 @@
+package ok
+
+import (
+	"autoposting/internal/infrastructure/social_network_client"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ztrue/tracerr"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type OKCredentials struct {
+	AppID       string `json:"app_id"`
+	PublicKey   string `json:"public_key"`
+	SecretKey   string `json:"secret_key"`
+	AccessToken string `json:"access_token"`
+}
+
+const (
+	okPagesChunkLimit = 100
+	okPhotosInfoLimit = 100
+	okTopicsByIdLimit = 100
+)
+
+type okClient struct {
+	httpClient  *http.Client
+	authApiUrl  string
+	workApiUrl  string
+	redirectUrl string
+}
+
+type okAccessTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    string `json:"expires_in"`
+}
+
+// okError OK возвращает ошибки со статусом 200 в теле ответа
+type okError struct {
+	ErrorCode int    `json:"error_code"`
+	ErrorMsg  string `json:"error_msg"`
+}
+
+type okGetAccountPagesResponse struct {
+	okError
+	Groups []struct {
+		GroupId string `json:"groupId"`
+		Status  string `json:"status"`
+	} `json:"groups"`
+	Anchor string `json:"anchor"`
+}
+
+type okGetPagesInfoResponse struct {
+	ID             string `json:"uid"`
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	PreviewImageId string `json:"photo_id"`
+}
+
+type okPhotoInfo struct {
+	ID       string `json:"id"`
+	ImageUrl string `json:"pic128x128"`
+}
+
+type okGetPhotosInfoResponse struct {
+	Photos []okPhotoInfo `json:"photos"`
+}
+
+// okGetMediaTopicsResponse удаленных тем в media_topics нет
+type okGetMediaTopicsResponse struct {
+	okError
+	MediaTopics []okMediaTopic `json:"media_topics"`
+}
+
+type okMediaTopic struct {
+	ID    string `json:"id"`
+	Media []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"media"`
+	LikeSummary struct {
+		Count int `json:"count"`
+	} `json:"like_summary"`
+	ReshareSummary struct {
+		Count int `json:"count"`
+	} `json:"reshare_summary"`
+	DiscussionSummary struct {
+		CommentsCount int `json:"comments_count"`
+	} `json:"discussion_summary"`
+	ViewsCount int `json:"views_count"`
+}
+
+// text текст темы - текстовые блоки media через перевод строки
+func (t okMediaTopic) text() string {
+	var texts []string
+	for _, media := range t.Media {
+		if media.Type == "text" {
+			texts = append(texts, media.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+func (o *okClient) GetAuthURL(credentials string) (string, error) {
+	okCredentials, err := o.stringToOKCredentials(credentials)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/oauth/authorize", o.authApiUrl), nil)
+	if err != nil {
+		return "", tracerr.Errorf("cannot create auth url request")
+	}
+
+	q := url.Values{
+		"client_id":     []string{okCredentials.AppID},
+		"redirect_uri":  []string{o.redirectUrl},
+		"response_type": []string{"code"},
+		"scope":         []string{oauthScope},
+	}
+	req.URL.RawQuery = q.Encode()
+
+	return req.URL.String(), nil
+}
+
+func (o *okClient) GetAccessToken(credentials string, queryParams map[string][]string) (*social_network_client.AccessToken, error) {
+	var (
+		data okAccessTokenResponse
+	)
+
+	okCredentials, err := o.stringToOKCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s%s", o.workApiUrl, "/oauth/token.do"), nil)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot create access token request:\n%s", err)
+	}
+	q := url.Values{
+		"code":          []string{queryParams["code"][0]},
+		"client_id":     []string{okCredentials.AppID},
+		"client_secret": []string{okCredentials.SecretKey},
+		"redirect_uri":  []string{o.redirectUrl},
+		"grant_type":    []string{"authorization_code"},
+	}
+	req.URL.RawQuery = q.Encode()
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot get access token:\n%s", err)
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot read access token response:\n%s", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, tracerr.Errorf(
+			"access token response status is %d\ntokenResponse:%s",
+			resp.StatusCode,
+			resp.Request.URL,
+		)
+	}
+
+	err = json.Unmarshal(respBody, &data)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot unmarshal access token body:\n%s", err)
+	}
+
+	// OK отдает expires_in строкой, пустое значение считаем бессрочным токеном
+	expiresIn, _ := strconv.ParseInt(data.ExpiresIn, 10, 64)
+
+	return social_network_client.NewAccessToken(data.AccessToken, expiresIn), nil
+}
+
+func (o *okClient) GetAccountPages(credentials, accessToken string) ([]social_network_client.SocialNetworkPage, error) {
+	return social_network_client.GetAllAccountPages(o, credentials, accessToken)
+}
+
+func (o *okClient) GetAccountPagesChunk(
+	credentials string,
+	accessToken string,
+	cursor social_network_client.PagesCursor,
+) (*social_network_client.SocialNetworkPagesChunk, error) {
+	var (
+		pagesIds []string
+		data     okGetAccountPagesResponse
+	)
+
+	if cursor.Limit == 0 {
+		cursor.Limit = okPagesChunkLimit
+	}
+
+	okCredentials, err := o.stringToOKCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(
+		"GET", fmt.Sprintf("%s/api/group/getUserGroupsV2", o.workApiUrl), nil,
+	)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot create getting account pages request:\n%s", err)
+	}
+	q := url.Values{
+		"application_key":    []string{okCredentials.PublicKey},
+		"access_token":       []string{accessToken},
+		"session_secret_key": []string{okCredentials.SecretKey},
+		"format":             []string{"json"},
+		"count":              []string{strconv.Itoa(cursor.Limit)},
+		"direction":          []string{"FORWARD"},
+	}
+	if cursor.Cursor != "" {
+		q.Set("anchor", cursor.Cursor)
+	}
+	req.URL.RawQuery = q.Encode()
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot get account pages:\n%s", err)
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot read getting pages response:\n%s", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, tracerr.Errorf(
+			"get account pages response status %d\npagesResponse:%s",
+			resp.StatusCode,
+			string(respBody),
+		)
+	}
+
+	err = json.Unmarshal(respBody, &data)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot unmarshal getting pages body:\n%s", err)
+	}
+
+	if data.ErrorCode != 0 {
+		return nil, tracerr.Errorf("get account pages failed with code %d: %s", data.ErrorCode, data.ErrorMsg)
+	}
+
+	for _, page := range data.Groups {
+		if page.Status == "ADMIN" {
+			pagesIds = append(pagesIds, page.GroupId)
+		}
+	}
+
+	chunk := &social_network_client.SocialNetworkPagesChunk{}
+	// Неполная порция означает, что групп больше нет
+	if len(data.Groups) == cursor.Limit {
+		chunk.NextCursor = data.Anchor
+	}
+	if len(pagesIds) == 0 {
+		return chunk, nil
+	}
+
+	chunk.Pages, err = o.getPagesInfo(okCredentials, accessToken, pagesIds)
+	if err != nil {
+		return nil, err
+	}
+
+	return chunk, nil
+}
+
+func (o *okClient) getPagesInfo(
+	okCredentials *OKCredentials,
+	accessToken string,
+	pagesIds []string,
+) ([]social_network_client.SocialNetworkPage, error) {
+	var (
+		pages []social_network_client.SocialNetworkPage
+		data  []okGetPagesInfoResponse
+	)
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/group/getInfo", o.workApiUrl), nil)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot create getting pages info request:\n%s", err)
+	}
+	q := url.Values{
+		"application_key":    []string{okCredentials.PublicKey},
+		"access_token":       []string{accessToken},
+		"session_secret_key": []string{okCredentials.SecretKey},
+		"format":             []string{"json"},
+		"uids":               []string{strings.Join(pagesIds, ",")},
+		"fields":             []string{"name,description,photo_id,uid"},
+	}
+	req.URL.RawQuery = q.Encode()
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot get pages info:\n%s", err)
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot read getting pages info response:\n%s", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, tracerr.Errorf(
+			"get pages info response status %d\npagesInfoResponse:%s",
+			resp.StatusCode,
+			string(respBody),
+		)
+	}
+
+	err = json.Unmarshal(respBody, &data)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot unmarshal getting pages info body:\n%s", err)
+	}
+
+	var imagesIds []string
+	for _, page := range data {
+		if page.PreviewImageId != "" {
+			imagesIds = append(imagesIds, page.PreviewImageId)
+		}
+	}
+
+	// Обложка не обязательна, при ошибке страницы возвращаются без нее
+	imagesUrls, err := o.getImagesUrls(okCredentials, accessToken, imagesIds)
+	if err != nil {
+		slog.Warn(
+			"failed to get images info",
+			slog.Any("imagesIds", imagesIds),
+			slog.Any("err", err),
+		)
+	}
+
+	for _, page := range data {
+		pages = append(pages, social_network_client.SocialNetworkPage{
+			ID:          page.ID,
+			Name:        page.Name,
+			Description: page.Description,
+			Image:       imagesUrls[page.PreviewImageId],
+		})
+	}
+
+	return pages, nil
+}
+
+// getImagesUrls получает ссылки на изображения порциями по okPhotosInfoLimit за запрос
+func (o *okClient) getImagesUrls(
+	okCredentials *OKCredentials,
+	accessToken string,
+	imagesIds []string,
+) (map[string]string, error) {
+	imagesUrls := make(map[string]string, len(imagesIds))
+
+	for start := 0; start < len(imagesIds); start += okPhotosInfoLimit {
+		end := start + okPhotosInfoLimit
+		if end > len(imagesIds) {
+			end = len(imagesIds)
+		}
+
+		photos, err := o.getPhotosInfo(okCredentials, accessToken, imagesIds[start:end])
+		if err != nil {
+			return imagesUrls, err
+		}
+		for _, photo := range photos {
+			imagesUrls[photo.ID] = photo.ImageUrl
+		}
+	}
+
+	return imagesUrls, nil
+}
+
+func (o *okClient) getPhotosInfo(
+	okCredentials *OKCredentials,
+	accessToken string,
+	imagesIds []string,
+) ([]okPhotoInfo, error) {
+	var data okGetPhotosInfoResponse
+
+	req, err := http.NewRequest(
+		"GET", fmt.Sprintf("%s/api/photos/getInfo", o.workApiUrl), nil,
+	)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot create getting images info request:\n%s", err)
+	}
+	q := url.Values{
+		"photo_ids":          []string{strings.Join(imagesIds, ",")},
+		"fields":             []string{"photo.ID,photo.PIC128X128"},
+		"application_key":    []string{okCredentials.PublicKey},
+		"access_token":       []string{accessToken},
+		"session_secret_key": []string{okCredentials.SecretKey},
+		"format":             []string{"json"},
+	}
+	req.URL.RawQuery = q.Encode()
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot get images info:\n%s", err)
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot read getting images info response:\n%s", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, tracerr.Errorf(
+			"get images info response status %d\nimagesInfoResponse:%s",
+			resp.StatusCode,
+			string(respBody),
+		)
+	}
+
+	err = json.Unmarshal(respBody, &data)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot unmarshal getting images info body:\n%s", err)
+	}
+
+	return data.Photos, nil
+}
+
+func (o *okClient) CreatePost(
+	ctx context.Context,
+	credentials string,
+	accessToken string,
+	groupID string,
+	post social_network_client.Post,
+) (string, error) {
+	var topicID string
+
+	req, err := o.newCreatePostRequest(credentials, accessToken, groupID, post)
+	if err != nil {
+		return "", err
+	}
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return "", tracerr.Errorf("cannot create post:\n%s", err)
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", tracerr.Errorf("cannot read create post response:\n%s", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", tracerr.Errorf(
+			"create post response status is %d\nresponse:%s",
+			resp.StatusCode,
+			string(respBody),
+		)
+	}
+
+	// mediatopic.post возвращает идентификатор темы JSON-строкой
+	err = json.Unmarshal(respBody, &topicID)
+	if err != nil {
+		return "", tracerr.Errorf("cannot unmarshal create post body %s:\n%s", string(respBody), err)
+	}
+
+	return topicID, nil
+}
+
+// PreparePost собирает запросы mediatopic.post, которые отправил бы CreatePost
+func (o *okClient) PreparePost(
+	credentials string,
+	accessToken string,
+	groupIDs []string,
+	post social_network_client.Post,
+) ([]social_network_client.PreparedRequest, error) {
+	requests := make([]social_network_client.PreparedRequest, 0, len(groupIDs))
+	for _, groupID := range groupIDs {
+		req, err := o.newCreatePostRequest(credentials, accessToken, groupID, post)
+		if err != nil {
+			return nil, err
+		}
+		preparedRequest, err := social_network_client.NewPreparedRequest([]string{groupID}, req)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, preparedRequest)
+	}
+
+	return requests, nil
+}
+
+func (o *okClient) newCreatePostRequest(
+	credentials string,
+	accessToken string,
+	groupID string,
+	post social_network_client.Post,
+) (*http.Request, error) {
+	okCredentials, err := o.stringToOKCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+	if accessToken == "" {
+		accessToken = okCredentials.AccessToken
+	}
+
+	attachment, err := okPostAttachment(post)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/mediatopic/post", o.workApiUrl), nil)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot create createPost request:\n%s", err)
+	}
+
+	q := req.URL.Query()
+	q.Add("application_key", okCredentials.PublicKey)
+	q.Add("access_token", accessToken)
+	q.Add("type", "GROUP_THEME")
+	q.Add("gid", groupID)
+	q.Add("attachment", attachment)
+	req.URL.RawQuery = q.Encode()
+
+	return req, nil
+}
+
+// okPostAttachment собирает attachment для mediatopic.post
+func okPostAttachment(post social_network_client.Post) (string, error) {
+	var media []map[string]interface{}
+	if post.Text != "" {
+		media = append(media, map[string]interface{}{
+			"type": "text",
+			"text": post.Text,
+		})
+	}
+	if post.Link != "" {
+		media = append(media, map[string]interface{}{
+			"type": "link",
+			"url":  post.Link,
+		})
+	}
+	if post.Poll != nil {
+		media = append(media, map[string]interface{}{
+			"type":     "poll",
+			"question": post.Poll.Question,
+			"answers":  okPollAnswers(post.Poll.Answers),
+		})
+	}
+
+	attachment, err := json.Marshal(map[string]interface{}{
+		"media": media,
+	})
+	if err != nil {
+		return "", tracerr.Errorf("cannot marshal post attachment:\n%s", err)
+	}
+
+	return string(attachment), nil
+}
+
+func okPollAnswers(answers []string) []map[string]string {
+	pollAnswers := make([]map[string]string, 0, len(answers))
+	for _, answer := range answers {
+		pollAnswers = append(pollAnswers, map[string]string{
+			"text": answer,
+		})
+	}
+	return pollAnswers
+}
+
+// GetRemotePosts получает темы через mediatopic.getByIds порциями по okTopicsByIdLimit
+func (o *okClient) GetRemotePosts(
+	credentials string,
+	accessToken string,
+	posts []social_network_client.RemotePost,
+) ([]social_network_client.RemotePostState, error) {
+	okCredentials, err := o.stringToOKCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+	if accessToken == "" {
+		accessToken = okCredentials.AccessToken
+	}
+
+	states := make([]social_network_client.RemotePostState, 0, len(posts))
+	for start := 0; start < len(posts); start += okTopicsByIdLimit {
+		end := start + okTopicsByIdLimit
+		if end > len(posts) {
+			end = len(posts)
+		}
+
+		topicsIDs := make([]string, 0, end-start)
+		for _, post := range posts[start:end] {
+			topicsIDs = append(topicsIDs, post.PostID)
+		}
+		topicsByID, err := o.getMediaTopics(okCredentials, accessToken, topicsIDs)
+		for _, post := range posts[start:end] {
+			state := social_network_client.RemotePostState{
+				Post: post,
+				Err:  err,
+			}
+			if err == nil {
+				topic, ok := topicsByID[post.PostID]
+				state.Deleted = !ok
+				state.Text = topic.text()
+			}
+			states = append(states, state)
+		}
+	}
+
+	return states, nil
+}
+
+// getMediaTopics темы по id, удаленных тем в ответе нет
+func (o *okClient) getMediaTopics(
+	okCredentials *OKCredentials,
+	accessToken string,
+	topicsIDs []string,
+) (map[string]okMediaTopic, error) {
+	var data okGetMediaTopicsResponse
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/mediatopic/getByIds", o.workApiUrl), nil)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot create getting media topics request:\n%s", err)
+	}
+	q := url.Values{
+		"topic_ids":          []string{strings.Join(topicsIDs, ",")},
+		"fields":             []string{"media_topic.*"},
+		"application_key":    []string{okCredentials.PublicKey},
+		"access_token":       []string{accessToken},
+		"session_secret_key": []string{okCredentials.SecretKey},
+		"format":             []string{"json"},
+	}
+	req.URL.RawQuery = q.Encode()
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot get media topics:\n%s", err)
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot read getting media topics response:\n%s", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, tracerr.Errorf(
+			"get media topics response status %d\nresponse:%s",
+			resp.StatusCode,
+			string(respBody),
+		)
+	}
+
+	err = json.Unmarshal(respBody, &data)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot unmarshal getting media topics body:\n%s", err)
+	}
+
+	if data.ErrorCode != 0 {
+		return nil, tracerr.Errorf("get media topics failed with code %d: %s", data.ErrorCode, data.ErrorMsg)
+	}
+
+	topicsByID := make(map[string]okMediaTopic, len(data.MediaTopics))
+	for _, topic := range data.MediaTopics {
+		topicsByID[topic.ID] = topic
+	}
+
+	return topicsByID, nil
+}
+
+func (o *okClient) Capabilities() social_network_client.Capabilities {
+	return capabilities
+}
+
+// DeletePost mediatopic.deleteTopic требует gid, а по id топика группу не определить
+func (o *okClient) DeletePost(credentials, accessToken, postID string) error {
+	return tracerr.Errorf("ok client does not support deleting posts")
+}
+
+func (o *okClient) stringToOKCredentials(credentials string) (*OKCredentials, error) {
+	okCredentials := &OKCredentials{}
+	err := json.Unmarshal([]byte(credentials), okCredentials)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot unmarshal ok credentials {%s}:\n%s", credentials, err)
+	}
+	return okCredentials, nil
+}
+
+func NewOKClient(config social_network_client.ClientConfig) social_network_client.SocialNetworkClient {
+	client := okClient{
+		httpClient:  &http.Client{},
+		authApiUrl:  config.AuthApiUrl,
+		workApiUrl:  config.WorkApiUrl,
+		redirectUrl: config.RedirectUrl,
+	}
+	return &client
+}
@@ -0,0 +1,188 @@
+package ok
+
+import (
+	"autoposting/internal/infrastructure/social_network_client"
+	"encoding/json"
+	"fmt"
+	"github.com/ztrue/tracerr"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// okCommentsChunkLimit максимальный count discussions.getComments
+	okCommentsChunkLimit = 100
+	// okDiscussionType комментарии поста группы - обсуждение ее темы
+	okDiscussionType = "GROUP_TOPIC"
+)
+
+type okGetCommentsResponse struct {
+	okError
+	Comments []struct {
+		ID               string `json:"id"`
+		Text             string `json:"text"`
+		AuthorID         string `json:"author_id"`
+		AuthorName       string `json:"author_name"`
+		AuthorType       string `json:"author_type"`
+		DateMs           int64  `json:"date_ms"`
+		ReplyToCommentID string `json:"reply_to_comment_id"`
+	} `json:"comments"`
+	Anchor  string `json:"anchor"`
+	HasMore bool   `json:"has_more"`
+}
+
+// GetPostComments читает обсуждение темы порциями discussions.getComments от старых к новым
+func (o *okClient) GetPostComments(
+	credentials string,
+	accessToken string,
+	post social_network_client.RemotePost,
+) ([]social_network_client.Comment, error) {
+	okCredentials, err := o.stringToOKCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+	if accessToken == "" {
+		accessToken = okCredentials.AccessToken
+	}
+
+	var (
+		comments []social_network_client.Comment
+		anchor   string
+	)
+	for {
+		var data okGetCommentsResponse
+		params := url.Values{
+			"discussionId":   []string{post.PostID},
+			"discussionType": []string{okDiscussionType},
+			"count":          []string{strconv.Itoa(okCommentsChunkLimit)},
+			"direction":      []string{"FORWARD"},
+		}
+		if anchor != "" {
+			params.Set("anchor", anchor)
+		}
+		if err := o.callDiscussionsMethod(okCredentials, accessToken, "getComments", params, &data); err != nil {
+			return nil, err
+		}
+
+		for _, comment := range data.Comments {
+			comments = append(comments, social_network_client.Comment{
+				CommentID:  comment.ID,
+				ParentID:   comment.ReplyToCommentID,
+				AuthorID:   comment.AuthorID,
+				AuthorName: comment.AuthorName,
+				ByPage:     comment.AuthorType == "GROUP" && comment.AuthorID == post.PageID,
+				Text:       comment.Text,
+				CreatedAt:  time.UnixMilli(comment.DateMs),
+			})
+		}
+
+		if !data.HasMore || len(data.Comments) == 0 || data.Anchor == anchor {
+			return comments, nil
+		}
+		anchor = data.Anchor
+	}
+}
+
+// ReplyToComment отвечает через discussions.comment от имени группы
+func (o *okClient) ReplyToComment(
+	credentials string,
+	accessToken string,
+	comment social_network_client.RemoteComment,
+	text string,
+) (string, error) {
+	okCredentials, err := o.stringToOKCredentials(credentials)
+	if err != nil {
+		return "", err
+	}
+	if accessToken == "" {
+		accessToken = okCredentials.AccessToken
+	}
+
+	var commentID string
+	err = o.callDiscussionsMethod(okCredentials, accessToken, "comment", url.Values{
+		"discussionId":        []string{comment.PostID},
+		"discussionType":      []string{okDiscussionType},
+		"comment":             []string{text},
+		"reply_to_comment_id": []string{comment.CommentID},
+		"as_admin":            []string{"true"},
+	}, &commentID)
+	if err != nil {
+		return "", err
+	}
+
+	return commentID, nil
+}
+
+// DeleteComment удаляет комментарий из обсуждения темы через discussions.deleteComment
+func (o *okClient) DeleteComment(
+	credentials string,
+	accessToken string,
+	comment social_network_client.RemoteComment,
+) error {
+	okCredentials, err := o.stringToOKCredentials(credentials)
+	if err != nil {
+		return err
+	}
+	if accessToken == "" {
+		accessToken = okCredentials.AccessToken
+	}
+
+	var deleted bool
+	return o.callDiscussionsMethod(okCredentials, accessToken, "deleteComment", url.Values{
+		"discussionId":   []string{comment.PostID},
+		"discussionType": []string{okDiscussionType},
+		"commentId":      []string{comment.CommentID},
+	}, &deleted)
+}
+
+// callDiscussionsMethod вызывает метод discussions.*. Ответ методов может быть строкой или bool,
+// поэтому ошибка проверяется отдельным разбором тела
+func (o *okClient) callDiscussionsMethod(
+	okCredentials *OKCredentials,
+	accessToken string,
+	method string,
+	params url.Values,
+	data interface{},
+) error {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/discussions/%s", o.workApiUrl, method), nil)
+	if err != nil {
+		return tracerr.Errorf("cannot create discussions.%s request:\n%s", method, err)
+	}
+	params.Set("application_key", okCredentials.PublicKey)
+	params.Set("access_token", accessToken)
+	params.Set("session_secret_key", okCredentials.SecretKey)
+	params.Set("format", "json")
+	req.URL.RawQuery = params.Encode()
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return tracerr.Errorf("cannot call discussions.%s:\n%s", method, err)
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return tracerr.Errorf("cannot read discussions.%s response:\n%s", method, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return tracerr.Errorf(
+			"discussions.%s response status %d\nresponse:%s",
+			method,
+			resp.StatusCode,
+			string(respBody),
+		)
+	}
+
+	var errorData okError
+	if json.Unmarshal(respBody, &errorData) == nil && errorData.ErrorCode != 0 {
+		return tracerr.Errorf("discussions.%s failed with code %d: %s", method, errorData.ErrorCode, errorData.ErrorMsg)
+	}
+
+	err = json.Unmarshal(respBody, data)
+	if err != nil {
+		return tracerr.Errorf("cannot unmarshal discussions.%s body:\n%s", method, err)
+	}
+	return nil
+}
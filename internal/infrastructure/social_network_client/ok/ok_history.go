@@ -0,0 +1,132 @@
+package ok
+
+import (
+	"autoposting/internal/infrastructure/social_network_client"
+	"encoding/json"
+	"fmt"
+	"github.com/ztrue/tracerr"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// okTopicsChunkLimit максимальный count mediatopic.getTopics
+const okTopicsChunkLimit = 100
+
+type okGetTopicsResponse struct {
+	okError
+	MediaTopics []struct {
+		ID        string `json:"id"`
+		CreatedMs int64  `json:"created_ms"`
+		Media     []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+			URL  string `json:"url"`
+		} `json:"media"`
+	} `json:"media_topics"`
+	Anchor  string `json:"anchor"`
+	HasMore bool   `json:"has_more"`
+}
+
+// GetPageHistoryChunk темы группы через mediatopic.getTopics, курсором служит anchor.
+// Фото тем приходят отдельными сущностями и не импортируются
+func (o *okClient) GetPageHistoryChunk(
+	credentials string,
+	accessToken string,
+	groupID string,
+	since time.Time,
+	cursor social_network_client.PagesCursor,
+) (*social_network_client.PageHistoryChunk, error) {
+	var data okGetTopicsResponse
+
+	if cursor.Limit == 0 || cursor.Limit > okTopicsChunkLimit {
+		cursor.Limit = okTopicsChunkLimit
+	}
+	okCredentials, err := o.stringToOKCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+	if accessToken == "" {
+		accessToken = okCredentials.AccessToken
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/mediatopic/getTopics", o.workApiUrl), nil)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot create getting group topics request:\n%s", err)
+	}
+	q := url.Values{
+		"gid":                []string{groupID},
+		"fields":             []string{"media_topic.*"},
+		"direction":          []string{"FORWARD"},
+		"count":              []string{strconv.Itoa(cursor.Limit)},
+		"application_key":    []string{okCredentials.PublicKey},
+		"access_token":       []string{accessToken},
+		"session_secret_key": []string{okCredentials.SecretKey},
+		"format":             []string{"json"},
+	}
+	if cursor.Cursor != "" {
+		q.Set("anchor", cursor.Cursor)
+	}
+	req.URL.RawQuery = q.Encode()
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot get topics of group %s:\n%s", groupID, err)
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot read getting group topics response:\n%s", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, tracerr.Errorf(
+			"get group topics response status %d\nresponse:%s",
+			resp.StatusCode,
+			string(respBody),
+		)
+	}
+
+	err = json.Unmarshal(respBody, &data)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot unmarshal getting group topics body:\n%s", err)
+	}
+
+	if data.ErrorCode != 0 {
+		return nil, tracerr.Errorf("get group topics failed with code %d: %s", data.ErrorCode, data.ErrorMsg)
+	}
+
+	chunk := &social_network_client.PageHistoryChunk{}
+	for _, topic := range data.MediaTopics {
+		publishedAt := time.UnixMilli(topic.CreatedMs)
+		if publishedAt.Before(since) {
+			return chunk, nil
+		}
+
+		var (
+			post  social_network_client.Post
+			texts []string
+		)
+		for _, media := range topic.Media {
+			switch media.Type {
+			case "text":
+				texts = append(texts, media.Text)
+			case "link":
+				post.Link = media.URL
+			}
+		}
+		post.Text = strings.Join(texts, "\n")
+		chunk.Posts = append(chunk.Posts, social_network_client.PageHistoryPost{
+			PostID:      topic.ID,
+			Post:        post,
+			PublishedAt: publishedAt,
+		})
+	}
+	if data.HasMore {
+		chunk.NextCursor = data.Anchor
+	}
+
+	return chunk, nil
+}
@@ -0,0 +1,53 @@
+package ok
+
+import (
+	"autoposting/internal/infrastructure/social_network_client"
+)
+
+// GetPostsMetrics счетчики тем из mediatopic.getByIds, охват OK в нем не отдает
+func (o *okClient) GetPostsMetrics(
+	credentials string,
+	accessToken string,
+	posts []social_network_client.RemotePost,
+) ([]social_network_client.PostMetricsResult, error) {
+	okCredentials, err := o.stringToOKCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+	if accessToken == "" {
+		accessToken = okCredentials.AccessToken
+	}
+
+	results := make([]social_network_client.PostMetricsResult, 0, len(posts))
+	for start := 0; start < len(posts); start += okTopicsByIdLimit {
+		end := start + okTopicsByIdLimit
+		if end > len(posts) {
+			end = len(posts)
+		}
+
+		topicsIDs := make([]string, 0, end-start)
+		for _, post := range posts[start:end] {
+			topicsIDs = append(topicsIDs, post.PostID)
+		}
+		topicsByID, err := o.getMediaTopics(okCredentials, accessToken, topicsIDs)
+		for _, post := range posts[start:end] {
+			result := social_network_client.PostMetricsResult{
+				Post: post,
+				Err:  err,
+			}
+			if err == nil {
+				topic, ok := topicsByID[post.PostID]
+				result.Deleted = !ok
+				result.Metrics = social_network_client.PostMetrics{
+					Likes:    topic.LikeSummary.Count,
+					Reposts:  topic.ReshareSummary.Count,
+					Comments: topic.DiscussionSummary.CommentsCount,
+					Views:    topic.ViewsCount,
+				}
+			}
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,126 @@
+package social_network_client
+
+import (
+	"bytes"
+	"github.com/ztrue/tracerr"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const redactedValue = "[REDACTED]"
+
+// secretParams параметры и заголовки с токенами и ключами, которые не показываются в dry-run
+var secretParams = map[string]bool{
+	"access_token":       true,
+	"client_secret":      true,
+	"appsecret_proof":    true,
+	"session_secret_key": true,
+	"sig":                true,
+	"fb_exchange_token":  true,
+	"authorization":      true,
+}
+
+// PreparedRequest запрос к API соц сети, который клиент отправил бы при публикации
+type PreparedRequest struct {
+	PagesIDs []string
+	Method   string
+	URL      string
+	// Params параметры query и тела формы
+	Params  url.Values
+	Headers http.Header
+	// Body тело запроса, если это не форма, например JSON
+	Body string
+	// Secrets значения, которые скрываются везде, включая URL и тело, например токен в адресе вебхука
+	Secrets []string
+}
+
+// NewPreparedRequest снимает параметры с собранного запроса, тело запроса остается доступным для отправки
+func NewPreparedRequest(pagesIDs []string, req *http.Request) (PreparedRequest, error) {
+	requestURL := *req.URL
+	requestURL.RawQuery = ""
+
+	preparedRequest := PreparedRequest{
+		PagesIDs: pagesIDs,
+		Method:   req.Method,
+		URL:      requestURL.String(),
+		Params:   req.URL.Query(),
+		Headers:  req.Header.Clone(),
+	}
+	if req.Body == nil {
+		return preparedRequest, nil
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return PreparedRequest{}, tracerr.Errorf("cannot read request body:\n%s", err)
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	contentType := req.Header.Get("Content-Type")
+	if contentType != "" && contentType != "application/x-www-form-urlencoded" {
+		preparedRequest.Body = string(body)
+		return preparedRequest, nil
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return PreparedRequest{}, tracerr.Errorf("cannot parse request form:\n%s", err)
+	}
+	for key, values := range form {
+		preparedRequest.Params[key] = append(preparedRequest.Params[key], values...)
+	}
+
+	return preparedRequest, nil
+}
+
+// Redacted копия запроса с замененными значениями секретов
+func (r PreparedRequest) Redacted() PreparedRequest {
+	redacted := r
+	redacted.Params = url.Values{}
+	for key, values := range r.Params {
+		redacted.Params[key] = redactValues(key, values)
+	}
+	redacted.Headers = http.Header{}
+	for key, values := range r.Headers {
+		redacted.Headers[key] = redactValues(key, values)
+	}
+
+	if len(r.Secrets) == 0 {
+		return redacted
+	}
+	replacements := make([]string, 0, len(r.Secrets)*2)
+	for _, secret := range r.Secrets {
+		if secret != "" {
+			replacements = append(replacements, secret, redactedValue)
+		}
+	}
+	replacer := strings.NewReplacer(replacements...)
+	redacted.URL = replacer.Replace(redacted.URL)
+	redacted.Body = replacer.Replace(redacted.Body)
+	for _, values := range redacted.Params {
+		for i := range values {
+			values[i] = replacer.Replace(values[i])
+		}
+	}
+	for _, values := range redacted.Headers {
+		for i := range values {
+			values[i] = replacer.Replace(values[i])
+		}
+	}
+	redacted.Secrets = nil
+	return redacted
+}
+
+// redactValues всегда возвращает копию, чтобы замена Secrets не меняла исходный запрос
+func redactValues(key string, values []string) []string {
+	redacted := make([]string, len(values))
+	for i := range values {
+		redacted[i] = values[i]
+		if secretParams[strings.ToLower(key)] {
+			redacted[i] = redactedValue
+		}
+	}
+	return redacted
+}
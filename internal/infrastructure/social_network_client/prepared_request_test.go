@@ -0,0 +1,119 @@
+package social_network_client_test
+
+import (
+	"autoposting/internal/infrastructure/social_network_client"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPreparedRequestRedacted(t *testing.T) {
+	tests := []struct {
+		name    string
+		request func() *http.Request
+		secrets []string
+		want    social_network_client.PreparedRequest
+	}{
+		{
+			name: "secret query params and headers",
+			request: func() *http.Request {
+				req, _ := http.NewRequest("GET", "https://api.example.com/method?access_token=token&message=hi&SIG=abc", nil)
+				req.Header.Set("Authorization", "Bearer token")
+				req.Header.Set("X-Request-Id", "1")
+				return req
+			},
+			want: social_network_client.PreparedRequest{
+				Method: "GET",
+				URL:    "https://api.example.com/method",
+				Params: url.Values{
+					"access_token": []string{"[REDACTED]"},
+					"message":      []string{"hi"},
+					"SIG":          []string{"[REDACTED]"},
+				},
+				Headers: http.Header{
+					"Authorization": []string{"[REDACTED]"},
+					"X-Request-Id":  []string{"1"},
+				},
+			},
+		},
+		{
+			name: "secret form params",
+			request: func() *http.Request {
+				form := url.Values{"client_secret": []string{"secret"}, "text": []string{"hello"}}
+				req, _ := http.NewRequest("POST", "https://api.example.com/post", strings.NewReader(form.Encode()))
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+				return req
+			},
+			want: social_network_client.PreparedRequest{
+				Method: "POST",
+				URL:    "https://api.example.com/post",
+				Params: url.Values{
+					"client_secret": []string{"[REDACTED]"},
+					"text":          []string{"hello"},
+				},
+				Headers: http.Header{
+					"Content-Type": []string{"application/x-www-form-urlencoded"},
+				},
+			},
+		},
+		{
+			name: "secrets in url, json body and params",
+			request: func() *http.Request {
+				req, _ := http.NewRequest(
+					"POST",
+					"https://hooks.example.com/webhooks/1/hooktoken?wait=true&note=hooktoken",
+					strings.NewReader(`{"token":"hooktoken","text":"hi"}`),
+				)
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("X-Api-Key", "apikey")
+				return req
+			},
+			secrets: []string{"hooktoken", "apikey", ""},
+			want: social_network_client.PreparedRequest{
+				Method: "POST",
+				URL:    "https://hooks.example.com/webhooks/1/[REDACTED]",
+				Params: url.Values{
+					"wait": []string{"true"},
+					"note": []string{"[REDACTED]"},
+				},
+				Headers: http.Header{
+					"Content-Type": []string{"application/json"},
+					"X-Api-Key":    []string{"[REDACTED]"},
+				},
+				Body: `{"token":"[REDACTED]","text":"hi"}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preparedRequest, err := social_network_client.NewPreparedRequest(nil, tt.request())
+			if err != nil {
+				t.Fatalf("NewPreparedRequest: %v", err)
+			}
+			preparedRequest.Secrets = tt.secrets
+			original := clonePreparedRequest(preparedRequest)
+
+			got := preparedRequest.Redacted()
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(preparedRequest, original) {
+				t.Fatalf("Redacted changed original request: got %+v, want %+v", preparedRequest, original)
+			}
+		})
+	}
+}
+
+func clonePreparedRequest(r social_network_client.PreparedRequest) social_network_client.PreparedRequest {
+	clone := r
+	clone.Params = url.Values{}
+	for key, values := range r.Params {
+		clone.Params[key] = append([]string(nil), values...)
+	}
+	clone.Headers = r.Headers.Clone()
+	clone.Secrets = append([]string(nil), r.Secrets...)
+	return clone
+}
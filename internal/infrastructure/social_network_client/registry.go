@@ -0,0 +1,164 @@
+package social_network_client
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"unicode/utf8"
+)
+
+// Network описание соц сети, которое пакет клиента регистрирует в init
+type Network struct {
+	// Name идентификатор соц сети в аккаунтах и API, например VK
+	Name        string
+	DisplayName string
+	// OAuth nil для соц сетей без авторизации через OAuth
+	OAuth *OAuthSettings
+	// AuthNetwork соц сеть, через подключенный аккаунт которой работает эта, например Instagram через Facebook
+	AuthNetwork  string
+	Capabilities Capabilities
+	// DefaultConfig боевые адреса API, RedirectUrl задается приложением
+	DefaultConfig ClientConfig
+	NewClient     func(ClientConfig) SocialNetworkClient
+}
+
+// ClientConfig адреса и версия API, с которыми создается клиент соц сети
+type ClientConfig struct {
+	AuthApiUrl  string
+	WorkApiUrl  string
+	ApiVersion  string
+	RedirectUrl string
+}
+
+type OAuthSettings struct {
+	Scope string
+}
+
+// Capabilities ограничения соц сети на публикуемый пост, нулевое значение лимита - без ограничений
+type Capabilities struct {
+	MaxTextLength int
+	// Images клиент публикует изображения, без него MaxImages и MinImages не учитываются
+	Images    bool
+	MaxImages int
+	// MinImages число изображений, без которых соц сеть не принимает пост
+	MinImages int
+	Video     bool
+	Links     bool
+	Polls     bool
+	// PollExcludesMedia соц сеть не принимает опрос вместе с изображениями или видео
+	PollExcludesMedia bool
+}
+
+// CapabilityViolation нарушенное правило Capabilities, Rule совпадает с именем поля Capabilities
+type CapabilityViolation struct {
+	Field   string
+	Rule    string
+	Message string
+}
+
+// Check проверяет пост на соответствие ограничениям соц сети
+func (c Capabilities) Check(post Post) []CapabilityViolation {
+	var violations []CapabilityViolation
+
+	if textLength := utf8.RuneCountInString(post.Text); c.MaxTextLength > 0 && textLength > c.MaxTextLength {
+		violations = append(violations, CapabilityViolation{
+			Field:   "text",
+			Rule:    "maxTextLength",
+			Message: fmt.Sprintf("text length %d exceeds %d characters", textLength, c.MaxTextLength),
+		})
+	}
+	if len(post.Images) != 0 && !c.Images {
+		violations = append(violations, CapabilityViolation{
+			Field:   "images",
+			Rule:    "images",
+			Message: "images are not supported",
+		})
+	}
+	if c.Images && c.MaxImages > 0 && len(post.Images) > c.MaxImages {
+		violations = append(violations, CapabilityViolation{
+			Field:   "images",
+			Rule:    "maxImages",
+			Message: fmt.Sprintf("%d images exceed limit of %d", len(post.Images), c.MaxImages),
+		})
+	}
+	if c.Images && len(post.Images) < c.MinImages {
+		violations = append(violations, CapabilityViolation{
+			Field:   "images",
+			Rule:    "minImages",
+			Message: fmt.Sprintf("at least %d images required", c.MinImages),
+		})
+	}
+	if post.Video != "" && !c.Video {
+		violations = append(violations, CapabilityViolation{
+			Field:   "video",
+			Rule:    "video",
+			Message: "video is not supported",
+		})
+	}
+	if post.Link != "" && !c.Links {
+		violations = append(violations, CapabilityViolation{
+			Field:   "link",
+			Rule:    "links",
+			Message: "links are not supported",
+		})
+	}
+	if post.Poll != nil && !c.Polls {
+		violations = append(violations, CapabilityViolation{
+			Field:   "poll",
+			Rule:    "polls",
+			Message: "polls are not supported",
+		})
+	}
+	if post.Poll != nil && c.PollExcludesMedia && (len(post.Images) != 0 || post.Video != "") {
+		violations = append(violations, CapabilityViolation{
+			Field:   "poll",
+			Rule:    "pollExcludesMedia",
+			Message: "polls cannot be combined with images or video",
+		})
+	}
+
+	return violations
+}
+
+var (
+	networksMu sync.RWMutex
+	networks   = map[string]Network{}
+)
+
+// Register вызывается из init пакета клиента, повторная регистрация имени - ошибка программиста
+func Register(network Network) {
+	networksMu.Lock()
+	defer networksMu.Unlock()
+
+	if network.Name == "" || network.NewClient == nil {
+		panic("social_network_client: Register network without name or client factory")
+	}
+	if _, ok := networks[network.Name]; ok {
+		panic(fmt.Sprintf("social_network_client: Register called twice for network %s", network.Name))
+	}
+	networks[network.Name] = network
+}
+
+func LookupNetwork(name string) (Network, bool) {
+	networksMu.RLock()
+	defer networksMu.RUnlock()
+
+	network, ok := networks[name]
+	return network, ok
+}
+
+// Networks зарегистрированные соц сети, отсортированные по имени
+func Networks() []Network {
+	networksMu.RLock()
+	defer networksMu.RUnlock()
+
+	list := make([]Network, 0, len(networks))
+	for _, network := range networks {
+		list = append(list, network)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+
+	return list
+}
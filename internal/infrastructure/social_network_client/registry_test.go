@@ -0,0 +1,125 @@
+package social_network_client_test
+
+import (
+	"autoposting/internal/infrastructure/social_network_client"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCapabilitiesCheck(t *testing.T) {
+	capabilities := social_network_client.Capabilities{
+		MaxTextLength: 10,
+		Images:        true,
+		MaxImages:     2,
+		MinImages:     1,
+		Links:         true,
+		Polls:         true,
+	}
+	poll := &social_network_client.Poll{Question: "?", Answers: []string{"a", "b"}}
+
+	tests := []struct {
+		name         string
+		capabilities social_network_client.Capabilities
+		post         social_network_client.Post
+		wantRules    []string
+	}{
+		{
+			name:         "valid post",
+			capabilities: capabilities,
+			post:         social_network_client.Post{Text: "hello", Images: []string{"a"}},
+		},
+		{
+			// Длина считается в символах, а не в байтах
+			name:         "cyrillic text at limit",
+			capabilities: capabilities,
+			post:         social_network_client.Post{Text: "приветмир!", Images: []string{"a"}},
+		},
+		{
+			name:         "text too long",
+			capabilities: capabilities,
+			post:         social_network_client.Post{Text: strings.Repeat("я", 11), Images: []string{"a"}},
+			wantRules:    []string{"maxTextLength"},
+		},
+		{
+			name:         "no text limit",
+			capabilities: social_network_client.Capabilities{},
+			post:         social_network_client.Post{Text: strings.Repeat("a", 100000)},
+		},
+		{
+			name:         "too many images",
+			capabilities: capabilities,
+			post:         social_network_client.Post{Images: []string{"a", "b", "c"}},
+			wantRules:    []string{"maxImages"},
+		},
+		{
+			name:         "too few images",
+			capabilities: capabilities,
+			post:         social_network_client.Post{Text: "hello"},
+			wantRules:    []string{"minImages"},
+		},
+		{
+			name:         "images not supported",
+			capabilities: social_network_client.Capabilities{MaxImages: 1, MinImages: 1},
+			post:         social_network_client.Post{Images: []string{"a", "b"}},
+			wantRules:    []string{"images"},
+		},
+		{
+			name:         "video not supported",
+			capabilities: capabilities,
+			post:         social_network_client.Post{Images: []string{"a"}, Video: "v"},
+			wantRules:    []string{"video"},
+		},
+		{
+			name:         "links not supported",
+			capabilities: social_network_client.Capabilities{},
+			post:         social_network_client.Post{Link: "https://example.com"},
+			wantRules:    []string{"links"},
+		},
+		{
+			name:         "polls not supported",
+			capabilities: social_network_client.Capabilities{},
+			post:         social_network_client.Post{Poll: poll},
+			wantRules:    []string{"polls"},
+		},
+		{
+			name:         "poll with media allowed",
+			capabilities: capabilities,
+			post:         social_network_client.Post{Images: []string{"a"}, Poll: poll},
+		},
+		{
+			name:         "poll excludes images",
+			capabilities: social_network_client.Capabilities{Images: true, Polls: true, PollExcludesMedia: true},
+			post:         social_network_client.Post{Images: []string{"a"}, Poll: poll},
+			wantRules:    []string{"pollExcludesMedia"},
+		},
+		{
+			name:         "poll excludes video",
+			capabilities: social_network_client.Capabilities{Video: true, Polls: true, PollExcludesMedia: true},
+			post:         social_network_client.Post{Video: "v", Poll: poll},
+			wantRules:    []string{"pollExcludesMedia"},
+		},
+		{
+			name:         "several violations",
+			capabilities: capabilities,
+			post: social_network_client.Post{
+				Text:   strings.Repeat("a", 11),
+				Images: []string{"a", "b", "c"},
+				Video:  "v",
+			},
+			wantRules: []string{"maxTextLength", "maxImages", "video"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rules []string
+			for _, violation := range tt.capabilities.Check(tt.post) {
+				rules = append(rules, violation.Rule)
+			}
+			if !reflect.DeepEqual(rules, tt.wantRules) {
+				t.Fatalf("got violated rules %v, want %v", rules, tt.wantRules)
+			}
+		})
+	}
+}
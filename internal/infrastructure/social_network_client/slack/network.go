@@ -0,0 +1,25 @@
+package slack
+
+import "autoposting/internal/infrastructure/social_network_client"
+
+const Name = "SLACK"
+
+// capabilities текст section блока ограничен 3000 символами
+var capabilities = social_network_client.Capabilities{
+	MaxTextLength: 3000,
+	Images:        true,
+	MaxImages:     10,
+	Video:         false,
+	Links:         true,
+	Polls:         false,
+}
+
+func init() {
+	// Credentials аккаунта - JSON с адресом входящего вебхука, OAuth не используется
+	social_network_client.Register(social_network_client.Network{
+		Name:         Name,
+		DisplayName:  "Slack",
+		Capabilities: capabilities,
+		NewClient:    NewSlackClient,
+	})
+}
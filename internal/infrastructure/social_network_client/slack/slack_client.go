@@ -0,0 +1,239 @@
+package slack
+
+import (
+	"autoposting/internal/infrastructure/social_network_client"
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/ztrue/tracerr"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+const slackTimeout = 10 * time.Second
+
+// slackMrkdwnEscaper Slack требует экранировать управляющие символы mrkdwn
+var slackMrkdwnEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// SlackCredentials адрес входящего вебхука, которым публикуются посты аккаунта
+type SlackCredentials struct {
+	WebhookURL string `json:"webhook_url"`
+}
+
+type slackMessage struct {
+	Text   string       `json:"text"`
+	Blocks []slackBlock `json:"blocks,omitempty"`
+}
+
+type slackBlock struct {
+	Type     string          `json:"type"`
+	Text     *slackTextBlock `json:"text,omitempty"`
+	ImageURL string          `json:"image_url,omitempty"`
+	AltText  string          `json:"alt_text,omitempty"`
+}
+
+type slackTextBlock struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// slackClient публикует посты через входящие вебхуки Slack. Вебхук аккаунта задается в credentials,
+// вебхуки других каналов - токенами их страниц
+type slackClient struct {
+	httpClient *http.Client
+}
+
+func (s *slackClient) GetAuthURL(credentials string) (string, error) {
+	return "", tracerr.Errorf("slack webhooks do not use oauth")
+}
+
+func (s *slackClient) GetAccessToken(
+	credentials string,
+	queryParams map[string][]string,
+) (*social_network_client.AccessToken, error) {
+	return nil, tracerr.Errorf("slack webhooks do not use oauth")
+}
+
+func (s *slackClient) GetAccountPages(
+	credentials string,
+	accessToken string,
+) ([]social_network_client.SocialNetworkPage, error) {
+	return social_network_client.GetAllAccountPages(s, credentials, accessToken)
+}
+
+// GetAccountPagesChunk входящий вебхук Slack нельзя запросить, страница строится из адреса
+// /services/{team}/{webhook}/{token}
+func (s *slackClient) GetAccountPagesChunk(
+	credentials string,
+	accessToken string,
+	cursor social_network_client.PagesCursor,
+) (*social_network_client.SocialNetworkPagesChunk, error) {
+	webhookURL, err := getWebhookURL(credentials, "")
+	if err != nil {
+		return nil, err
+	}
+
+	parsedURL, err := url.Parse(webhookURL)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot parse slack webhook url:\n%s", err)
+	}
+
+	return &social_network_client.SocialNetworkPagesChunk{
+		Pages: []social_network_client.SocialNetworkPage{{
+			ID:   path.Base(path.Dir(parsedURL.Path)),
+			Name: "Slack webhook",
+		}},
+	}, nil
+}
+
+// CreatePost входящий вебхук отвечает "ok" без идентификатора сообщения, поэтому id поста пустой
+func (s *slackClient) CreatePost(
+	ctx context.Context,
+	credentials string,
+	accessToken string,
+	channelID string,
+	post social_network_client.Post,
+) (string, error) {
+	req, err := s.newCreatePostRequest(credentials, accessToken, post)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", tracerr.Errorf("cannot execute slack webhook:\n%s", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", tracerr.Errorf("cannot read response:\n%s", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", tracerr.Errorf(
+			"cannot execute slack webhook: response status is %d\nresponse:%s",
+			resp.StatusCode,
+			string(respBody),
+		)
+	}
+
+	return "", nil
+}
+
+func (s *slackClient) PreparePost(
+	credentials string,
+	accessToken string,
+	channelIDs []string,
+	post social_network_client.Post,
+) ([]social_network_client.PreparedRequest, error) {
+	requests := make([]social_network_client.PreparedRequest, 0, len(channelIDs))
+	for _, channelID := range channelIDs {
+		req, err := s.newCreatePostRequest(credentials, accessToken, post)
+		if err != nil {
+			return nil, err
+		}
+		preparedRequest, err := social_network_client.NewPreparedRequest([]string{channelID}, req)
+		if err != nil {
+			return nil, err
+		}
+		// Последний сегмент адреса вебхука - секретный токен
+		preparedRequest.Secrets = []string{path.Base(req.URL.Path)}
+		requests = append(requests, preparedRequest)
+	}
+
+	return requests, nil
+}
+
+func (s *slackClient) DeletePost(credentials, accessToken, postID string) error {
+	return tracerr.Errorf("slack incoming webhooks do not support deleting messages")
+}
+
+func (s *slackClient) Capabilities() social_network_client.Capabilities {
+	return capabilities
+}
+
+// newCreatePostRequest текст и ссылка идут в section блок, изображения - отдельными image блоками
+func (s *slackClient) newCreatePostRequest(
+	credentials string,
+	accessToken string,
+	post social_network_client.Post,
+) (*http.Request, error) {
+	webhookURL, err := getWebhookURL(credentials, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	text := slackMrkdwnEscaper.Replace(post.Text)
+	if post.Link != "" {
+		if text != "" {
+			text += "\n"
+		}
+		text += "<" + post.Link + ">"
+	}
+
+	message := slackMessage{
+		Text: text,
+	}
+	if text != "" {
+		message.Blocks = append(message.Blocks, slackBlock{
+			Type: "section",
+			Text: &slackTextBlock{Type: "mrkdwn", Text: text},
+		})
+	}
+	for i, image := range post.Images {
+		// alt_text у image блока обязателен
+		altText := "image"
+		if i < len(post.ImagesAlt) && post.ImagesAlt[i] != "" {
+			altText = post.ImagesAlt[i]
+		}
+		message.Blocks = append(message.Blocks, slackBlock{
+			Type:     "image",
+			ImageURL: image,
+			AltText:  altText,
+		})
+	}
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot marshal slack message:\n%s", err)
+	}
+
+	req, err := http.NewRequest("POST", webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, tracerr.Errorf("cannot create slack webhook request:\n%s", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return req, nil
+}
+
+// getWebhookURL вебхук страницы приоритетнее вебхука аккаунта
+func getWebhookURL(credentials, accessToken string) (string, error) {
+	webhookURL := strings.TrimSpace(accessToken)
+	if webhookURL == "" {
+		slackCredentials := &SlackCredentials{}
+		err := json.Unmarshal([]byte(credentials), slackCredentials)
+		if err != nil {
+			return "", tracerr.Errorf("cannot unmarshal slack credentials:\n%s", err)
+		}
+		webhookURL = strings.TrimSpace(slackCredentials.WebhookURL)
+	}
+
+	parsedURL, err := url.Parse(webhookURL)
+	if err != nil || parsedURL.Scheme == "" || parsedURL.Host == "" {
+		return "", tracerr.Errorf("slack webhook url is not valid")
+	}
+
+	return strings.TrimSuffix(webhookURL, "/"), nil
+}
+
+func NewSlackClient(config social_network_client.ClientConfig) social_network_client.SocialNetworkClient {
+	return &slackClient{
+		httpClient: &http.Client{Timeout: slackTimeout},
+	}
+}
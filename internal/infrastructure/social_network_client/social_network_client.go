@@ -1,12 +1,211 @@
 package social_network_client
 
+import (
+	"context"
+	"time"
+)
+
 type SocialNetworkClient interface {
 	GetAuthURL(string) (string, error)
-	GetAccessToken(string, map[string][]string) (string, error)
+	GetAccessToken(string, map[string][]string) (*AccessToken, error)
 	GetAccountPages(string, string) ([]SocialNetworkPage, error)
-	UploadImage()
-	CreatePost(string, string, string) (string, error)
-	DeletePost()
+	GetAccountPagesChunk(string, string, PagesCursor) (*SocialNetworkPagesChunk, error)
+	CreatePost(context.Context, string, string, string, Post) (string, error)
+	PreparePost(string, string, []string, Post) ([]PreparedRequest, error)
+	DeletePost(string, string, string) error
+	Capabilities() Capabilities
+}
+
+// BatchPostCreator клиенты, публикующие пост в несколько страниц за один запрос
+type BatchPostCreator interface {
+	CreatePosts(context.Context, string, string, []string, Post) ([]PostResult, error)
+}
+
+// RemotePostFetcher клиенты, которые умеют получать опубликованные посты для сверки с posts
+type RemotePostFetcher interface {
+	GetRemotePosts(string, string, []RemotePost) ([]RemotePostState, error)
+}
+
+// PageHistoryFetcher клиенты, которые умеют читать посты, опубликованные на странице до ее подключения
+type PageHistoryFetcher interface {
+	GetPageHistoryChunk(string, string, string, time.Time, PagesCursor) (*PageHistoryChunk, error)
+}
+
+// PostMetricsFetcher клиенты, которые умеют получать счетчики вовлеченности опубликованных постов
+type PostMetricsFetcher interface {
+	GetPostsMetrics(string, string, []RemotePost) ([]PostMetricsResult, error)
+}
+
+// PageAudienceFetcher клиенты, которые умеют получать число подписчиков страниц
+type PageAudienceFetcher interface {
+	GetPagesAudience(string, string, []string) ([]PageAudienceResult, error)
+}
+
+// CommentsManager клиенты, которые умеют читать комментарии постов, отвечать на них от имени страницы и удалять их
+type CommentsManager interface {
+	GetPostComments(string, string, RemotePost) ([]Comment, error)
+	ReplyToComment(string, string, RemoteComment, string) (string, error)
+	DeleteComment(string, string, RemoteComment) error
+}
+
+// CommentsModerator клиенты, которые умеют скрывать комментарии. Скрытый комментарий видят только автор и администраторы
+type CommentsModerator interface {
+	SetCommentHidden(string, string, RemoteComment, bool) error
+}
+
+// CommentsRestorer клиенты, которые умеют восстанавливать комментарии, удаленные страницей
+type CommentsRestorer interface {
+	RestoreComment(string, string, RemoteComment) error
+}
+
+// TextComposer клиенты, которые дописывают к тексту поста другие поля, например ссылку.
+// Лимит длины и сверка с соц сетью применяются к собранному тексту
+type TextComposer interface {
+	ComposeText(Post) string
+}
+
+// AppRegistrar клиенты, которые сами регистрируют OAuth приложение при создании аккаунта
+// и возвращают credentials с его идентификатором и секретом
+type AppRegistrar interface {
+	RegisterApp(context.Context, string) (string, error)
+}
+
+// Post публикуемый пост, пустые поля не отправляются
+type Post struct {
+	Text   string
+	Images []string
+	// ImagesAlt описания изображений для соц сетей с alt text, по индексам Images
+	ImagesAlt []string
+	Video     string
+	Link      string
+	Poll      *Poll
+	// ContentWarning предупреждение, под которым соц сеть скрывает текст поста
+	ContentWarning string
+	// Visibility пустая - видимость по умолчанию аккаунта
+	Visibility PostVisibility
+}
+
+type PostVisibility string
+
+const (
+	PostVisibilityPublic   PostVisibility = "public"
+	PostVisibilityUnlisted PostVisibility = "unlisted"
+	PostVisibilityPrivate  PostVisibility = "private"
+	PostVisibilityDirect   PostVisibility = "direct"
+)
+
+type Poll struct {
+	Question string
+	Answers  []string
+}
+
+// PostResult результат публикации в одну страницу, Err не прерывает публикацию в остальные
+type PostResult struct {
+	PageID string
+	PostID string
+	Err    error
+	// Attempts попытки доставки у клиентов, которые повторяют запрос, у остальных пусто
+	Attempts []DeliveryAttempt
+}
+
+// DeliveryAttempt одна попытка отправки поста, StatusCode 0 - ответ не получен
+type DeliveryAttempt struct {
+	DeliveryID   string
+	Attempt      int
+	StatusCode   int
+	ResponseBody string
+	Err          error
+	AttemptedAt  time.Time
+}
+
+// RemotePost пост, опубликованный в соц сети
+type RemotePost struct {
+	PageID string
+	PostID string
+}
+
+// RemotePostState пост в соц сети на момент запроса, Deleted - пост удален или больше недоступен
+type RemotePostState struct {
+	Post    RemotePost
+	Deleted bool
+	Text    string
+	Err     error
+}
+
+// PageHistoryPost пост со стены страницы, в Post заполняется то, что удалось разобрать
+type PageHistoryPost struct {
+	PostID      string
+	Post        Post
+	PublishedAt time.Time
+}
+
+// PageHistoryChunk посты от новых к старым, пустой NextCursor означает, что посты после since закончились
+type PageHistoryChunk struct {
+	Posts      []PageHistoryPost
+	NextCursor string
+}
+
+// PostMetrics счетчики поста, метрики, которые соц сеть не отдает, остаются нулевыми
+type PostMetrics struct {
+	Likes    int
+	Reposts  int
+	Comments int
+	Views    int
+	Reach    int
+}
+
+// PostMetricsResult Deleted - пост удален или больше недоступен, метрик у него нет
+type PostMetricsResult struct {
+	Post    RemotePost
+	Metrics PostMetrics
+	Deleted bool
+	Err     error
+	// ReachErr охват не получен, остальные счетчики в Metrics собраны
+	ReachErr error
+}
+
+// RemoteComment комментарий к посту в соц сети. ParentID - комментарий первого уровня, если это ответ:
+// соц сети с одноуровневыми ответами отвечают в его ветку
+type RemoteComment struct {
+	PageID    string
+	PostID    string
+	CommentID string
+	ParentID  string
+}
+
+// Comment комментарий поста, ParentID - комментарий, на который это ответ. ByPage - комментарий оставлен от имени страницы
+type Comment struct {
+	CommentID  string
+	ParentID   string
+	AuthorID   string
+	AuthorName string
+	ByPage     bool
+	Text       string
+	CreatedAt  time.Time
+}
+
+type PostReach struct {
+	Total       int
+	Subscribers int
+	Links       int
+	ToGroup     int
+	JoinGroup   int
+	Hide        int
+	Report      int
+	Unsubscribe int
+}
+
+type PostReachResult struct {
+	Post  RemotePost
+	Reach PostReach
+	Err   error
+}
+
+// PageAudienceResult подписчики одной страницы, Err не прерывает получение остальных
+type PageAudienceResult struct {
+	PageID    string
+	Followers int
+	Err       error
 }
 
 type SocialNetworkPage struct {
@@ -14,4 +213,59 @@ type SocialNetworkPage struct {
 	Name        string
 	Description string
 	Image       string
+	AccessToken *AccessToken
+}
+
+// PagesCursor пустой Cursor означает первую порцию, нулевой Limit - размер порции соц сети по умолчанию
+type PagesCursor struct {
+	Cursor string
+	Limit  int
+}
+
+// SocialNetworkPagesChunk пустой NextCursor означает последнюю порцию
+type SocialNetworkPagesChunk struct {
+	Pages      []SocialNetworkPage
+	NextCursor string
+}
+
+// AccessToken нулевой ExpiresAt означает бессрочный токен
+type AccessToken struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+// NewAccessToken нулевой или отрицательный expiresIn означает бессрочный токен
+func NewAccessToken(token string, expiresIn int64) *AccessToken {
+	accessToken := &AccessToken{
+		Token: token,
+	}
+	if expiresIn > 0 {
+		accessToken.ExpiresAt = time.Now().Add(time.Duration(expiresIn) * time.Second)
+	}
+	return accessToken
+}
+
+// GetAllAccountPages последовательно выбирает все порции страниц аккаунта
+func GetAllAccountPages(
+	client SocialNetworkClient,
+	credentials string,
+	accessToken string,
+) ([]SocialNetworkPage, error) {
+	var (
+		pages  []SocialNetworkPage
+		cursor PagesCursor
+	)
+
+	for {
+		chunk, err := client.GetAccountPagesChunk(credentials, accessToken, cursor)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, chunk.Pages...)
+
+		if chunk.NextCursor == "" || chunk.NextCursor == cursor.Cursor {
+			return pages, nil
+		}
+		cursor.Cursor = chunk.NextCursor
+	}
 }
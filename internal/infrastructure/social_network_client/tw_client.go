@@ -1 +0,0 @@
-package social_network_client
@@ -0,0 +1,34 @@
+package vk
+
+import "autoposting/internal/infrastructure/social_network_client"
+
+const (
+	Name       = "VK"
+	oauthScope = "offline,groups,photos,video,pages,wall"
+)
+
+// capabilities клиент публикует только текст и ссылку
+var capabilities = social_network_client.Capabilities{
+	MaxTextLength: 16384,
+	Images:        false,
+	Video:         false,
+	Links:         true,
+	Polls:         false,
+}
+
+func init() {
+	social_network_client.Register(social_network_client.Network{
+		Name:        Name,
+		DisplayName: "ВКонтакте",
+		OAuth: &social_network_client.OAuthSettings{
+			Scope: oauthScope,
+		},
+		Capabilities: capabilities,
+		DefaultConfig: social_network_client.ClientConfig{
+			AuthApiUrl: "https://oauth.vk.com",
+			WorkApiUrl: "https://api.vk.com",
+			ApiVersion: "5.131",
+		},
+		NewClient: NewVKClient,
+	})
+}
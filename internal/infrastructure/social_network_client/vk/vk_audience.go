@@ -0,0 +1,43 @@
+package vk
+
+import (
+	"autoposting/internal/infrastructure/social_network_client"
+	"github.com/ztrue/tracerr"
+)
+
+// GetPagesAudience число участников групп из members_count groups.getById
+func (v *vkClient) GetPagesAudience(
+	credentials string,
+	accessToken string,
+	groupIDs []string,
+) ([]social_network_client.PageAudienceResult, error) {
+	vkCredentials, err := v.stringToVKCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+	if accessToken == "" {
+		accessToken = vkCredentials.AccessToken
+	}
+
+	groups, errByGroup, err := v.getGroupsByID(accessToken, groupIDs, "members_count")
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]social_network_client.PageAudienceResult, 0, len(groupIDs))
+	for _, groupID := range groupIDs {
+		result := social_network_client.PageAudienceResult{PageID: groupID}
+		group, ok := groups[groupID]
+		switch {
+		case errByGroup[groupID] != nil:
+			result.Err = errByGroup[groupID]
+		case !ok:
+			result.Err = tracerr.Errorf("group %s not found", groupID)
+		default:
+			result.Followers = group.MembersCount
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
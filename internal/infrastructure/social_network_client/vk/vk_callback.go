@@ -0,0 +1,148 @@
+package vk
+
+import (
+	"autoposting/internal/infrastructure/social_network_client"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/ztrue/tracerr"
+	"strconv"
+	"time"
+)
+
+const (
+	vkCallbackConfirmation = "confirmation"
+	vkCallbackPostNew      = "wall_post_new"
+	vkCallbackPostEdit     = "wall_post_edit"
+	vkCallbackReplyNew     = "wall_reply_new"
+	vkCallbackReplyEdit    = "wall_reply_edit"
+	vkCallbackReplyDelete  = "wall_reply_delete"
+)
+
+// CallbackRequest уведомление VK Callback API
+type CallbackRequest struct {
+	Type    string          `json:"type"`
+	EventID string          `json:"event_id"`
+	GroupID int             `json:"group_id"`
+	Secret  string          `json:"secret"`
+	Object  json.RawMessage `json:"object"`
+
+	body []byte
+}
+
+type vkCallbackPost struct {
+	ID        int    `json:"id"`
+	FromID    int    `json:"from_id"`
+	CreatedBy int    `json:"created_by"`
+	Date      int64  `json:"date"`
+	Text      string `json:"text"`
+}
+
+type vkCallbackComment struct {
+	ID           int    `json:"id"`
+	FromID       int    `json:"from_id"`
+	Date         int64  `json:"date"`
+	Text         string `json:"text"`
+	PostID       int    `json:"post_id"`
+	ParentsStack []int  `json:"parents_stack"`
+}
+
+type vkCallbackCommentDelete struct {
+	ID        int `json:"id"`
+	DeleterID int `json:"deleter_id"`
+	PostID    int `json:"post_id"`
+}
+
+func ParseCallbackRequest(body []byte) (*CallbackRequest, error) {
+	request := &CallbackRequest{body: body}
+	if err := json.Unmarshal(body, request); err != nil {
+		return nil, tracerr.Errorf("cannot unmarshal vk callback request:\n%s", err)
+	}
+	if request.Type == "" || request.GroupID == 0 {
+		return nil, tracerr.Errorf("vk callback request has no type or group_id")
+	}
+	return request, nil
+}
+
+func (r *CallbackRequest) IsConfirmation() bool {
+	return r.Type == vkCallbackConfirmation
+}
+
+// CheckSecret секрет задается в настройках Callback API сообщества, без секрета уведомления не принимаются
+func (r *CallbackRequest) CheckSecret(secret string) bool {
+	if secret == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(r.Secret), []byte(secret)) == 1
+}
+
+// PageEvent nil для типов, которые не нормализуются. Удаления постов VK в Callback API не присылает
+func (r *CallbackRequest) PageEvent() (*social_network_client.PageEvent, error) {
+	groupOwnerID := -r.GroupID
+	event := &social_network_client.PageEvent{
+		EventID: r.EventID,
+		PageID:  strconv.Itoa(r.GroupID),
+		// Сохраняется только object, в запросе есть секрет
+		Payload: r.Object,
+	}
+	if event.EventID == "" {
+		// Старые версии API не присылают event_id, повторная доставка совпадает с исходной побайтно
+		hash := sha256.Sum256(r.body)
+		event.EventID = hex.EncodeToString(hash[:])
+	}
+
+	switch r.Type {
+	case vkCallbackPostNew, vkCallbackPostEdit:
+		var post vkCallbackPost
+		if err := json.Unmarshal(r.Object, &post); err != nil {
+			return nil, tracerr.Errorf("cannot unmarshal vk callback post:\n%s", err)
+		}
+		event.Type = social_network_client.PageEventPostNew
+		if r.Type == vkCallbackPostEdit {
+			event.Type = social_network_client.PageEventPostEdited
+		}
+		event.PostID = strconv.Itoa(post.ID)
+		event.AuthorID = strconv.Itoa(post.FromID)
+		event.ByPage = post.FromID == groupOwnerID
+		if event.ByPage && post.CreatedBy != 0 {
+			// Пост от имени сообщества, автор - администратор, который его написал
+			event.AuthorID = strconv.Itoa(post.CreatedBy)
+		}
+		event.Text = post.Text
+		event.OccurredAt = time.Unix(post.Date, 0)
+	case vkCallbackReplyNew, vkCallbackReplyEdit:
+		var comment vkCallbackComment
+		if err := json.Unmarshal(r.Object, &comment); err != nil {
+			return nil, tracerr.Errorf("cannot unmarshal vk callback comment:\n%s", err)
+		}
+		event.Type = social_network_client.PageEventCommentNew
+		if r.Type == vkCallbackReplyEdit {
+			event.Type = social_network_client.PageEventCommentEdited
+		}
+		event.PostID = strconv.Itoa(comment.PostID)
+		event.CommentID = strconv.Itoa(comment.ID)
+		if len(comment.ParentsStack) != 0 {
+			event.ParentCommentID = strconv.Itoa(comment.ParentsStack[len(comment.ParentsStack)-1])
+		}
+		event.AuthorID = strconv.Itoa(comment.FromID)
+		event.ByPage = comment.FromID == groupOwnerID
+		event.Text = comment.Text
+		event.OccurredAt = time.Unix(comment.Date, 0)
+	case vkCallbackReplyDelete:
+		var comment vkCallbackCommentDelete
+		if err := json.Unmarshal(r.Object, &comment); err != nil {
+			return nil, tracerr.Errorf("cannot unmarshal vk callback comment delete:\n%s", err)
+		}
+		event.Type = social_network_client.PageEventCommentDeleted
+		event.PostID = strconv.Itoa(comment.PostID)
+		event.CommentID = strconv.Itoa(comment.ID)
+		event.AuthorID = strconv.Itoa(comment.DeleterID)
+		event.ByPage = comment.DeleterID == groupOwnerID
+		event.OccurredAt = time.Now()
+	default:
+		return nil, nil
+	}
+
+	return event, nil
+}
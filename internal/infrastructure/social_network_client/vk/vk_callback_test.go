@@ -0,0 +1,119 @@
+package vk
+
+import (
+	"autoposting/internal/infrastructure/social_network_client"
+	"testing"
+)
+
+func TestCallbackRequestCheckSecret(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		secret string
+		want   bool
+	}{
+		{
+			name:   "valid secret",
+			body:   `{"type":"wall_post_new","group_id":1,"secret":"s3cret","object":{}}`,
+			secret: "s3cret",
+			want:   true,
+		},
+		{
+			name:   "invalid secret",
+			body:   `{"type":"wall_post_new","group_id":1,"secret":"other","object":{}}`,
+			secret: "s3cret",
+			want:   false,
+		},
+		{
+			name:   "missing secret",
+			body:   `{"type":"wall_post_new","group_id":1,"object":{}}`,
+			secret: "s3cret",
+			want:   false,
+		},
+		{
+			name:   "empty configured secret",
+			body:   `{"type":"wall_post_new","group_id":1,"object":{}}`,
+			secret: "",
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := ParseCallbackRequest([]byte(tt.body))
+			if err != nil {
+				t.Fatalf("ParseCallbackRequest: %v", err)
+			}
+			if got := request.CheckSecret(tt.secret); got != tt.want {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCallbackRequestPageEvent(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want *social_network_client.PageEvent
+	}{
+		{
+			name: "post edit by community",
+			body: `{"type":"wall_post_edit","event_id":"e1","group_id":10,` +
+				`"object":{"id":5,"from_id":-10,"created_by":7,"date":1700000000,"text":"edited"}}`,
+			want: &social_network_client.PageEvent{
+				EventID:  "e1",
+				PageID:   "10",
+				Type:     social_network_client.PageEventPostEdited,
+				PostID:   "5",
+				AuthorID: "7",
+				ByPage:   true,
+				Text:     "edited",
+			},
+		},
+		{
+			name: "reply delete by user",
+			body: `{"type":"wall_reply_delete","event_id":"e2","group_id":10,` +
+				`"object":{"id":8,"deleter_id":3,"post_id":5}}`,
+			want: &social_network_client.PageEvent{
+				EventID:   "e2",
+				PageID:    "10",
+				Type:      social_network_client.PageEventCommentDeleted,
+				PostID:    "5",
+				CommentID: "8",
+				AuthorID:  "3",
+			},
+		},
+		{
+			name: "not normalized type",
+			body: `{"type":"group_join","event_id":"e3","group_id":10,"object":{}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := ParseCallbackRequest([]byte(tt.body))
+			if err != nil {
+				t.Fatalf("ParseCallbackRequest: %v", err)
+			}
+			got, err := request.PageEvent()
+			if err != nil {
+				t.Fatalf("PageEvent: %v", err)
+			}
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("got event %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("got nil event, want %+v", tt.want)
+			}
+			if got.EventID != tt.want.EventID || got.PageID != tt.want.PageID || got.Type != tt.want.Type ||
+				got.PostID != tt.want.PostID || got.CommentID != tt.want.CommentID ||
+				got.AuthorID != tt.want.AuthorID || got.ByPage != tt.want.ByPage || got.Text != tt.want.Text {
+				t.Fatalf("got event %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
-package social_network_client
+package vk
 
 import (
+	"autoposting/internal/infrastructure/social_network_client"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/ztrue/tracerr"
@@ -10,6 +12,8 @@ import (
 	"strconv"
 )
 
+const vkPagesChunkLimit = 1000
+
 type VKCredentials struct {
 	AppID       string `json:"app_id"`
 	SecureKey   string `json:"secure_key"`
@@ -28,6 +32,7 @@ type vkCreatePostResponse struct {
 	Response struct {
 		PostID int `json:"post_id"`
 	} `json:"response"`
+	Error *vkError `json:"error"`
 }
 
 type vkGetAccountPagesResponse struct {
@@ -39,6 +44,7 @@ type vkGetAccountPagesResponse struct {
 			Image string `json:"photo_200"`
 		} `json:"items"`
 	}
+	Error *vkError `json:"error"`
 }
 
 type vkClient struct {
@@ -46,6 +52,7 @@ type vkClient struct {
 	authApiUrl  string
 	workApiUrl  string
 	redirectUrl string
+	apiVersion  string
 	scope       string
 }
 
@@ -64,24 +71,24 @@ func (v *vkClient) GetAuthURL(credentials string) (string, error) {
 		"client_id":     []string{vkCredentials.AppID},
 		"redirect_uri":  []string{v.redirectUrl},
 		"response_type": []string{"code"},
-		"scope":         []string{"offline,groups,photos,video,pages,wall"},
+		"scope":         []string{v.scope},
 	}
 	req.URL.RawQuery = q.Encode()
 
 	return req.URL.String(), nil
 }
 
-func (v *vkClient) GetAccessToken(credentials string, queryParams map[string][]string) (string, error) {
+func (v *vkClient) GetAccessToken(credentials string, queryParams map[string][]string) (*social_network_client.AccessToken, error) {
 	var data vkAccessTokenResponse
 
 	vkCredentials, err := v.stringToVKCredentials(credentials)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	req, err := http.NewRequest("GET", fmt.Sprintf("%s/access_token", v.authApiUrl), nil)
 	if err != nil {
-		return "", tracerr.Errorf("cannot create access token request:\n%s", err)
+		return nil, tracerr.Errorf("cannot create access token request:\n%s", err)
 	}
 	q := url.Values{
 		"client_id":     []string{vkCredentials.AppID},
@@ -92,16 +99,16 @@ func (v *vkClient) GetAccessToken(credentials string, queryParams map[string][]s
 	req.URL.RawQuery = q.Encode()
 	resp, err := v.httpClient.Do(req)
 	if err != nil {
-		return "", tracerr.Errorf("cannot get access token:\n%s", err)
+		return nil, tracerr.Errorf("cannot get access token:\n%s", err)
 	}
 
 	respBody, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return "", tracerr.Errorf("cannot read access token response:\n%s", err)
+		return nil, tracerr.Errorf("cannot read access token response:\n%s", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", tracerr.Errorf(
+		return nil, tracerr.Errorf(
 			"access token response status is %d\ntokenResponse:%s",
 			resp.StatusCode,
 			string(respBody),
@@ -110,17 +117,38 @@ func (v *vkClient) GetAccessToken(credentials string, queryParams map[string][]s
 
 	err = json.Unmarshal(respBody, &data)
 	if err != nil {
-		return "", tracerr.Errorf("cannot unmarshal access token body:\n%s", err)
+		return nil, tracerr.Errorf("cannot unmarshal access token body:\n%s", err)
 	}
 
-	return data.AccessToken, nil
+	return social_network_client.NewAccessToken(data.AccessToken, int64(data.ExpiresIn)), nil
 }
 
-func (v *vkClient) GetAccountPages(credentials, accessToken string) ([]SocialNetworkPage, error) {
+func (v *vkClient) GetAccountPages(credentials, accessToken string) ([]social_network_client.SocialNetworkPage, error) {
+	return social_network_client.GetAllAccountPages(v, credentials, accessToken)
+}
+
+// GetAccountPagesChunk курсором VK служит offset
+func (v *vkClient) GetAccountPagesChunk(
+	credentials string,
+	accessToken string,
+	cursor social_network_client.PagesCursor,
+) (*social_network_client.SocialNetworkPagesChunk, error) {
 	var (
-		pages []SocialNetworkPage
+		pages []social_network_client.SocialNetworkPage
 		data  vkGetAccountPagesResponse
 	)
+
+	offset := 0
+	if cursor.Cursor != "" {
+		parsedOffset, err := strconv.Atoi(cursor.Cursor)
+		if err != nil {
+			return nil, tracerr.Errorf("invalid vk pages cursor %s:\n%s", cursor.Cursor, err)
+		}
+		offset = parsedOffset
+	}
+	if cursor.Limit == 0 {
+		cursor.Limit = vkPagesChunkLimit
+	}
 	vkCredentials, err := v.stringToVKCredentials(credentials)
 	if err != nil {
 		return nil, err
@@ -136,7 +164,9 @@ func (v *vkClient) GetAccountPages(credentials, accessToken string) ([]SocialNet
 		"extended":     []string{"1"},
 		"filter":       []string{"admin"},
 		"fields":       []string{"id,name,photo_200"},
-		"v":            []string{"5.131"},
+		"offset":       []string{strconv.Itoa(offset)},
+		"count":        []string{strconv.Itoa(cursor.Limit)},
+		"v":            []string{v.apiVersion},
 	}
 	req.URL.RawQuery = q.Encode()
 	resp, err := v.httpClient.Do(req)
@@ -161,37 +191,49 @@ func (v *vkClient) GetAccountPages(credentials, accessToken string) ([]SocialNet
 	if err != nil {
 		return nil, tracerr.Errorf("cannot unmarshal getting pages body:\n%s", err)
 	}
+	if data.Error != nil {
+		return nil, tracerr.Errorf("get account pages failed with code %d: %s", data.Error.ErrorCode, data.Error.ErrorMsg)
+	}
 
 	for _, page := range data.Response.Items {
-		pages = append(pages, SocialNetworkPage{
+		pages = append(pages, social_network_client.SocialNetworkPage{
 			ID:    strconv.Itoa(page.ID),
 			Name:  page.Name,
 			Image: page.Image,
 		})
 	}
 
-	return pages, nil
+	chunk := &social_network_client.SocialNetworkPagesChunk{
+		Pages: pages,
+	}
+	nextOffset := offset + len(data.Response.Items)
+	if len(data.Response.Items) > 0 && nextOffset < data.Response.Count {
+		chunk.NextCursor = strconv.Itoa(nextOffset)
+	}
+
+	return chunk, nil
 }
 
-func (v *vkClient) CreatePost(credentials string, groupID, post string) (string, error) {
+func (v *vkClient) CreatePost(
+	ctx context.Context,
+	credentials string,
+	accessToken string,
+	groupID string,
+	post social_network_client.Post,
+) (string, error) {
 	var data vkCreatePostResponse
 	vkCredentials, err := v.stringToVKCredentials(credentials)
 	if err != nil {
 		return "", err
 	}
+	if accessToken == "" {
+		accessToken = vkCredentials.AccessToken
+	}
 
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/method/wall.post", v.workApiUrl), nil)
+	req, err := v.newWallPostRequest(accessToken, groupID, post)
 	if err != nil {
-		return "", fmt.Errorf("cannot create createPost request:\n%s", err)
+		return "", err
 	}
-
-	q := req.URL.Query()
-	q.Add("owner_id", groupID)
-	q.Add("access_token", vkCredentials.AccessToken)
-	q.Add("from_group", "1")
-	q.Add("message", post)
-	q.Add("v", "5.131")
-	req.URL.RawQuery = q.Encode()
 	resp, err := v.httpClient.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("cannot create post:\n%s", err)
@@ -214,18 +256,44 @@ func (v *vkClient) CreatePost(credentials string, groupID, post string) (string,
 	if err != nil {
 		return "", fmt.Errorf("cannot unmarshal access token body:\n%s", err)
 	}
+	if data.Error != nil {
+		return "", tracerr.Errorf("create post failed with code %d: %s", data.Error.ErrorCode, data.Error.ErrorMsg)
+	}
 
 	return strconv.Itoa(data.Response.PostID), nil
 }
 
-func (v *vkClient) DeletePost() {
-	//TODO implement me
-	panic("implement me")
+func (v *vkClient) newWallPostRequest(
+	accessToken string,
+	groupID string,
+	post social_network_client.Post,
+) (*http.Request, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/method/wall.post", v.workApiUrl), nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create createPost request:\n%s", err)
+	}
+
+	q := req.URL.Query()
+	q.Add("owner_id", vkGroupOwnerID(groupID))
+	q.Add("access_token", accessToken)
+	q.Add("from_group", "1")
+	q.Add("message", post.Text)
+	if post.Link != "" {
+		q.Add("attachments", post.Link)
+	}
+	q.Add("v", v.apiVersion)
+	req.URL.RawQuery = q.Encode()
+
+	return req, nil
+}
+
+func (v *vkClient) Capabilities() social_network_client.Capabilities {
+	return capabilities
 }
 
-func (v *vkClient) UploadImage() {
-	//TODO implement me
-	panic("implement me")
+// DeletePost wall.delete требует owner_id, а по id поста сообщество не определить
+func (v *vkClient) DeletePost(credentials, accessToken, postID string) error {
+	return tracerr.Errorf("vk client does not support deleting posts")
 }
 
 func (v *vkClient) stringToVKCredentials(credentials string) (*VKCredentials, error) {
@@ -237,13 +305,14 @@ func (v *vkClient) stringToVKCredentials(credentials string) (*VKCredentials, er
 	return vkCredentials, nil
 }
 
-func NewVKClient() SocialNetworkClient {
+func NewVKClient(config social_network_client.ClientConfig) social_network_client.SocialNetworkClient {
 	client := vkClient{
 		httpClient:  &http.Client{},
-		authApiUrl:  "https://oauth.vk.com",
-		workApiUrl:  "https://api.vk.com",
-		redirectUrl: "http://localhost:8080/auth/get_token?socialNetwork=VK",
-		scope:       "offline,groups,photos,video,pages,wall",
+		authApiUrl:  config.AuthApiUrl,
+		workApiUrl:  config.WorkApiUrl,
+		redirectUrl: config.RedirectUrl,
+		apiVersion:  config.ApiVersion,
+		scope:       oauthScope,
 	}
 	return &client
 }
@@ -0,0 +1,262 @@
+package vk
+
+import (
+	"autoposting/internal/infrastructure/social_network_client"
+	"encoding/json"
+	"github.com/ztrue/tracerr"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// vkCommentsChunkLimit максимальный count wall.getComments
+	vkCommentsChunkLimit = 100
+	// vkThreadItemsLimit максимальный thread_items_count wall.getComments
+	vkThreadItemsLimit = 10
+)
+
+type vkCommentsResponse struct {
+	Count    int         `json:"count"`
+	Items    []vkComment `json:"items"`
+	Profiles []struct {
+		ID        int    `json:"id"`
+		FirstName string `json:"first_name"`
+		LastName  string `json:"last_name"`
+	} `json:"profiles"`
+	Groups []struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"groups"`
+}
+
+type vkComment struct {
+	ID           int    `json:"id"`
+	FromID       int    `json:"from_id"`
+	Date         int64  `json:"date"`
+	Text         string `json:"text"`
+	ParentsStack []int  `json:"parents_stack"`
+	Thread       struct {
+		Count int         `json:"count"`
+		Items []vkComment `json:"items"`
+	} `json:"thread"`
+}
+
+// GetPostComments читает комментарии первого уровня порциями wall.getComments вместе с ветками ответов.
+// Ветки, которые не поместились в thread_items_count, дочитываются по comment_id
+func (v *vkClient) GetPostComments(
+	credentials string,
+	accessToken string,
+	post social_network_client.RemotePost,
+) ([]social_network_client.Comment, error) {
+	vkCredentials, err := v.stringToVKCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+	if accessToken == "" {
+		accessToken = vkCredentials.AccessToken
+	}
+
+	var (
+		comments       []social_network_client.Comment
+		truncatedIDs   []int
+		authorsNames   = map[int]string{}
+		vkComments     []vkComment
+		topLevelOffset = 0
+	)
+	for {
+		data, err := v.getComments(accessToken, post, map[string]interface{}{
+			"offset":             topLevelOffset,
+			"count":              vkCommentsChunkLimit,
+			"thread_items_count": vkThreadItemsLimit,
+		}, authorsNames)
+		if err != nil {
+			return nil, err
+		}
+		for _, comment := range data.Items {
+			vkComments = append(vkComments, comment)
+			if comment.Thread.Count > len(comment.Thread.Items) {
+				truncatedIDs = append(truncatedIDs, comment.ID)
+			} else {
+				vkComments = append(vkComments, comment.Thread.Items...)
+			}
+		}
+
+		topLevelOffset += len(data.Items)
+		if len(data.Items) == 0 || topLevelOffset >= data.Count {
+			break
+		}
+	}
+
+	for _, commentID := range truncatedIDs {
+		for offset := 0; ; {
+			data, err := v.getComments(accessToken, post, map[string]interface{}{
+				"comment_id": commentID,
+				"offset":     offset,
+				"count":      vkCommentsChunkLimit,
+			}, authorsNames)
+			if err != nil {
+				return nil, err
+			}
+			vkComments = append(vkComments, data.Items...)
+
+			offset += len(data.Items)
+			if len(data.Items) == 0 || offset >= data.Count {
+				break
+			}
+		}
+	}
+
+	pageOwnerID, _ := strconv.Atoi(vkGroupOwnerID(post.PageID))
+	for _, comment := range vkComments {
+		result := social_network_client.Comment{
+			CommentID:  strconv.Itoa(comment.ID),
+			AuthorID:   strconv.Itoa(comment.FromID),
+			AuthorName: authorsNames[comment.FromID],
+			ByPage:     comment.FromID == pageOwnerID,
+			Text:       comment.Text,
+			CreatedAt:  time.Unix(comment.Date, 0),
+		}
+		if len(comment.ParentsStack) != 0 {
+			result.ParentID = strconv.Itoa(comment.ParentsStack[len(comment.ParentsStack)-1])
+		}
+		comments = append(comments, result)
+	}
+
+	return comments, nil
+}
+
+// getComments один вызов wall.getComments с extended, имена авторов добавляются в authorsNames по from_id
+func (v *vkClient) getComments(
+	accessToken string,
+	post social_network_client.RemotePost,
+	params map[string]interface{},
+	authorsNames map[int]string,
+) (*vkCommentsResponse, error) {
+	params["owner_id"] = vkGroupOwnerID(post.PageID)
+	params["post_id"] = post.PostID
+	params["extended"] = 1
+	params["sort"] = "asc"
+
+	executeResults, err := v.execute(accessToken, []vkExecuteCall{{
+		Method: "wall.getComments",
+		Params: params,
+	}})
+	if err != nil {
+		return nil, err
+	}
+	if executeResults[0].Err != nil {
+		return nil, executeResults[0].Err
+	}
+
+	var data vkCommentsResponse
+	if err := json.Unmarshal(executeResults[0].Response, &data); err != nil {
+		return nil, tracerr.Errorf("cannot unmarshal wall.getComments result:\n%s", err)
+	}
+	for _, profile := range data.Profiles {
+		authorsNames[profile.ID] = strings.TrimSpace(profile.FirstName + " " + profile.LastName)
+	}
+	for _, group := range data.Groups {
+		authorsNames[-group.ID] = group.Name
+	}
+
+	return &data, nil
+}
+
+// ReplyToComment отвечает через wall.createComment от имени группы
+func (v *vkClient) ReplyToComment(
+	credentials string,
+	accessToken string,
+	comment social_network_client.RemoteComment,
+	text string,
+) (string, error) {
+	vkCredentials, err := v.stringToVKCredentials(credentials)
+	if err != nil {
+		return "", err
+	}
+	if accessToken == "" {
+		accessToken = vkCredentials.AccessToken
+	}
+
+	executeResults, err := v.execute(accessToken, []vkExecuteCall{{
+		Method: "wall.createComment",
+		Params: map[string]interface{}{
+			"owner_id":         vkGroupOwnerID(comment.PageID),
+			"post_id":          comment.PostID,
+			"reply_to_comment": comment.CommentID,
+			"from_group":       strings.TrimPrefix(comment.PageID, "-"),
+			"message":          text,
+		},
+	}})
+	if err != nil {
+		return "", err
+	}
+	if executeResults[0].Err != nil {
+		return "", executeResults[0].Err
+	}
+
+	var data struct {
+		CommentID int `json:"comment_id"`
+	}
+	if err := json.Unmarshal(executeResults[0].Response, &data); err != nil {
+		return "", tracerr.Errorf("cannot unmarshal wall.createComment result:\n%s", err)
+	}
+
+	return strconv.Itoa(data.CommentID), nil
+}
+
+// DeleteComment удаляет комментарий со стены группы через wall.deleteComment
+func (v *vkClient) DeleteComment(
+	credentials string,
+	accessToken string,
+	comment social_network_client.RemoteComment,
+) error {
+	vkCredentials, err := v.stringToVKCredentials(credentials)
+	if err != nil {
+		return err
+	}
+	if accessToken == "" {
+		accessToken = vkCredentials.AccessToken
+	}
+
+	executeResults, err := v.execute(accessToken, []vkExecuteCall{{
+		Method: "wall.deleteComment",
+		Params: map[string]interface{}{
+			"owner_id":   vkGroupOwnerID(comment.PageID),
+			"comment_id": comment.CommentID,
+		},
+	}})
+	if err != nil {
+		return err
+	}
+
+	return executeResults[0].Err
+}
+
+// RestoreComment возвращает комментарий, удаленный wall.deleteComment, через wall.restoreComment
+func (v *vkClient) RestoreComment(
+	credentials string,
+	accessToken string,
+	comment social_network_client.RemoteComment,
+) error {
+	vkCredentials, err := v.stringToVKCredentials(credentials)
+	if err != nil {
+		return err
+	}
+	if accessToken == "" {
+		accessToken = vkCredentials.AccessToken
+	}
+
+	executeResults, err := v.execute(accessToken, []vkExecuteCall{{
+		Method: "wall.restoreComment",
+		Params: map[string]interface{}{
+			"owner_id":   vkGroupOwnerID(comment.PageID),
+			"comment_id": comment.CommentID,
+		},
+	}})
+	if err != nil {
+		return err
+	}
+
+	return executeResults[0].Err
+}
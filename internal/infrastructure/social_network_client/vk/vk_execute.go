@@ -0,0 +1,554 @@
+package vk
+
+import (
+	"autoposting/internal/infrastructure/social_network_client"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/ztrue/tracerr"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// vkExecuteCallsLimit максимальное число вызовов API в одном execute
+const vkExecuteCallsLimit = 25
+
+const (
+	vkGroupsByIdLimit = 500
+	vkPostReachLimit  = 30
+	vkWallByIdLimit   = 100
+)
+
+type vkExecuteCall struct {
+	Method string
+	Params map[string]interface{}
+}
+
+type vkExecuteResult struct {
+	Response json.RawMessage
+	Err      error
+}
+
+type vkError struct {
+	Method    string `json:"method"`
+	ErrorCode int    `json:"error_code"`
+	ErrorMsg  string `json:"error_msg"`
+}
+
+type vkExecuteResponse struct {
+	Response      []json.RawMessage `json:"response"`
+	ExecuteErrors []vkError         `json:"execute_errors"`
+	Error         *vkError          `json:"error"`
+}
+
+type vkGroupsByIdResponse []vkGroupById
+
+type vkGroupById struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	Image        string `json:"photo_200"`
+	MembersCount int    `json:"members_count"`
+}
+
+type vkWallByIdResponse []vkWallPostById
+
+type vkWallPostById struct {
+	ID       int     `json:"id"`
+	OwnerID  int     `json:"owner_id"`
+	Text     string  `json:"text"`
+	Likes    vkCount `json:"likes"`
+	Reposts  vkCount `json:"reposts"`
+	Comments vkCount `json:"comments"`
+	Views    vkCount `json:"views"`
+}
+
+type vkCount struct {
+	Count int `json:"count"`
+}
+
+type vkWallPostByIdResult struct {
+	post     social_network_client.RemotePost
+	wallPost *vkWallPostById
+	err      error
+}
+
+type vkPostReachResponse []struct {
+	PostID           int `json:"post_id"`
+	ReachTotal       int `json:"reach_total"`
+	ReachSubscribers int `json:"reach_subscribers"`
+	Links            int `json:"links"`
+	ToGroup          int `json:"to_group"`
+	JoinGroup        int `json:"join_group"`
+	Hide             int `json:"hide"`
+	Report           int `json:"report"`
+	Unsubscribe      int `json:"unsubscribe"`
+}
+
+// CreatePosts публикует пост во все группы, объединяя wall.post в execute
+func (v *vkClient) CreatePosts(
+	ctx context.Context,
+	credentials string,
+	accessToken string,
+	groupIDs []string,
+	post social_network_client.Post,
+) ([]social_network_client.PostResult, error) {
+	vkCredentials, err := v.stringToVKCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+	if accessToken == "" {
+		accessToken = vkCredentials.AccessToken
+	}
+
+	// Посты порций, выполненных до ошибки, уже опубликованы, ошибка относится только к остальным группам
+	executeResults, executeErr := v.execute(accessToken, vkWallPostCalls(groupIDs, post))
+	if executeErr != nil && len(executeResults) == 0 {
+		return nil, executeErr
+	}
+
+	results := make([]social_network_client.PostResult, 0, len(groupIDs))
+	for i, executeResult := range executeResults {
+		result := social_network_client.PostResult{
+			PageID: groupIDs[i],
+			Err:    executeResult.Err,
+		}
+		if result.Err == nil {
+			var data struct {
+				PostID int `json:"post_id"`
+			}
+			if err := json.Unmarshal(executeResult.Response, &data); err != nil {
+				result.Err = tracerr.Errorf("cannot unmarshal wall.post result:\n%s", err)
+			} else {
+				result.PostID = strconv.Itoa(data.PostID)
+			}
+		}
+		results = append(results, result)
+	}
+	for _, groupID := range groupIDs[len(executeResults):] {
+		results = append(results, social_network_client.PostResult{
+			PageID: groupID,
+			Err:    executeErr,
+		})
+	}
+
+	return results, nil
+}
+
+// PreparePost собирает запросы execute, которые отправил бы CreatePosts
+func (v *vkClient) PreparePost(
+	credentials string,
+	accessToken string,
+	groupIDs []string,
+	post social_network_client.Post,
+) ([]social_network_client.PreparedRequest, error) {
+	vkCredentials, err := v.stringToVKCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+	if accessToken == "" {
+		accessToken = vkCredentials.AccessToken
+	}
+
+	calls := vkWallPostCalls(groupIDs, post)
+	var requests []social_network_client.PreparedRequest
+	for start := 0; start < len(calls); start += vkExecuteCallsLimit {
+		end := start + vkExecuteCallsLimit
+		if end > len(calls) {
+			end = len(calls)
+		}
+
+		req, err := v.newExecuteRequest(accessToken, calls[start:end])
+		if err != nil {
+			return nil, err
+		}
+		preparedRequest, err := social_network_client.NewPreparedRequest(groupIDs[start:end], req)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, preparedRequest)
+	}
+
+	return requests, nil
+}
+
+func vkWallPostCalls(groupIDs []string, post social_network_client.Post) []vkExecuteCall {
+	calls := make([]vkExecuteCall, 0, len(groupIDs))
+	for _, groupID := range groupIDs {
+		params := map[string]interface{}{
+			"owner_id":   vkGroupOwnerID(groupID),
+			"from_group": 1,
+			"message":    post.Text,
+		}
+		if post.Link != "" {
+			params["attachments"] = post.Link
+		}
+		calls = append(calls, vkExecuteCall{
+			Method: "wall.post",
+			Params: params,
+		})
+	}
+	return calls
+}
+
+// getGroupsByID данные групп через groups.getById порциями по vkGroupsByIdLimit в одном execute.
+// Ошибка вызова относится ко всем группам его порции, ненайденных групп нет ни в одной из карт
+func (v *vkClient) getGroupsByID(
+	accessToken string,
+	groupIDs []string,
+	fields string,
+) (map[string]vkGroupById, map[string]error, error) {
+	var calls []vkExecuteCall
+	for start := 0; start < len(groupIDs); start += vkGroupsByIdLimit {
+		end := start + vkGroupsByIdLimit
+		if end > len(groupIDs) {
+			end = len(groupIDs)
+		}
+		calls = append(calls, vkExecuteCall{
+			Method: "groups.getById",
+			Params: map[string]interface{}{
+				"group_ids": strings.Join(groupIDs[start:end], ","),
+				"fields":    fields,
+			},
+		})
+	}
+
+	executeResults, err := v.execute(accessToken, calls)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	groups := make(map[string]vkGroupById, len(groupIDs))
+	errByGroup := map[string]error{}
+	for i, executeResult := range executeResults {
+		start := i * vkGroupsByIdLimit
+		end := start + vkGroupsByIdLimit
+		if end > len(groupIDs) {
+			end = len(groupIDs)
+		}
+		if executeResult.Err != nil {
+			for _, groupID := range groupIDs[start:end] {
+				errByGroup[groupID] = executeResult.Err
+			}
+			continue
+		}
+
+		var data vkGroupsByIdResponse
+		if err := json.Unmarshal(executeResult.Response, &data); err != nil {
+			return nil, nil, tracerr.Errorf("cannot unmarshal groups.getById result:\n%s", err)
+		}
+		for _, group := range data {
+			groups[strconv.Itoa(group.ID)] = group
+		}
+	}
+
+	return groups, errByGroup, nil
+}
+
+// GetPostsReach получает охват постов через stats.getPostReach, по одному вызову на группу
+func (v *vkClient) GetPostsReach(accessToken string, posts []social_network_client.RemotePost) ([]social_network_client.PostReachResult, error) {
+	var (
+		calls      []vkExecuteCall
+		callsPosts [][]social_network_client.RemotePost
+	)
+	postsByGroup := map[string][]social_network_client.RemotePost{}
+	var groupsOrder []string
+	for _, post := range posts {
+		if _, ok := postsByGroup[post.PageID]; !ok {
+			groupsOrder = append(groupsOrder, post.PageID)
+		}
+		postsByGroup[post.PageID] = append(postsByGroup[post.PageID], post)
+	}
+	for _, groupID := range groupsOrder {
+		groupPosts := postsByGroup[groupID]
+		for start := 0; start < len(groupPosts); start += vkPostReachLimit {
+			end := start + vkPostReachLimit
+			if end > len(groupPosts) {
+				end = len(groupPosts)
+			}
+			postIDs := make([]string, 0, end-start)
+			for _, post := range groupPosts[start:end] {
+				postIDs = append(postIDs, post.PostID)
+			}
+			calls = append(calls, vkExecuteCall{
+				Method: "stats.getPostReach",
+				Params: map[string]interface{}{
+					"owner_id": vkGroupOwnerID(groupID),
+					"post_ids": strings.Join(postIDs, ","),
+				},
+			})
+			callsPosts = append(callsPosts, groupPosts[start:end])
+		}
+	}
+
+	executeResults, err := v.execute(accessToken, calls)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]social_network_client.PostReachResult, 0, len(posts))
+	for i, executeResult := range executeResults {
+		reachByPost := map[string]social_network_client.PostReach{}
+		err := executeResult.Err
+		if err == nil {
+			var data vkPostReachResponse
+			if unmarshalErr := json.Unmarshal(executeResult.Response, &data); unmarshalErr != nil {
+				err = tracerr.Errorf("cannot unmarshal stats.getPostReach result:\n%s", unmarshalErr)
+			}
+			for _, reach := range data {
+				reachByPost[strconv.Itoa(reach.PostID)] = social_network_client.PostReach{
+					Total:       reach.ReachTotal,
+					Subscribers: reach.ReachSubscribers,
+					Links:       reach.Links,
+					ToGroup:     reach.ToGroup,
+					JoinGroup:   reach.JoinGroup,
+					Hide:        reach.Hide,
+					Report:      reach.Report,
+					Unsubscribe: reach.Unsubscribe,
+				}
+			}
+		}
+		for _, post := range callsPosts[i] {
+			results = append(results, social_network_client.PostReachResult{
+				Post:  post,
+				Reach: reachByPost[post.PostID],
+				Err:   err,
+			})
+		}
+	}
+
+	return results, nil
+}
+
+// GetRemotePosts получает посты через wall.getById, удаленных постов в ответе нет
+func (v *vkClient) GetRemotePosts(
+	credentials string,
+	accessToken string,
+	posts []social_network_client.RemotePost,
+) ([]social_network_client.RemotePostState, error) {
+	vkCredentials, err := v.stringToVKCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+	if accessToken == "" {
+		accessToken = vkCredentials.AccessToken
+	}
+
+	wallPosts, err := v.getWallPostsByID(accessToken, posts)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]social_network_client.RemotePostState, 0, len(posts))
+	for _, wallPost := range wallPosts {
+		state := social_network_client.RemotePostState{
+			Post:    wallPost.post,
+			Deleted: wallPost.err == nil && wallPost.wallPost == nil,
+			Err:     wallPost.err,
+		}
+		if wallPost.wallPost != nil {
+			state.Text = wallPost.wallPost.Text
+		}
+		states = append(states, state)
+	}
+
+	return states, nil
+}
+
+// getWallPostsByID вызывает wall.getById порциями по vkWallByIdLimit. Ошибка порции относится ко всем ее постам,
+// wallPost nil - поста нет в ответе
+func (v *vkClient) getWallPostsByID(
+	accessToken string,
+	posts []social_network_client.RemotePost,
+) ([]vkWallPostByIdResult, error) {
+	var calls []vkExecuteCall
+	for start := 0; start < len(posts); start += vkWallByIdLimit {
+		end := start + vkWallByIdLimit
+		if end > len(posts) {
+			end = len(posts)
+		}
+		wallPostsIDs := make([]string, 0, end-start)
+		for _, post := range posts[start:end] {
+			wallPostsIDs = append(wallPostsIDs, vkGroupOwnerID(post.PageID)+"_"+post.PostID)
+		}
+		calls = append(calls, vkExecuteCall{
+			Method: "wall.getById",
+			Params: map[string]interface{}{
+				"posts": strings.Join(wallPostsIDs, ","),
+			},
+		})
+	}
+
+	executeResults, err := v.execute(accessToken, calls)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]vkWallPostByIdResult, 0, len(posts))
+	for i, executeResult := range executeResults {
+		wallPostsByID := map[string]*vkWallPostById{}
+		err := executeResult.Err
+		if err == nil {
+			var data vkWallByIdResponse
+			if unmarshalErr := json.Unmarshal(executeResult.Response, &data); unmarshalErr != nil {
+				err = tracerr.Errorf("cannot unmarshal wall.getById result:\n%s", unmarshalErr)
+			}
+			for j := range data {
+				wallPostsByID[fmt.Sprintf("%d_%d", data[j].OwnerID, data[j].ID)] = &data[j]
+			}
+		}
+
+		end := (i + 1) * vkWallByIdLimit
+		if end > len(posts) {
+			end = len(posts)
+		}
+		for _, post := range posts[i*vkWallByIdLimit : end] {
+			results = append(results, vkWallPostByIdResult{
+				post:     post,
+				wallPost: wallPostsByID[vkGroupOwnerID(post.PageID)+"_"+post.PostID],
+				err:      err,
+			})
+		}
+	}
+
+	return results, nil
+}
+
+// execute выполняет вызовы порциями по vkExecuteCallsLimit и возвращает результаты в порядке вызовов.
+// При ошибке порции возвращаются и результаты уже выполненных порций, вызовы с ними уже применены в VK
+func (v *vkClient) execute(accessToken string, calls []vkExecuteCall) ([]vkExecuteResult, error) {
+	results := make([]vkExecuteResult, 0, len(calls))
+
+	for start := 0; start < len(calls); start += vkExecuteCallsLimit {
+		end := start + vkExecuteCallsLimit
+		if end > len(calls) {
+			end = len(calls)
+		}
+
+		chunkResults, err := v.executeChunk(accessToken, calls[start:end])
+		if err != nil {
+			return results, err
+		}
+		results = append(results, chunkResults...)
+	}
+
+	return results, nil
+}
+
+func (v *vkClient) executeChunk(accessToken string, calls []vkExecuteCall) ([]vkExecuteResult, error) {
+	var data vkExecuteResponse
+
+	req, err := v.newExecuteRequest(accessToken, calls)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot execute:\n%s", err)
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot read execute response:\n%s", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, tracerr.Errorf(
+			"execute response status is %d\nresponse:%s",
+			resp.StatusCode,
+			string(respBody),
+		)
+	}
+
+	err = json.Unmarshal(respBody, &data)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot unmarshal execute body:\n%s", err)
+	}
+
+	if data.Error != nil {
+		return nil, tracerr.Errorf("execute failed with code %d: %s", data.Error.ErrorCode, data.Error.ErrorMsg)
+	}
+
+	if len(data.Response) != len(calls) {
+		return nil, tracerr.Errorf(
+			"execute returned %d results for %d calls\nresponse:%s",
+			len(data.Response),
+			len(calls),
+			string(respBody),
+		)
+	}
+
+	// Упавший вызов возвращает false, его ошибка лежит в execute_errors в порядке вызовов
+	results := make([]vkExecuteResult, 0, len(calls))
+	executeErrors := data.ExecuteErrors
+	for i, response := range data.Response {
+		if string(response) != "false" {
+			results = append(results, vkExecuteResult{Response: response})
+			continue
+		}
+
+		err := tracerr.Errorf("%s failed", calls[i].Method)
+		if len(executeErrors) > 0 {
+			err = tracerr.Errorf(
+				"%s failed with code %d: %s",
+				calls[i].Method,
+				executeErrors[0].ErrorCode,
+				executeErrors[0].ErrorMsg,
+			)
+			executeErrors = executeErrors[1:]
+		}
+		results = append(results, vkExecuteResult{Err: err})
+	}
+
+	return results, nil
+}
+
+func (v *vkClient) newExecuteRequest(accessToken string, calls []vkExecuteCall) (*http.Request, error) {
+	code, err := vkExecuteCode(calls)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"code":         []string{code},
+		"access_token": []string{accessToken},
+		"v":            []string{v.apiVersion},
+	}
+	req, err := http.NewRequest(
+		"POST",
+		fmt.Sprintf("%s/method/execute", v.workApiUrl),
+		bytes.NewBufferString(form.Encode()),
+	)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot create execute request:\n%s", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	return req, nil
+}
+
+// vkExecuteCode собирает VKScript вида return [API.method({...}), ...];
+func vkExecuteCode(calls []vkExecuteCall) (string, error) {
+	apiCalls := make([]string, 0, len(calls))
+	for _, call := range calls {
+		params, err := json.Marshal(call.Params)
+		if err != nil {
+			return "", tracerr.Errorf("cannot marshal %s params:\n%s", call.Method, err)
+		}
+		apiCalls = append(apiCalls, fmt.Sprintf("API.%s(%s)", call.Method, params))
+	}
+
+	return fmt.Sprintf("return [%s];", strings.Join(apiCalls, ",")), nil
+}
+
+// vkGroupOwnerID стена группы в VK адресуется отрицательным owner_id
+func vkGroupOwnerID(groupID string) string {
+	if strings.HasPrefix(groupID, "-") {
+		return groupID
+	}
+	return "-" + groupID
+}
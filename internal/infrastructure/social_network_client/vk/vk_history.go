@@ -0,0 +1,162 @@
+package vk
+
+import (
+	"autoposting/internal/infrastructure/social_network_client"
+	"encoding/json"
+	"fmt"
+	"github.com/ztrue/tracerr"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// vkWallChunkLimit максимальный count wall.get
+const vkWallChunkLimit = 100
+
+type vkWallGetResponse struct {
+	Response struct {
+		Count int          `json:"count"`
+		Items []vkWallPost `json:"items"`
+	} `json:"response"`
+	Error *vkError `json:"error"`
+}
+
+type vkWallPost struct {
+	ID          int    `json:"id"`
+	Date        int64  `json:"date"`
+	Text        string `json:"text"`
+	IsPinned    int    `json:"is_pinned"`
+	Attachments []struct {
+		Type  string `json:"type"`
+		Photo struct {
+			Sizes []struct {
+				URL   string `json:"url"`
+				Width int    `json:"width"`
+			} `json:"sizes"`
+		} `json:"photo"`
+		Link struct {
+			URL string `json:"url"`
+		} `json:"link"`
+	} `json:"attachments"`
+}
+
+// GetPageHistoryChunk курсором служит offset wall.get. Закрепленный пост VK отдает первым независимо от даты,
+// поэтому порция заканчивается на первом незакрепленном посте старше since
+func (v *vkClient) GetPageHistoryChunk(
+	credentials string,
+	accessToken string,
+	groupID string,
+	since time.Time,
+	cursor social_network_client.PagesCursor,
+) (*social_network_client.PageHistoryChunk, error) {
+	var data vkWallGetResponse
+
+	offset := 0
+	if cursor.Cursor != "" {
+		parsedOffset, err := strconv.Atoi(cursor.Cursor)
+		if err != nil {
+			return nil, tracerr.Errorf("invalid vk wall cursor %s:\n%s", cursor.Cursor, err)
+		}
+		offset = parsedOffset
+	}
+	if cursor.Limit == 0 || cursor.Limit > vkWallChunkLimit {
+		cursor.Limit = vkWallChunkLimit
+	}
+	vkCredentials, err := v.stringToVKCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+	if accessToken == "" {
+		accessToken = vkCredentials.AccessToken
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/method/wall.get", v.workApiUrl), nil)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot create getting wall request:\n%s", err)
+	}
+	q := url.Values{
+		"access_token": []string{accessToken},
+		"owner_id":     []string{vkGroupOwnerID(groupID)},
+		"filter":       []string{"owner"},
+		"offset":       []string{strconv.Itoa(offset)},
+		"count":        []string{strconv.Itoa(cursor.Limit)},
+		"v":            []string{v.apiVersion},
+	}
+	req.URL.RawQuery = q.Encode()
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot get wall of group %s:\n%s", groupID, err)
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot read getting wall response:\n%s", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, tracerr.Errorf(
+			"get wall response status %d\nresponse:%s",
+			resp.StatusCode,
+			string(respBody),
+		)
+	}
+
+	err = json.Unmarshal(respBody, &data)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot unmarshal getting wall body:\n%s", err)
+	}
+	if data.Error != nil {
+		return nil, tracerr.Errorf("get wall failed with code %d: %s", data.Error.ErrorCode, data.Error.ErrorMsg)
+	}
+
+	chunk := &social_network_client.PageHistoryChunk{}
+	reachedSince := false
+	for _, wallPost := range data.Response.Items {
+		publishedAt := time.Unix(wallPost.Date, 0)
+		if publishedAt.Before(since) {
+			if wallPost.IsPinned == 1 {
+				continue
+			}
+			reachedSince = true
+			break
+		}
+		chunk.Posts = append(chunk.Posts, social_network_client.PageHistoryPost{
+			PostID:      strconv.Itoa(wallPost.ID),
+			Post:        vkWallPostToPost(wallPost),
+			PublishedAt: publishedAt,
+		})
+	}
+
+	nextOffset := offset + len(data.Response.Items)
+	if !reachedSince && len(data.Response.Items) > 0 && nextOffset < data.Response.Count {
+		chunk.NextCursor = strconv.Itoa(nextOffset)
+	}
+
+	return chunk, nil
+}
+
+// vkWallPostToPost из фото берется самый широкий размер
+func vkWallPostToPost(wallPost vkWallPost) social_network_client.Post {
+	post := social_network_client.Post{
+		Text: wallPost.Text,
+	}
+	for _, attachment := range wallPost.Attachments {
+		switch attachment.Type {
+		case "photo":
+			imageURL, width := "", 0
+			for _, size := range attachment.Photo.Sizes {
+				if size.Width >= width {
+					imageURL, width = size.URL, size.Width
+				}
+			}
+			if imageURL != "" {
+				post.Images = append(post.Images, imageURL)
+			}
+		case "link":
+			post.Link = attachment.Link.URL
+		}
+	}
+	return post
+}
@@ -0,0 +1,62 @@
+package vk
+
+import (
+	"autoposting/internal/infrastructure/social_network_client"
+)
+
+// GetPostsMetrics счетчики берутся из wall.getById, охват - из stats.getPostReach.
+// Охват доступен только администраторам сообщества, без него остаются счетчики и ошибка в ReachErr
+func (v *vkClient) GetPostsMetrics(
+	credentials string,
+	accessToken string,
+	posts []social_network_client.RemotePost,
+) ([]social_network_client.PostMetricsResult, error) {
+	vkCredentials, err := v.stringToVKCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+	if accessToken == "" {
+		accessToken = vkCredentials.AccessToken
+	}
+
+	wallPosts, err := v.getWallPostsByID(accessToken, posts)
+	if err != nil {
+		return nil, err
+	}
+
+	reachByPost := map[social_network_client.RemotePost]int{}
+	reachErrByPost := map[social_network_client.RemotePost]error{}
+	reachResults, reachErr := v.GetPostsReach(accessToken, posts)
+	for _, reachResult := range reachResults {
+		if reachResult.Err != nil {
+			reachErrByPost[reachResult.Post] = reachResult.Err
+			continue
+		}
+		reachByPost[reachResult.Post] = reachResult.Reach.Total
+	}
+
+	results := make([]social_network_client.PostMetricsResult, 0, len(posts))
+	for _, wallPost := range wallPosts {
+		result := social_network_client.PostMetricsResult{
+			Post:    wallPost.post,
+			Deleted: wallPost.err == nil && wallPost.wallPost == nil,
+			Err:     wallPost.err,
+		}
+		if wallPost.wallPost != nil {
+			result.ReachErr = reachErrByPost[wallPost.post]
+			if reachErr != nil {
+				result.ReachErr = reachErr
+			}
+			result.Metrics = social_network_client.PostMetrics{
+				Likes:    wallPost.wallPost.Likes.Count,
+				Reposts:  wallPost.wallPost.Reposts.Count,
+				Comments: wallPost.wallPost.Comments.Count,
+				Views:    wallPost.wallPost.Views.Count,
+				Reach:    reachByPost[wallPost.post],
+			}
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
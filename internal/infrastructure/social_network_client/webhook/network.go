@@ -0,0 +1,23 @@
+package webhook
+
+import "autoposting/internal/infrastructure/social_network_client"
+
+const Name = "WEBHOOK"
+
+// capabilities свои сайты принимают пост целиком, ограничения задает получатель
+var capabilities = social_network_client.Capabilities{
+	Images: true,
+	Video:  true,
+	Links:  true,
+	Polls:  true,
+}
+
+func init() {
+	// Credentials аккаунта - секрет подписи и адреса получателей, OAuth не используется
+	social_network_client.Register(social_network_client.Network{
+		Name:         Name,
+		DisplayName:  "Webhook",
+		Capabilities: capabilities,
+		NewClient:    NewWebhookClient,
+	})
+}
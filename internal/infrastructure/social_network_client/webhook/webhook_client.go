@@ -0,0 +1,436 @@
+package webhook
+
+import (
+	"autoposting/internal/infrastructure/social_network_client"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/ztrue/tracerr"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// webhookMaxAttempts попытки доставки, повторяются сетевые ошибки, 429 и 5xx
+	webhookMaxAttempts   = 3
+	webhookRetryDelay    = time.Second
+	webhookMaxRetryDelay = 30 * time.Second
+	webhookTimeout       = 10 * time.Second
+	// webhookMaxRecordedBody сколько байт ответа получателя сохраняется в попытке доставки
+	webhookMaxRecordedBody = 4096
+
+	WebhookIDHeader        = "X-Webhook-Id"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	// WebhookSignatureHeader sha256=HMAC-SHA256(secret, timestamp + "." + body) в hex
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// WebhookCredentials секрет подписи общий для всех получателей аккаунта
+type WebhookCredentials struct {
+	Secret    string            `json:"secret"`
+	Endpoints []WebhookEndpoint `json:"endpoints"`
+}
+
+// WebhookEndpoint получатель постов, в проекте это страница с PageID равным ID
+type WebhookEndpoint struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// Template JSON шаблон тела запроса, пустой - webhookDefaultTemplate
+	Template json.RawMessage   `json:"template"`
+	Headers  map[string]string `json:"headers"`
+}
+
+type webhookResponse struct {
+	ID interface{} `json:"id"`
+}
+
+// webhookDelivery тело доставки, подписывается заново перед каждой попыткой
+type webhookDelivery struct {
+	id       string
+	endpoint WebhookEndpoint
+	body     []byte
+}
+
+type webhookClient struct {
+	httpClient *http.Client
+}
+
+func (w *webhookClient) GetAuthURL(credentials string) (string, error) {
+	return "", tracerr.Errorf("webhooks do not use oauth")
+}
+
+func (w *webhookClient) GetAccessToken(
+	credentials string,
+	queryParams map[string][]string,
+) (*social_network_client.AccessToken, error) {
+	return nil, tracerr.Errorf("webhooks do not use oauth")
+}
+
+func (w *webhookClient) GetAccountPages(
+	credentials string,
+	accessToken string,
+) ([]social_network_client.SocialNetworkPage, error) {
+	return social_network_client.GetAllAccountPages(w, credentials, accessToken)
+}
+
+// GetAccountPagesChunk страницы - получатели из credentials, запросов к ним не делается
+func (w *webhookClient) GetAccountPagesChunk(
+	credentials string,
+	accessToken string,
+	cursor social_network_client.PagesCursor,
+) (*social_network_client.SocialNetworkPagesChunk, error) {
+	webhookCredentials, err := w.stringToWebhookCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]social_network_client.SocialNetworkPage, 0, len(webhookCredentials.Endpoints))
+	for _, endpoint := range webhookCredentials.Endpoints {
+		pages = append(pages, social_network_client.SocialNetworkPage{
+			ID:          endpoint.ID,
+			Name:        endpoint.Name,
+			Description: endpoint.URL,
+		})
+	}
+
+	return &social_network_client.SocialNetworkPagesChunk{
+		Pages: pages,
+	}, nil
+}
+
+func (w *webhookClient) CreatePost(
+	ctx context.Context,
+	credentials string,
+	accessToken string,
+	endpointID string,
+	post social_network_client.Post,
+) (string, error) {
+	webhookCredentials, err := w.stringToWebhookCredentials(credentials)
+	if err != nil {
+		return "", err
+	}
+
+	result := w.createPost(ctx, webhookCredentials, endpointID, post)
+	return result.PostID, result.Err
+}
+
+// CreatePosts доставляет пост получателям по очереди, в результатах остаются все попытки доставки
+func (w *webhookClient) CreatePosts(
+	ctx context.Context,
+	credentials string,
+	accessToken string,
+	endpointsIDs []string,
+	post social_network_client.Post,
+) ([]social_network_client.PostResult, error) {
+	webhookCredentials, err := w.stringToWebhookCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]social_network_client.PostResult, 0, len(endpointsIDs))
+	for _, endpointID := range endpointsIDs {
+		results = append(results, w.createPost(ctx, webhookCredentials, endpointID, post))
+	}
+
+	return results, nil
+}
+
+func (w *webhookClient) PreparePost(
+	credentials string,
+	accessToken string,
+	endpointsIDs []string,
+	post social_network_client.Post,
+) ([]social_network_client.PreparedRequest, error) {
+	webhookCredentials, err := w.stringToWebhookCredentials(credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	requests := make([]social_network_client.PreparedRequest, 0, len(endpointsIDs))
+	for _, endpointID := range endpointsIDs {
+		delivery, err := newWebhookDelivery(webhookCredentials, endpointID, post)
+		if err != nil {
+			return nil, err
+		}
+		req, err := delivery.newSignedRequest(webhookCredentials.Secret, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		preparedRequest, err := social_network_client.NewPreparedRequest([]string{endpointID}, req)
+		if err != nil {
+			return nil, err
+		}
+		// В заголовках получателя обычно токены его API
+		for _, value := range delivery.endpoint.Headers {
+			if value != "" {
+				preparedRequest.Secrets = append(preparedRequest.Secrets, value)
+			}
+		}
+		requests = append(requests, preparedRequest)
+	}
+
+	return requests, nil
+}
+
+func (w *webhookClient) DeletePost(credentials, accessToken, postID string) error {
+	return tracerr.Errorf("webhooks do not support deleting posts")
+}
+
+func (w *webhookClient) Capabilities() social_network_client.Capabilities {
+	return capabilities
+}
+
+// createPost id поста - поле id из ответа получателя, если его нет - id доставки
+func (w *webhookClient) createPost(
+	ctx context.Context,
+	webhookCredentials *WebhookCredentials,
+	endpointID string,
+	post social_network_client.Post,
+) social_network_client.PostResult {
+	result := social_network_client.PostResult{
+		PageID: endpointID,
+	}
+
+	delivery, err := newWebhookDelivery(webhookCredentials, endpointID, post)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	respBody, attempts, err := w.deliver(ctx, webhookCredentials.Secret, delivery)
+	result.Attempts = attempts
+	if err != nil {
+		result.Err = tracerr.Errorf("cannot deliver webhook %s to %s:\n%s", delivery.id, endpointID, err)
+		return result
+	}
+
+	result.PostID = webhookResponsePostID(respBody)
+	if result.PostID == "" {
+		result.PostID = delivery.id
+	}
+	return result
+}
+
+// deliver отправляет доставку с повторами и возвращает тело успешного ответа вместе со всеми попытками.
+// Пауза между повторами прерывается отменой ctx
+func (w *webhookClient) deliver(
+	ctx context.Context,
+	secret string,
+	delivery *webhookDelivery,
+) ([]byte, []social_network_client.DeliveryAttempt, error) {
+	var attempts []social_network_client.DeliveryAttempt
+	var lastErr error
+	delay := webhookRetryDelay
+	for attempt := 1; attempt <= webhookMaxAttempts; attempt++ {
+		req, err := delivery.newSignedRequest(secret, time.Now())
+		if err != nil {
+			return nil, attempts, err
+		}
+
+		deliveryAttempt := social_network_client.DeliveryAttempt{
+			DeliveryID:  delivery.id,
+			Attempt:     attempt,
+			AttemptedAt: time.Now(),
+		}
+		statusCode, respBody, retryAfter, err := w.doRequest(req.WithContext(ctx))
+		deliveryAttempt.StatusCode = statusCode
+		deliveryAttempt.ResponseBody = recordedResponseBody(respBody)
+		deliveryAttempt.Err = err
+		attempts = append(attempts, deliveryAttempt)
+		if err == nil {
+			return respBody, attempts, nil
+		}
+		lastErr = tracerr.Errorf("attempt %d of %d:\n%s", attempt, webhookMaxAttempts, err)
+		if retryAfter < 0 || attempt == webhookMaxAttempts {
+			break
+		}
+
+		if retryAfter > delay {
+			delay = retryAfter
+		}
+		if delay > webhookMaxRetryDelay {
+			delay = webhookMaxRetryDelay
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, attempts, tracerr.Errorf("%s\nretry canceled:\n%s", lastErr, ctx.Err())
+		case <-timer.C:
+		}
+		delay *= 2
+	}
+
+	return nil, attempts, lastErr
+}
+
+// doRequest отрицательный retryAfter означает, что повтор не поможет.
+// Тело ответа возвращается и при ошибочном статусе, чтобы попало в попытку доставки
+func (w *webhookClient) doRequest(req *http.Request) (int, []byte, time.Duration, error) {
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, 0, tracerr.Errorf("cannot read response:\n%s", err)
+	}
+
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		return resp.StatusCode, respBody, 0, nil
+	}
+
+	err = tracerr.Errorf("response status is %d\nresponse:%s", resp.StatusCode, string(respBody))
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < http.StatusInternalServerError {
+		return resp.StatusCode, respBody, -1, err
+	}
+	retryAfter, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+
+	return resp.StatusCode, respBody, time.Duration(retryAfter) * time.Second, err
+}
+
+func recordedResponseBody(respBody []byte) string {
+	if len(respBody) > webhookMaxRecordedBody {
+		respBody = respBody[:webhookMaxRecordedBody]
+	}
+	return strings.ToValidUTF8(string(respBody), "")
+}
+
+// webhookResponsePostID id поста в ответе получателя может быть строкой или числом, ответ может быть не JSON
+func webhookResponsePostID(respBody []byte) string {
+	var data webhookResponse
+	decoder := json.NewDecoder(bytes.NewReader(respBody))
+	decoder.UseNumber()
+	if err := decoder.Decode(&data); err != nil {
+		return ""
+	}
+
+	switch id := data.ID.(type) {
+	case string:
+		return id
+	case json.Number:
+		return id.String()
+	default:
+		return ""
+	}
+}
+
+func newWebhookDelivery(
+	webhookCredentials *WebhookCredentials,
+	endpointID string,
+	post social_network_client.Post,
+) (*webhookDelivery, error) {
+	var endpoint *WebhookEndpoint
+	for i := range webhookCredentials.Endpoints {
+		if webhookCredentials.Endpoints[i].ID == endpointID {
+			endpoint = &webhookCredentials.Endpoints[i]
+			break
+		}
+	}
+	if endpoint == nil {
+		return nil, tracerr.Errorf("webhook credentials have no endpoint %s", endpointID)
+	}
+
+	deliveryID, err := newDeliveryID()
+	if err != nil {
+		return nil, err
+	}
+
+	template := endpoint.Template
+	if len(template) == 0 {
+		template = webhookDefaultTemplate
+	}
+	body, err := renderWebhookPayload(template, webhookTemplateValues(endpointID, deliveryID, time.Now(), post))
+	if err != nil {
+		return nil, tracerr.Errorf("cannot render payload for webhook endpoint %s:\n%s", endpointID, err)
+	}
+
+	return &webhookDelivery{
+		id:       deliveryID,
+		endpoint: *endpoint,
+		body:     body,
+	}, nil
+}
+
+// newSignedRequest id доставки не меняется между попытками, получатель отбрасывает по нему повторы
+func (d *webhookDelivery) newSignedRequest(secret string, now time.Time) (*http.Request, error) {
+	req, err := http.NewRequest("POST", d.endpoint.URL, bytes.NewReader(d.body))
+	if err != nil {
+		return nil, tracerr.Errorf("cannot create webhook request:\n%s", err)
+	}
+
+	for name, value := range d.endpoint.Headers {
+		req.Header.Set(name, value)
+	}
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookIDHeader, d.id)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhook(secret, timestamp, d.body))
+
+	return req, nil
+}
+
+// SignWebhook подпись тела доставки, получатель проверяет ее тем же секретом
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func newDeliveryID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", tracerr.Errorf("cannot generate webhook delivery id:\n%s", err)
+	}
+	return hex.EncodeToString(id), nil
+}
+
+func (w *webhookClient) stringToWebhookCredentials(credentials string) (*WebhookCredentials, error) {
+	webhookCredentials := &WebhookCredentials{}
+	err := json.Unmarshal([]byte(credentials), webhookCredentials)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot unmarshal webhook credentials:\n%s", err)
+	}
+	if webhookCredentials.Secret == "" {
+		return nil, tracerr.Errorf("webhook credentials have no secret")
+	}
+
+	seenIDs := make(map[string]bool, len(webhookCredentials.Endpoints))
+	for i, endpoint := range webhookCredentials.Endpoints {
+		if endpoint.ID == "" || seenIDs[endpoint.ID] {
+			return nil, tracerr.Errorf("webhook endpoint %d has empty or duplicate id %q", i, endpoint.ID)
+		}
+		seenIDs[endpoint.ID] = true
+
+		endpointURL, err := url.Parse(endpoint.URL)
+		if err != nil || (endpointURL.Scheme != "http" && endpointURL.Scheme != "https") || endpointURL.Host == "" {
+			return nil, tracerr.Errorf("webhook endpoint %s url %q is not valid", endpoint.ID, endpoint.URL)
+		}
+		if endpoint.Name == "" {
+			webhookCredentials.Endpoints[i].Name = fmt.Sprintf("%s%s", endpointURL.Host, strings.TrimSuffix(endpointURL.Path, "/"))
+		}
+	}
+
+	return webhookCredentials, nil
+}
+
+func NewWebhookClient(config social_network_client.ClientConfig) social_network_client.SocialNetworkClient {
+	return &webhookClient{
+		httpClient: &http.Client{Timeout: webhookTimeout},
+	}
+}
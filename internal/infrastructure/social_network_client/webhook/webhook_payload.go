@@ -0,0 +1,163 @@
+package webhook
+
+import (
+	"autoposting/internal/infrastructure/social_network_client"
+	"bytes"
+	"encoding/json"
+	"github.com/ztrue/tracerr"
+	"regexp"
+	"time"
+)
+
+// webhookPlaceholderRegexp плейсхолдер шаблона вида {{text}}
+var webhookPlaceholderRegexp = regexp.MustCompile(`\{\{\s*([a-zA-Z]+)\s*\}\}`)
+
+// webhookDefaultTemplate шаблон получателей, для которых шаблон не задан
+var webhookDefaultTemplate = json.RawMessage(`{
+	"id": "{{deliveryId}}",
+	"page": "{{pageId}}",
+	"text": "{{text}}",
+	"images": "{{images}}",
+	"imagesAlt": "{{imagesAlt}}",
+	"video": "{{video}}",
+	"link": "{{link}}",
+	"poll": "{{poll}}",
+	"contentWarning": "{{contentWarning}}",
+	"visibility": "{{visibility}}",
+	"publishedAt": "{{publishedAt}}"
+}`)
+
+type webhookPoll struct {
+	Question string   `json:"question"`
+	Answers  []string `json:"answers"`
+}
+
+// webhookTemplateValues значения плейсхолдеров для поста
+func webhookTemplateValues(
+	pageID string,
+	deliveryID string,
+	publishedAt time.Time,
+	post social_network_client.Post,
+) map[string]interface{} {
+	images := post.Images
+	if images == nil {
+		images = []string{}
+	}
+	imagesAlt := post.ImagesAlt
+	if imagesAlt == nil {
+		imagesAlt = []string{}
+	}
+	image := ""
+	if len(images) > 0 {
+		image = images[0]
+	}
+	var poll *webhookPoll
+	if post.Poll != nil {
+		poll = &webhookPoll{
+			Question: post.Poll.Question,
+			Answers:  post.Poll.Answers,
+		}
+	}
+
+	return map[string]interface{}{
+		"deliveryId":     deliveryID,
+		"pageId":         pageID,
+		"text":           post.Text,
+		"images":         images,
+		"imagesAlt":      imagesAlt,
+		"image":          image,
+		"video":          post.Video,
+		"link":           post.Link,
+		"poll":           poll,
+		"contentWarning": post.ContentWarning,
+		"visibility":     string(post.Visibility),
+		"publishedAt":    publishedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// renderWebhookPayload подставляет значения в JSON шаблон. Строка, которая целиком состоит из плейсхолдера,
+// заменяется значением с его JSON типом, плейсхолдеры внутри строки подставляются текстом
+func renderWebhookPayload(template json.RawMessage, values map[string]interface{}) ([]byte, error) {
+	var parsedTemplate interface{}
+	decoder := json.NewDecoder(bytes.NewReader(template))
+	decoder.UseNumber()
+	if err := decoder.Decode(&parsedTemplate); err != nil {
+		return nil, tracerr.Errorf("cannot parse webhook payload template:\n%s", err)
+	}
+
+	payload, err := renderWebhookValue(parsedTemplate, values)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, tracerr.Errorf("cannot marshal webhook payload:\n%s", err)
+	}
+
+	return body, nil
+}
+
+func renderWebhookValue(value interface{}, values map[string]interface{}) (interface{}, error) {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		rendered := make(map[string]interface{}, len(typedValue))
+		for key, item := range typedValue {
+			renderedItem, err := renderWebhookValue(item, values)
+			if err != nil {
+				return nil, err
+			}
+			rendered[key] = renderedItem
+		}
+		return rendered, nil
+	case []interface{}:
+		rendered := make([]interface{}, 0, len(typedValue))
+		for _, item := range typedValue {
+			renderedItem, err := renderWebhookValue(item, values)
+			if err != nil {
+				return nil, err
+			}
+			rendered = append(rendered, renderedItem)
+		}
+		return rendered, nil
+	case string:
+		return renderWebhookString(typedValue, values)
+	default:
+		return value, nil
+	}
+}
+
+func renderWebhookString(value string, values map[string]interface{}) (interface{}, error) {
+	if match := webhookPlaceholderRegexp.FindStringSubmatch(value); match != nil && match[0] == value {
+		placeholderValue, ok := values[match[1]]
+		if !ok {
+			return nil, tracerr.Errorf("unknown webhook template placeholder %s", match[0])
+		}
+		return placeholderValue, nil
+	}
+
+	var renderErr error
+	rendered := webhookPlaceholderRegexp.ReplaceAllStringFunc(value, func(placeholder string) string {
+		name := webhookPlaceholderRegexp.FindStringSubmatch(placeholder)[1]
+		placeholderValue, ok := values[name]
+		if !ok {
+			renderErr = tracerr.Errorf("unknown webhook template placeholder %s", placeholder)
+			return placeholder
+		}
+		if text, ok := placeholderValue.(string); ok {
+			return text
+		}
+		// Списки и объекты внутри строки подставляются своим JSON представлением
+		encoded, err := json.Marshal(placeholderValue)
+		if err != nil {
+			renderErr = tracerr.Errorf("cannot marshal webhook template placeholder %s:\n%s", placeholder, err)
+			return placeholder
+		}
+		return string(encoded)
+	})
+	if renderErr != nil {
+		return nil, renderErr
+	}
+
+	return rendered, nil
+}
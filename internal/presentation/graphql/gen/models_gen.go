@@ -2,6 +2,28 @@
 
 package gen
 
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+type AddWatchedPageOutput interface {
+	IsAddWatchedPageOutput()
+}
+
+type ComparePagesOutput interface {
+	IsComparePagesOutput()
+}
+
+type CreateCommentAutoReplyRuleOutput interface {
+	IsCreateCommentAutoReplyRuleOutput()
+}
+
+type CreateCommentModerationRuleOutput interface {
+	IsCreateCommentModerationRuleOutput()
+}
+
 type CreatePostOutput interface {
 	IsCreatePostOutput()
 }
@@ -14,20 +36,104 @@ type CreateSocialNetworkPageOutput interface {
 	IsCreateSocialNetworkPageOutput()
 }
 
+type DeleteCommentAutoReplyRuleOutput interface {
+	IsDeleteCommentAutoReplyRuleOutput()
+}
+
+type DeleteCommentModerationRuleOutput interface {
+	IsDeleteCommentModerationRuleOutput()
+}
+
+type DeleteCommentOutput interface {
+	IsDeleteCommentOutput()
+}
+
 type GetAccountAuthURLOutput interface {
 	IsGetAccountAuthURLOutput()
 }
 
+type GetAudienceGrowthOutput interface {
+	IsGetAudienceGrowthOutput()
+}
+
+type GetCommentAutoReplyRulesOutput interface {
+	IsGetCommentAutoReplyRulesOutput()
+}
+
+type GetCommentModerationLogOutput interface {
+	IsGetCommentModerationLogOutput()
+}
+
+type GetCommentModerationRulesOutput interface {
+	IsGetCommentModerationRulesOutput()
+}
+
+type GetCommentsOutput interface {
+	IsGetCommentsOutput()
+}
+
+type GetMetricsAggregatesOutput interface {
+	IsGetMetricsAggregatesOutput()
+}
+
 type GetPagesFromSocialNetworkOutput interface {
 	IsGetPagesFromSocialNetworkOutput()
 }
 
+type GetPostMetricsOutput interface {
+	IsGetPostMetricsOutput()
+}
+
+type GetPostsOutput interface {
+	IsGetPostsOutput()
+}
+
+type GetProjectSettingsOutput interface {
+	IsGetProjectSettingsOutput()
+}
+
+type GetScheduledPostsOutput interface {
+	IsGetScheduledPostsOutput()
+}
+
+type GetWebhookDeliveriesOutput interface {
+	IsGetWebhookDeliveriesOutput()
+}
+
+type ImportPageHistoryOutput interface {
+	IsImportPageHistoryOutput()
+}
+
+type RecommendedSlotsOutput interface {
+	IsRecommendedSlotsOutput()
+}
+
+type RemoveWatchedPageOutput interface {
+	IsRemoveWatchedPageOutput()
+}
+
+type ReplyToCommentOutput interface {
+	IsReplyToCommentOutput()
+}
+
+type RevertCommentModerationOutput interface {
+	IsRevertCommentModerationOutput()
+}
+
+type SchedulePostOutput interface {
+	IsSchedulePostOutput()
+}
+
 // Базовый интерфейс ошибок
 type ServiceErrorInterface interface {
 	IsServiceErrorInterface()
 	GetMessage() string
 }
 
+type SetProjectTimezoneOutput interface {
+	IsSetProjectTimezoneOutput()
+}
+
 // Ошибка доступа
 type AccessDeniedError struct {
 	Message string `json:"message"`
@@ -49,13 +155,179 @@ type AccessTokenInput struct {
 	ExpiresIn *string `json:"expiresIn,omitempty"`
 }
 
+type AddWatchedPageInput struct {
+	//  Проект, для которого отслеживается страница
+	Project string `json:"project"`
+	//  Название соц сети
+	SocialNetwork string `json:"socialNetwork"`
+	//  Идентификатор публичной страницы в соц сети
+	PageID string `json:"pageId"`
+	//  Название для отчетов
+	Name *string `json:"name,omitempty"`
+}
+
+type AddWatchedPageResult struct {
+	WatchedPageID int `json:"watchedPageId"`
+}
+
+func (AddWatchedPageResult) IsAddWatchedPageOutput() {}
+
+// Подписчики на конец дня
+type AudiencePoint struct {
+	//  День по UTC, YYYY-MM-DD
+	Date      string `json:"date"`
+	Followers int    `json:"followers"`
+}
+
+// Комментарий к посту страницы
+type Comment struct {
+	ID            int    `json:"id"`
+	PageID        int    `json:"pageId"`
+	PostID        int    `json:"postId"`
+	SocialNetwork string `json:"socialNetwork"`
+	//  Идентификатор комментария в соц сети
+	RemoteCommentID string `json:"remoteCommentId"`
+	//  Комментарий в соц сети, на который это ответ
+	ParentRemoteCommentID *string `json:"parentRemoteCommentId,omitempty"`
+	AuthorID              string  `json:"authorId"`
+	AuthorName            string  `json:"authorName"`
+	//  Комментарий оставлен от имени страницы
+	ByPage bool   `json:"byPage"`
+	Text   string `json:"text"`
+	//  RFC3339
+	CreatedAt string `json:"createdAt"`
+	//  Время первого ответа страницы, RFC3339
+	AnsweredAt *string `json:"answeredAt,omitempty"`
+	//  Время скрытия модерацией, RFC3339
+	HiddenAt *string `json:"hiddenAt,omitempty"`
+	//  Время удаления, RFC3339
+	DeletedAt *string `json:"deletedAt,omitempty"`
+}
+
+// Правило автоответа на комментарии к посту или ко всем постам страницы, правила постов срабатывают раньше правил страницы
+type CommentAutoReplyRule struct {
+	ID     int `json:"id"`
+	PageID int `json:"pageId"`
+	//  Пост, пустой для правила страницы
+	PostID *int `json:"postId,omitempty"`
+	//  Регулярные выражения без учета регистра, достаточно совпадения одного
+	Patterns []string `json:"patterns"`
+	//  Текст ответа, {author} заменяется именем автора комментария
+	Template string `json:"template"`
+	//  Пауза перед повторным автоответом тому же автору на странице
+	CooldownMinutes int `json:"cooldownMinutes"`
+	//  Не больше стольких автоответов на странице за сутки
+	DailyLimit int `json:"dailyLimit"`
+	//  RFC3339
+	CreatedAt string `json:"createdAt"`
+}
+
+// Действие модерации над комментарием
+type CommentModeration struct {
+	ID int `json:"id"`
+	//  Правило, пустое для удаленных правил
+	RuleID *int                    `json:"ruleId,omitempty"`
+	Action CommentModerationAction `json:"action"`
+	//  Что совпало с правилом
+	Reason string `json:"reason"`
+	//  RFC3339
+	CreatedAt string `json:"createdAt"`
+	//  Время отмены действия, RFC3339
+	RevertedAt *string  `json:"revertedAt,omitempty"`
+	Comment    *Comment `json:"comment,omitempty"`
+}
+
+// Правило модерации комментариев страниц проекта, срабатывает первое подходящее по возрастанию id
+type CommentModerationRule struct {
+	ID      int                       `json:"id"`
+	Project string                    `json:"project"`
+	Kind    CommentModerationRuleKind `json:"kind"`
+	//  Слова или выражения
+	Values              []string                `json:"values"`
+	RepeatLimit         int                     `json:"repeatLimit"`
+	RepeatWindowMinutes int                     `json:"repeatWindowMinutes"`
+	Action              CommentModerationAction `json:"action"`
+	//  DELETE разрешен и там, где удаление нельзя отменить
+	AllowIrreversible bool `json:"allowIrreversible"`
+	//  RFC3339
+	CreatedAt string `json:"createdAt"`
+}
+
+type ComparePagesInput struct {
+	Project string `json:"project"`
+	//  Начало периода, RFC3339
+	From *string `json:"from,omitempty"`
+	//  Конец периода, RFC3339
+	To *string `json:"to,omitempty"`
+}
+
+type ComparePagesResult struct {
+	//  Сначала страницы проекта, затем отслеживаемые
+	Pages []*PageComparison `json:"pages"`
+}
+
+func (ComparePagesResult) IsComparePagesOutput() {}
+
+type CreateCommentAutoReplyRuleInput struct {
+	//  Страница, можно не указывать для правила поста
+	PageID *int `json:"pageId,omitempty"`
+	//  Пост, по умолчанию правило действует на все посты страницы
+	PostID   *int     `json:"postId,omitempty"`
+	Patterns []string `json:"patterns"`
+	Template string   `json:"template"`
+	//  По умолчанию 60
+	CooldownMinutes *int `json:"cooldownMinutes,omitempty"`
+	//  По умолчанию 50
+	DailyLimit *int `json:"dailyLimit,omitempty"`
+}
+
+type CreateCommentAutoReplyRuleResult struct {
+	Rule *CommentAutoReplyRule `json:"rule"`
+}
+
+func (CreateCommentAutoReplyRuleResult) IsCreateCommentAutoReplyRuleOutput() {}
+
+type CreateCommentModerationRuleInput struct {
+	Project string                    `json:"project"`
+	Kind    CommentModerationRuleKind `json:"kind"`
+	//  Слова для KEYWORDS, выражения для REGEX
+	Values []string `json:"values,omitempty"`
+	//  Для REPEAT_POSTER
+	RepeatLimit *int `json:"repeatLimit,omitempty"`
+	//  Для REPEAT_POSTER
+	RepeatWindowMinutes *int                    `json:"repeatWindowMinutes,omitempty"`
+	Action              CommentModerationAction `json:"action"`
+	//  Разрешить DELETE в соц сетях, где удаленный комментарий нельзя восстановить, по умолчанию false
+	AllowIrreversible *bool `json:"allowIrreversible,omitempty"`
+}
+
+type CreateCommentModerationRuleResult struct {
+	Rule *CommentModerationRule `json:"rule"`
+}
+
+func (CreateCommentModerationRuleResult) IsCreateCommentModerationRuleOutput() {}
+
+// Запросы, которые были бы отправлены при публикации
+type CreatePostDryRunResult struct {
+	Requests []*PreparedRequest `json:"requests"`
+}
+
+func (CreatePostDryRunResult) IsCreatePostOutput() {}
+
 type CreatePostInput struct {
-	Page     int       `json:"page"`
+	//  Страницы, в которые публикуется пост
+	Pages    []int     `json:"pages"`
 	PostData *PostData `json:"postData"`
+	//  Собрать запросы к соц сетям без публикации
+	DryRun *bool `json:"dryRun,omitempty"`
 }
 
 type CreatePostResult struct {
-	Ok bool `json:"ok"`
+	//  Пост опубликован во все страницы
+	Ok      bool                 `json:"ok"`
+	Results []*PostPublishResult `json:"results"`
+	//  Ошибка после публикации, например посты не сохранились. Посты из results уже опубликованы
+	Error *string `json:"error,omitempty"`
 }
 
 func (CreatePostResult) IsCreatePostOutput() {}
@@ -63,7 +335,7 @@ func (CreatePostResult) IsCreatePostOutput() {}
 type CreateSocialNetworkAccountInput struct {
 	//  Название соц сети
 	SocialNetwork string `json:"socialNetwork"`
-	//  Информация с доступами, ключами и тп
+	//  Информация с доступами, ключами и тп, для соц сетей с authNetwork не используется
 	Credentials string `json:"credentials"`
 }
 
@@ -90,6 +362,36 @@ type CreateSocialNetworkPageResult struct {
 
 func (CreateSocialNetworkPageResult) IsCreateSocialNetworkPageOutput() {}
 
+type DeleteCommentAutoReplyRuleInput struct {
+	RuleID int `json:"ruleId"`
+}
+
+type DeleteCommentAutoReplyRuleResult struct {
+	Ok bool `json:"ok"`
+}
+
+func (DeleteCommentAutoReplyRuleResult) IsDeleteCommentAutoReplyRuleOutput() {}
+
+type DeleteCommentInput struct {
+	CommentID int `json:"commentId"`
+}
+
+type DeleteCommentModerationRuleInput struct {
+	RuleID int `json:"ruleId"`
+}
+
+type DeleteCommentModerationRuleResult struct {
+	Ok bool `json:"ok"`
+}
+
+func (DeleteCommentModerationRuleResult) IsDeleteCommentModerationRuleOutput() {}
+
+type DeleteCommentResult struct {
+	Ok bool `json:"ok"`
+}
+
+func (DeleteCommentResult) IsDeleteCommentOutput() {}
+
 type GetAccountAuthURLInput struct {
 	//  Соц сеть
 	SocialNetwork string `json:"socialNetwork"`
@@ -102,17 +404,213 @@ type GetAccountAuthURLResult struct {
 
 func (GetAccountAuthURLResult) IsGetAccountAuthURLOutput() {}
 
+type GetAudienceGrowthInput struct {
+	//  Страницы, по умолчанию все
+	Pages []int `json:"pages,omitempty"`
+	//  Проекты, по умолчанию все
+	Projects []string `json:"projects,omitempty"`
+	//  Начало периода, RFC3339
+	From *string `json:"from,omitempty"`
+	//  Конец периода, RFC3339
+	To *string `json:"to,omitempty"`
+}
+
+type GetAudienceGrowthResult struct {
+	Pages    []*PageAudienceGrowth    `json:"pages"`
+	Projects []*ProjectAudienceGrowth `json:"projects"`
+}
+
+func (GetAudienceGrowthResult) IsGetAudienceGrowthOutput() {}
+
+type GetCommentAutoReplyRulesInput struct {
+	//  Страницы, по умолчанию все
+	Pages []int `json:"pages,omitempty"`
+	//  Посты, по умолчанию все
+	Posts []int `json:"posts,omitempty"`
+}
+
+type GetCommentAutoReplyRulesResult struct {
+	Rules []*CommentAutoReplyRule `json:"rules"`
+}
+
+func (GetCommentAutoReplyRulesResult) IsGetCommentAutoReplyRulesOutput() {}
+
+type GetCommentModerationLogInput struct {
+	//  Проекты, по умолчанию все
+	Projects []string `json:"projects,omitempty"`
+	//  Размер порции, по умолчанию 50, не больше 200
+	Limit  *int `json:"limit,omitempty"`
+	Offset *int `json:"offset,omitempty"`
+}
+
+type GetCommentModerationLogResult struct {
+	//  Действия от новых к старым
+	Moderations []*CommentModeration `json:"moderations"`
+}
+
+func (GetCommentModerationLogResult) IsGetCommentModerationLogOutput() {}
+
+type GetCommentModerationRulesInput struct {
+	//  Проекты, по умолчанию все
+	Projects []string `json:"projects,omitempty"`
+}
+
+type GetCommentModerationRulesResult struct {
+	Rules []*CommentModerationRule `json:"rules"`
+}
+
+func (GetCommentModerationRulesResult) IsGetCommentModerationRulesOutput() {}
+
+type GetCommentsInput struct {
+	//  Проекты, по умолчанию все
+	Projects []string `json:"projects,omitempty"`
+	//  Соц сети, по умолчанию все
+	SocialNetworks []string `json:"socialNetworks,omitempty"`
+	//  Страницы, по умолчанию все
+	Pages []int `json:"pages,omitempty"`
+	//  Только комментарии подписчиков, на которые страница еще не ответила
+	Unanswered *bool `json:"unanswered,omitempty"`
+	//  Размер порции, по умолчанию 50, не больше 200
+	Limit  *int `json:"limit,omitempty"`
+	Offset *int `json:"offset,omitempty"`
+}
+
+type GetCommentsResult struct {
+	//  Комментарии от новых к старым, удаленные не возвращаются
+	Comments []*Comment `json:"comments"`
+}
+
+func (GetCommentsResult) IsGetCommentsOutput() {}
+
+type GetMetricsAggregatesInput struct {
+	GroupBy MetricsGroupBy `json:"groupBy"`
+	//  Страницы, по умолчанию все
+	Pages []int `json:"pages,omitempty"`
+	//  Проекты, по умолчанию все
+	Projects []string `json:"projects,omitempty"`
+	//  Соц сети, по умолчанию все
+	SocialNetworks []string `json:"socialNetworks,omitempty"`
+	//  Начало периода публикации постов, RFC3339
+	From *string `json:"from,omitempty"`
+	//  Конец периода публикации постов, RFC3339
+	To *string `json:"to,omitempty"`
+}
+
+type GetMetricsAggregatesResult struct {
+	Aggregates []*MetricsAggregate `json:"aggregates"`
+}
+
+func (GetMetricsAggregatesResult) IsGetMetricsAggregatesOutput() {}
+
 type GetPagesFromSocialNetworkInput struct {
 	//  Соц сеть
 	SocialNetwork string `json:"socialNetwork"`
+	//  Размер порции, без limit и cursor возвращаются все страницы
+	Limit *int `json:"limit,omitempty"`
+	//  Курсор порции из nextCursor предыдущего ответа
+	Cursor *string `json:"cursor,omitempty"`
 }
 
 type GetPagesFromSocialNetworkResult struct {
 	Pages []*SocialNetworkPage `json:"pages,omitempty"`
+	//  Курсор следующей порции, отсутствует на последней порции
+	NextCursor *string `json:"nextCursor,omitempty"`
 }
 
 func (GetPagesFromSocialNetworkResult) IsGetPagesFromSocialNetworkOutput() {}
 
+type GetPostMetricsInput struct {
+	PostID int `json:"postId"`
+	//  Начало периода сбора снимков, RFC3339
+	From *string `json:"from,omitempty"`
+	//  Конец периода сбора снимков, RFC3339
+	To *string `json:"to,omitempty"`
+}
+
+type GetPostMetricsResult struct {
+	PostID int `json:"postId"`
+	//  Снимки метрик от старых к новым
+	Metrics []*PostMetrics `json:"metrics"`
+}
+
+func (GetPostMetricsResult) IsGetPostMetricsOutput() {}
+
+type GetPostsInput struct {
+	//  Страницы постов, по умолчанию все
+	Pages []int `json:"pages,omitempty"`
+	//  Состояние поста в соц сети
+	RemoteState *PostRemoteState `json:"remoteState,omitempty"`
+	//  Размер порции, по умолчанию 50, не больше 200
+	Limit  *int `json:"limit,omitempty"`
+	Offset *int `json:"offset,omitempty"`
+}
+
+type GetPostsResult struct {
+	//  Посты от новых к старым
+	Posts []*Post `json:"posts"`
+}
+
+func (GetPostsResult) IsGetPostsOutput() {}
+
+type GetProjectSettingsInput struct {
+	Project string `json:"project"`
+}
+
+type GetProjectSettingsResult struct {
+	Settings *ProjectSettings `json:"settings"`
+}
+
+func (GetProjectSettingsResult) IsGetProjectSettingsOutput() {}
+
+type GetScheduledPostsInput struct {
+	//  Страницы, по умолчанию все
+	Pages  []int                `json:"pages,omitempty"`
+	Status *ScheduledPostStatus `json:"status,omitempty"`
+	//  Размер порции, по умолчанию 50, не больше 200
+	Limit  *int `json:"limit,omitempty"`
+	Offset *int `json:"offset,omitempty"`
+}
+
+type GetScheduledPostsResult struct {
+	//  Посты по времени публикации, ближайшие первыми
+	ScheduledPosts []*ScheduledPost `json:"scheduledPosts"`
+}
+
+func (GetScheduledPostsResult) IsGetScheduledPostsOutput() {}
+
+type GetWebhookDeliveriesInput struct {
+	//  Страницы получателей, по умолчанию все
+	Pages []int `json:"pages,omitempty"`
+	//  Доставки, по умолчанию все
+	DeliveryIds []string `json:"deliveryIds,omitempty"`
+	//  Размер порции, по умолчанию 50, не больше 200
+	Limit  *int `json:"limit,omitempty"`
+	Offset *int `json:"offset,omitempty"`
+}
+
+type GetWebhookDeliveriesResult struct {
+	//  Попытки от новых к старым
+	Deliveries []*WebhookDelivery `json:"deliveries"`
+}
+
+func (GetWebhookDeliveriesResult) IsGetWebhookDeliveriesOutput() {}
+
+type ImportPageHistoryInput struct {
+	//  Страница соц сети
+	PageID int `json:"pageId"`
+	//  Импортируются посты, опубликованные после этого момента, RFC3339
+	Since string `json:"since"`
+}
+
+// Импорт поставлен в очередь, посты сохраняются фоновой задачей
+type ImportPageHistoryResult struct {
+	//  Идентификатор задачи импорта
+	ImportID int                     `json:"importId"`
+	Status   PageHistoryImportStatus `json:"status"`
+}
+
+func (ImportPageHistoryResult) IsImportPageHistoryOutput() {}
+
 // Внутренняя ошибка
 type InternalError struct {
 	Message string `json:"message"`
@@ -127,10 +625,73 @@ func (InternalError) IsCreateSocialNetworkPageOutput() {}
 
 func (InternalError) IsCreatePostOutput() {}
 
+func (InternalError) IsImportPageHistoryOutput() {}
+
+func (InternalError) IsAddWatchedPageOutput() {}
+
+func (InternalError) IsRemoveWatchedPageOutput() {}
+
+func (InternalError) IsReplyToCommentOutput() {}
+
+func (InternalError) IsDeleteCommentOutput() {}
+
+func (InternalError) IsCreateCommentModerationRuleOutput() {}
+
+func (InternalError) IsDeleteCommentModerationRuleOutput() {}
+
+func (InternalError) IsRevertCommentModerationOutput() {}
+
+func (InternalError) IsCreateCommentAutoReplyRuleOutput() {}
+
+func (InternalError) IsDeleteCommentAutoReplyRuleOutput() {}
+
+func (InternalError) IsSetProjectTimezoneOutput() {}
+
+func (InternalError) IsSchedulePostOutput() {}
+
 func (InternalError) IsGetAccountAuthURLOutput() {}
 
 func (InternalError) IsGetPagesFromSocialNetworkOutput() {}
 
+func (InternalError) IsGetPostsOutput() {}
+
+func (InternalError) IsGetPostMetricsOutput() {}
+
+func (InternalError) IsGetMetricsAggregatesOutput() {}
+
+func (InternalError) IsGetAudienceGrowthOutput() {}
+
+func (InternalError) IsRecommendedSlotsOutput() {}
+
+func (InternalError) IsComparePagesOutput() {}
+
+func (InternalError) IsGetCommentsOutput() {}
+
+func (InternalError) IsGetCommentModerationRulesOutput() {}
+
+func (InternalError) IsGetCommentModerationLogOutput() {}
+
+func (InternalError) IsGetCommentAutoReplyRulesOutput() {}
+
+func (InternalError) IsGetProjectSettingsOutput() {}
+
+func (InternalError) IsGetWebhookDeliveriesOutput() {}
+
+func (InternalError) IsGetScheduledPostsOutput() {}
+
+// Сумма последних снимков метрик постов группы
+type MetricsAggregate struct {
+	//  Идентификатор страницы, проект или соц сеть
+	Key string `json:"key"`
+	//  Число постов с метриками
+	Posts    int `json:"posts"`
+	Likes    int `json:"likes"`
+	Reposts  int `json:"reposts"`
+	Comments int `json:"comments"`
+	Views    int `json:"views"`
+	Reach    int `json:"reach"`
+}
+
 // Страница соц сети уже существует
 type PageAlreadyExistsError struct {
 	Message string `json:"message"`
@@ -141,6 +702,36 @@ func (this PageAlreadyExistsError) GetMessage() string  { return this.Message }
 
 func (PageAlreadyExistsError) IsCreateSocialNetworkPageOutput() {}
 
+type PageAudienceGrowth struct {
+	PageID  int    `json:"pageId"`
+	Project string `json:"project"`
+	//  Дневной ряд от старых дней к новым
+	Points         []*AudiencePoint `json:"points"`
+	StartFollowers int              `json:"startFollowers"`
+	EndFollowers   int              `json:"endFollowers"`
+	Delta          int              `json:"delta"`
+}
+
+// Показатели страницы за период
+type PageComparison struct {
+	Kind PageComparisonKind `json:"kind"`
+	//  Идентификатор страницы проекта или отслеживаемой страницы
+	ID            int    `json:"id"`
+	SocialNetwork string `json:"socialNetwork"`
+	//  Идентификатор страницы в соц сети
+	PageID         string `json:"pageId"`
+	Name           string `json:"name"`
+	StartFollowers int    `json:"startFollowers"`
+	EndFollowers   int    `json:"endFollowers"`
+	FollowersDelta int    `json:"followersDelta"`
+	//  Число постов, опубликованных в периоде
+	Posts int `json:"posts"`
+	//  Сумма лайков, репостов и комментариев постов
+	Engagement int `json:"engagement"`
+	//  Средняя вовлеченность поста в процентах от подписчиков на конец периода
+	EngagementRate float64 `json:"engagementRate"`
+}
+
 type PageInfoInput struct {
 	//  Идентификатор страницы в соц сети
 	SocialNetworkID string `json:"socialNetworkId"`
@@ -152,9 +743,241 @@ type PageInfoInput struct {
 	PreviewImage *string `json:"previewImage,omitempty"`
 }
 
+type Poll struct {
+	Question string   `json:"question"`
+	Answers  []string `json:"answers"`
+}
+
+type PollInput struct {
+	Question string   `json:"question"`
+	Answers  []string `json:"answers"`
+}
+
+// Опубликованный пост
+type Post struct {
+	ID   int `json:"id"`
+	Page int `json:"page"`
+	//  Идентификатор поста в соц сети
+	RemotePostID *string            `json:"remotePostId,omitempty"`
+	PostData     *PublishedPostData `json:"postData"`
+	PublishedAt  string             `json:"publishedAt"`
+	//  Состояние поста в соц сети по последней сверке
+	RemoteState PostRemoteState `json:"remoteState"`
+	//  Текст поста в соц сети, если его изменили там
+	RemoteText *string `json:"remoteText,omitempty"`
+	//  Построчная разница опубликованного текста и remoteText
+	RemoteDiff *string `json:"remoteDiff,omitempty"`
+	//  Время последней сверки с соц сетью
+	ReconciledAt *string `json:"reconciledAt,omitempty"`
+	//  Пост опубликован до подключения страницы и загружен импортом истории
+	Imported bool `json:"imported"`
+}
+
 type PostData struct {
 	Text  string  `json:"text"`
 	Image *string `json:"image,omitempty"`
+	//  Изображения, дополняют image
+	Images []string `json:"images,omitempty"`
+	//  Описания изображений в порядке image, images
+	ImagesAlt []string   `json:"imagesAlt,omitempty"`
+	Video     *string    `json:"video,omitempty"`
+	Link      *string    `json:"link,omitempty"`
+	Poll      *PollInput `json:"poll,omitempty"`
+	//  Предупреждение, под которым скрывается текст, для соц сетей, которые это поддерживают
+	ContentWarning *string `json:"contentWarning,omitempty"`
+	//  Видимость поста, по умолчанию видимость аккаунта
+	Visibility *PostVisibility `json:"visibility,omitempty"`
+}
+
+// Снимок метрик поста
+type PostMetrics struct {
+	CollectedAt string `json:"collectedAt"`
+	Likes       int    `json:"likes"`
+	Reposts     int    `json:"reposts"`
+	Comments    int    `json:"comments"`
+	Views       int    `json:"views"`
+	//  Охват, 0 для соц сетей, которые его не отдают
+	Reach int `json:"reach"`
+}
+
+// Результат публикации в страницу
+type PostPublishResult struct {
+	Page          int    `json:"page"`
+	SocialNetwork string `json:"socialNetwork"`
+	//  Идентификатор поста в соц сети
+	PostID *string `json:"postId,omitempty"`
+	Error  *string `json:"error,omitempty"`
+}
+
+// Запрос к API соц сети, токены и ключи скрыты
+type PreparedRequest struct {
+	SocialNetwork string `json:"socialNetwork"`
+	//  Страницы, в которые публикует запрос
+	Pages   []int           `json:"pages"`
+	Method  string          `json:"method"`
+	URL     string          `json:"url"`
+	Params  []*RequestParam `json:"params"`
+	Headers []*RequestParam `json:"headers"`
+	//  Тело запроса, если это не форма
+	Body *string `json:"body,omitempty"`
+}
+
+// Сумма подписчиков страниц проекта
+type ProjectAudienceGrowth struct {
+	Project        string           `json:"project"`
+	Points         []*AudiencePoint `json:"points"`
+	StartFollowers int              `json:"startFollowers"`
+	EndFollowers   int              `json:"endFollowers"`
+	Delta          int              `json:"delta"`
+}
+
+// Настройки проекта, у проекта без сохраненных настроек часовой пояс UTC
+type ProjectSettings struct {
+	Project string `json:"project"`
+	//  Часовой пояс IANA, в котором считаются окна публикации
+	Timezone string `json:"timezone"`
+}
+
+// Содержимое опубликованного поста
+type PublishedPostData struct {
+	Text           string          `json:"text"`
+	Images         []string        `json:"images"`
+	ImagesAlt      []string        `json:"imagesAlt"`
+	Video          *string         `json:"video,omitempty"`
+	Link           *string         `json:"link,omitempty"`
+	Poll           *Poll           `json:"poll,omitempty"`
+	ContentWarning *string         `json:"contentWarning,omitempty"`
+	Visibility     *PostVisibility `json:"visibility,omitempty"`
+}
+
+// Часовое окно публикации с hour:00 до hour:59
+type RecommendedSlot struct {
+	Weekday Weekday `json:"weekday"`
+	Hour    int     `json:"hour"`
+	//  Число постов, опубликованных в окне
+	Posts int `json:"posts"`
+	//  Ожидаемая вовлеченность относительно средней по странице, 1 - средняя
+	Score float64 `json:"score"`
+	//  Уверенность от 0 до 1, растет с числом постов окна
+	Confidence float64 `json:"confidence"`
+	//  Ближайшее начало окна, RFC3339
+	NextStartsAt string `json:"nextStartsAt"`
+}
+
+type RecommendedSlotsInput struct {
+	PageID int `json:"pageId"`
+	//  Число окон, по умолчанию 5
+	Limit *int `json:"limit,omitempty"`
+}
+
+type RecommendedSlotsResult struct {
+	PageID int `json:"pageId"`
+	//  Часовой пояс проекта страницы, в котором считаются дни недели и часы
+	Timezone string `json:"timezone"`
+	//  Число постов с метриками за последние 90 дней
+	AnalyzedPosts int `json:"analyzedPosts"`
+	//  Окна от лучшего к худшему
+	Slots []*RecommendedSlot `json:"slots"`
+}
+
+func (RecommendedSlotsResult) IsRecommendedSlotsOutput() {}
+
+type RemoveWatchedPageInput struct {
+	WatchedPageID int `json:"watchedPageId"`
+}
+
+type RemoveWatchedPageResult struct {
+	Ok bool `json:"ok"`
+}
+
+func (RemoveWatchedPageResult) IsRemoveWatchedPageOutput() {}
+
+type ReplyToCommentInput struct {
+	CommentID int    `json:"commentId"`
+	Text      string `json:"text"`
+}
+
+type ReplyToCommentResult struct {
+	//  Ответ, опубликованный от имени страницы
+	Reply *Comment `json:"reply"`
+}
+
+func (ReplyToCommentResult) IsReplyToCommentOutput() {}
+
+type RequestParam struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type RevertCommentModerationInput struct {
+	ModerationID int `json:"moderationId"`
+}
+
+type RevertCommentModerationResult struct {
+	Moderation *CommentModeration `json:"moderation"`
+}
+
+func (RevertCommentModerationResult) IsRevertCommentModerationOutput() {}
+
+type SchedulePostInput struct {
+	//  Страницы, в которые публикуется пост
+	Pages    []int     `json:"pages"`
+	PostData *PostData `json:"postData"`
+	//  Время публикации, RFC3339, по умолчанию сейчас
+	PublishAt *string `json:"publishAt,omitempty"`
+	//  Сдвинуть публикацию к ближайшему после publishAt из лучших окон recommendedSlots страницы
+	Auto *bool `json:"auto,omitempty"`
+}
+
+type SchedulePostResult struct {
+	//  Публикации по одной на страницу, выполняет их фоновая задача
+	ScheduledPosts []*ScheduledPost `json:"scheduledPosts"`
+}
+
+func (SchedulePostResult) IsSchedulePostOutput() {}
+
+// Пост, запланированный к публикации в страницу
+type ScheduledPost struct {
+	ID       int                `json:"id"`
+	PageID   int                `json:"pageId"`
+	PostData *PublishedPostData `json:"postData"`
+	//  Запрошенное время публикации, RFC3339
+	RequestedAt string `json:"requestedAt"`
+	//  Время публикации, RFC3339. У auto постов сдвинуто к рекомендованному окну
+	PublishAt string `json:"publishAt"`
+	//  Время сдвинуто к рекомендованному окну страницы
+	Auto   bool                `json:"auto"`
+	Status ScheduledPostStatus `json:"status"`
+	//  Идентификатор поста в соц сети
+	RemotePostID *string `json:"remotePostId,omitempty"`
+	//  Причина неудачной публикации или ошибка после нее
+	Error *string `json:"error,omitempty"`
+	//  RFC3339
+	PublishedAt *string `json:"publishedAt,omitempty"`
+}
+
+type SetProjectTimezoneInput struct {
+	Project string `json:"project"`
+	//  Часовой пояс IANA, например Europe/Moscow
+	Timezone string `json:"timezone"`
+}
+
+type SetProjectTimezoneResult struct {
+	Settings *ProjectSettings `json:"settings"`
+}
+
+func (SetProjectTimezoneResult) IsSetProjectTimezoneOutput() {}
+
+// Подключенная соц сеть
+type SocialNetwork struct {
+	//  Идентификатор соц сети для аккаунтов и запросов
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	//  Аккаунт соц сети авторизуется через OAuth
+	RequiresOAuth bool `json:"requiresOAuth"`
+	//  Соц сеть, через аккаунт которой работает эта, например Instagram через Facebook
+	AuthNetwork  *string                    `json:"authNetwork,omitempty"`
+	Capabilities *SocialNetworkCapabilities `json:"capabilities"`
 }
 
 // Аккаунт в социальной сети
@@ -174,6 +997,18 @@ func (this SocialNetworkAccountAlreadyExistsError) GetMessage() string  { return
 
 func (SocialNetworkAccountAlreadyExistsError) IsCreateSocialNetworkAccountOutput() {}
 
+// Ограничения соц сети на пост, отсутствующий лимит означает отсутствие ограничения
+type SocialNetworkCapabilities struct {
+	MaxTextLength *int `json:"maxTextLength,omitempty"`
+	MaxImages     *int `json:"maxImages,omitempty"`
+	MinImages     *int `json:"minImages,omitempty"`
+	Video         bool `json:"video"`
+	Links         bool `json:"links"`
+	Polls         bool `json:"polls"`
+	//  Опрос нельзя публиковать вместе с изображениями или видео
+	PollExcludesMedia bool `json:"pollExcludesMedia"`
+}
+
 // Страница в соц сети
 type SocialNetworkPage struct {
 	Project     string                 `json:"project"`
@@ -192,6 +1027,10 @@ type SocialNetworkPageInfo struct {
 // Ошибка валидации
 type ValidationError struct {
 	Message string `json:"message"`
+	//  Поле, не прошедшее проверку
+	Field *string `json:"field,omitempty"`
+	//  Нарушенное правило
+	Rule *string `json:"rule,omitempty"`
 }
 
 func (ValidationError) IsServiceErrorInterface() {}
@@ -203,6 +1042,494 @@ func (ValidationError) IsCreateSocialNetworkPageOutput() {}
 
 func (ValidationError) IsCreatePostOutput() {}
 
+func (ValidationError) IsImportPageHistoryOutput() {}
+
+func (ValidationError) IsAddWatchedPageOutput() {}
+
+func (ValidationError) IsRemoveWatchedPageOutput() {}
+
+func (ValidationError) IsReplyToCommentOutput() {}
+
+func (ValidationError) IsDeleteCommentOutput() {}
+
+func (ValidationError) IsCreateCommentModerationRuleOutput() {}
+
+func (ValidationError) IsDeleteCommentModerationRuleOutput() {}
+
+func (ValidationError) IsRevertCommentModerationOutput() {}
+
+func (ValidationError) IsCreateCommentAutoReplyRuleOutput() {}
+
+func (ValidationError) IsDeleteCommentAutoReplyRuleOutput() {}
+
+func (ValidationError) IsSetProjectTimezoneOutput() {}
+
+func (ValidationError) IsSchedulePostOutput() {}
+
 func (ValidationError) IsGetAccountAuthURLOutput() {}
 
 func (ValidationError) IsGetPagesFromSocialNetworkOutput() {}
+
+func (ValidationError) IsGetPostsOutput() {}
+
+func (ValidationError) IsGetPostMetricsOutput() {}
+
+func (ValidationError) IsGetMetricsAggregatesOutput() {}
+
+func (ValidationError) IsGetAudienceGrowthOutput() {}
+
+func (ValidationError) IsRecommendedSlotsOutput() {}
+
+func (ValidationError) IsComparePagesOutput() {}
+
+func (ValidationError) IsGetCommentsOutput() {}
+
+func (ValidationError) IsGetCommentModerationRulesOutput() {}
+
+func (ValidationError) IsGetCommentModerationLogOutput() {}
+
+func (ValidationError) IsGetCommentAutoReplyRulesOutput() {}
+
+func (ValidationError) IsGetProjectSettingsOutput() {}
+
+func (ValidationError) IsGetWebhookDeliveriesOutput() {}
+
+func (ValidationError) IsGetScheduledPostsOutput() {}
+
+// Несколько ошибок валидации
+type ValidationErrors struct {
+	Message string             `json:"message"`
+	Errors  []*ValidationError `json:"errors"`
+}
+
+func (ValidationErrors) IsServiceErrorInterface() {}
+func (this ValidationErrors) GetMessage() string  { return this.Message }
+
+func (ValidationErrors) IsCreatePostOutput() {}
+
+func (ValidationErrors) IsSchedulePostOutput() {}
+
+// Попытка доставки поста получателю вебхука
+type WebhookDelivery struct {
+	ID     int `json:"id"`
+	PageID int `json:"pageId"`
+	//  Id доставки из заголовка X-Webhook-Id, одинаковый у всех попыток
+	DeliveryID string `json:"deliveryId"`
+	//  Номер попытки, начиная с 1
+	Attempt int `json:"attempt"`
+	//  Статус ответа, пустой если ответ не получен
+	StatusCode *int `json:"statusCode,omitempty"`
+	//  Начало тела ответа
+	ResponseBody string `json:"responseBody"`
+	//  Причина неудачи, пустая у успешной попытки
+	Error *string `json:"error,omitempty"`
+	//  RFC3339
+	AttemptedAt string `json:"attemptedAt"`
+}
+
+type CommentModerationAction string
+
+const (
+	CommentModerationActionHide   CommentModerationAction = "HIDE"
+	CommentModerationActionDelete CommentModerationAction = "DELETE"
+)
+
+var AllCommentModerationAction = []CommentModerationAction{
+	CommentModerationActionHide,
+	CommentModerationActionDelete,
+}
+
+func (e CommentModerationAction) IsValid() bool {
+	switch e {
+	case CommentModerationActionHide, CommentModerationActionDelete:
+		return true
+	}
+	return false
+}
+
+func (e CommentModerationAction) String() string {
+	return string(e)
+}
+
+func (e *CommentModerationAction) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CommentModerationAction(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CommentModerationAction", str)
+	}
+	return nil
+}
+
+func (e CommentModerationAction) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type CommentModerationRuleKind string
+
+const (
+	//  Комментарий содержит одно из слов без учета регистра
+	CommentModerationRuleKindKeywords CommentModerationRuleKind = "KEYWORDS"
+	//  Текст совпадает с одним из регулярных выражений
+	CommentModerationRuleKindRegex CommentModerationRuleKind = "REGEX"
+	//  В комментарии нет ничего, кроме ссылок
+	CommentModerationRuleKindLinkOnly CommentModerationRuleKind = "LINK_ONLY"
+	//  Автор оставил на странице больше repeatLimit комментариев за repeatWindowMinutes
+	CommentModerationRuleKindRepeatPoster CommentModerationRuleKind = "REPEAT_POSTER"
+)
+
+var AllCommentModerationRuleKind = []CommentModerationRuleKind{
+	CommentModerationRuleKindKeywords,
+	CommentModerationRuleKindRegex,
+	CommentModerationRuleKindLinkOnly,
+	CommentModerationRuleKindRepeatPoster,
+}
+
+func (e CommentModerationRuleKind) IsValid() bool {
+	switch e {
+	case CommentModerationRuleKindKeywords, CommentModerationRuleKindRegex, CommentModerationRuleKindLinkOnly, CommentModerationRuleKindRepeatPoster:
+		return true
+	}
+	return false
+}
+
+func (e CommentModerationRuleKind) String() string {
+	return string(e)
+}
+
+func (e *CommentModerationRuleKind) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CommentModerationRuleKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CommentModerationRuleKind", str)
+	}
+	return nil
+}
+
+func (e CommentModerationRuleKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type MetricsGroupBy string
+
+const (
+	MetricsGroupByPage          MetricsGroupBy = "PAGE"
+	MetricsGroupByProject       MetricsGroupBy = "PROJECT"
+	MetricsGroupBySocialNetwork MetricsGroupBy = "SOCIAL_NETWORK"
+)
+
+var AllMetricsGroupBy = []MetricsGroupBy{
+	MetricsGroupByPage,
+	MetricsGroupByProject,
+	MetricsGroupBySocialNetwork,
+}
+
+func (e MetricsGroupBy) IsValid() bool {
+	switch e {
+	case MetricsGroupByPage, MetricsGroupByProject, MetricsGroupBySocialNetwork:
+		return true
+	}
+	return false
+}
+
+func (e MetricsGroupBy) String() string {
+	return string(e)
+}
+
+func (e *MetricsGroupBy) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = MetricsGroupBy(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid MetricsGroupBy", str)
+	}
+	return nil
+}
+
+func (e MetricsGroupBy) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type PageComparisonKind string
+
+const (
+	//  Страница проекта
+	PageComparisonKindOwn PageComparisonKind = "OWN"
+	//  Отслеживаемая страница
+	PageComparisonKindWatched PageComparisonKind = "WATCHED"
+)
+
+var AllPageComparisonKind = []PageComparisonKind{
+	PageComparisonKindOwn,
+	PageComparisonKindWatched,
+}
+
+func (e PageComparisonKind) IsValid() bool {
+	switch e {
+	case PageComparisonKindOwn, PageComparisonKindWatched:
+		return true
+	}
+	return false
+}
+
+func (e PageComparisonKind) String() string {
+	return string(e)
+}
+
+func (e *PageComparisonKind) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PageComparisonKind(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PageComparisonKind", str)
+	}
+	return nil
+}
+
+func (e PageComparisonKind) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type PageHistoryImportStatus string
+
+const (
+	PageHistoryImportStatusPending PageHistoryImportStatus = "PENDING"
+	PageHistoryImportStatusRunning PageHistoryImportStatus = "RUNNING"
+	PageHistoryImportStatusDone    PageHistoryImportStatus = "DONE"
+	PageHistoryImportStatusFailed  PageHistoryImportStatus = "FAILED"
+)
+
+var AllPageHistoryImportStatus = []PageHistoryImportStatus{
+	PageHistoryImportStatusPending,
+	PageHistoryImportStatusRunning,
+	PageHistoryImportStatusDone,
+	PageHistoryImportStatusFailed,
+}
+
+func (e PageHistoryImportStatus) IsValid() bool {
+	switch e {
+	case PageHistoryImportStatusPending, PageHistoryImportStatusRunning, PageHistoryImportStatusDone, PageHistoryImportStatusFailed:
+		return true
+	}
+	return false
+}
+
+func (e PageHistoryImportStatus) String() string {
+	return string(e)
+}
+
+func (e *PageHistoryImportStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PageHistoryImportStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PageHistoryImportStatus", str)
+	}
+	return nil
+}
+
+func (e PageHistoryImportStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type PostRemoteState string
+
+const (
+	PostRemoteStatePublished PostRemoteState = "PUBLISHED"
+	//  Текст поста изменили в соц сети
+	PostRemoteStateModified PostRemoteState = "MODIFIED"
+	//  Пост удалили в соц сети
+	PostRemoteStateDeleted PostRemoteState = "DELETED"
+)
+
+var AllPostRemoteState = []PostRemoteState{
+	PostRemoteStatePublished,
+	PostRemoteStateModified,
+	PostRemoteStateDeleted,
+}
+
+func (e PostRemoteState) IsValid() bool {
+	switch e {
+	case PostRemoteStatePublished, PostRemoteStateModified, PostRemoteStateDeleted:
+		return true
+	}
+	return false
+}
+
+func (e PostRemoteState) String() string {
+	return string(e)
+}
+
+func (e *PostRemoteState) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PostRemoteState(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PostRemoteState", str)
+	}
+	return nil
+}
+
+func (e PostRemoteState) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type PostVisibility string
+
+const (
+	PostVisibilityPublic   PostVisibility = "PUBLIC"
+	PostVisibilityUnlisted PostVisibility = "UNLISTED"
+	PostVisibilityPrivate  PostVisibility = "PRIVATE"
+	PostVisibilityDirect   PostVisibility = "DIRECT"
+)
+
+var AllPostVisibility = []PostVisibility{
+	PostVisibilityPublic,
+	PostVisibilityUnlisted,
+	PostVisibilityPrivate,
+	PostVisibilityDirect,
+}
+
+func (e PostVisibility) IsValid() bool {
+	switch e {
+	case PostVisibilityPublic, PostVisibilityUnlisted, PostVisibilityPrivate, PostVisibilityDirect:
+		return true
+	}
+	return false
+}
+
+func (e PostVisibility) String() string {
+	return string(e)
+}
+
+func (e *PostVisibility) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PostVisibility(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PostVisibility", str)
+	}
+	return nil
+}
+
+func (e PostVisibility) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ScheduledPostStatus string
+
+const (
+	ScheduledPostStatusPending    ScheduledPostStatus = "PENDING"
+	ScheduledPostStatusPublishing ScheduledPostStatus = "PUBLISHING"
+	ScheduledPostStatusPublished  ScheduledPostStatus = "PUBLISHED"
+	ScheduledPostStatusFailed     ScheduledPostStatus = "FAILED"
+)
+
+var AllScheduledPostStatus = []ScheduledPostStatus{
+	ScheduledPostStatusPending,
+	ScheduledPostStatusPublishing,
+	ScheduledPostStatusPublished,
+	ScheduledPostStatusFailed,
+}
+
+func (e ScheduledPostStatus) IsValid() bool {
+	switch e {
+	case ScheduledPostStatusPending, ScheduledPostStatusPublishing, ScheduledPostStatusPublished, ScheduledPostStatusFailed:
+		return true
+	}
+	return false
+}
+
+func (e ScheduledPostStatus) String() string {
+	return string(e)
+}
+
+func (e *ScheduledPostStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ScheduledPostStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ScheduledPostStatus", str)
+	}
+	return nil
+}
+
+func (e ScheduledPostStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type Weekday string
+
+const (
+	WeekdayMonday    Weekday = "MONDAY"
+	WeekdayTuesday   Weekday = "TUESDAY"
+	WeekdayWednesday Weekday = "WEDNESDAY"
+	WeekdayThursday  Weekday = "THURSDAY"
+	WeekdayFriday    Weekday = "FRIDAY"
+	WeekdaySaturday  Weekday = "SATURDAY"
+	WeekdaySunday    Weekday = "SUNDAY"
+)
+
+var AllWeekday = []Weekday{
+	WeekdayMonday,
+	WeekdayTuesday,
+	WeekdayWednesday,
+	WeekdayThursday,
+	WeekdayFriday,
+	WeekdaySaturday,
+	WeekdaySunday,
+}
+
+func (e Weekday) IsValid() bool {
+	switch e {
+	case WeekdayMonday, WeekdayTuesday, WeekdayWednesday, WeekdayThursday, WeekdayFriday, WeekdaySaturday, WeekdaySunday:
+		return true
+	}
+	return false
+}
+
+func (e Weekday) String() string {
+	return string(e)
+}
+
+func (e *Weekday) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Weekday(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Weekday", str)
+	}
+	return nil
+}
+
+func (e Weekday) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
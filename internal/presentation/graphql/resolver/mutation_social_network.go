@@ -49,3 +49,183 @@ func (r *mutationResolver) CreatePost(
 
 	return out, nil
 }
+
+func (r *mutationResolver) SchedulePost(
+	ctx context.Context,
+	input gen.SchedulePostInput,
+) (gen.SchedulePostOutput, error) {
+	out, err := r.usecase.SocialNetwork.SchedulePost(ctx, input)
+	if err != nil {
+		return nil, NewResolverError(
+			"Не удалось запланировать пост",
+			err,
+		)
+	}
+
+	return out, nil
+}
+
+func (r *mutationResolver) ImportPageHistory(
+	ctx context.Context,
+	input gen.ImportPageHistoryInput,
+) (gen.ImportPageHistoryOutput, error) {
+	out, err := r.usecase.SocialNetwork.ImportPageHistory(ctx, input)
+	if err != nil {
+		return nil, NewResolverError(
+			"Не удалось запустить импорт истории страницы",
+			err,
+		)
+	}
+
+	return out, nil
+}
+
+func (r *mutationResolver) AddWatchedPage(
+	ctx context.Context,
+	input gen.AddWatchedPageInput,
+) (gen.AddWatchedPageOutput, error) {
+	out, err := r.usecase.SocialNetwork.AddWatchedPage(ctx, input)
+	if err != nil {
+		return nil, NewResolverError(
+			"Не удалось добавить отслеживаемую страницу",
+			err,
+		)
+	}
+
+	return out, nil
+}
+
+func (r *mutationResolver) RemoveWatchedPage(
+	ctx context.Context,
+	input gen.RemoveWatchedPageInput,
+) (gen.RemoveWatchedPageOutput, error) {
+	out, err := r.usecase.SocialNetwork.RemoveWatchedPage(ctx, input)
+	if err != nil {
+		return nil, NewResolverError(
+			"Не удалось удалить отслеживаемую страницу",
+			err,
+		)
+	}
+
+	return out, nil
+}
+
+func (r *mutationResolver) ReplyToComment(
+	ctx context.Context,
+	input gen.ReplyToCommentInput,
+) (gen.ReplyToCommentOutput, error) {
+	out, err := r.usecase.SocialNetwork.ReplyToComment(ctx, input)
+	if err != nil {
+		return nil, NewResolverError(
+			"Не удалось ответить на комментарий",
+			err,
+		)
+	}
+
+	return out, nil
+}
+
+func (r *mutationResolver) DeleteComment(
+	ctx context.Context,
+	input gen.DeleteCommentInput,
+) (gen.DeleteCommentOutput, error) {
+	out, err := r.usecase.SocialNetwork.DeleteComment(ctx, input)
+	if err != nil {
+		return nil, NewResolverError(
+			"Не удалось удалить комментарий",
+			err,
+		)
+	}
+
+	return out, nil
+}
+
+func (r *mutationResolver) CreateCommentModerationRule(
+	ctx context.Context,
+	input gen.CreateCommentModerationRuleInput,
+) (gen.CreateCommentModerationRuleOutput, error) {
+	out, err := r.usecase.SocialNetwork.CreateCommentModerationRule(ctx, input)
+	if err != nil {
+		return nil, NewResolverError(
+			"Не удалось создать правило модерации",
+			err,
+		)
+	}
+
+	return out, nil
+}
+
+func (r *mutationResolver) DeleteCommentModerationRule(
+	ctx context.Context,
+	input gen.DeleteCommentModerationRuleInput,
+) (gen.DeleteCommentModerationRuleOutput, error) {
+	out, err := r.usecase.SocialNetwork.DeleteCommentModerationRule(ctx, input)
+	if err != nil {
+		return nil, NewResolverError(
+			"Не удалось удалить правило модерации",
+			err,
+		)
+	}
+
+	return out, nil
+}
+
+func (r *mutationResolver) RevertCommentModeration(
+	ctx context.Context,
+	input gen.RevertCommentModerationInput,
+) (gen.RevertCommentModerationOutput, error) {
+	out, err := r.usecase.SocialNetwork.RevertCommentModeration(ctx, input)
+	if err != nil {
+		return nil, NewResolverError(
+			"Не удалось отменить действие модерации",
+			err,
+		)
+	}
+
+	return out, nil
+}
+
+func (r *mutationResolver) CreateCommentAutoReplyRule(
+	ctx context.Context,
+	input gen.CreateCommentAutoReplyRuleInput,
+) (gen.CreateCommentAutoReplyRuleOutput, error) {
+	out, err := r.usecase.SocialNetwork.CreateCommentAutoReplyRule(ctx, input)
+	if err != nil {
+		return nil, NewResolverError(
+			"Не удалось создать правило автоответа",
+			err,
+		)
+	}
+
+	return out, nil
+}
+
+func (r *mutationResolver) DeleteCommentAutoReplyRule(
+	ctx context.Context,
+	input gen.DeleteCommentAutoReplyRuleInput,
+) (gen.DeleteCommentAutoReplyRuleOutput, error) {
+	out, err := r.usecase.SocialNetwork.DeleteCommentAutoReplyRule(ctx, input)
+	if err != nil {
+		return nil, NewResolverError(
+			"Не удалось удалить правило автоответа",
+			err,
+		)
+	}
+
+	return out, nil
+}
+
+func (r *mutationResolver) SetProjectTimezone(
+	ctx context.Context,
+	input gen.SetProjectTimezoneInput,
+) (gen.SetProjectTimezoneOutput, error) {
+	out, err := r.usecase.SocialNetwork.SetProjectTimezone(ctx, input)
+	if err != nil {
+		return nil, NewResolverError(
+			"Не удалось задать часовой пояс проекта",
+			err,
+		)
+	}
+
+	return out, nil
+}
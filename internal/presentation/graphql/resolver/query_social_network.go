@@ -6,6 +6,19 @@ import (
 	"fmt"
 )
 
+func (r *queryResolver) GetSocialNetworks(
+	ctx context.Context,
+) ([]*gen.SocialNetwork, error) {
+	out, err := r.usecase.SocialNetwork.GetSocialNetworks(ctx)
+	if err != nil {
+		return nil, NewResolverError(
+			"Cannot get social networks",
+			err,
+		)
+	}
+	return out, nil
+}
+
 func (r *queryResolver) GetAccountAuthURL(
 	ctx context.Context,
 	input gen.GetAccountAuthURLInput,
@@ -33,3 +46,187 @@ func (r *queryResolver) GetPagesFromSocialNetwork(
 	}
 	return out, nil
 }
+
+func (r *queryResolver) GetPosts(
+	ctx context.Context,
+	input gen.GetPostsInput,
+) (gen.GetPostsOutput, error) {
+	out, err := r.usecase.SocialNetwork.GetPosts(ctx, input)
+	if err != nil {
+		return nil, NewResolverError(
+			"Cannot get posts",
+			err,
+		)
+	}
+	return out, nil
+}
+
+func (r *queryResolver) GetPostMetrics(
+	ctx context.Context,
+	input gen.GetPostMetricsInput,
+) (gen.GetPostMetricsOutput, error) {
+	out, err := r.usecase.SocialNetwork.GetPostMetrics(ctx, input)
+	if err != nil {
+		return nil, NewResolverError(
+			fmt.Sprintf("Cannot get post %d metrics", input.PostID),
+			err,
+		)
+	}
+	return out, nil
+}
+
+func (r *queryResolver) GetMetricsAggregates(
+	ctx context.Context,
+	input gen.GetMetricsAggregatesInput,
+) (gen.GetMetricsAggregatesOutput, error) {
+	out, err := r.usecase.SocialNetwork.GetMetricsAggregates(ctx, input)
+	if err != nil {
+		return nil, NewResolverError(
+			"Cannot get metrics aggregates",
+			err,
+		)
+	}
+	return out, nil
+}
+
+func (r *queryResolver) GetAudienceGrowth(
+	ctx context.Context,
+	input gen.GetAudienceGrowthInput,
+) (gen.GetAudienceGrowthOutput, error) {
+	out, err := r.usecase.SocialNetwork.GetAudienceGrowth(ctx, input)
+	if err != nil {
+		return nil, NewResolverError(
+			"Cannot get audience growth",
+			err,
+		)
+	}
+	return out, nil
+}
+
+func (r *queryResolver) RecommendedSlots(
+	ctx context.Context,
+	input gen.RecommendedSlotsInput,
+) (gen.RecommendedSlotsOutput, error) {
+	out, err := r.usecase.SocialNetwork.RecommendedSlots(ctx, input)
+	if err != nil {
+		return nil, NewResolverError(
+			fmt.Sprintf("Cannot get page %d recommended slots", input.PageID),
+			err,
+		)
+	}
+	return out, nil
+}
+
+func (r *queryResolver) ComparePages(
+	ctx context.Context,
+	input gen.ComparePagesInput,
+) (gen.ComparePagesOutput, error) {
+	out, err := r.usecase.SocialNetwork.ComparePages(ctx, input)
+	if err != nil {
+		return nil, NewResolverError(
+			fmt.Sprintf("Cannot compare project %s pages", input.Project),
+			err,
+		)
+	}
+	return out, nil
+}
+
+func (r *queryResolver) GetComments(
+	ctx context.Context,
+	input gen.GetCommentsInput,
+) (gen.GetCommentsOutput, error) {
+	out, err := r.usecase.SocialNetwork.GetComments(ctx, input)
+	if err != nil {
+		return nil, NewResolverError(
+			"Cannot get comments",
+			err,
+		)
+	}
+	return out, nil
+}
+
+func (r *queryResolver) GetCommentModerationRules(
+	ctx context.Context,
+	input gen.GetCommentModerationRulesInput,
+) (gen.GetCommentModerationRulesOutput, error) {
+	out, err := r.usecase.SocialNetwork.GetCommentModerationRules(ctx, input)
+	if err != nil {
+		return nil, NewResolverError(
+			"Cannot get comment moderation rules",
+			err,
+		)
+	}
+	return out, nil
+}
+
+func (r *queryResolver) GetCommentModerationLog(
+	ctx context.Context,
+	input gen.GetCommentModerationLogInput,
+) (gen.GetCommentModerationLogOutput, error) {
+	out, err := r.usecase.SocialNetwork.GetCommentModerationLog(ctx, input)
+	if err != nil {
+		return nil, NewResolverError(
+			"Cannot get comment moderation log",
+			err,
+		)
+	}
+	return out, nil
+}
+
+func (r *queryResolver) GetCommentAutoReplyRules(
+	ctx context.Context,
+	input gen.GetCommentAutoReplyRulesInput,
+) (gen.GetCommentAutoReplyRulesOutput, error) {
+	out, err := r.usecase.SocialNetwork.GetCommentAutoReplyRules(ctx, input)
+	if err != nil {
+		return nil, NewResolverError(
+			"Cannot get comment auto reply rules",
+			err,
+		)
+	}
+	return out, nil
+}
+
+func (r *queryResolver) GetProjectSettings(
+	ctx context.Context,
+	input gen.GetProjectSettingsInput,
+) (gen.GetProjectSettingsOutput, error) {
+	out, err := r.usecase.SocialNetwork.GetProjectSettings(ctx, input)
+	if err != nil {
+		return nil, NewResolverError(
+			"Cannot get project settings",
+			err,
+		)
+	}
+
+	return out, nil
+}
+
+func (r *queryResolver) GetWebhookDeliveries(
+	ctx context.Context,
+	input gen.GetWebhookDeliveriesInput,
+) (gen.GetWebhookDeliveriesOutput, error) {
+	out, err := r.usecase.SocialNetwork.GetWebhookDeliveries(ctx, input)
+	if err != nil {
+		return nil, NewResolverError(
+			"Cannot get webhook deliveries",
+			err,
+		)
+	}
+	return out, nil
+}
+
+func (r *queryResolver) GetScheduledPosts(
+	ctx context.Context,
+	input gen.GetScheduledPostsInput,
+) (gen.GetScheduledPostsOutput, error) {
+	out, err := r.usecase.SocialNetwork.GetScheduledPosts(ctx, input)
+	if err != nil {
+		return nil, NewResolverError(
+			"Cannot get scheduled posts",
+			err,
+		)
+	}
+
+	return out, nil
+}
@@ -3,7 +3,9 @@ CREATE TABLE public.social_network_accounts (
     "social_network" text NOT NULL,
     "credentials" jsonb NOT NULL,
     "access_token" jsonb NULL,
-    CONSTRAINT social_network_accounts_pk PRIMARY KEY ("id")
+    "auth_account" int4 NULL,
+    CONSTRAINT social_network_accounts_pk PRIMARY KEY ("id"),
+    CONSTRAINT accounts_auth_account_fk FOREIGN KEY ("auth_account") REFERENCES public.social_network_accounts("id") ON DELETE CASCADE
 );
 
 CREATE TABLE public.social_network_pages (
@@ -16,14 +18,256 @@ CREATE TABLE public.social_network_pages (
     CONSTRAINT social_network_page_pk PRIMARY KEY ("id"),
     CONSTRAINT pages_fk FOREIGN KEY ("account_id") REFERENCES public.social_network_accounts("id"),
     CONSTRAINT "SOCIAL_NETWORK_PAGES_UNIQUE" UNIQUE ("account_id", "page_id")
-)
+);
 
 CREATE TABLE public.posts (
     "id" int8 NOT NULL GENERATED BY DEFAULT AS identity,
     "page" int4 NOT NULL,
+    "remote_post_id" text NULL,
     "post_data" jsonb NOT NULL,
     "published_at" timestamptz NOT NULL,
+    "remote_state" text NOT NULL DEFAULT 'PUBLISHED',
+    "remote_text" text NULL,
+    "remote_diff" text NULL,
+    "reconciled_at" timestamptz NULL,
+    "imported" bool NOT NULL DEFAULT false,
     CONSTRAINT posts_pk PRIMARY KEY ("id"),
     CONSTRAINT posts_fk FOREIGN KEY ("page") REFERENCES public.social_network_pages("id")
-)
+);
+
+CREATE INDEX posts_published_at_idx ON public.posts ("published_at");
+-- Сообщения Slack публикуются без идентификатора
+CREATE UNIQUE INDEX posts_page_remote_post_id_idx ON public.posts ("page", "remote_post_id") WHERE "remote_post_id" <> '';
+
+CREATE TABLE public.post_metrics (
+    "id" int8 NOT NULL GENERATED BY DEFAULT AS identity,
+    "post" int8 NOT NULL,
+    "collected_at" timestamptz NOT NULL,
+    "likes" int4 NOT NULL,
+    "reposts" int4 NOT NULL,
+    "comments" int4 NOT NULL,
+    "views" int4 NOT NULL,
+    "reach" int4 NOT NULL,
+    CONSTRAINT post_metrics_pk PRIMARY KEY ("id"),
+    CONSTRAINT post_metrics_fk FOREIGN KEY ("post") REFERENCES public.posts("id")
+);
+
+CREATE INDEX post_metrics_post_idx ON public.post_metrics ("post", "collected_at");
+
+CREATE TABLE public.page_audience (
+    "id" int8 NOT NULL GENERATED BY DEFAULT AS identity,
+    "page" int4 NOT NULL,
+    "collected_at" timestamptz NOT NULL,
+    "followers" int4 NOT NULL,
+    CONSTRAINT page_audience_pk PRIMARY KEY ("id"),
+    CONSTRAINT page_audience_fk FOREIGN KEY ("page") REFERENCES public.social_network_pages("id")
+);
+
+CREATE INDEX page_audience_page_idx ON public.page_audience ("page", "collected_at");
+
+CREATE TABLE public.watched_pages (
+    "id" int4 NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+    "project" text NOT NULL,
+    "social_network" text NOT NULL,
+    "page_id" text NOT NULL,
+    "name" text NOT NULL,
+    "created_at" timestamptz NOT NULL,
+    CONSTRAINT watched_pages_pk PRIMARY KEY ("id"),
+    CONSTRAINT "WATCHED_PAGES_UNIQUE" UNIQUE ("project", "social_network", "page_id")
+);
+
+CREATE TABLE public.watched_page_audience (
+    "id" int8 NOT NULL GENERATED BY DEFAULT AS identity,
+    "watched_page" int4 NOT NULL,
+    "collected_at" timestamptz NOT NULL,
+    "followers" int4 NOT NULL,
+    CONSTRAINT watched_page_audience_pk PRIMARY KEY ("id"),
+    CONSTRAINT watched_page_audience_fk FOREIGN KEY ("watched_page") REFERENCES public.watched_pages("id") ON DELETE CASCADE
+);
+
+CREATE INDEX watched_page_audience_page_idx ON public.watched_page_audience ("watched_page", "collected_at");
+
+CREATE TABLE public.watched_page_posts (
+    "id" int8 NOT NULL GENERATED BY DEFAULT AS identity,
+    "watched_page" int4 NOT NULL,
+    "remote_post_id" text NOT NULL,
+    "published_at" timestamptz NOT NULL,
+    "likes" int4 NOT NULL,
+    "reposts" int4 NOT NULL,
+    "comments" int4 NOT NULL,
+    "views" int4 NOT NULL,
+    "collected_at" timestamptz NOT NULL,
+    CONSTRAINT watched_page_posts_pk PRIMARY KEY ("id"),
+    CONSTRAINT watched_page_posts_fk FOREIGN KEY ("watched_page") REFERENCES public.watched_pages("id") ON DELETE CASCADE,
+    CONSTRAINT "WATCHED_PAGE_POSTS_UNIQUE" UNIQUE ("watched_page", "remote_post_id")
+);
+
+CREATE TABLE public.comments (
+    "id" int8 NOT NULL GENERATED BY DEFAULT AS identity,
+    "page" int4 NOT NULL,
+    "post" int8 NOT NULL,
+    "social_network" text NOT NULL,
+    "remote_post_id" text NOT NULL,
+    "remote_comment_id" text NOT NULL,
+    "parent_remote_comment_id" text NULL,
+    "author_id" text NOT NULL,
+    "author_name" text NOT NULL,
+    "by_page" bool NOT NULL,
+    "text" text NOT NULL,
+    "created_at" timestamptz NOT NULL,
+    "answered_at" timestamptz NULL,
+    "deleted_at" timestamptz NULL,
+    "hidden_at" timestamptz NULL,
+    "processed_at" timestamptz NULL,
+    "collected_at" timestamptz NOT NULL,
+    CONSTRAINT comments_pk PRIMARY KEY ("id"),
+    CONSTRAINT comments_page_fk FOREIGN KEY ("page") REFERENCES public.social_network_pages("id"),
+    CONSTRAINT comments_post_fk FOREIGN KEY ("post") REFERENCES public.posts("id"),
+    CONSTRAINT "COMMENTS_UNIQUE" UNIQUE ("page", "remote_comment_id")
+);
+
+CREATE INDEX comments_created_at_idx ON public.comments ("created_at");
+CREATE INDEX comments_post_idx ON public.comments ("post");
+CREATE INDEX comments_author_idx ON public.comments ("page", "author_id", "created_at");
+CREATE INDEX comments_unprocessed_idx ON public.comments ("id") WHERE "processed_at" IS NULL;
+
+CREATE TABLE public.comment_moderation_rules (
+    "id" int4 NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+    "project" text NOT NULL,
+    "kind" text NOT NULL,
+    "values" text[] NOT NULL DEFAULT '{}',
+    "repeat_limit" int4 NOT NULL DEFAULT 0,
+    "repeat_window_minutes" int4 NOT NULL DEFAULT 0,
+    "action" text NOT NULL,
+    "allow_irreversible" bool NOT NULL DEFAULT false,
+    "created_at" timestamptz NOT NULL,
+    CONSTRAINT comment_moderation_rules_pk PRIMARY KEY ("id")
+);
+
+CREATE INDEX comment_moderation_rules_project_idx ON public.comment_moderation_rules ("project");
+
+CREATE TABLE public.comment_moderation_log (
+    "id" int8 NOT NULL GENERATED BY DEFAULT AS identity,
+    "comment" int8 NOT NULL,
+    "rule" int4 NULL,
+    "action" text NOT NULL,
+    "reason" text NOT NULL,
+    "created_at" timestamptz NOT NULL,
+    "reverted_at" timestamptz NULL,
+    CONSTRAINT comment_moderation_log_pk PRIMARY KEY ("id"),
+    CONSTRAINT comment_moderation_log_comment_fk FOREIGN KEY ("comment") REFERENCES public.comments("id"),
+    CONSTRAINT comment_moderation_log_rule_fk FOREIGN KEY ("rule") REFERENCES public.comment_moderation_rules("id") ON DELETE SET NULL
+);
+
+CREATE INDEX comment_moderation_log_comment_idx ON public.comment_moderation_log ("comment");
+
+CREATE TABLE public.comment_auto_reply_rules (
+    "id" int4 NOT NULL GENERATED BY DEFAULT AS IDENTITY,
+    "page" int4 NOT NULL,
+    "post" int8 NULL,
+    "patterns" text[] NOT NULL,
+    "template" text NOT NULL,
+    "cooldown_minutes" int4 NOT NULL,
+    "daily_limit" int4 NOT NULL,
+    "created_at" timestamptz NOT NULL,
+    CONSTRAINT comment_auto_reply_rules_pk PRIMARY KEY ("id"),
+    CONSTRAINT comment_auto_reply_rules_page_fk FOREIGN KEY ("page") REFERENCES public.social_network_pages("id"),
+    CONSTRAINT comment_auto_reply_rules_post_fk FOREIGN KEY ("post") REFERENCES public.posts("id")
+);
+
+CREATE INDEX comment_auto_reply_rules_page_idx ON public.comment_auto_reply_rules ("page");
+
+CREATE TABLE public.comment_auto_replies (
+    "id" int8 NOT NULL GENERATED BY DEFAULT AS identity,
+    "rule" int4 NULL,
+    "page" int4 NOT NULL,
+    "comment" int8 NOT NULL,
+    "author_id" text NOT NULL,
+    "reply_remote_comment_id" text NOT NULL,
+    "created_at" timestamptz NOT NULL,
+    CONSTRAINT comment_auto_replies_pk PRIMARY KEY ("id"),
+    CONSTRAINT comment_auto_replies_rule_fk FOREIGN KEY ("rule") REFERENCES public.comment_auto_reply_rules("id") ON DELETE SET NULL,
+    CONSTRAINT comment_auto_replies_page_fk FOREIGN KEY ("page") REFERENCES public.social_network_pages("id"),
+    CONSTRAINT comment_auto_replies_comment_fk FOREIGN KEY ("comment") REFERENCES public.comments("id")
+);
+
+CREATE INDEX comment_auto_replies_page_idx ON public.comment_auto_replies ("page", "created_at");
+CREATE INDEX comment_auto_replies_author_idx ON public.comment_auto_replies ("page", "author_id", "created_at");
+
+CREATE TABLE public.page_history_imports (
+    "id" int8 NOT NULL GENERATED BY DEFAULT AS identity,
+    "page" int4 NOT NULL,
+    "since" timestamptz NOT NULL,
+    "status" text NOT NULL,
+    "fetched" int4 NOT NULL DEFAULT 0,
+    "imported" int4 NOT NULL DEFAULT 0,
+    "error" text NULL,
+    "created_at" timestamptz NOT NULL,
+    "started_at" timestamptz NULL,
+    "finished_at" timestamptz NULL,
+    CONSTRAINT page_history_imports_pk PRIMARY KEY ("id"),
+    CONSTRAINT page_history_imports_fk FOREIGN KEY ("page") REFERENCES public.social_network_pages("id")
+);
+
+CREATE INDEX page_history_imports_status_idx ON public.page_history_imports ("status", "id");
+
+CREATE TABLE public.social_network_events (
+    "id" int8 NOT NULL GENERATED BY DEFAULT AS identity,
+    "social_network" text NOT NULL,
+    "event_id" text NOT NULL,
+    "page" int4 NULL,
+    "remote_page_id" text NOT NULL,
+    "type" text NOT NULL,
+    "remote_post_id" text NULL,
+    "remote_comment_id" text NULL,
+    "author_id" text NULL,
+    "by_page" bool NOT NULL,
+    "text" text NULL,
+    "payload" jsonb NOT NULL,
+    "occurred_at" timestamptz NOT NULL,
+    "received_at" timestamptz NOT NULL,
+    CONSTRAINT social_network_events_pk PRIMARY KEY ("id"),
+    CONSTRAINT social_network_events_fk FOREIGN KEY ("page") REFERENCES public.social_network_pages("id"),
+    CONSTRAINT "SOCIAL_NETWORK_EVENTS_UNIQUE" UNIQUE ("social_network", "event_id")
+);
+
+CREATE INDEX social_network_events_page_idx ON public.social_network_events ("page", "id");
+
+CREATE TABLE public.project_settings (
+    "project" text NOT NULL,
+    "timezone" text NOT NULL,
+    CONSTRAINT project_settings_pk PRIMARY KEY ("project")
+);
+
+CREATE TABLE public.webhook_deliveries (
+    "id" int8 NOT NULL GENERATED BY DEFAULT AS identity,
+    "page" int4 NOT NULL,
+    "delivery_id" text NOT NULL,
+    "attempt" int4 NOT NULL,
+    "status_code" int4 NULL,
+    "response_body" text NOT NULL,
+    "error" text NULL,
+    "attempted_at" timestamptz NOT NULL,
+    CONSTRAINT webhook_deliveries_pk PRIMARY KEY ("id"),
+    CONSTRAINT webhook_deliveries_page_fk FOREIGN KEY ("page") REFERENCES public.social_network_pages("id")
+);
+
+CREATE INDEX webhook_deliveries_page_idx ON public.webhook_deliveries ("page", "attempted_at");
+
+CREATE TABLE public.scheduled_posts (
+    "id" int8 NOT NULL GENERATED BY DEFAULT AS identity,
+    "page" int4 NOT NULL,
+    "post_data" jsonb NOT NULL,
+    "requested_at" timestamptz NOT NULL,
+    "publish_at" timestamptz NOT NULL,
+    "auto" bool NOT NULL,
+    "status" text NOT NULL,
+    "remote_post_id" text NULL,
+    "error" text NULL,
+    "created_at" timestamptz NOT NULL,
+    "published_at" timestamptz NULL,
+    CONSTRAINT scheduled_posts_pk PRIMARY KEY ("id"),
+    CONSTRAINT scheduled_posts_page_fk FOREIGN KEY ("page") REFERENCES public.social_network_pages("id")
+);
 
+CREATE INDEX scheduled_posts_status_idx ON public.scheduled_posts ("status", "publish_at");
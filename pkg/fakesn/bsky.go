@@ -0,0 +1,249 @@
+package fakesn
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const bskyRefreshTokenPrefix = "BSKY-refresh-"
+
+type bskyXRPCError struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+type bskyRecordInput struct {
+	Repo       string          `json:"repo"`
+	Collection string          `json:"collection"`
+	Rkey       string          `json:"rkey"`
+	Record     json.RawMessage `json:"record"`
+}
+
+func (s *Server) bskyRoutes(r chi.Router) {
+	r.Post("/xrpc/com.atproto.server.createSession", s.bskyCreateSession)
+	r.Post("/xrpc/com.atproto.server.refreshSession", s.bskyRefreshSession)
+	r.Get("/xrpc/com.atproto.identity.resolveHandle", s.bskyResolveHandle)
+	r.Get("/xrpc/app.bsky.actor.getProfile", s.bskyXRPC("app.bsky.actor.getProfile", s.bskyGetProfile))
+	r.Post("/xrpc/com.atproto.repo.uploadBlob", s.bskyXRPC("com.atproto.repo.uploadBlob", s.bskyUploadBlob))
+	r.Post("/xrpc/com.atproto.repo.createRecord", s.bskyXRPC("com.atproto.repo.createRecord", s.bskyCreateRecord))
+	r.Post("/xrpc/com.atproto.repo.deleteRecord", s.bskyXRPC("com.atproto.repo.deleteRecord", s.bskyDeleteRecord))
+}
+
+func (s *Server) bskyCreateSession(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Identifier string `json:"identifier"`
+		Password   string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeJSON(w, http.StatusBadRequest, bskyXRPCError{Error: "InvalidRequest", Message: err.Error()})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account := s.networks[BSKY].Groups[0]
+	if input.Password == "" || (input.Identifier != bskyHandle(0) && input.Identifier != bskyDID(account)) {
+		writeJSON(w, http.StatusUnauthorized, bskyXRPCError{
+			Error:   "AuthenticationRequired",
+			Message: "Invalid identifier or password",
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, s.newBSKYSession(account))
+}
+
+func (s *Server) bskyRefreshSession(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	refreshJwt := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !strings.HasPrefix(refreshJwt, bskyRefreshTokenPrefix) || !s.checkToken(refreshJwt) {
+		writeJSON(w, http.StatusBadRequest, bskyXRPCError{Error: "ExpiredToken", Message: "Token has expired"})
+		return
+	}
+	delete(s.tokens, refreshJwt)
+	writeJSON(w, http.StatusOK, s.newBSKYSession(s.networks[BSKY].Groups[0]))
+}
+
+// bskyResolveHandle handle i-й группы - group{i}.bsky.local, первая группа - аккаунт сессии
+func (s *Server) bskyResolveHandle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	handle := r.URL.Query().Get("handle")
+	for i, group := range s.networks[BSKY].Groups {
+		if bskyHandle(i) == handle {
+			writeJSON(w, http.StatusOK, map[string]string{"did": bskyDID(group)})
+			return
+		}
+	}
+	writeJSON(w, http.StatusBadRequest, bskyXRPCError{Error: "InvalidRequest", Message: "Unable to resolve handle"})
+}
+
+// bskyXRPC проверяет неисправности и accessJwt перед вызовом метода
+func (s *Server) bskyXRPC(
+	method string,
+	handler func(w http.ResponseWriter, r *http.Request),
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if fault := s.takeFault(BSKY, method); fault != nil {
+			switch fault.Kind {
+			case FaultServerError:
+				w.WriteHeader(http.StatusInternalServerError)
+			case FaultRateLimit:
+				writeJSON(w, http.StatusTooManyRequests, bskyXRPCError{
+					Error:   "RateLimitExceeded",
+					Message: "Rate Limit Exceeded",
+				})
+			default:
+				writeJSON(w, http.StatusBadRequest, bskyXRPCError{Error: "ExpiredToken", Message: "Token has expired"})
+			}
+			return
+		}
+
+		accessJwt := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if strings.HasPrefix(accessJwt, bskyRefreshTokenPrefix) || !s.checkToken(accessJwt) {
+			writeJSON(w, http.StatusBadRequest, bskyXRPCError{Error: "ExpiredToken", Message: "Token has expired"})
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+func (s *Server) bskyGetProfile(w http.ResponseWriter, r *http.Request) {
+	actor := r.URL.Query().Get("actor")
+	for i, group := range s.networks[BSKY].Groups {
+		if bskyDID(group) == actor || bskyHandle(i) == actor {
+			writeJSON(w, http.StatusOK, map[string]interface{}{
+				"did":            bskyDID(group),
+				"handle":         bskyHandle(i),
+				"displayName":    group.Name,
+				"description":    group.Description,
+				"avatar":         group.PhotoURL,
+				"followersCount": group.Members,
+			})
+			return
+		}
+	}
+	writeJSON(w, http.StatusBadRequest, bskyXRPCError{Error: "InvalidRequest", Message: "Profile not found"})
+}
+
+func (s *Server) bskyUploadBlob(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil || len(body) == 0 {
+		writeJSON(w, http.StatusBadRequest, bskyXRPCError{Error: "InvalidRequest", Message: "Request body is empty"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"blob": map[string]interface{}{
+			"$type":    "blob",
+			"ref":      map[string]string{"$link": "bafkreifakesn" + strconv.Itoa(s.newID())},
+			"mimeType": r.Header.Get("Content-Type"),
+			"size":     len(body),
+		},
+	})
+}
+
+// bskyCreateRecord проверяет, что facets ссылаются на границы внутри текста в байтах
+func (s *Server) bskyCreateRecord(w http.ResponseWriter, r *http.Request) {
+	var (
+		input  bskyRecordInput
+		record struct {
+			Text   string `json:"text"`
+			Facets []struct {
+				Index struct {
+					ByteStart int `json:"byteStart"`
+					ByteEnd   int `json:"byteEnd"`
+				} `json:"index"`
+			} `json:"facets"`
+		}
+	)
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeJSON(w, http.StatusBadRequest, bskyXRPCError{Error: "InvalidRequest", Message: err.Error()})
+		return
+	}
+	if err := json.Unmarshal(input.Record, &record); err != nil {
+		writeJSON(w, http.StatusBadRequest, bskyXRPCError{Error: "InvalidRecord", Message: err.Error()})
+		return
+	}
+
+	state := s.networks[BSKY]
+	account := state.Groups[0]
+	if input.Repo != bskyDID(account) {
+		writeJSON(w, http.StatusBadRequest, bskyXRPCError{Error: "InvalidRequest", Message: "Unauthorized repo"})
+		return
+	}
+	for _, facet := range record.Facets {
+		if facet.Index.ByteStart < 0 || facet.Index.ByteStart >= facet.Index.ByteEnd ||
+			facet.Index.ByteEnd > len(record.Text) {
+			writeJSON(w, http.StatusBadRequest, bskyXRPCError{Error: "InvalidRecord", Message: "Invalid facet index"})
+			return
+		}
+	}
+
+	post := Post{
+		ID:        strconv.Itoa(s.newID()),
+		GroupID:   account.ID,
+		Text:      record.Text,
+		Record:    input.Record,
+		CreatedAt: time.Now(),
+	}
+	state.Posts[account.ID] = append(state.Posts[account.ID], post)
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"uri": "at://" + bskyDID(account) + "/" + input.Collection + "/" + post.ID,
+		"cid": "bafyreifakesn" + post.ID,
+	})
+}
+
+func (s *Server) bskyDeleteRecord(w http.ResponseWriter, r *http.Request) {
+	var input bskyRecordInput
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		writeJSON(w, http.StatusBadRequest, bskyXRPCError{Error: "InvalidRequest", Message: err.Error()})
+		return
+	}
+
+	state := s.networks[BSKY]
+	account := state.Groups[0]
+	posts := state.Posts[account.ID]
+	for i, post := range posts {
+		if input.Repo == bskyDID(account) && post.ID == input.Rkey {
+			state.Posts[account.ID] = append(posts[:i:i], posts[i+1:]...)
+			break
+		}
+	}
+	// Удаление отсутствующей записи в atproto не ошибка
+	writeJSON(w, http.StatusOK, map[string]interface{}{})
+}
+
+// newBSKYSession вызывается под s.mu
+func (s *Server) newBSKYSession(account Group) map[string]string {
+	refreshJwt := bskyRefreshTokenPrefix + strconv.Itoa(s.newID())
+	s.tokens[refreshJwt] = time.Time{}
+
+	return map[string]string{
+		"did":        bskyDID(account),
+		"handle":     bskyHandle(0),
+		"accessJwt":  s.issueToken(BSKY),
+		"refreshJwt": refreshJwt,
+	}
+}
+
+func bskyDID(group Group) string {
+	return "did:plc:fakesn" + group.ID
+}
+
+func bskyHandle(index int) string {
+	return "group" + strconv.Itoa(index+1) + ".bsky.local"
+}
@@ -0,0 +1,464 @@
+package fakesn
+
+import (
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fbTimeLayout формат дат Graph API
+const fbTimeLayout = "2006-01-02T15:04:05-0700"
+
+type fbAPIError struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Code    int    `json:"code"`
+}
+
+func (s *Server) fbRoutes(r chi.Router) {
+	r.Get("/{version}/dialog/oauth", func(w http.ResponseWriter, r *http.Request) {
+		redirectWithCode(w, r, "fb-code")
+	})
+	r.Get("/{version}/oauth/access_token", s.fbAccessToken)
+	r.Get("/{version}/debug_token", s.fbGraph(FB, "debug_token", s.fbDebugToken))
+	r.Get("/{version}/me/accounts", s.fbGraph(FB, "me/accounts", s.fbAccounts))
+	r.Get("/{version}/{page}", s.fbGraph(FB, "page", s.fbPage))
+	r.Post("/{version}/{page}/feed", s.fbGraph(FB, "feed", s.fbFeed))
+	r.Get("/{version}/{page}/posts", s.fbGraph(FB, "posts", s.fbPosts))
+	r.Get("/{version}/{object}/comments", s.fbGraph(FB, "comments", s.fbComments))
+	r.Post("/{version}/{object}/comments", s.fbGraph(FB, "comment", s.fbComment))
+	r.Post("/{version}/{object}", s.fbGraph(FB, "update", s.fbUpdate))
+	r.Delete("/{version}/{object}", s.fbGraph(FB, "delete", s.fbDelete))
+	r.Post("/{version}/{object}/replies", s.fbGraph(IG, "replies", s.igReply))
+	r.Get("/{version}/{igUser}/media", s.fbGraph(IG, "media_list", s.igMediaList))
+	r.Post("/{version}/{igUser}/media", s.fbGraph(IG, "media", s.igMedia))
+	r.Post("/{version}/{igUser}/media_publish", s.fbGraph(IG, "media_publish", s.igMediaPublish))
+}
+
+func (s *Server) fbAccessToken(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case q.Get("grant_type") == "fb_exchange_token":
+		if !s.checkToken(q.Get("fb_exchange_token")) {
+			writeFBError(w, FaultExpiredToken)
+			return
+		}
+	case q.Get("code") == "":
+		writeJSON(w, http.StatusBadRequest, map[string]fbAPIError{"error": {
+			Message: "Missing authorization code",
+			Type:    "OAuthException",
+			Code:    1,
+		}})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": s.issueToken(FB),
+		"token_type":   "bearer",
+		"expires_in":   int(s.options.TokenTTL.Seconds()),
+	})
+}
+
+// fbGraph проверяет неисправности, токен и appsecret_proof перед вызовом метода Graph API
+func (s *Server) fbGraph(
+	network string,
+	method string,
+	handler func(w http.ResponseWriter, r *http.Request),
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if fault := s.takeFault(network, method); fault != nil {
+			if fault.Kind == FaultServerError {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			writeFBError(w, fault.Kind)
+			return
+		}
+
+		if r.URL.Query().Get("appsecret_proof") == "" {
+			writeJSON(w, http.StatusBadRequest, map[string]fbAPIError{"error": {
+				Message: "API calls from the server require an appsecret_proof argument",
+				Type:    "GraphMethodException",
+				Code:    100,
+			}})
+			return
+		}
+
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" {
+			token = r.URL.Query().Get("access_token")
+		}
+		// Токен приложения вида app_id|app_secret принимается без выдачи
+		if !strings.Contains(token, "|") && !s.checkToken(token) {
+			writeFBError(w, FaultExpiredToken)
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+func (s *Server) fbDebugToken(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("input_token")
+	expiresAt, ok := s.tokens[token]
+
+	data := map[string]interface{}{
+		"is_valid":   s.checkToken(token),
+		"expires_at": 0,
+	}
+	if ok && !expiresAt.IsZero() {
+		data["expires_at"] = expiresAt.Unix()
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": data})
+}
+
+func (s *Server) fbAccounts(w http.ResponseWriter, r *http.Request) {
+	state := s.networks[FB]
+	offset, _ := strconv.Atoi(r.URL.Query().Get("after"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit == 0 {
+		limit = 25
+	}
+
+	data := []map[string]string{}
+	for i := offset; i < len(state.Groups) && i < offset+limit; i++ {
+		// Токены страниц бессрочные
+		pageToken := "FB-page-token-" + state.Groups[i].ID
+		s.tokens[pageToken] = time.Time{}
+		data = append(data, map[string]string{
+			"id":           state.Groups[i].ID,
+			"name":         state.Groups[i].Name,
+			"access_token": pageToken,
+		})
+	}
+
+	after := offset + len(data)
+	paging := map[string]interface{}{
+		"cursors": map[string]string{
+			"before": strconv.Itoa(offset),
+			"after":  strconv.Itoa(after),
+		},
+	}
+	if after < len(state.Groups) {
+		paging["next"] = r.URL.Path + "?after=" + strconv.Itoa(after)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"data":   data,
+		"paging": paging,
+	})
+}
+
+func (s *Server) fbPage(w http.ResponseWriter, r *http.Request) {
+	objectID := chi.URLParam(r, "page")
+	// Под тем же путем отдаются посты страниц, медиа и контейнеры Instagram
+	if _, ok := s.igContainers[objectID]; ok {
+		writeJSON(w, http.StatusOK, map[string]string{
+			"id":          objectID,
+			"status_code": "FINISHED",
+		})
+		return
+	}
+	if post := findPost(s.networks[FB], objectID); post != nil {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"id":        post.ID,
+			"message":   post.Text,
+			"reactions": fbSummary(post.Metrics.Likes),
+			"comments":  fbSummary(post.Metrics.Comments),
+			"shares":    map[string]int{"count": post.Metrics.Reposts},
+			"insights": fbInsights(map[string]int{
+				"post_impressions":        post.Metrics.Views,
+				"post_impressions_unique": post.Metrics.Reach,
+			}),
+		})
+		return
+	}
+	if post := findPost(s.networks[IG], objectID); post != nil {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"id":             post.ID,
+			"caption":        post.Text,
+			"like_count":     post.Metrics.Likes,
+			"comments_count": post.Metrics.Comments,
+			"insights": fbInsights(map[string]int{
+				"impressions": post.Metrics.Views,
+				"reach":       post.Metrics.Reach,
+			}),
+		})
+		return
+	}
+
+	if igGroup := findGroup(s.networks[IG], objectID); igGroup != nil {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"id":              igGroup.ID,
+			"name":            igGroup.Name,
+			"followers_count": igGroup.Members,
+		})
+		return
+	}
+
+	group := findGroup(s.networks[FB], objectID)
+	if group == nil {
+		writeFBObjectNotFound(w)
+		return
+	}
+
+	page := map[string]interface{}{
+		"id":        group.ID,
+		"name":      group.Name,
+		"fan_count": group.Members,
+	}
+	if strings.Contains(r.URL.Query().Get("fields"), "instagram_business_account") && group.InstagramID != "" {
+		igGroup := findGroup(s.networks[IG], group.InstagramID)
+		page["instagram_business_account"] = map[string]string{
+			"id":                  igGroup.ID,
+			"username":            strings.ReplaceAll(strings.ToLower(igGroup.Name), " ", "_"),
+			"name":                igGroup.Name,
+			"biography":           igGroup.Description,
+			"profile_picture_url": igGroup.PhotoURL,
+		}
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (s *Server) fbFeed(w http.ResponseWriter, r *http.Request) {
+	state := s.networks[FB]
+	groupID := chi.URLParam(r, "page")
+	if findGroup(state, groupID) == nil {
+		writeJSON(w, http.StatusBadRequest, map[string]fbAPIError{"error": {
+			Message: "Unsupported post request",
+			Type:    "GraphMethodException",
+			Code:    100,
+		}})
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	post := Post{
+		ID:        groupID + "_" + strconv.Itoa(s.newID()),
+		GroupID:   groupID,
+		Text:      r.Form.Get("message"),
+		Link:      r.Form.Get("link"),
+		CreatedAt: time.Now(),
+	}
+	state.Posts[groupID] = append(state.Posts[groupID], post)
+
+	writeJSON(w, http.StatusOK, map[string]string{"id": post.ID})
+}
+
+// fbPosts лента страницы от новых к старым, курсор after - смещение
+func (s *Server) fbPosts(w http.ResponseWriter, r *http.Request) {
+	state := s.networks[FB]
+	groupID := chi.URLParam(r, "page")
+	if findGroup(state, groupID) == nil {
+		writeFBObjectNotFound(w)
+		return
+	}
+
+	var since time.Time
+	if sinceUnix, err := strconv.ParseInt(r.URL.Query().Get("since"), 10, 64); err == nil {
+		since = time.Unix(sinceUnix, 0)
+	}
+	var posts []Post
+	for _, post := range newestPosts(state, groupID) {
+		if !post.CreatedAt.Before(since) {
+			posts = append(posts, post)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, fbPagedData(r, posts, func(post Post) map[string]string {
+		item := map[string]string{
+			"id":           post.ID,
+			"message":      post.Text,
+			"created_time": post.CreatedAt.UTC().Format(fbTimeLayout),
+		}
+		if len(post.Images) > 0 {
+			item["full_picture"] = post.Images[0]
+		}
+		return item
+	}))
+}
+
+// fbComments комментарии поста страницы, как с filter=stream, или медиа Instagram от старых к новым
+func (s *Server) fbComments(w http.ResponseWriter, r *http.Request) {
+	objectID := chi.URLParam(r, "object")
+	if findPost(s.networks[IG], objectID) != nil {
+		s.igComments(w, r)
+		return
+	}
+	state := s.networks[FB]
+	if findPost(state, objectID) == nil {
+		writeFBObjectNotFound(w)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, fbPagedData(r, postComments(state, objectID), func(comment Comment) map[string]interface{} {
+		item := map[string]interface{}{
+			"id":           comment.ID,
+			"from":         map[string]string{"id": comment.AuthorID, "name": comment.AuthorName},
+			"message":      comment.Text,
+			"created_time": comment.CreatedAt.UTC().Format(fbTimeLayout),
+			"is_hidden":    comment.Hidden,
+		}
+		if comment.ParentID != "" {
+			item["parent"] = map[string]string{"id": comment.ParentID}
+		}
+		return item
+	}))
+}
+
+// fbComment комментарий к посту или ответ на комментарий от имени страницы
+func (s *Server) fbComment(w http.ResponseWriter, r *http.Request) {
+	state := s.networks[FB]
+	objectID := chi.URLParam(r, "object")
+	comment := Comment{PostID: objectID}
+	if parent := findComment(state, objectID); parent != nil {
+		comment.PostID = parent.PostID
+		comment.ParentID = parent.ID
+		// Ответы в Facebook одноуровневые
+		if parent.ParentID != "" {
+			comment.ParentID = parent.ParentID
+		}
+	}
+	post := findPost(state, comment.PostID)
+	if post == nil {
+		writeFBObjectNotFound(w)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	group := findGroup(state, post.GroupID)
+	comment.AuthorID = group.ID
+	comment.AuthorName = group.Name
+	comment.ByPage = true
+	comment.Text = r.Form.Get("message")
+	comment = s.addComment(state, comment)
+
+	writeJSON(w, http.StatusOK, map[string]string{"id": comment.ID})
+}
+
+// fbUpdate скрывает комментарий: is_hidden у Facebook, hide у Instagram
+func (s *Server) fbUpdate(w http.ResponseWriter, r *http.Request) {
+	objectID := chi.URLParam(r, "object")
+	q := r.URL.Query()
+	comment, param := findComment(s.networks[FB], objectID), "is_hidden"
+	if comment == nil {
+		comment, param = findComment(s.networks[IG], objectID), "hide"
+	}
+	if comment == nil {
+		writeFBObjectNotFound(w)
+		return
+	}
+	hidden, err := strconv.ParseBool(q.Get(param))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]fbAPIError{"error": {
+			Message: "(#100) Param " + param + " must be a boolean",
+			Type:    "OAuthException",
+			Code:    100,
+		}})
+		return
+	}
+	comment.Hidden = hidden
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+// fbDelete удаляет пост Facebook или комментарий Facebook или Instagram
+func (s *Server) fbDelete(w http.ResponseWriter, r *http.Request) {
+	objectID := chi.URLParam(r, "object")
+	if !deletePost(s.networks[FB], objectID) &&
+		!deleteComment(s.networks[FB], objectID) &&
+		!deleteComment(s.networks[IG], objectID) {
+		writeFBObjectNotFound(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"success": true})
+}
+
+// fbPagedData порция списка в формате Graph API с cursors.after и next
+func fbPagedData[T, V any](r *http.Request, items []T, toItem func(T) V) map[string]interface{} {
+	offset, _ := strconv.Atoi(r.URL.Query().Get("after"))
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit == 0 {
+		limit = 25
+	}
+
+	data := []V{}
+	for i := offset; i < len(items) && i < offset+limit; i++ {
+		data = append(data, toItem(items[i]))
+	}
+
+	after := offset + len(data)
+	paging := map[string]interface{}{
+		"cursors": map[string]string{
+			"before": strconv.Itoa(offset),
+			"after":  strconv.Itoa(after),
+		},
+	}
+	if after < len(items) {
+		paging["next"] = r.URL.Path + "?after=" + strconv.Itoa(after)
+	}
+
+	return map[string]interface{}{
+		"data":   data,
+		"paging": paging,
+	}
+}
+
+func writeFBObjectNotFound(w http.ResponseWriter) {
+	writeJSON(w, http.StatusBadRequest, map[string]fbAPIError{"error": {
+		Message: "Unsupported get request. Object does not exist",
+		Type:    "GraphMethodException",
+		Code:    100,
+	}})
+}
+
+func writeFBError(w http.ResponseWriter, kind FaultKind) {
+	apiErr := fbAPIError{
+		Message: "Error validating access token: Session has expired",
+		Type:    "OAuthException",
+		Code:    190,
+	}
+	if kind == FaultRateLimit {
+		apiErr = fbAPIError{
+			Message: "Application request limit reached",
+			Type:    "OAuthException",
+			Code:    4,
+		}
+	}
+	writeJSON(w, http.StatusBadRequest, map[string]fbAPIError{"error": apiErr})
+}
+
+// fbSummary счетчик edge, запрошенного с summary(total_count)
+func fbSummary(totalCount int) map[string]interface{} {
+	return map[string]interface{}{
+		"data":    []interface{}{},
+		"summary": map[string]int{"total_count": totalCount},
+	}
+}
+
+// fbInsights edge insights по значениям метрик
+func fbInsights(metrics map[string]int) map[string]interface{} {
+	data := []map[string]interface{}{}
+	for name, value := range metrics {
+		data = append(data, map[string]interface{}{
+			"name":   name,
+			"period": "lifetime",
+			"values": []map[string]int{{"value": value}},
+		})
+	}
+	return map[string]interface{}{"data": data}
+}
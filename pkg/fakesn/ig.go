@@ -0,0 +1,185 @@
+package fakesn
+
+import (
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type igContainer struct {
+	IGUserID       string
+	ImageURL       string
+	Caption        string
+	IsCarouselItem bool
+	Children       []string
+}
+
+// igMedia создает контейнер изображения, элемента карусели или карусели
+func (s *Server) igMedia(w http.ResponseWriter, r *http.Request) {
+	igUserID := chi.URLParam(r, "igUser")
+	if findGroup(s.networks[IG], igUserID) == nil {
+		writeFBObjectNotFound(w)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	container := igContainer{
+		IGUserID:       igUserID,
+		ImageURL:       r.Form.Get("image_url"),
+		Caption:        r.Form.Get("caption"),
+		IsCarouselItem: r.Form.Get("is_carousel_item") == "true",
+	}
+	if r.Form.Get("media_type") == "CAROUSEL" {
+		for _, childID := range splitFormList(r.Form["children"]) {
+			child, ok := s.igContainers[childID]
+			if !ok || !child.IsCarouselItem || child.IGUserID != igUserID {
+				writeIGParamError(w, "Invalid children container "+childID)
+				return
+			}
+		}
+		container.Children = splitFormList(r.Form["children"])
+	} else if container.ImageURL == "" {
+		writeIGParamError(w, "Only photo or video can be accepted as media type")
+		return
+	}
+
+	containerID := strconv.Itoa(s.newID())
+	s.igContainers[containerID] = container
+	writeJSON(w, http.StatusOK, map[string]string{"id": containerID})
+}
+
+func (s *Server) igMediaPublish(w http.ResponseWriter, r *http.Request) {
+	igUserID := chi.URLParam(r, "igUser")
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	containerID := r.Form.Get("creation_id")
+	container, ok := s.igContainers[containerID]
+	if !ok || container.IGUserID != igUserID || container.IsCarouselItem {
+		writeIGParamError(w, "Media ID is not available")
+		return
+	}
+	delete(s.igContainers, containerID)
+
+	post := Post{
+		ID:        strconv.Itoa(s.newID()),
+		GroupID:   igUserID,
+		Text:      container.Caption,
+		CreatedAt: time.Now(),
+	}
+	if container.ImageURL != "" {
+		post.Images = append(post.Images, container.ImageURL)
+	}
+	for _, childID := range container.Children {
+		post.Images = append(post.Images, s.igContainers[childID].ImageURL)
+		delete(s.igContainers, childID)
+	}
+	state := s.networks[IG]
+	state.Posts[igUserID] = append(state.Posts[igUserID], post)
+
+	writeJSON(w, http.StatusOK, map[string]string{"id": post.ID})
+}
+
+// igMediaList медиа аккаунта от новых к старым
+func (s *Server) igMediaList(w http.ResponseWriter, r *http.Request) {
+	state := s.networks[IG]
+	igUserID := chi.URLParam(r, "igUser")
+	if findGroup(state, igUserID) == nil {
+		writeFBObjectNotFound(w)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, fbPagedData(r, newestPosts(state, igUserID), func(post Post) map[string]string {
+		item := map[string]string{
+			"id":         post.ID,
+			"caption":    post.Text,
+			"timestamp":  post.CreatedAt.UTC().Format(fbTimeLayout),
+			"media_type": "IMAGE",
+		}
+		if len(post.Images) > 1 {
+			item["media_type"] = "CAROUSEL_ALBUM"
+		}
+		if len(post.Images) > 0 {
+			item["media_url"] = post.Images[0]
+		}
+		return item
+	}))
+}
+
+func writeIGParamError(w http.ResponseWriter, message string) {
+	writeJSON(w, http.StatusBadRequest, map[string]fbAPIError{"error": {
+		Message: message,
+		Type:    "OAuthException",
+		Code:    9004,
+	}})
+}
+
+// igComments комментарии первого уровня медиа от старых к новым, ответы в replies
+func (s *Server) igComments(w http.ResponseWriter, r *http.Request) {
+	state := s.networks[IG]
+	var comments []Comment
+	repliesByParent := map[string][]Comment{}
+	for _, comment := range postComments(state, chi.URLParam(r, "object")) {
+		if comment.ParentID == "" {
+			comments = append(comments, comment)
+		} else {
+			repliesByParent[comment.ParentID] = append(repliesByParent[comment.ParentID], comment)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, fbPagedData(r, comments, func(comment Comment) map[string]interface{} {
+		replies := []map[string]interface{}{}
+		for _, reply := range repliesByParent[comment.ID] {
+			replies = append(replies, igCommentItem(reply))
+		}
+		item := igCommentItem(comment)
+		item["replies"] = map[string]interface{}{"data": replies}
+		return item
+	}))
+}
+
+// igReply ответ на комментарий от имени аккаунта, ответы в Instagram одноуровневые
+func (s *Server) igReply(w http.ResponseWriter, r *http.Request) {
+	state := s.networks[IG]
+	parent := findComment(state, chi.URLParam(r, "object"))
+	if parent == nil {
+		writeFBObjectNotFound(w)
+		return
+	}
+	if parent.ParentID != "" {
+		writeIGParamError(w, "Cannot reply to a reply")
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	group := findGroup(state, findPost(state, parent.PostID).GroupID)
+	comment := s.addComment(state, Comment{
+		PostID:     parent.PostID,
+		ParentID:   parent.ID,
+		AuthorID:   group.ID,
+		AuthorName: group.Name,
+		ByPage:     true,
+		Text:       r.Form.Get("message"),
+	})
+
+	writeJSON(w, http.StatusOK, map[string]string{"id": comment.ID})
+}
+
+func igCommentItem(comment Comment) map[string]interface{} {
+	return map[string]interface{}{
+		"id":        comment.ID,
+		"text":      comment.Text,
+		"timestamp": comment.CreatedAt.UTC().Format(fbTimeLayout),
+		"from":      map[string]string{"id": comment.AuthorID, "username": comment.AuthorName},
+		"hidden":    comment.Hidden,
+	}
+}
@@ -0,0 +1,212 @@
+package fakesn
+
+import (
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type mastodonMedia struct {
+	ID          string `json:"id"`
+	FileName    string `json:"-"`
+	Description string `json:"description"`
+	// Processed видео обрабатывается до первого запроса /api/v1/media/{id}
+	Processed bool `json:"-"`
+}
+
+func (s *Server) mastodonRoutes(r chi.Router) {
+	r.Get("/oauth/authorize", func(w http.ResponseWriter, r *http.Request) {
+		redirectWithCode(w, r, "mastodon-code")
+	})
+	r.Post("/oauth/token", s.mastodonToken)
+	r.Get("/api/v1/accounts/verify_credentials", s.mastodonAPI("verify_credentials", s.mastodonVerifyCredentials))
+	r.Post("/api/v2/media", s.mastodonAPI("media", s.mastodonUploadMedia))
+	r.Get("/api/v1/media/{id}", s.mastodonAPI("media", s.mastodonGetMedia))
+	r.Post("/api/v1/statuses", s.mastodonAPI("statuses", s.mastodonCreateStatus))
+	r.Delete("/api/v1/statuses/{id}", s.mastodonAPI("statuses", s.mastodonDeleteStatus))
+}
+
+func (s *Server) mastodonToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if r.Form.Get("code") == "" || r.Form.Get("client_id") == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error":             "invalid_grant",
+			"error_description": "The provided authorization grant is invalid, expired, revoked, does not match the redirection URI used in the authorization request, or was issued to another client.",
+		})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Токены Mastodon бессрочные
+	token := "MASTODON-token-" + strconv.Itoa(s.newID())
+	s.tokens[token] = time.Time{}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": token,
+		"token_type":   "Bearer",
+		"scope":        r.Form.Get("scope"),
+		"created_at":   time.Now().Unix(),
+	})
+}
+
+// mastodonAPI проверяет неисправности и Bearer токен перед вызовом метода
+func (s *Server) mastodonAPI(
+	method string,
+	handler func(w http.ResponseWriter, r *http.Request),
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		if fault := s.takeFault(MASTODON, method); fault != nil {
+			switch fault.Kind {
+			case FaultServerError:
+				w.WriteHeader(http.StatusInternalServerError)
+			case FaultRateLimit:
+				writeJSON(w, http.StatusTooManyRequests, map[string]string{"error": "Too many requests"})
+			default:
+				writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "The access token is invalid"})
+			}
+			return
+		}
+		if !s.checkToken(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")) {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "The access token is invalid"})
+			return
+		}
+
+		handler(w, r)
+	}
+}
+
+func (s *Server) mastodonVerifyCredentials(w http.ResponseWriter, _ *http.Request) {
+	account := s.networks[MASTODON].Groups[0]
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":              account.ID,
+		"username":        "fakesn",
+		"acct":            "fakesn",
+		"display_name":    account.Name,
+		"note":            "<p>" + account.Description + "</p>",
+		"avatar":          account.PhotoURL,
+		"followers_count": account.Members,
+	})
+}
+
+// mastodonUploadMedia изображения доступны сразу, видео возвращается в обработке с кодом 202
+func (s *Server) mastodonUploadMedia(w http.ResponseWriter, r *http.Request) {
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "Validation failed: File can't be blank"})
+		return
+	}
+	_ = file.Close()
+
+	media := &mastodonMedia{
+		ID:          strconv.Itoa(s.newID()),
+		FileName:    header.Filename,
+		Description: r.FormValue("description"),
+		Processed:   !strings.HasSuffix(header.Filename, ".mp4"),
+	}
+	s.mastodonMedia[media.ID] = media
+
+	if !media.Processed {
+		writeJSON(w, http.StatusAccepted, mastodonMediaBody(media))
+		return
+	}
+	writeJSON(w, http.StatusOK, mastodonMediaBody(media))
+}
+
+func (s *Server) mastodonGetMedia(w http.ResponseWriter, r *http.Request) {
+	media, ok := s.mastodonMedia[chi.URLParam(r, "id")]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "Record not found"})
+		return
+	}
+	media.Processed = true
+	writeJSON(w, http.StatusOK, mastodonMediaBody(media))
+}
+
+func (s *Server) mastodonCreateStatus(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	account := s.networks[MASTODON].Groups[0]
+	post := Post{
+		ID:          strconv.Itoa(s.newID()),
+		GroupID:     account.ID,
+		Text:        r.Form.Get("status"),
+		SpoilerText: r.Form.Get("spoiler_text"),
+		Visibility:  r.Form.Get("visibility"),
+		CreatedAt:   time.Now(),
+	}
+	if post.Visibility == "" {
+		post.Visibility = "public"
+	}
+	for _, mediaID := range r.Form["media_ids[]"] {
+		media, ok := s.mastodonMedia[mediaID]
+		if !ok || !media.Processed {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "Cannot attach files that have not finished processing. Try again in a moment!"})
+			return
+		}
+		post.Images = append(post.Images, media.FileName)
+	}
+	if post.Text == "" && len(post.Images) == 0 {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "Validation failed: Text can't be blank"})
+		return
+	}
+
+	state := s.networks[MASTODON]
+	state.Posts[account.ID] = append(state.Posts[account.ID], post)
+	writeJSON(w, http.StatusOK, mastodonStatusBody(post))
+}
+
+func (s *Server) mastodonDeleteStatus(w http.ResponseWriter, r *http.Request) {
+	state := s.networks[MASTODON]
+	account := state.Groups[0]
+	statusID := chi.URLParam(r, "id")
+
+	posts := state.Posts[account.ID]
+	for i, post := range posts {
+		if post.ID == statusID {
+			state.Posts[account.ID] = append(posts[:i:i], posts[i+1:]...)
+			writeJSON(w, http.StatusOK, mastodonStatusBody(post))
+			return
+		}
+	}
+	writeJSON(w, http.StatusNotFound, map[string]string{"error": "Record not found"})
+}
+
+func mastodonMediaBody(media *mastodonMedia) map[string]interface{} {
+	mediaType := "image"
+	if strings.HasSuffix(media.FileName, ".mp4") {
+		mediaType = "video"
+	}
+	body := map[string]interface{}{
+		"id":          media.ID,
+		"type":        mediaType,
+		"description": media.Description,
+		"url":         nil,
+	}
+	if media.Processed {
+		body["url"] = "https://fakesn.local/MASTODON/media/" + media.FileName
+	}
+	return body
+}
+
+func mastodonStatusBody(post Post) map[string]interface{} {
+	return map[string]interface{}{
+		"id":           post.ID,
+		"content":      post.Text,
+		"spoiler_text": post.SpoilerText,
+		"visibility":   post.Visibility,
+		"created_at":   post.CreatedAt,
+	}
+}
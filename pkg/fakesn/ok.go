@@ -0,0 +1,284 @@
+package fakesn
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type okAPIError struct {
+	ErrorCode int    `json:"error_code"`
+	ErrorMsg  string `json:"error_msg"`
+}
+
+type okAttachment struct {
+	Media []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+		URL  string `json:"url"`
+	} `json:"media"`
+}
+
+func (s *Server) okRoutes(r chi.Router) {
+	r.Get("/oauth/authorize", func(w http.ResponseWriter, r *http.Request) {
+		redirectWithCode(w, r, "ok-code")
+	})
+	r.Post("/oauth/token.do", s.okAccessToken)
+	r.HandleFunc("/api/{group}/{method}", s.okMethod)
+}
+
+func (s *Server) okAccessToken(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("code") == "" {
+		writeJSON(w, http.StatusOK, map[string]string{
+			"error":             "invalid_grant",
+			"error_description": "Expired code",
+		})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"access_token":  s.issueToken(OK),
+		"refresh_token": s.issueToken(OK),
+		"expires_in":    strconv.Itoa(int(s.options.TokenTTL.Seconds())),
+	})
+}
+
+func (s *Server) okMethod(w http.ResponseWriter, r *http.Request) {
+	method := chi.URLParam(r, "group") + "." + chi.URLParam(r, "method")
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if fault := s.takeFault(OK, method); fault != nil {
+		switch fault.Kind {
+		case FaultServerError:
+			w.WriteHeader(http.StatusInternalServerError)
+		case FaultRateLimit:
+			writeJSON(w, http.StatusOK, okAPIError{ErrorCode: 1, ErrorMsg: "LIMIT : Rate limit exceeded"})
+		default:
+			writeJSON(w, http.StatusOK, okAPIError{ErrorCode: 102, ErrorMsg: "PARAM_SESSION_EXPIRED"})
+		}
+		return
+	}
+	if !s.checkToken(r.Form.Get("access_token")) {
+		writeJSON(w, http.StatusOK, okAPIError{ErrorCode: 102, ErrorMsg: "PARAM_SESSION_EXPIRED"})
+		return
+	}
+
+	state := s.networks[OK]
+	switch method {
+	case "group.getUserGroupsV2":
+		offset, _ := strconv.Atoi(r.Form.Get("anchor"))
+		count, _ := strconv.Atoi(r.Form.Get("count"))
+		if count == 0 {
+			count = 100
+		}
+		groups := []map[string]string{}
+		for i := offset; i < len(state.Groups) && i < offset+count; i++ {
+			groups = append(groups, map[string]string{
+				"groupId": state.Groups[i].ID,
+				"status":  "ADMIN",
+			})
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"groups": groups,
+			"anchor": strconv.Itoa(offset + len(groups)),
+		})
+	case "group.getInfo":
+		info := []map[string]interface{}{}
+		for _, groupID := range splitFormList(r.Form["uids"]) {
+			if group := findGroup(state, groupID); group != nil {
+				info = append(info, map[string]interface{}{
+					"uid":           group.ID,
+					"name":          group.Name,
+					"description":   group.Description,
+					"photo_id":      group.PhotoID,
+					"members_count": group.Members,
+				})
+			}
+		}
+		writeJSON(w, http.StatusOK, info)
+	case "photos.getInfo":
+		photos := []map[string]string{}
+		for _, photoID := range splitFormList(r.Form["photo_ids"]) {
+			for _, group := range state.Groups {
+				if group.PhotoID == photoID {
+					photos = append(photos, map[string]string{
+						"id":         group.PhotoID,
+						"pic128x128": group.PhotoURL,
+					})
+				}
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"photos": photos})
+	case "mediatopic.post":
+		var attachment okAttachment
+		if err := json.Unmarshal([]byte(r.Form.Get("attachment")), &attachment); err != nil {
+			writeJSON(w, http.StatusOK, okAPIError{ErrorCode: 100, ErrorMsg: "PARAM : Invalid attachment"})
+			return
+		}
+		groupID := r.Form.Get("gid")
+		if findGroup(state, groupID) == nil {
+			writeJSON(w, http.StatusOK, okAPIError{ErrorCode: 160, ErrorMsg: "GROUP : Group not found"})
+			return
+		}
+		post := Post{
+			ID:        strconv.Itoa(s.newID()),
+			GroupID:   groupID,
+			CreatedAt: time.Now(),
+		}
+		for _, media := range attachment.Media {
+			switch media.Type {
+			case "text":
+				post.Text = media.Text
+			case "link":
+				post.Link = media.URL
+			}
+		}
+		state.Posts[groupID] = append(state.Posts[groupID], post)
+		writeJSON(w, http.StatusOK, post.ID)
+	case "mediatopic.getTopics":
+		groupID := r.Form.Get("gid")
+		if findGroup(state, groupID) == nil {
+			writeJSON(w, http.StatusOK, okAPIError{ErrorCode: 160, ErrorMsg: "GROUP : Group not found"})
+			return
+		}
+		offset, _ := strconv.Atoi(r.Form.Get("anchor"))
+		count, _ := strconv.Atoi(r.Form.Get("count"))
+		if count == 0 {
+			count = 20
+		}
+		posts := newestPosts(state, groupID)
+		topics := []map[string]interface{}{}
+		for i := offset; i < len(posts) && i < offset+count; i++ {
+			media := []map[string]string{{"type": "text", "text": posts[i].Text}}
+			if posts[i].Link != "" {
+				media = append(media, map[string]string{"type": "link", "url": posts[i].Link})
+			}
+			topics = append(topics, map[string]interface{}{
+				"id":         posts[i].ID,
+				"created_ms": posts[i].CreatedAt.UnixMilli(),
+				"media":      media,
+			})
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"media_topics": topics,
+			"anchor":       strconv.Itoa(offset + len(topics)),
+			"has_more":     offset+len(topics) < len(posts),
+		})
+	case "mediatopic.getByIds":
+		// Удаленных тем в ответе нет
+		topics := []map[string]interface{}{}
+		for _, topicID := range splitFormList(r.Form["topic_ids"]) {
+			post := findPost(state, topicID)
+			if post == nil {
+				continue
+			}
+			media := []map[string]string{{"type": "text", "text": post.Text}}
+			if post.Link != "" {
+				media = append(media, map[string]string{"type": "link", "url": post.Link})
+			}
+			topics = append(topics, map[string]interface{}{
+				"id":                 post.ID,
+				"media":              media,
+				"like_summary":       map[string]int{"count": post.Metrics.Likes},
+				"reshare_summary":    map[string]int{"count": post.Metrics.Reposts},
+				"discussion_summary": map[string]int{"comments_count": post.Metrics.Comments},
+				"views_count":        post.Metrics.Views,
+			})
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"media_topics": topics})
+	case "discussions.getComments":
+		post := findPost(state, r.Form.Get("discussionId"))
+		if post == nil || r.Form.Get("discussionType") != "GROUP_TOPIC" {
+			writeJSON(w, http.StatusOK, okAPIError{ErrorCode: 300, ErrorMsg: "NOT_FOUND : Discussion not found"})
+			return
+		}
+		offset, _ := strconv.Atoi(r.Form.Get("anchor"))
+		count, _ := strconv.Atoi(r.Form.Get("count"))
+		if count == 0 {
+			count = 20
+		}
+		topicComments := postComments(state, post.ID)
+		comments := []map[string]interface{}{}
+		for i := offset; i < len(topicComments) && i < offset+count; i++ {
+			authorType := "USER"
+			if topicComments[i].ByPage {
+				authorType = "GROUP"
+			}
+			comment := map[string]interface{}{
+				"id":          topicComments[i].ID,
+				"text":        topicComments[i].Text,
+				"author_id":   topicComments[i].AuthorID,
+				"author_name": topicComments[i].AuthorName,
+				"author_type": authorType,
+				"date_ms":     topicComments[i].CreatedAt.UnixMilli(),
+			}
+			if topicComments[i].ParentID != "" {
+				comment["reply_to_comment_id"] = topicComments[i].ParentID
+			}
+			comments = append(comments, comment)
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"comments": comments,
+			"anchor":   strconv.Itoa(offset + len(comments)),
+			"has_more": offset+len(comments) < len(topicComments),
+		})
+	case "discussions.comment":
+		post := findPost(state, r.Form.Get("discussionId"))
+		if post == nil || r.Form.Get("discussionType") != "GROUP_TOPIC" {
+			writeJSON(w, http.StatusOK, okAPIError{ErrorCode: 300, ErrorMsg: "NOT_FOUND : Discussion not found"})
+			return
+		}
+		comment := Comment{
+			PostID:     post.ID,
+			ParentID:   r.Form.Get("reply_to_comment_id"),
+			AuthorID:   "1",
+			AuthorName: "Admin",
+			Text:       r.Form.Get("comment"),
+		}
+		if comment.ParentID != "" {
+			if parent := findComment(state, comment.ParentID); parent == nil || parent.PostID != post.ID {
+				writeJSON(w, http.StatusOK, okAPIError{ErrorCode: 300, ErrorMsg: "NOT_FOUND : Comment not found"})
+				return
+			}
+		}
+		if r.Form.Get("as_admin") == "true" {
+			group := findGroup(state, post.GroupID)
+			comment.AuthorID = group.ID
+			comment.AuthorName = group.Name
+			comment.ByPage = true
+		}
+		comment = s.addComment(state, comment)
+		writeJSON(w, http.StatusOK, comment.ID)
+	case "discussions.deleteComment":
+		comment := findComment(state, r.Form.Get("commentId"))
+		if comment == nil || comment.PostID != r.Form.Get("discussionId") {
+			writeJSON(w, http.StatusOK, okAPIError{ErrorCode: 300, ErrorMsg: "NOT_FOUND : Comment not found"})
+			return
+		}
+		deleteComment(state, comment.ID)
+		writeJSON(w, http.StatusOK, true)
+	default:
+		writeJSON(w, http.StatusOK, okAPIError{ErrorCode: 3, ErrorMsg: "METHOD : Method does not exist"})
+	}
+}
+
+// splitFormList принимает как повторяющиеся параметры, так и значения через запятую
+func splitFormList(values []string) []string {
+	var list []string
+	for _, value := range values {
+		list = append(list, strings.Split(value, ",")...)
+	}
+	return list
+}
@@ -0,0 +1,571 @@
+// Package fakesn эмулирует API VK, OK, FB, Instagram через Graph API, инстанса Mastodon и PDS Bluesky,
+// которые используют клиенты соц сетей,
+// для локальной разработки и интеграционных тестов.
+// Состояние хранится в памяти, ошибки соц сетей подмешиваются через InjectFault.
+package fakesn
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	VK = "VK"
+	OK = "OK"
+	FB = "FB"
+	IG = "IG"
+	// MASTODON инстанс с единственным аккаунтом, его адрес MastodonInstanceURL
+	MASTODON = "MASTODON"
+	// BSKY PDS с единственным аккаунтом, пароль приложения подходит любой непустой
+	BSKY = "BSKY"
+)
+
+type FaultKind string
+
+const (
+	// FaultRateLimit ответ соц сети о превышении лимита запросов
+	FaultRateLimit FaultKind = "rate_limit"
+	// FaultExpiredToken ответ соц сети об истекшем токене
+	FaultExpiredToken FaultKind = "expired_token"
+	// FaultServerError ответ 500 без тела соц сети
+	FaultServerError FaultKind = "server_error"
+)
+
+// Fault ошибка, которую вернет метод соц сети. Пустой Method подходит под любой метод,
+// Times - число срабатываний, 0 - пока не вызван ClearFaults.
+// After - число подходящих вызовов, которые проходят успешно до первого срабатывания
+type Fault struct {
+	Network string    `json:"network"`
+	Method  string    `json:"method"`
+	Kind    FaultKind `json:"kind"`
+	Times   int       `json:"times"`
+	After   int       `json:"after"`
+}
+
+type Options struct {
+	// GroupsPerNetwork число групп, которые администрирует пользователь каждой соц сети
+	GroupsPerNetwork int
+	// TokenTTL время жизни выданных токенов, 0 - бессрочные
+	TokenTTL time.Duration
+}
+
+type Group struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	PhotoID     string `json:"photoId"`
+	PhotoURL    string `json:"photoUrl"`
+	Members     int    `json:"members"`
+	// InstagramID Instagram Business аккаунт, подключенный к странице FB
+	InstagramID string `json:"instagramId,omitempty"`
+}
+
+type Post struct {
+	ID      string   `json:"id"`
+	GroupID string   `json:"groupId"`
+	Text    string   `json:"text"`
+	Link    string   `json:"link,omitempty"`
+	Images  []string `json:"images,omitempty"`
+	// SpoilerText и Visibility заполняются только для Mastodon
+	SpoilerText string `json:"spoilerText,omitempty"`
+	Visibility  string `json:"visibility,omitempty"`
+	// Record запись Bluesky как ее прислал клиент
+	Record    json.RawMessage `json:"record,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+	// Metrics счетчики, которые отдают методы получения постов
+	Metrics PostMetrics `json:"metrics"`
+}
+
+// PostMetrics счетчики поста, задаются через SetPostMetrics
+type PostMetrics struct {
+	Likes    int `json:"likes"`
+	Reposts  int `json:"reposts"`
+	Comments int `json:"comments"`
+	Views    int `json:"views"`
+	Reach    int `json:"reach"`
+}
+
+// Comment комментарий к посту, ParentID - комментарий, на который это ответ
+type Comment struct {
+	ID         string    `json:"id"`
+	PostID     string    `json:"postId"`
+	ParentID   string    `json:"parentId,omitempty"`
+	AuthorID   string    `json:"authorId"`
+	AuthorName string    `json:"authorName"`
+	ByPage     bool      `json:"byPage"`
+	Text       string    `json:"text"`
+	CreatedAt  time.Time `json:"createdAt"`
+	// Hidden комментарий скрыт администратором страницы
+	Hidden bool `json:"hidden,omitempty"`
+}
+
+type networkState struct {
+	Groups   []Group           `json:"groups"`
+	Posts    map[string][]Post `json:"posts"`
+	Comments []Comment         `json:"comments"`
+	// DeletedComments удаленные комментарии, VK позволяет их восстановить
+	DeletedComments []Comment `json:"deletedComments,omitempty"`
+}
+
+type Server struct {
+	mu       sync.Mutex
+	options  Options
+	networks map[string]*networkState
+	tokens   map[string]time.Time
+	faults   []*Fault
+	nextID   int
+	// igContainers контейнеры Instagram, созданные в /media и еще не опубликованные
+	igContainers map[string]igContainer
+	// mastodonMedia загруженные в Mastodon вложения
+	mastodonMedia map[string]*mastodonMedia
+	router        *chi.Mux
+}
+
+func New(options Options) *Server {
+	if options.GroupsPerNetwork == 0 {
+		options.GroupsPerNetwork = 3
+	}
+
+	s := &Server{
+		options: options,
+		router:  chi.NewRouter(),
+	}
+	s.reset()
+
+	s.router.Route("/vk", s.vkRoutes)
+	s.router.Route("/ok", s.okRoutes)
+	s.router.Route("/fb", s.fbRoutes)
+	s.router.Route("/mastodon", s.mastodonRoutes)
+	s.router.Route("/bsky", s.bskyRoutes)
+	s.router.Route("/_fakesn", func(r chi.Router) {
+		r.Get("/state", s.handleState)
+		r.Get("/files/{name}", s.handleFile)
+		r.Post("/reset", s.handleReset)
+		r.Post("/faults", s.handleInjectFault)
+		r.Delete("/faults", s.handleClearFaults)
+	})
+
+	return s
+}
+
+// NewTestServer запускает эмулятор на httptest.Server, закрывать сервер должен вызывающий
+func NewTestServer(options Options) (*Server, *httptest.Server) {
+	s := New(options)
+	return s, httptest.NewServer(s)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.router.ServeHTTP(w, r)
+}
+
+// MastodonInstanceURL адрес инстанса Mastodon для instance_url в credentials аккаунта
+func MastodonInstanceURL(baseURL string) string {
+	return baseURL + "/mastodon"
+}
+
+// FileURL ссылка на сгенерированный файл, который можно прикрепить к посту
+func FileURL(baseURL, name string) string {
+	return baseURL + "/_fakesn/files/" + name
+}
+
+// Env переменные окружения приложения, направляющие клиентов соц сетей на эмулятор по baseURL
+func Env(baseURL string) map[string]string {
+	return map[string]string{
+		"VK_AUTH_API_URL": baseURL + "/vk/oauth",
+		"VK_API_URL":      baseURL + "/vk",
+		"OK_AUTH_API_URL": baseURL + "/ok",
+		"OK_API_URL":      baseURL + "/ok",
+		"FB_AUTH_API_URL": baseURL + "/fb",
+		"FB_API_URL":      baseURL + "/fb",
+		"IG_AUTH_API_URL": baseURL + "/fb",
+		"IG_API_URL":      baseURL + "/fb",
+		"BSKY_API_URL":    baseURL + "/bsky",
+	}
+}
+
+func (s *Server) InjectFault(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, &fault)
+}
+
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = nil
+}
+
+// ExpireTokens делает недействительными все выданные токены
+func (s *Server) ExpireTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for token := range s.tokens {
+		s.tokens[token] = time.Unix(1, 0)
+	}
+}
+
+func (s *Server) Groups(network string) []Group {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Group(nil), s.networks[network].Groups...)
+}
+
+func (s *Server) Posts(network, groupID string) []Post {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Post(nil), s.networks[network].Posts[groupID]...)
+}
+
+// SetGroupMembers меняет число участников группы, как если бы на нее подписались или отписались
+func (s *Server) SetGroupMembers(network, groupID string, members int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	group := findGroup(s.networks[network], groupID)
+	if group == nil {
+		return false
+	}
+	group.Members = members
+	return true
+}
+
+// EditPost меняет текст поста, как если бы его отредактировали прямо в соц сети
+func (s *Server) EditPost(network, postID, text string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	post := findPost(s.networks[network], postID)
+	if post == nil {
+		return false
+	}
+	post.Text = text
+	return true
+}
+
+// SetPostMetrics задает счетчики поста, как если бы их набрал пост в соц сети
+func (s *Server) SetPostMetrics(network, postID string, metrics PostMetrics) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	post := findPost(s.networks[network], postID)
+	if post == nil {
+		return false
+	}
+	post.Metrics = metrics
+	return true
+}
+
+// AddComment добавляет комментарий подписчика к посту, как если бы его оставили в соц сети
+func (s *Server) AddComment(network, postID, authorName, text string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := s.networks[network]
+	if findPost(state, postID) == nil {
+		return "", false
+	}
+	comment := s.addComment(state, Comment{
+		PostID:     postID,
+		AuthorID:   strconv.Itoa(s.newID()),
+		AuthorName: authorName,
+		Text:       text,
+	})
+	return comment.ID, true
+}
+
+// Comments комментарии поста от старых к новым
+func (s *Server) Comments(network, postID string) []Comment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return postComments(s.networks[network], postID)
+}
+
+// DeletePost удаляет пост, как если бы его удалили прямо в соц сети
+func (s *Server) DeletePost(network, postID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return deletePost(s.networks[network], postID)
+}
+
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reset()
+}
+
+func (s *Server) reset() {
+	s.networks = map[string]*networkState{}
+	s.tokens = map[string]time.Time{}
+	s.faults = nil
+	s.nextID = 1000
+	s.igContainers = map[string]igContainer{}
+	s.mastodonMedia = map[string]*mastodonMedia{}
+
+	for _, network := range []string{VK, OK, FB, IG, MASTODON, BSKY} {
+		state := &networkState{
+			Posts: map[string][]Post{},
+		}
+		for i := 1; i <= s.options.GroupsPerNetwork; i++ {
+			id := strconv.Itoa(s.newID())
+			photoID := strconv.Itoa(s.newID())
+			state.Groups = append(state.Groups, Group{
+				ID:          id,
+				Name:        fmt.Sprintf("%s group %d", network, i),
+				Description: fmt.Sprintf("Fake %s group %d", network, i),
+				PhotoID:     photoID,
+				PhotoURL:    fmt.Sprintf("https://fakesn.local/%s/photos/%s.jpg", network, photoID),
+				Members:     100 * i,
+			})
+		}
+		s.networks[network] = state
+	}
+
+	// Instagram подключен ко всем страницам FB, кроме последней
+	fbGroups := s.networks[FB].Groups
+	for i := 0; i < len(fbGroups)-1; i++ {
+		fbGroups[i].InstagramID = s.networks[IG].Groups[i].ID
+	}
+}
+
+func (s *Server) newID() int {
+	s.nextID++
+	return s.nextID
+}
+
+// findPost вызывается под s.mu
+func findPost(state *networkState, postID string) *Post {
+	for _, posts := range state.Posts {
+		for i := range posts {
+			if posts[i].ID == postID {
+				return &posts[i]
+			}
+		}
+	}
+	return nil
+}
+
+// addComment назначает комментарию id и время, вызывается под s.mu
+func (s *Server) addComment(state *networkState, comment Comment) Comment {
+	comment.ID = strconv.Itoa(s.newID())
+	comment.CreatedAt = time.Now()
+	state.Comments = append(state.Comments, comment)
+	return comment
+}
+
+// findComment вызывается под s.mu
+func findComment(state *networkState, commentID string) *Comment {
+	for i := range state.Comments {
+		if state.Comments[i].ID == commentID {
+			return &state.Comments[i]
+		}
+	}
+	return nil
+}
+
+// deleteComment удаляет комментарий вместе со всеми ответами на него, вызывается под s.mu
+func deletePost(state *networkState, postID string) bool {
+	for groupID, posts := range state.Posts {
+		for i := range posts {
+			if posts[i].ID == postID {
+				state.Posts[groupID] = append(posts[:i], posts[i+1:]...)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func deleteComment(state *networkState, commentID string) bool {
+	deletedIDs := map[string]bool{}
+	for _, comment := range state.Comments {
+		// Ответы всегда добавляются после комментария, на который отвечают
+		if comment.ID == commentID || deletedIDs[comment.ParentID] {
+			deletedIDs[comment.ID] = true
+		}
+	}
+	if !deletedIDs[commentID] {
+		return false
+	}
+
+	comments := state.Comments[:0]
+	for _, comment := range state.Comments {
+		if deletedIDs[comment.ID] {
+			state.DeletedComments = append(state.DeletedComments, comment)
+		} else {
+			comments = append(comments, comment)
+		}
+	}
+	state.Comments = comments
+	return true
+}
+
+// restoreComment возвращает удаленный комментарий вместе с ответами, удаленными вместе с ним, вызывается под s.mu
+func restoreComment(state *networkState, commentID string) bool {
+	restoredIDs := map[string]bool{}
+	for _, comment := range state.DeletedComments {
+		if comment.ID == commentID || restoredIDs[comment.ParentID] {
+			restoredIDs[comment.ID] = true
+		}
+	}
+	if !restoredIDs[commentID] {
+		return false
+	}
+
+	deleted := state.DeletedComments[:0]
+	for _, comment := range state.DeletedComments {
+		if restoredIDs[comment.ID] {
+			state.Comments = append(state.Comments, comment)
+		} else {
+			deleted = append(deleted, comment)
+		}
+	}
+	state.DeletedComments = deleted
+	// id назначаются по возрастанию, порядок по ним совпадает с порядком добавления
+	sort.SliceStable(state.Comments, func(i, j int) bool {
+		left, _ := strconv.Atoi(state.Comments[i].ID)
+		right, _ := strconv.Atoi(state.Comments[j].ID)
+		return left < right
+	})
+	return true
+}
+
+// postComments комментарии поста от старых к новым, вызывается под s.mu
+func postComments(state *networkState, postID string) []Comment {
+	var comments []Comment
+	for _, comment := range state.Comments {
+		if comment.PostID == postID {
+			comments = append(comments, comment)
+		}
+	}
+	return comments
+}
+
+// newestPosts посты группы от новых к старым, вызывается под s.mu
+func newestPosts(state *networkState, groupID string) []Post {
+	posts := state.Posts[groupID]
+	newest := make([]Post, 0, len(posts))
+	for i := len(posts) - 1; i >= 0; i-- {
+		newest = append(newest, posts[i])
+	}
+	return newest
+}
+
+// issueToken вызывается под s.mu
+func (s *Server) issueToken(network string) string {
+	token := fmt.Sprintf("%s-token-%d", network, s.newID())
+	expiresAt := time.Time{}
+	if s.options.TokenTTL > 0 {
+		expiresAt = time.Now().Add(s.options.TokenTTL)
+	}
+	s.tokens[token] = expiresAt
+	return token
+}
+
+// checkToken вызывается под s.mu
+func (s *Server) checkToken(token string) bool {
+	expiresAt, ok := s.tokens[token]
+	if !ok {
+		return false
+	}
+	return expiresAt.IsZero() || time.Now().Before(expiresAt)
+}
+
+// takeFault вызывается под s.mu
+func (s *Server) takeFault(network, method string) *Fault {
+	for i, fault := range s.faults {
+		if fault.Network != network || (fault.Method != "" && fault.Method != method) {
+			continue
+		}
+		if fault.After > 0 {
+			fault.After--
+			continue
+		}
+		if fault.Times > 0 {
+			fault.Times--
+			if fault.Times == 0 {
+				s.faults = append(s.faults[:i], s.faults[i+1:]...)
+			}
+		}
+		return fault
+	}
+	return nil
+}
+
+func (s *Server) handleState(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"networks": s.networks,
+		"faults":   s.faults,
+	})
+}
+
+func (s *Server) handleReset(w http.ResponseWriter, _ *http.Request) {
+	s.Reset()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleInjectFault(w http.ResponseWriter, r *http.Request) {
+	var fault Fault
+	if err := json.NewDecoder(r.Body).Decode(&fault); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.InjectFault(fault)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleClearFaults(w http.ResponseWriter, _ *http.Request) {
+	s.ClearFaults()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleFile отдает несколько байт с типом по расширению имени
+func (s *Server) handleFile(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write([]byte("fakesn file " + name))
+}
+
+// redirectWithCode эмулирует согласие пользователя в OAuth диалоге
+func redirectWithCode(w http.ResponseWriter, r *http.Request, code string) {
+	redirectURI := r.URL.Query().Get("redirect_uri")
+	if redirectURI == "" {
+		http.Error(w, "redirect_uri is required", http.StatusBadRequest)
+		return
+	}
+	separator := "?"
+	if strings.Contains(redirectURI, "?") {
+		separator = "&"
+	}
+	http.Redirect(w, r, redirectURI+separator+"code="+code, http.StatusFound)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
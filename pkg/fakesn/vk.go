@@ -0,0 +1,459 @@
+package fakesn
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type vkAPIError struct {
+	ErrorCode int    `json:"error_code"`
+	ErrorMsg  string `json:"error_msg"`
+	Method    string `json:"method,omitempty"`
+}
+
+func (s *Server) vkRoutes(r chi.Router) {
+	r.Get("/oauth/authorize", func(w http.ResponseWriter, r *http.Request) {
+		redirectWithCode(w, r, "vk-code")
+	})
+	r.Get("/oauth/access_token", s.vkAccessToken)
+	r.HandleFunc("/method/{method}", s.vkMethod)
+}
+
+func (s *Server) vkAccessToken(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("code") == "" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{
+			"error":             "invalid_grant",
+			"error_description": "Code is invalid or expired.",
+		})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": s.issueToken(VK),
+		"expires_in":   int(s.options.TokenTTL.Seconds()),
+		"user_id":      1,
+	})
+}
+
+func (s *Server) vkMethod(w http.ResponseWriter, r *http.Request) {
+	method := chi.URLParam(r, "method")
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	params := map[string]string{}
+	for key := range r.Form {
+		params[key] = r.Form.Get(key)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if fault := s.takeFault(VK, method); fault != nil && fault.Kind == FaultServerError {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	} else if fault != nil {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"error": vkFaultError(fault.Kind)})
+		return
+	}
+	if !s.checkToken(params["access_token"]) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"error": vkFaultError(FaultExpiredToken)})
+		return
+	}
+
+	if method == "execute" {
+		s.vkExecute(w, params["code"])
+		return
+	}
+
+	response, apiErr := s.vkCall(method, params)
+	if apiErr != nil {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"error": apiErr})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"response": response})
+}
+
+// vkExecute разбирает код вида return [API.method({...}), ...]; который собирает клиент
+func (s *Server) vkExecute(w http.ResponseWriter, code string) {
+	calls, err := parseVKExecuteCode(code)
+	if err != nil {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"error": vkAPIError{
+			ErrorCode: 12,
+			ErrorMsg:  fmt.Sprintf("Unable to compile code: %s", err),
+		}})
+		return
+	}
+
+	var (
+		responses     []interface{}
+		executeErrors []vkAPIError
+	)
+	for _, call := range calls {
+		if fault := s.takeFault(VK, call.method); fault != nil {
+			apiErr := vkFaultError(fault.Kind)
+			apiErr.Method = call.method
+			executeErrors = append(executeErrors, apiErr)
+			responses = append(responses, false)
+			continue
+		}
+
+		response, apiErr := s.vkCall(call.method, call.params)
+		if apiErr != nil {
+			apiErr.Method = call.method
+			executeErrors = append(executeErrors, *apiErr)
+			responses = append(responses, false)
+			continue
+		}
+		responses = append(responses, response)
+	}
+
+	body := map[string]interface{}{"response": responses}
+	if len(executeErrors) > 0 {
+		body["execute_errors"] = executeErrors
+	}
+	writeJSON(w, http.StatusOK, body)
+}
+
+// vkCall вызывается под s.mu
+func (s *Server) vkCall(method string, params map[string]string) (interface{}, *vkAPIError) {
+	state := s.networks[VK]
+
+	switch method {
+	case "groups.get":
+		offset, _ := strconv.Atoi(params["offset"])
+		count, _ := strconv.Atoi(params["count"])
+		if count == 0 {
+			count = 1000
+		}
+		items := []map[string]interface{}{}
+		for i := offset; i < len(state.Groups) && i < offset+count; i++ {
+			items = append(items, vkGroupItem(state.Groups[i]))
+		}
+		return map[string]interface{}{
+			"count": len(state.Groups),
+			"items": items,
+		}, nil
+	case "groups.getById":
+		items := []map[string]interface{}{}
+		for _, groupID := range strings.Split(params["group_ids"], ",") {
+			if group := findGroup(state, groupID); group != nil {
+				items = append(items, vkGroupItem(*group))
+			}
+		}
+		return items, nil
+	case "wall.post":
+		groupID := strings.TrimPrefix(params["owner_id"], "-")
+		if findGroup(state, groupID) == nil {
+			return nil, &vkAPIError{ErrorCode: 214, ErrorMsg: "Access to adding post denied"}
+		}
+		post := Post{
+			ID:        strconv.Itoa(s.newID()),
+			GroupID:   groupID,
+			Text:      params["message"],
+			Link:      params["attachments"],
+			CreatedAt: time.Now(),
+		}
+		state.Posts[groupID] = append(state.Posts[groupID], post)
+		postID, _ := strconv.Atoi(post.ID)
+		return map[string]int{"post_id": postID}, nil
+	case "wall.getById":
+		// Удаленных постов в ответе нет
+		items := []map[string]interface{}{}
+		for _, wallPostID := range strings.Split(params["posts"], ",") {
+			ownerID, postID, _ := strings.Cut(wallPostID, "_")
+			post := findPost(state, postID)
+			if post == nil || post.GroupID != strings.TrimPrefix(ownerID, "-") {
+				continue
+			}
+			id, _ := strconv.Atoi(post.ID)
+			owner, _ := strconv.Atoi(ownerID)
+			items = append(items, map[string]interface{}{
+				"id":       id,
+				"owner_id": owner,
+				"text":     post.Text,
+				"date":     post.CreatedAt.Unix(),
+				"likes":    map[string]int{"count": post.Metrics.Likes},
+				"reposts":  map[string]int{"count": post.Metrics.Reposts},
+				"comments": map[string]int{"count": post.Metrics.Comments},
+				"views":    map[string]int{"count": post.Metrics.Views},
+			})
+		}
+		return items, nil
+	case "wall.get":
+		groupID := strings.TrimPrefix(params["owner_id"], "-")
+		if findGroup(state, groupID) == nil {
+			return nil, &vkAPIError{ErrorCode: 15, ErrorMsg: "Access denied"}
+		}
+		offset, _ := strconv.Atoi(params["offset"])
+		count, _ := strconv.Atoi(params["count"])
+		if count == 0 {
+			count = 20
+		}
+		posts := newestPosts(state, groupID)
+		items := []map[string]interface{}{}
+		for i := offset; i < len(posts) && i < offset+count; i++ {
+			id, _ := strconv.Atoi(posts[i].ID)
+			attachments := []map[string]interface{}{}
+			for _, image := range posts[i].Images {
+				attachments = append(attachments, map[string]interface{}{
+					"type":  "photo",
+					"photo": map[string]interface{}{"sizes": []map[string]interface{}{{"url": image, "width": 604}}},
+				})
+			}
+			if posts[i].Link != "" {
+				attachments = append(attachments, map[string]interface{}{
+					"type": "link",
+					"link": map[string]string{"url": posts[i].Link},
+				})
+			}
+			items = append(items, map[string]interface{}{
+				"id":          id,
+				"date":        posts[i].CreatedAt.Unix(),
+				"text":        posts[i].Text,
+				"attachments": attachments,
+			})
+		}
+		return map[string]interface{}{
+			"count": len(posts),
+			"items": items,
+		}, nil
+	case "wall.getComments":
+		// Без comment_id возвращаются комментарии первого уровня с ответами в thread,
+		// с comment_id - ответы на этот комментарий
+		groupID := strings.TrimPrefix(params["owner_id"], "-")
+		post := findPost(state, params["post_id"])
+		if post == nil || post.GroupID != groupID {
+			return nil, &vkAPIError{ErrorCode: 100, ErrorMsg: "One of the parameters specified was missing or invalid: post not found"}
+		}
+		offset, _ := strconv.Atoi(params["offset"])
+		count, _ := strconv.Atoi(params["count"])
+		if count == 0 {
+			count = 10
+		}
+		threadItemsCount, _ := strconv.Atoi(params["thread_items_count"])
+
+		var comments []Comment
+		repliesByParent := map[string][]Comment{}
+		for _, comment := range postComments(state, post.ID) {
+			if comment.ParentID == params["comment_id"] {
+				comments = append(comments, comment)
+			}
+			if comment.ParentID != "" {
+				repliesByParent[comment.ParentID] = append(repliesByParent[comment.ParentID], comment)
+			}
+		}
+
+		items := []map[string]interface{}{}
+		profiles := []map[string]interface{}{}
+		for i := offset; i < len(comments) && i < offset+count; i++ {
+			item := vkCommentItem(comments[i], groupID)
+			if params["comment_id"] == "" {
+				replies := repliesByParent[comments[i].ID]
+				threadItems := []map[string]interface{}{}
+				for j := 0; j < len(replies) && j < threadItemsCount; j++ {
+					threadItems = append(threadItems, vkCommentItem(replies[j], groupID))
+				}
+				item["thread"] = map[string]interface{}{
+					"count": len(replies),
+					"items": threadItems,
+				}
+			}
+			items = append(items, item)
+		}
+		seenAuthors := map[string]bool{}
+		for _, comment := range postComments(state, post.ID) {
+			if !comment.ByPage && !seenAuthors[comment.AuthorID] {
+				seenAuthors[comment.AuthorID] = true
+				id, _ := strconv.Atoi(comment.AuthorID)
+				profiles = append(profiles, map[string]interface{}{
+					"id":         id,
+					"first_name": comment.AuthorName,
+					"last_name":  "",
+				})
+			}
+		}
+		group := findGroup(state, groupID)
+		id, _ := strconv.Atoi(group.ID)
+		return map[string]interface{}{
+			"count":    len(comments),
+			"items":    items,
+			"profiles": profiles,
+			"groups":   []map[string]interface{}{{"id": id, "name": group.Name}},
+		}, nil
+	case "wall.createComment":
+		groupID := strings.TrimPrefix(params["owner_id"], "-")
+		post := findPost(state, params["post_id"])
+		if post == nil || post.GroupID != groupID {
+			return nil, &vkAPIError{ErrorCode: 100, ErrorMsg: "One of the parameters specified was missing or invalid: post not found"}
+		}
+		comment := Comment{
+			PostID:     post.ID,
+			ParentID:   params["reply_to_comment"],
+			AuthorID:   "1",
+			AuthorName: "Admin",
+			Text:       params["message"],
+		}
+		if comment.ParentID != "" {
+			parent := findComment(state, comment.ParentID)
+			if parent == nil || parent.PostID != post.ID {
+				return nil, &vkAPIError{ErrorCode: 100, ErrorMsg: "One of the parameters specified was missing or invalid: reply_to_comment"}
+			}
+			// Ответы в VK одноуровневые, ответ на ответ попадает в ветку комментария
+			if parent.ParentID != "" {
+				comment.ParentID = parent.ParentID
+			}
+		}
+		if params["from_group"] != "" && params["from_group"] != "0" {
+			group := findGroup(state, groupID)
+			comment.AuthorID = group.ID
+			comment.AuthorName = group.Name
+			comment.ByPage = true
+		}
+		comment = s.addComment(state, comment)
+		commentID, _ := strconv.Atoi(comment.ID)
+		return map[string]int{"comment_id": commentID}, nil
+	case "wall.deleteComment":
+		comment := findComment(state, params["comment_id"])
+		if comment == nil {
+			return nil, &vkAPIError{ErrorCode: 100, ErrorMsg: "One of the parameters specified was missing or invalid: comment not found"}
+		}
+		if post := findPost(state, comment.PostID); post == nil || post.GroupID != strings.TrimPrefix(params["owner_id"], "-") {
+			return nil, &vkAPIError{ErrorCode: 15, ErrorMsg: "Access denied"}
+		}
+		deleteComment(state, comment.ID)
+		return 1, nil
+	case "wall.restoreComment":
+		if !restoreComment(state, params["comment_id"]) {
+			return nil, &vkAPIError{ErrorCode: 100, ErrorMsg: "One of the parameters specified was missing or invalid: comment not found"}
+		}
+		return 1, nil
+	case "stats.getPostReach":
+		groupID := strings.TrimPrefix(params["owner_id"], "-")
+		var reach []map[string]int
+		for _, post := range state.Posts[groupID] {
+			for _, postID := range strings.Split(params["post_ids"], ",") {
+				if post.ID == postID {
+					id, _ := strconv.Atoi(post.ID)
+					reach = append(reach, map[string]int{
+						"post_id":           id,
+						"reach_total":       len(post.Text) * 10,
+						"reach_subscribers": len(post.Text) * 5,
+					})
+				}
+			}
+		}
+		return reach, nil
+	default:
+		return nil, &vkAPIError{ErrorCode: 3, ErrorMsg: "Unknown method passed."}
+	}
+}
+
+func vkGroupItem(group Group) map[string]interface{} {
+	id, _ := strconv.Atoi(group.ID)
+	return map[string]interface{}{
+		"id":            id,
+		"name":          group.Name,
+		"description":   group.Description,
+		"photo_200":     group.PhotoURL,
+		"members_count": group.Members,
+	}
+}
+
+// vkCommentItem комментарий от имени сообщества подписан отрицательным id группы
+func vkCommentItem(comment Comment, groupID string) map[string]interface{} {
+	id, _ := strconv.Atoi(comment.ID)
+	postID, _ := strconv.Atoi(comment.PostID)
+	fromID, _ := strconv.Atoi(comment.AuthorID)
+	if comment.ByPage {
+		fromID = -fromID
+	}
+	ownerID, _ := strconv.Atoi(groupID)
+	item := map[string]interface{}{
+		"id":       id,
+		"from_id":  fromID,
+		"post_id":  postID,
+		"owner_id": -ownerID,
+		"date":     comment.CreatedAt.Unix(),
+		"text":     comment.Text,
+	}
+	if comment.ParentID != "" {
+		parentID, _ := strconv.Atoi(comment.ParentID)
+		item["parents_stack"] = []int{parentID}
+	}
+	return item
+}
+
+func vkFaultError(kind FaultKind) vkAPIError {
+	switch kind {
+	case FaultRateLimit:
+		return vkAPIError{ErrorCode: 6, ErrorMsg: "Too many requests per second"}
+	default:
+		return vkAPIError{ErrorCode: 5, ErrorMsg: "User authorization failed: access_token has expired."}
+	}
+}
+
+type vkExecuteCall struct {
+	method string
+	params map[string]string
+}
+
+func parseVKExecuteCode(code string) ([]vkExecuteCall, error) {
+	code = strings.TrimSpace(code)
+	if !strings.HasPrefix(code, "return [") || !strings.HasSuffix(code, "];") {
+		return nil, fmt.Errorf("expected return [...];")
+	}
+	rest := strings.TrimSuffix(strings.TrimPrefix(code, "return ["), "];")
+
+	var calls []vkExecuteCall
+	for strings.TrimSpace(rest) != "" {
+		rest = strings.TrimLeft(rest, " ,")
+		if !strings.HasPrefix(rest, "API.") {
+			return nil, fmt.Errorf("expected API call at %q", rest)
+		}
+		rest = strings.TrimPrefix(rest, "API.")
+		openIndex := strings.Index(rest, "(")
+		if openIndex < 0 {
+			return nil, fmt.Errorf("expected ( after method")
+		}
+		method := rest[:openIndex]
+		rest = rest[openIndex+1:]
+
+		var rawParams map[string]interface{}
+		decoder := json.NewDecoder(strings.NewReader(rest))
+		if err := decoder.Decode(&rawParams); err != nil {
+			return nil, fmt.Errorf("invalid %s params: %w", method, err)
+		}
+		rest = strings.TrimLeft(rest[decoder.InputOffset():], " ")
+		if !strings.HasPrefix(rest, ")") {
+			return nil, fmt.Errorf("expected ) after %s params", method)
+		}
+		rest = rest[1:]
+
+		params := make(map[string]string, len(rawParams))
+		for key, value := range rawParams {
+			params[key] = fmt.Sprint(value)
+		}
+		calls = append(calls, vkExecuteCall{method: method, params: params})
+	}
+
+	return calls, nil
+}
+
+// findGroup вызывается под s.mu
+func findGroup(state *networkState, groupID string) *Group {
+	for i := range state.Groups {
+		if state.Groups[i].ID == groupID {
+			return &state.Groups[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package textdiff
+
+import "strings"
+
+// Lines построчная разница в стиле unified diff без заголовков: "-" удаленная строка,
+// "+" добавленная, " " общая. Для одинаковых текстов возвращает пустую строку
+func Lines(oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+	oldLines := strings.Split(oldText, "\n")
+	newLines := strings.Split(newText, "\n")
+
+	// lcs[i][j] длина общей подпоследовательности oldLines[i:] и newLines[j:]
+	lcs := make([][]int, len(oldLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var diff []string
+	i, j := 0, 0
+	for i < len(oldLines) && j < len(newLines) {
+		switch {
+		case oldLines[i] == newLines[j]:
+			diff = append(diff, " "+oldLines[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, "-"+oldLines[i])
+			i++
+		default:
+			diff = append(diff, "+"+newLines[j])
+			j++
+		}
+	}
+	for ; i < len(oldLines); i++ {
+		diff = append(diff, "-"+oldLines[i])
+	}
+	for ; j < len(newLines); j++ {
+		diff = append(diff, "+"+newLines[j])
+	}
+
+	return strings.Join(diff, "\n")
+}